@@ -18,6 +18,18 @@ const (
 	ArgAccessToken = "access-token"
 	// ArgContext is the name of the auth context
 	ArgContext = "context"
+	// ArgMaxRetries is the number of times a rate limited or failed request is retried.
+	ArgMaxRetries = "max-retries"
+	// ArgPaginationWorkers is the number of pages of a list fetched concurrently.
+	ArgPaginationWorkers = "pagination-workers"
+	// ArgTimeout is the time allowed for a command's API calls.
+	ArgTimeout = "timeout"
+	// ArgDryRun prints requests that would change resources instead of sending them.
+	ArgDryRun = "dry-run"
+	// ArgRecord is the path of a cassette file to record requests and responses to.
+	ArgRecord = "record"
+	// ArgReplay is the path of a cassette file to replay responses from.
+	ArgReplay = "replay"
 	// ArgDefaultContext is the default auth context
 	ArgDefaultContext = "default"
 	// ArgActionID is an action id argument.
@@ -42,6 +54,8 @@ const (
 	ArgVPCID = "vpc-id"
 	// ArgCommandWait is a wait for a resource to be created argument.
 	ArgCommandWait = "wait"
+	// ArgWaitTimeout is the maximum time to wait for actions argument.
+	ArgWaitTimeout = "wait-timeout"
 	// ArgServerID is a server id argument.
 	ArgServerID = "server-id"
 	// ArgServerIDs is a list of server IDs.
@@ -116,6 +130,14 @@ const (
 	ArgUserData = "user-data"
 	// ArgUserDataFile is a user data file location argument.
 	ArgUserDataFile = "user-data-file"
+	// ArgUserDataTemplate renders user data as a template.
+	ArgUserDataTemplate = "user-data-template"
+	// ArgUserDataVar is a value of user data templates.
+	ArgUserDataVar = "user-data-var"
+	// ArgUserDataValues is the path to a file of values of user data templates.
+	ArgUserDataValues = "user-data-values"
+	// ArgUserDataGzip compresses user data.
+	ArgUserDataGzip = "user-data-gzip"
 	// ArgImageName name is an image name argument.
 	ArgImageName = "image-name"
 	// ArgImageExternalURL is a URL that returns an image file.
@@ -138,6 +160,10 @@ const (
 	ArgFormat = "format"
 	// ArgNoHeader hides the output header.
 	ArgNoHeader = "no-header"
+	// ArgSortBy is columns to sort listed rows by argument.
+	ArgSortBy = "sort-by"
+	// ArgFilter is conditions listed rows must match argument.
+	ArgFilter = "filter"
 	// ArgPollTime is how long before the next poll argument.
 	ArgPollTime = "poll-timeout"
 	// ArgTagName is a tag name
@@ -257,6 +283,13 @@ const (
 	// ArgForce forces confirmation on actions
 	ArgForce = "force"
 
+	// ArgStackFile is the path to a stack spec file.
+	ArgStackFile = "file"
+	// ArgStackPrune deletes resources that are not declared in a stack spec.
+	ArgStackPrune = "prune"
+	// ArgConcurrency is the number of resources created at once.
+	ArgConcurrency = "concurrency"
+
 	// ArgObjectName is the Kubernetes object name
 	ArgObjectName = "name"
 	// ArgObjectNamespace is the Kubernetes object namespace
@@ -275,4 +308,18 @@ const (
 	ArgReadWrite = "read-write"
 	// ArgRegistryExpirySeconds indicates the length of time the token will be valid in seconds.
 	ArgRegistryExpirySeconds = "expiry-seconds"
+
+	// ArgListen is the address the fake API listens on.
+	ArgListen = "listen"
+	// ArgFakeAPIToken is the only access token the fake API accepts.
+	ArgFakeAPIToken = "token"
+	// ArgActionDuration is how long the fake API's actions stay in progress.
+	ArgActionDuration = "action-duration"
+	// ArgRateLimit is the number of requests per hour the fake API allows.
+	ArgRateLimit = "rate-limit"
+
+	// ArgCredentialStore is where an auth context's access token is stored.
+	ArgCredentialStore = "credential-store"
+	// ArgCredentialHelper is the command an auth context's access token is fetched with.
+	ArgCredentialHelper = "credential-helper"
 )
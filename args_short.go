@@ -16,4 +16,6 @@ package blcli
 const (
 	// ArgShortForce forces confirmation on actions
 	ArgShortForce = "f"
+	// ArgShortFile is the path to a file of resources to create
+	ArgShortFile = "f"
 )
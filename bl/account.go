@@ -36,19 +36,21 @@ type AccountService interface {
 
 type accountService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ AccountService = &accountService{}
 
 // NewAccountService builds an AccountService instance.
-func NewAccountService(client *binarylane.Client) AccountService {
+func NewAccountService(ctx context.Context, client *binarylane.Client) AccountService {
 	return &accountService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
 func (as *accountService) Get() (*Account, error) {
-	binarylaneAccount, _, err := as.client.Account.Get(context.TODO())
+	binarylaneAccount, _, err := as.client.Account.Get(as.ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +60,7 @@ func (as *accountService) Get() (*Account, error) {
 }
 
 func (as *accountService) RateLimit() (*RateLimit, error) {
-	_, resp, err := as.client.Account.Get(context.TODO())
+	_, resp, err := as.client.Account.Get(as.ctx)
 	if err != nil {
 		return nil, err
 	}
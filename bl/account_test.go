@@ -75,7 +75,7 @@ func TestAccountServiceGet(t *testing.T) {
 	client := &binarylane.Client{
 		Account: gAccountSvc,
 	}
-	as := bl.NewAccountService(client)
+	as := bl.NewAccountService(context.TODO(), client)
 
 	account, err := as.Get()
 	assert.NoError(t, err)
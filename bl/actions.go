@@ -15,6 +15,7 @@ package bl
 
 import (
 	"context"
+	"time"
 
 	"github.com/binarylane/go-binarylane"
 )
@@ -27,6 +28,17 @@ type Action struct {
 // Actions is a slice of Action.
 type Actions []Action
 
+// ActionEvent is a change to an action seen while watching the actions of
+// an account.
+type ActionEvent struct {
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+	Action
+}
+
+// ActionEvents is a slice of ActionEvent.
+type ActionEvents []ActionEvent
+
 // ActionsService is an interface for interacting with BinaryLane's action api.
 type ActionsService interface {
 	List() (Actions, error)
@@ -35,20 +47,22 @@ type ActionsService interface {
 
 type actionsService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ ActionsService = &actionsService{}
 
 // NewActionsService builds an ActionsService instance.
-func NewActionsService(client *binarylane.Client) ActionsService {
+func NewActionsService(ctx context.Context, client *binarylane.Client) ActionsService {
 	return &actionsService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
 func (as *actionsService) List() (Actions, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := as.client.Actions.List(context.TODO(), opt)
+		list, resp, err := as.client.Actions.List(as.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -76,7 +90,7 @@ func (as *actionsService) List() (Actions, error) {
 }
 
 func (as *actionsService) Get(id int) (*Action, error) {
-	a, _, err := as.client.Actions.Get(context.TODO(), id)
+	a, _, err := as.client.Actions.Get(as.ctx, id)
 	if err != nil {
 		return nil, err
 	}
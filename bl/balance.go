@@ -30,19 +30,21 @@ type BalanceService interface {
 
 type balanceService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ BalanceService = &balanceService{}
 
 // NewBalanceService builds an BalanceService instance.
-func NewBalanceService(client *binarylane.Client) BalanceService {
+func NewBalanceService(ctx context.Context, client *binarylane.Client) BalanceService {
 	return &balanceService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
 func (as *balanceService) Get() (*Balance, error) {
-	binarylaneBalance, _, err := as.client.Balance.Get(context.TODO())
+	binarylaneBalance, _, err := as.client.Balance.Get(as.ctx)
 	if err != nil {
 		return nil, err
 	}
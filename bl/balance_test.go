@@ -74,7 +74,7 @@ func TestBalanceServiceGet(t *testing.T) {
 	client := &binarylane.Client{
 		Balance: gBalanceSvc,
 	}
-	as := bl.NewBalanceService(client)
+	as := bl.NewBalanceService(context.TODO(), client)
 
 	balance, err := as.Get()
 	assert.NoError(t, err)
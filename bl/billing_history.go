@@ -31,20 +31,22 @@ type BillingHistoryService interface {
 
 type billingHistoryService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ BillingHistoryService = &billingHistoryService{}
 
 // NewBillingHistoryService builds an BillingHistoryService instance.
-func NewBillingHistoryService(client *binarylane.Client) BillingHistoryService {
+func NewBillingHistoryService(ctx context.Context, client *binarylane.Client) BillingHistoryService {
 	return &billingHistoryService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
 func (is *billingHistoryService) List() (*BillingHistory, error) {
 	listFn := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		historyList, resp, err := is.client.BillingHistory.List(context.Background(), opt)
+		historyList, resp, err := is.client.BillingHistory.List(is.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -73,20 +73,22 @@ type DomainsService interface {
 
 type domainsService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ DomainsService = &domainsService{}
 
 // NewDomainsService builds an instance of DomainsService.
-func NewDomainsService(client *binarylane.Client) DomainsService {
+func NewDomainsService(ctx context.Context, client *binarylane.Client) DomainsService {
 	return &domainsService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
 func (ds *domainsService) List() (Domains, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := ds.client.Domains.List(context.TODO(), opt)
+		list, resp, err := ds.client.Domains.List(ds.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -114,7 +116,7 @@ func (ds *domainsService) List() (Domains, error) {
 }
 
 func (ds *domainsService) Get(name string) (*Domain, error) {
-	d, _, err := ds.client.Domains.Get(context.TODO(), name)
+	d, _, err := ds.client.Domains.Get(ds.ctx, name)
 	if err != nil {
 		return nil, err
 	}
@@ -123,7 +125,7 @@ func (ds *domainsService) Get(name string) (*Domain, error) {
 }
 
 func (ds *domainsService) Create(dcr *binarylane.DomainCreateRequest) (*Domain, error) {
-	d, _, err := ds.client.Domains.Create(context.TODO(), dcr)
+	d, _, err := ds.client.Domains.Create(ds.ctx, dcr)
 	if err != nil {
 		return nil, err
 	}
@@ -132,13 +134,13 @@ func (ds *domainsService) Create(dcr *binarylane.DomainCreateRequest) (*Domain,
 }
 
 func (ds *domainsService) Delete(name string) error {
-	_, err := ds.client.Domains.Delete(context.TODO(), name)
+	_, err := ds.client.Domains.Delete(ds.ctx, name)
 	return err
 }
 
 func (ds *domainsService) Records(name string) (DomainRecords, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := ds.client.Domains.Records(context.TODO(), name, opt)
+		list, resp, err := ds.client.Domains.Records(ds.ctx, name, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -166,7 +168,7 @@ func (ds *domainsService) Records(name string) (DomainRecords, error) {
 }
 
 func (ds *domainsService) Record(domain string, id int) (*DomainRecord, error) {
-	dr, _, err := ds.client.Domains.Record(context.TODO(), domain, id)
+	dr, _, err := ds.client.Domains.Record(ds.ctx, domain, id)
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +177,7 @@ func (ds *domainsService) Record(domain string, id int) (*DomainRecord, error) {
 }
 
 func (ds *domainsService) DeleteRecord(domain string, id int) error {
-	_, err := ds.client.Domains.DeleteRecord(context.TODO(), domain, id)
+	_, err := ds.client.Domains.DeleteRecord(ds.ctx, domain, id)
 	return err
 }
 
@@ -198,13 +200,13 @@ func (ds *domainsService) EditRecord(domain string, id int, drer *DomainRecordEd
 	}
 
 	path := fmt.Sprintf(domainRecordPath, domain, id)
-	req, err := ds.client.NewRequest(context.TODO(), http.MethodPut, path, drer)
+	req, err := ds.client.NewRequest(ds.ctx, http.MethodPut, path, drer)
 	if err != nil {
 		return nil, err
 	}
 
 	root := new(domainRecordRoot)
-	if _, err := ds.client.Do(context.TODO(), req, root); err != nil {
+	if _, err := ds.client.Do(ds.ctx, req, root); err != nil {
 		return nil, err
 	}
 	return root.DomainRecord, nil
@@ -219,13 +221,13 @@ func (ds *domainsService) CreateRecord(domain string, drer *DomainRecordEditRequ
 	}
 
 	path := fmt.Sprintf(domainRecordsPath, domain)
-	req, err := ds.client.NewRequest(context.Background(), http.MethodPost, path, drer)
+	req, err := ds.client.NewRequest(ds.ctx, http.MethodPost, path, drer)
 	if err != nil {
 		return nil, err
 	}
 
 	root := new(domainRecordRoot)
-	if _, err := ds.client.Do(context.Background(), req, root); err != nil {
+	if _, err := ds.client.Do(ds.ctx, req, root); err != nil {
 		return nil, err
 	}
 	return root.DomainRecord, err
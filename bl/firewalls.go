@@ -48,15 +48,16 @@ var _ FirewallsService = &firewallsService{}
 
 type firewallsService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 // NewFirewallsService builds an instance of FirewallsService.
-func NewFirewallsService(client *binarylane.Client) FirewallsService {
-	return &firewallsService{client: client}
+func NewFirewallsService(ctx context.Context, client *binarylane.Client) FirewallsService {
+	return &firewallsService{client: client, ctx: ctx}
 }
 
 func (fs *firewallsService) Get(fID string) (*Firewall, error) {
-	f, _, err := fs.client.Firewalls.Get(context.TODO(), fID)
+	f, _, err := fs.client.Firewalls.Get(fs.ctx, fID)
 	if err != nil {
 		return nil, err
 	}
@@ -65,7 +66,7 @@ func (fs *firewallsService) Get(fID string) (*Firewall, error) {
 }
 
 func (fs *firewallsService) Create(fr *binarylane.FirewallRequest) (*Firewall, error) {
-	f, _, err := fs.client.Firewalls.Create(context.TODO(), fr)
+	f, _, err := fs.client.Firewalls.Create(fs.ctx, fr)
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +75,7 @@ func (fs *firewallsService) Create(fr *binarylane.FirewallRequest) (*Firewall, e
 }
 
 func (fs *firewallsService) Update(fID string, fr *binarylane.FirewallRequest) (*Firewall, error) {
-	f, _, err := fs.client.Firewalls.Update(context.TODO(), fID, fr)
+	f, _, err := fs.client.Firewalls.Update(fs.ctx, fID, fr)
 	if err != nil {
 		return nil, err
 	}
@@ -84,7 +85,7 @@ func (fs *firewallsService) Update(fID string, fr *binarylane.FirewallRequest) (
 
 func (fs *firewallsService) List() (Firewalls, error) {
 	listFn := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := fs.client.Firewalls.List(context.TODO(), opt)
+		list, resp, err := fs.client.Firewalls.List(fs.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -102,7 +103,7 @@ func (fs *firewallsService) List() (Firewalls, error) {
 
 func (fs *firewallsService) ListByServer(sID int) (Firewalls, error) {
 	listFn := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := fs.client.Firewalls.ListByServer(context.TODO(), sID, opt)
+		list, resp, err := fs.client.Firewalls.ListByServer(fs.ctx, sID, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -119,37 +120,37 @@ func (fs *firewallsService) ListByServer(sID int) (Firewalls, error) {
 }
 
 func (fs *firewallsService) Delete(fID string) error {
-	_, err := fs.client.Firewalls.Delete(context.TODO(), fID)
+	_, err := fs.client.Firewalls.Delete(fs.ctx, fID)
 	return err
 }
 
 func (fs *firewallsService) AddServers(fID string, sIDs ...int) error {
-	_, err := fs.client.Firewalls.AddServers(context.TODO(), fID, sIDs...)
+	_, err := fs.client.Firewalls.AddServers(fs.ctx, fID, sIDs...)
 	return err
 }
 
 func (fs *firewallsService) RemoveServers(fID string, sIDs ...int) error {
-	_, err := fs.client.Firewalls.RemoveServers(context.TODO(), fID, sIDs...)
+	_, err := fs.client.Firewalls.RemoveServers(fs.ctx, fID, sIDs...)
 	return err
 }
 
 func (fs *firewallsService) AddTags(fID string, tags ...string) error {
-	_, err := fs.client.Firewalls.AddTags(context.TODO(), fID, tags...)
+	_, err := fs.client.Firewalls.AddTags(fs.ctx, fID, tags...)
 	return err
 }
 
 func (fs *firewallsService) RemoveTags(fID string, tags ...string) error {
-	_, err := fs.client.Firewalls.RemoveTags(context.TODO(), fID, tags...)
+	_, err := fs.client.Firewalls.RemoveTags(fs.ctx, fID, tags...)
 	return err
 }
 
 func (fs *firewallsService) AddRules(fID string, rr *binarylane.FirewallRulesRequest) error {
-	_, err := fs.client.Firewalls.AddRules(context.TODO(), fID, rr)
+	_, err := fs.client.Firewalls.AddRules(fs.ctx, fID, rr)
 	return err
 }
 
 func (fs *firewallsService) RemoveRules(fID string, rr *binarylane.FirewallRulesRequest) error {
-	_, err := fs.client.Firewalls.RemoveRules(context.TODO(), fID, rr)
+	_, err := fs.client.Firewalls.RemoveRules(fs.ctx, fID, rr)
 	return err
 }
 
@@ -30,19 +30,21 @@ type FloatingIPActionsService interface {
 
 type floatingIPActionsService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ FloatingIPActionsService = &floatingIPActionsService{}
 
 // NewFloatingIPActionsService builds a FloatingIPActionsService instance.
-func NewFloatingIPActionsService(client *binarylane.Client) FloatingIPActionsService {
+func NewFloatingIPActionsService(ctx context.Context, client *binarylane.Client) FloatingIPActionsService {
 	return &floatingIPActionsService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
 func (fia *floatingIPActionsService) Assign(ip string, serverID int) (*Action, error) {
-	a, _, err := fia.client.FloatingIPActions.Assign(context.TODO(), ip, serverID)
+	a, _, err := fia.client.FloatingIPActions.Assign(fia.ctx, ip, serverID)
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +53,7 @@ func (fia *floatingIPActionsService) Assign(ip string, serverID int) (*Action, e
 }
 
 func (fia *floatingIPActionsService) Unassign(ip string) (*Action, error) {
-	a, _, err := fia.client.FloatingIPActions.Unassign(context.TODO(), ip)
+	a, _, err := fia.client.FloatingIPActions.Unassign(fia.ctx, ip)
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +62,7 @@ func (fia *floatingIPActionsService) Unassign(ip string) (*Action, error) {
 }
 
 func (fia *floatingIPActionsService) Get(ip string, actionID int) (*Action, error) {
-	a, _, err := fia.client.FloatingIPActions.Get(context.TODO(), ip, actionID)
+	a, _, err := fia.client.FloatingIPActions.Get(fia.ctx, ip, actionID)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +72,7 @@ func (fia *floatingIPActionsService) Get(ip string, actionID int) (*Action, erro
 
 func (fia *floatingIPActionsService) List(ip string, opt *binarylane.ListOptions) ([]Action, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := fia.client.FloatingIPActions.List(context.TODO(), ip, opt)
+		list, resp, err := fia.client.FloatingIPActions.List(fia.ctx, ip, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -37,20 +37,22 @@ type FloatingIPsService interface {
 
 type floatingIPsService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ FloatingIPsService = &floatingIPsService{}
 
 // NewFloatingIPsService builds an instance of FloatingIPsService.
-func NewFloatingIPsService(client *binarylane.Client) FloatingIPsService {
+func NewFloatingIPsService(ctx context.Context, client *binarylane.Client) FloatingIPsService {
 	return &floatingIPsService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
 func (fis *floatingIPsService) List() (FloatingIPs, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := fis.client.FloatingIPs.List(context.TODO(), opt)
+		list, resp, err := fis.client.FloatingIPs.List(fis.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -78,7 +80,7 @@ func (fis *floatingIPsService) List() (FloatingIPs, error) {
 }
 
 func (fis *floatingIPsService) Get(ip string) (*FloatingIP, error) {
-	fip, _, err := fis.client.FloatingIPs.Get(context.TODO(), ip)
+	fip, _, err := fis.client.FloatingIPs.Get(fis.ctx, ip)
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +89,7 @@ func (fis *floatingIPsService) Get(ip string) (*FloatingIP, error) {
 }
 
 func (fis *floatingIPsService) Create(ficr *binarylane.FloatingIPCreateRequest) (*FloatingIP, error) {
-	fip, _, err := fis.client.FloatingIPs.Create(context.TODO(), ficr)
+	fip, _, err := fis.client.FloatingIPs.Create(fis.ctx, ficr)
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +98,6 @@ func (fis *floatingIPsService) Create(ficr *binarylane.FloatingIPCreateRequest)
 }
 
 func (fis *floatingIPsService) Delete(ip string) error {
-	_, err := fis.client.FloatingIPs.Delete(context.TODO(), ip)
+	_, err := fis.client.FloatingIPs.Delete(fis.ctx, ip)
 	return err
 }
@@ -28,19 +28,21 @@ type ImageActionsService interface {
 
 type imageActionsService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ ImageActionsService = &imageActionsService{}
 
 // NewImageActionsService builds an ImageActionsService instance.
-func NewImageActionsService(client *binarylane.Client) ImageActionsService {
+func NewImageActionsService(ctx context.Context, client *binarylane.Client) ImageActionsService {
 	return &imageActionsService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
 func (ia *imageActionsService) Get(imageID, actionID int) (*Action, error) {
-	a, _, err := ia.client.ImageActions.Get(context.TODO(), imageID, actionID)
+	a, _, err := ia.client.ImageActions.Get(ia.ctx, imageID, actionID)
 	if err != nil {
 		return nil, err
 	}
@@ -49,7 +51,7 @@ func (ia *imageActionsService) Get(imageID, actionID int) (*Action, error) {
 }
 
 func (ia *imageActionsService) Convert(imageID int) (*Action, error) {
-	a, _, err := ia.client.ImageActions.Convert(context.TODO(), imageID)
+	a, _, err := ia.client.ImageActions.Convert(ia.ctx, imageID)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +60,7 @@ func (ia *imageActionsService) Convert(imageID int) (*Action, error) {
 }
 
 func (ia *imageActionsService) Transfer(imageID int, transferRequest *binarylane.ActionRequest) (*Action, error) {
-	a, _, err := ia.client.ImageActions.Transfer(context.TODO(), imageID, transferRequest)
+	a, _, err := ia.client.ImageActions.Transfer(ia.ctx, imageID, transferRequest)
 	if err != nil {
 		return nil, err
 	}
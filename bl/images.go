@@ -42,14 +42,16 @@ type ImagesService interface {
 
 type imagesService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ ImagesService = &imagesService{}
 
 // NewImagesService builds an instance of ImagesService.
-func NewImagesService(client *binarylane.Client) ImagesService {
+func NewImagesService(ctx context.Context, client *binarylane.Client) ImagesService {
 	return &imagesService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
@@ -70,7 +72,7 @@ func (is *imagesService) ListUser(public bool) (Images, error) {
 }
 
 func (is *imagesService) GetByID(id int) (*Image, error) {
-	i, _, err := is.client.Images.GetByID(context.TODO(), id)
+	i, _, err := is.client.Images.GetByID(is.ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +81,7 @@ func (is *imagesService) GetByID(id int) (*Image, error) {
 }
 
 func (is *imagesService) GetBySlug(slug string) (*Image, error) {
-	i, _, err := is.client.Images.GetBySlug(context.TODO(), slug)
+	i, _, err := is.client.Images.GetBySlug(is.ctx, slug)
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +90,7 @@ func (is *imagesService) GetBySlug(slug string) (*Image, error) {
 }
 
 func (is *imagesService) Update(id int, iur *binarylane.ImageUpdateRequest) (*Image, error) {
-	i, _, err := is.client.Images.Update(context.TODO(), id, iur)
+	i, _, err := is.client.Images.Update(is.ctx, id, iur)
 	if err != nil {
 		return nil, err
 	}
@@ -97,12 +99,12 @@ func (is *imagesService) Update(id int, iur *binarylane.ImageUpdateRequest) (*Im
 }
 
 func (is *imagesService) Delete(id int) error {
-	_, err := is.client.Images.Delete(context.TODO(), id)
+	_, err := is.client.Images.Delete(is.ctx, id)
 	return err
 }
 
 func (is *imagesService) Create(icr *binarylane.CustomImageCreateRequest) (*Image, error) {
-	i, _, err := is.client.Images.Create(context.TODO(), icr)
+	i, _, err := is.client.Images.Create(is.ctx, icr)
 	if err != nil {
 		return nil, err
 	}
@@ -114,7 +116,7 @@ type listFn func(context.Context, *binarylane.ListOptions) ([]binarylane.Image,
 
 func (is *imagesService) listImages(lFn listFn, public bool) (Images, error) {
 	fn := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := lFn(context.TODO(), opt)
+		list, resp, err := lFn(is.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -50,14 +50,16 @@ type InvoicesService interface {
 
 type invoicesService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ InvoicesService = &invoicesService{}
 
 // NewInvoicesService builds an InvoicesService instance.
-func NewInvoicesService(client *binarylane.Client) InvoicesService {
+func NewInvoicesService(ctx context.Context, client *binarylane.Client) InvoicesService {
 	return &invoicesService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
@@ -65,7 +67,7 @@ func (is *invoicesService) List() (*InvoiceList, error) {
 	var invoicePreview binarylane.InvoiceListItem
 
 	listFn := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		invoiceList, resp, err := is.client.Invoices.List(context.Background(), opt)
+		invoiceList, resp, err := is.client.Invoices.List(is.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -97,7 +99,7 @@ func (is *invoicesService) List() (*InvoiceList, error) {
 
 func (is *invoicesService) Get(uuid string) (*Invoice, error) {
 	listFn := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		invoice, resp, err := is.client.Invoices.Get(context.Background(), uuid, opt)
+		invoice, resp, err := is.client.Invoices.Get(is.ctx, uuid, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -126,7 +128,7 @@ func (is *invoicesService) Get(uuid string) (*Invoice, error) {
 }
 
 func (is *invoicesService) GetSummary(uuid string) (*InvoiceSummary, error) {
-	summary, _, err := is.client.Invoices.GetSummary(context.Background(), uuid)
+	summary, _, err := is.client.Invoices.GetSummary(is.ctx, uuid)
 	if err != nil {
 		return nil, err
 	}
@@ -135,7 +137,7 @@ func (is *invoicesService) GetSummary(uuid string) (*InvoiceSummary, error) {
 }
 
 func (is *invoicesService) GetPDF(uuid string) ([]byte, error) {
-	pdf, _, err := is.client.Invoices.GetPDF(context.Background(), uuid)
+	pdf, _, err := is.client.Invoices.GetPDF(is.ctx, uuid)
 	if err != nil {
 		return nil, err
 	}
@@ -144,7 +146,7 @@ func (is *invoicesService) GetPDF(uuid string) ([]byte, error) {
 }
 
 func (is *invoicesService) GetCSV(uuid string) ([]byte, error) {
-	csv, _, err := is.client.Invoices.GetCSV(context.Background(), uuid)
+	csv, _, err := is.client.Invoices.GetCSV(is.ctx, uuid)
 	if err != nil {
 		return nil, err
 	}
@@ -44,17 +44,19 @@ var _ LoadBalancersService = &loadBalancersService{}
 
 type loadBalancersService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 // NewLoadBalancersService builds an instance of LoadBalancersService.
-func NewLoadBalancersService(client *binarylane.Client) LoadBalancersService {
+func NewLoadBalancersService(ctx context.Context, client *binarylane.Client) LoadBalancersService {
 	return &loadBalancersService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
 func (lbs *loadBalancersService) Get(lbID int) (*LoadBalancer, error) {
-	lb, _, err := lbs.client.LoadBalancers.Get(context.TODO(), lbID)
+	lb, _, err := lbs.client.LoadBalancers.Get(lbs.ctx, lbID)
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +66,7 @@ func (lbs *loadBalancersService) Get(lbID int) (*LoadBalancer, error) {
 
 func (lbs *loadBalancersService) List() (LoadBalancers, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := lbs.client.LoadBalancers.List(context.TODO(), opt)
+		list, resp, err := lbs.client.LoadBalancers.List(lbs.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -92,7 +94,7 @@ func (lbs *loadBalancersService) List() (LoadBalancers, error) {
 }
 
 func (lbs *loadBalancersService) Create(lbr *binarylane.LoadBalancerRequest) (*LoadBalancer, error) {
-	lb, _, err := lbs.client.LoadBalancers.Create(context.TODO(), lbr)
+	lb, _, err := lbs.client.LoadBalancers.Create(lbs.ctx, lbr)
 	if err != nil {
 		return nil, err
 	}
@@ -101,7 +103,7 @@ func (lbs *loadBalancersService) Create(lbr *binarylane.LoadBalancerRequest) (*L
 }
 
 func (lbs *loadBalancersService) Update(lbID int, lbr *binarylane.LoadBalancerRequest) (*LoadBalancer, error) {
-	lb, _, err := lbs.client.LoadBalancers.Update(context.TODO(), lbID, lbr)
+	lb, _, err := lbs.client.LoadBalancers.Update(lbs.ctx, lbID, lbr)
 	if err != nil {
 		return nil, err
 	}
@@ -110,26 +112,26 @@ func (lbs *loadBalancersService) Update(lbID int, lbr *binarylane.LoadBalancerRe
 }
 
 func (lbs *loadBalancersService) Delete(lbID int) error {
-	_, err := lbs.client.LoadBalancers.Delete(context.TODO(), lbID)
+	_, err := lbs.client.LoadBalancers.Delete(lbs.ctx, lbID)
 	return err
 }
 
 func (lbs *loadBalancersService) AddServers(lbID int, sIDs ...int) error {
-	_, err := lbs.client.LoadBalancers.AddServers(context.TODO(), lbID, sIDs...)
+	_, err := lbs.client.LoadBalancers.AddServers(lbs.ctx, lbID, sIDs...)
 	return err
 }
 
 func (lbs *loadBalancersService) RemoveServers(lbID int, sIDs ...int) error {
-	_, err := lbs.client.LoadBalancers.RemoveServers(context.TODO(), lbID, sIDs...)
+	_, err := lbs.client.LoadBalancers.RemoveServers(lbs.ctx, lbID, sIDs...)
 	return err
 }
 
 func (lbs *loadBalancersService) AddForwardingRules(lbID int, rules ...binarylane.ForwardingRule) error {
-	_, err := lbs.client.LoadBalancers.AddForwardingRules(context.TODO(), lbID, rules...)
+	_, err := lbs.client.LoadBalancers.AddForwardingRules(lbs.ctx, lbID, rules...)
 	return err
 }
 
 func (lbs *loadBalancersService) RemoveForwardingRules(lbID int, rules ...binarylane.ForwardingRule) error {
-	_, err := lbs.client.LoadBalancers.RemoveForwardingRules(context.TODO(), lbID, rules...)
+	_, err := lbs.client.LoadBalancers.RemoveForwardingRules(lbs.ctx, lbID, rules...)
 	return err
 }
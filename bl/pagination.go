@@ -22,73 +22,168 @@ import (
 	"github.com/binarylane/go-binarylane"
 )
 
-const maxFetchPages = 5
+// DefaultPaginationWorkers is the number of pages fetched concurrently when
+// PaginationWorkers is not set.
+const DefaultPaginationWorkers = 4
+
+// PaginationWorkers is the number of pages after the first that
+// PaginateResp fetches concurrently.
+var PaginationWorkers = DefaultPaginationWorkers
 
 var perPage = 200
 
 var fetchFn = fetchPage
 
-type paginatedList struct {
-	list []interface{}
-	mu   sync.Mutex
-}
-
-func (pl *paginatedList) append(items ...interface{}) {
-	pl.mu.Lock()
-	defer pl.mu.Unlock()
-
-	pl.list = append(pl.list, items...)
-}
-
 // Generator is a function that generates the list to be paginated.
 type Generator func(*binarylane.ListOptions) ([]interface{}, *binarylane.Response, error)
 
-// PaginateResp paginates a Response.
+// PaginateResp paginates a Response. The first page is fetched to find the
+// number of pages, and the remaining pages are fetched concurrently by
+// PaginationWorkers workers. Items are returned in the order the API lists
+// them. If any page cannot be fetched the first error is returned and no
+// further pages are requested; transient failures have already been retried
+// by the client's transport by then.
 func PaginateResp(gen Generator) ([]interface{}, error) {
 	opt := &binarylane.ListOptions{Page: 1, PerPage: perPage}
 
-	l := paginatedList{}
+	// fetch first page to get page count (x)
+	items, resp, err := gen(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// find last page
+	lp, err := lastPage(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	// pages[i] holds the items of page i+1, so that the pages can be joined
+	// in order however the fetches are scheduled.
+	pages := make([][]interface{}, lp)
+	pages[0] = items
 
-	fetchChan := make(chan int, maxFetchPages)
+	workers := PaginationWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	failed := make(chan struct{})
+	fetchChan := make(chan int)
 
-	var wg sync.WaitGroup
-	for i := 0; i < maxFetchPages-1; i++ {
+	for i := 0; i < workers; i++ {
 		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			for page := range fetchChan {
 				items, err := fetchFn(gen, page)
-				if err == nil {
-					l.append(items...)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("could not fetch page %d: %w", page, err)
+						close(failed)
+					}
+					mu.Unlock()
+					continue
 				}
+				pages[page-1] = items
 			}
-			wg.Done()
 		}()
 	}
 
-	// fetch first page to get page count (x)
-	items, resp, err := gen(opt)
-	if err != nil {
-		return nil, err
+	// start with second page
+dispatch:
+	for page := 2; page <= lp; page++ {
+		select {
+		case fetchChan <- page:
+		case <-failed:
+			break dispatch
+		}
 	}
+	close(fetchChan)
 
-	l.append(items...)
+	wg.Wait()
 
-	// find last page
-	lp, err := lastPage(resp)
-	if err != nil {
-		return nil, err
+	if firstErr != nil {
+		return nil, firstErr
 	}
 
-	// start with second page
-	opt.Page++
-	for ; opt.Page <= lp; opt.Page++ {
-		fetchChan <- opt.Page
+	var list []interface{}
+	for _, p := range pages {
+		list = append(list, p...)
 	}
-	close(fetchChan)
 
-	wg.Wait()
+	return list, nil
+}
+
+// PageIterator streams the items of a paginated list one page at a time,
+// so that very large collections need not be held in memory at once.
+//
+//	it := NewPageIterator(gen)
+//	for it.Next() {
+//		item := it.Item()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type PageIterator struct {
+	gen Generator
+
+	page     int
+	lastPage int
+	items    []interface{}
+	item     interface{}
+	err      error
+}
+
+// NewPageIterator creates a PageIterator for the list generated by gen.
+func NewPageIterator(gen Generator) *PageIterator {
+	return &PageIterator{gen: gen}
+}
+
+// Next advances to the next item, fetching the next page when the current
+// one is exhausted. It returns false when there are no more items or a page
+// could not be fetched.
+func (it *PageIterator) Next() bool {
+	for len(it.items) == 0 {
+		if it.err != nil || (it.page > 0 && it.page >= it.lastPage) {
+			return false
+		}
+
+		it.page++
+		items, resp, err := it.gen(&binarylane.ListOptions{Page: it.page, PerPage: perPage})
+		if err != nil {
+			it.err = fmt.Errorf("could not fetch page %d: %w", it.page, err)
+			return false
+		}
+
+		if it.page == 1 {
+			if it.lastPage, err = lastPage(resp); err != nil {
+				it.err = err
+				return false
+			}
+		}
+
+		it.items = items
+	}
+
+	it.item, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Item returns the current item.
+func (it *PageIterator) Item() interface{} {
+	return it.item
+}
 
-	return l.list, nil
+// Err returns the error that stopped the iteration, if any.
+func (it *PageIterator) Err() error {
+	return it.err
 }
 
 func fetchPage(gen Generator, page int) ([]interface{}, error) {
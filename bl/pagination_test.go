@@ -14,8 +14,10 @@ limitations under the License.
 package bl
 
 import (
+	"errors"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/binarylane/go-binarylane"
 	"github.com/stretchr/testify/assert"
@@ -39,6 +41,127 @@ func Test_PaginateResp(t *testing.T) {
 	assert.Len(t, list, 5)
 }
 
+func Test_PaginateResp_order(t *testing.T) {
+	resp := &binarylane.Response{Links: &binarylane.Links{Pages: &binarylane.Pages{Last: "http://example.com/?page=20"}}}
+
+	gen := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
+		// Later pages return sooner, so that they finish out of order.
+		time.Sleep(time.Duration(20-opt.Page) * time.Millisecond)
+		return []interface{}{opt.Page * 10, opt.Page*10 + 1}, resp, nil
+	}
+
+	list, err := PaginateResp(gen)
+	assert.NoError(t, err)
+
+	expected := []interface{}{}
+	for page := 1; page <= 20; page++ {
+		expected = append(expected, page*10, page*10+1)
+	}
+	assert.Equal(t, expected, list)
+}
+
+func Test_PaginateResp_error(t *testing.T) {
+	var mu sync.Mutex
+	fetched := map[int]bool{}
+	resp := &binarylane.Response{Links: &binarylane.Links{Pages: &binarylane.Pages{Last: "http://example.com/?page=50"}}}
+
+	gen := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
+		mu.Lock()
+		fetched[opt.Page] = true
+		mu.Unlock()
+
+		if opt.Page == 3 {
+			return nil, nil, errors.New("502 Bad Gateway")
+		}
+		time.Sleep(time.Millisecond)
+		return []interface{}{opt.Page}, resp, nil
+	}
+
+	list, err := PaginateResp(gen)
+	assert.EqualError(t, err, "could not fetch page 3: 502 Bad Gateway")
+	assert.Nil(t, list)
+	assert.True(t, len(fetched) < 50, "pages were fetched after the error")
+}
+
+func Test_PaginateResp_workers(t *testing.T) {
+	defer func(w int) { PaginationWorkers = w }(PaginationWorkers)
+	PaginationWorkers = 2
+
+	var (
+		mu                sync.Mutex
+		active, maxActive int
+	)
+	resp := &binarylane.Response{Links: &binarylane.Links{Pages: &binarylane.Pages{Last: "http://example.com/?page=10"}}}
+
+	gen := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+
+		time.Sleep(2 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return []interface{}{opt.Page}, resp, nil
+	}
+
+	list, err := PaginateResp(gen)
+	assert.NoError(t, err)
+	assert.Len(t, list, 10)
+	assert.True(t, maxActive <= 2, "fetched %d pages concurrently", maxActive)
+}
+
+func Test_PageIterator(t *testing.T) {
+	resp := &binarylane.Response{Links: &binarylane.Links{Pages: &binarylane.Pages{Last: "http://example.com/?page=3"}}}
+	pages := []int{}
+
+	gen := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
+		pages = append(pages, opt.Page)
+		if opt.Page == 2 {
+			return []interface{}{}, resp, nil
+		}
+		return []interface{}{opt.Page * 10, opt.Page*10 + 1}, resp, nil
+	}
+
+	it := NewPageIterator(gen)
+	items := []interface{}{}
+	for it.Next() {
+		items = append(items, it.Item())
+
+		// Pages are only fetched once the previous one is exhausted.
+		assert.Equal(t, it.Item().(int)/10, len(pages))
+	}
+
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []interface{}{10, 11, 30, 31}, items)
+	assert.Equal(t, []int{1, 2, 3}, pages)
+}
+
+func Test_PageIterator_error(t *testing.T) {
+	resp := &binarylane.Response{Links: &binarylane.Links{Pages: &binarylane.Pages{Last: "http://example.com/?page=3"}}}
+
+	gen := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
+		if opt.Page == 2 {
+			return nil, nil, errors.New("502 Bad Gateway")
+		}
+		return []interface{}{opt.Page}, resp, nil
+	}
+
+	it := NewPageIterator(gen)
+	items := []interface{}{}
+	for it.Next() {
+		items = append(items, it.Item())
+	}
+
+	assert.EqualError(t, it.Err(), "could not fetch page 2: 502 Bad Gateway")
+	assert.Equal(t, []interface{}{1}, items)
+	assert.False(t, it.Next())
+}
+
 func Test_Pagination_fetchPage(t *testing.T) {
 	gen := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
 		items := []interface{}{}
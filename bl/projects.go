@@ -57,10 +57,10 @@ type projectsService struct {
 var _ ProjectsService = &projectsService{}
 
 // NewProjectsService builds an instance of ProjectsService.
-func NewProjectsService(client *binarylane.Client) ProjectsService {
+func NewProjectsService(ctx context.Context, client *binarylane.Client) ProjectsService {
 	return &projectsService{
 		client: client,
-		ctx:    context.Background(),
+		ctx:    ctx,
 	}
 }
 
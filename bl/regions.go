@@ -34,20 +34,22 @@ type RegionsService interface {
 
 type regionsService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ RegionsService = &regionsService{}
 
 // NewRegionsService builds an instance of RegionsService.
-func NewRegionsService(client *binarylane.Client) RegionsService {
+func NewRegionsService(ctx context.Context, client *binarylane.Client) RegionsService {
 	return &regionsService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
 func (rs *regionsService) List() (Regions, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := rs.client.Regions.List(context.TODO(), opt)
+		list, resp, err := rs.client.Regions.List(rs.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
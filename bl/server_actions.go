@@ -53,14 +53,16 @@ type ServerActionsService interface {
 
 type serverActionsService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ ServerActionsService = &serverActionsService{}
 
 // NewServerActionsService builds an instance of ServerActionsService.
-func NewServerActionsService(client *binarylane.Client) ServerActionsService {
+func NewServerActionsService(ctx context.Context, client *binarylane.Client) ServerActionsService {
 	return &serverActionsService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
@@ -79,149 +81,149 @@ func (sas *serverActionsService) handleTagActionResponse(a []binarylane.Action,
 
 	actions := make([]Action, 0, len(a))
 
-	for _, action := range a {
-		actions = append(actions, Action{Action: &action})
+	for i := range a {
+		actions = append(actions, Action{Action: &a[i]})
 	}
 
 	return actions, nil
 }
 
 func (sas *serverActionsService) Shutdown(id int) (*Action, error) {
-	a, _, err := sas.client.ServerActions.Shutdown(context.TODO(), id)
+	a, _, err := sas.client.ServerActions.Shutdown(sas.ctx, id)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) ShutdownByTag(tag string) (Actions, error) {
-	a, _, err := sas.client.ServerActions.ShutdownByTag(context.TODO(), tag)
+	a, _, err := sas.client.ServerActions.ShutdownByTag(sas.ctx, tag)
 	return sas.handleTagActionResponse(a, err)
 }
 
 func (sas *serverActionsService) PowerOff(id int) (*Action, error) {
-	a, _, err := sas.client.ServerActions.PowerOff(context.TODO(), id)
+	a, _, err := sas.client.ServerActions.PowerOff(sas.ctx, id)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) PowerOffByTag(tag string) (Actions, error) {
-	a, _, err := sas.client.ServerActions.PowerOffByTag(context.TODO(), tag)
+	a, _, err := sas.client.ServerActions.PowerOffByTag(sas.ctx, tag)
 	return sas.handleTagActionResponse(a, err)
 }
 
 func (sas *serverActionsService) PowerOn(id int) (*Action, error) {
-	a, _, err := sas.client.ServerActions.PowerOn(context.TODO(), id)
+	a, _, err := sas.client.ServerActions.PowerOn(sas.ctx, id)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) PowerOnByTag(tag string) (Actions, error) {
-	a, _, err := sas.client.ServerActions.PowerOnByTag(context.TODO(), tag)
+	a, _, err := sas.client.ServerActions.PowerOnByTag(sas.ctx, tag)
 	return sas.handleTagActionResponse(a, err)
 }
 
 func (sas *serverActionsService) PowerCycle(id int) (*Action, error) {
-	a, _, err := sas.client.ServerActions.PowerCycle(context.TODO(), id)
+	a, _, err := sas.client.ServerActions.PowerCycle(sas.ctx, id)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) PowerCycleByTag(tag string) (Actions, error) {
-	a, _, err := sas.client.ServerActions.PowerCycleByTag(context.TODO(), tag)
+	a, _, err := sas.client.ServerActions.PowerCycleByTag(sas.ctx, tag)
 	return sas.handleTagActionResponse(a, err)
 }
 
 func (sas *serverActionsService) Reboot(id int) (*Action, error) {
-	a, _, err := sas.client.ServerActions.Reboot(context.TODO(), id)
+	a, _, err := sas.client.ServerActions.Reboot(sas.ctx, id)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) Restore(id, imageID int) (*Action, error) {
-	a, _, err := sas.client.ServerActions.Restore(context.TODO(), id, imageID)
+	a, _, err := sas.client.ServerActions.Restore(sas.ctx, id, imageID)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) Resize(id int, sizeSlug string, resizeDisk bool) (*Action, error) {
-	a, _, err := sas.client.ServerActions.Resize(context.TODO(), id, sizeSlug, resizeDisk)
+	a, _, err := sas.client.ServerActions.Resize(sas.ctx, id, sizeSlug, resizeDisk)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) Rename(id int, name string) (*Action, error) {
-	a, _, err := sas.client.ServerActions.Rename(context.TODO(), id, name)
+	a, _, err := sas.client.ServerActions.Rename(sas.ctx, id, name)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) Snapshot(id int, name string) (*Action, error) {
-	a, _, err := sas.client.ServerActions.Snapshot(context.TODO(), id, name)
+	a, _, err := sas.client.ServerActions.Snapshot(sas.ctx, id, name)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) SnapshotByTag(tag string, name string) (Actions, error) {
-	a, _, err := sas.client.ServerActions.SnapshotByTag(context.TODO(), tag, name)
+	a, _, err := sas.client.ServerActions.SnapshotByTag(sas.ctx, tag, name)
 	return sas.handleTagActionResponse(a, err)
 }
 
 func (sas *serverActionsService) EnableBackups(id int) (*Action, error) {
-	a, _, err := sas.client.ServerActions.EnableBackups(context.TODO(), id)
+	a, _, err := sas.client.ServerActions.EnableBackups(sas.ctx, id)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) EnableBackupsByTag(tag string) (Actions, error) {
-	a, _, err := sas.client.ServerActions.EnableBackupsByTag(context.TODO(), tag)
+	a, _, err := sas.client.ServerActions.EnableBackupsByTag(sas.ctx, tag)
 	return sas.handleTagActionResponse(a, err)
 }
 
 func (sas *serverActionsService) DisableBackups(id int) (*Action, error) {
-	a, _, err := sas.client.ServerActions.DisableBackups(context.TODO(), id)
+	a, _, err := sas.client.ServerActions.DisableBackups(sas.ctx, id)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) DisableBackupsByTag(tag string) (Actions, error) {
-	a, _, err := sas.client.ServerActions.DisableBackupsByTag(context.TODO(), tag)
+	a, _, err := sas.client.ServerActions.DisableBackupsByTag(sas.ctx, tag)
 	return sas.handleTagActionResponse(a, err)
 }
 
 func (sas *serverActionsService) PasswordReset(id int) (*Action, error) {
-	a, _, err := sas.client.ServerActions.PasswordReset(context.TODO(), id)
+	a, _, err := sas.client.ServerActions.PasswordReset(sas.ctx, id)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) RebuildByImageID(id, imageID int) (*Action, error) {
-	a, _, err := sas.client.ServerActions.RebuildByImageID(context.TODO(), id, imageID)
+	a, _, err := sas.client.ServerActions.RebuildByImageID(sas.ctx, id, imageID)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) RebuildByImageSlug(id int, slug string) (*Action, error) {
-	a, _, err := sas.client.ServerActions.RebuildByImageSlug(context.TODO(), id, slug)
+	a, _, err := sas.client.ServerActions.RebuildByImageSlug(sas.ctx, id, slug)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) ChangeKernel(id, kernelID int) (*Action, error) {
-	a, _, err := sas.client.ServerActions.ChangeKernel(context.TODO(), id, kernelID)
+	a, _, err := sas.client.ServerActions.ChangeKernel(sas.ctx, id, kernelID)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) EnableIPv6(id int) (*Action, error) {
-	a, _, err := sas.client.ServerActions.EnableIPv6(context.TODO(), id)
+	a, _, err := sas.client.ServerActions.EnableIPv6(sas.ctx, id)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) EnableIPv6ByTag(tag string) (Actions, error) {
-	a, _, err := sas.client.ServerActions.EnableIPv6ByTag(context.TODO(), tag)
+	a, _, err := sas.client.ServerActions.EnableIPv6ByTag(sas.ctx, tag)
 	return sas.handleTagActionResponse(a, err)
 }
 
 func (sas *serverActionsService) EnablePrivateNetworking(id int) (*Action, error) {
-	a, _, err := sas.client.ServerActions.EnablePrivateNetworking(context.TODO(), id)
+	a, _, err := sas.client.ServerActions.EnablePrivateNetworking(sas.ctx, id)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) EnablePrivateNetworkingByTag(tag string) (Actions, error) {
-	a, _, err := sas.client.ServerActions.EnablePrivateNetworkingByTag(context.TODO(), tag)
+	a, _, err := sas.client.ServerActions.EnablePrivateNetworkingByTag(sas.ctx, tag)
 	return sas.handleTagActionResponse(a, err)
 }
 
 func (sas *serverActionsService) Get(id int, actionID int) (*Action, error) {
-	a, _, err := sas.client.ServerActions.Get(context.TODO(), id, actionID)
+	a, _, err := sas.client.ServerActions.Get(sas.ctx, id, actionID)
 	return sas.handleActionResponse(a, err)
 }
 
 func (sas *serverActionsService) GetByURI(uri string) (*Action, error) {
-	a, _, err := sas.client.ServerActions.GetByURI(context.TODO(), uri)
+	a, _, err := sas.client.ServerActions.GetByURI(sas.ctx, uri)
 	return sas.handleActionResponse(a, err)
 }
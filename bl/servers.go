@@ -67,20 +67,22 @@ type ServersService interface {
 
 type serversService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ ServersService = &serversService{}
 
 // NewServersService builds a ServersService instance.
-func NewServersService(client *binarylane.Client) ServersService {
+func NewServersService(ctx context.Context, client *binarylane.Client) ServersService {
 	return &serversService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
 func (ss *serversService) List() (Servers, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := ss.client.Servers.List(context.TODO(), opt)
+		list, resp, err := ss.client.Servers.List(ss.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -109,7 +111,7 @@ func (ss *serversService) List() (Servers, error) {
 
 func (ss *serversService) ListByTag(tagName string) (Servers, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := ss.client.Servers.ListByTag(context.TODO(), tagName, opt)
+		list, resp, err := ss.client.Servers.ListByTag(ss.ctx, tagName, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -137,7 +139,7 @@ func (ss *serversService) ListByTag(tagName string) (Servers, error) {
 }
 
 func (ss *serversService) Get(id int) (*Server, error) {
-	d, _, err := ss.client.Servers.Get(context.TODO(), id)
+	d, _, err := ss.client.Servers.Get(ss.ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -146,7 +148,7 @@ func (ss *serversService) Get(id int) (*Server, error) {
 }
 
 func (ss *serversService) Create(dcr *binarylane.ServerCreateRequest, wait bool) (*Server, error) {
-	d, resp, err := ss.client.Servers.Create(context.TODO(), dcr)
+	d, resp, err := ss.client.Servers.Create(ss.ctx, dcr)
 	if err != nil {
 		return nil, err
 	}
@@ -161,7 +163,7 @@ func (ss *serversService) Create(dcr *binarylane.ServerCreateRequest, wait bool)
 		}
 
 		if action != nil {
-			_ = util.WaitForActive(context.TODO(), ss.client, action.HREF)
+			_ = util.WaitForActive(ss.ctx, ss.client, action.HREF)
 			server, err := ss.Get(d.ID)
 			if err != nil {
 				return nil, err
@@ -174,7 +176,7 @@ func (ss *serversService) Create(dcr *binarylane.ServerCreateRequest, wait bool)
 }
 
 func (ss *serversService) CreateMultiple(dmcr *binarylane.ServerMultiCreateRequest) (Servers, error) {
-	binarylaneServers, _, err := ss.client.Servers.CreateMultiple(context.TODO(), dmcr)
+	binarylaneServers, _, err := ss.client.Servers.CreateMultiple(ss.ctx, dmcr)
 	if err != nil {
 		return nil, err
 	}
@@ -188,18 +190,18 @@ func (ss *serversService) CreateMultiple(dmcr *binarylane.ServerMultiCreateReque
 }
 
 func (ss *serversService) Delete(id int) error {
-	_, err := ss.client.Servers.Delete(context.TODO(), id)
+	_, err := ss.client.Servers.Delete(ss.ctx, id)
 	return err
 }
 
 func (ss *serversService) DeleteByTag(tag string) error {
-	_, err := ss.client.Servers.DeleteByTag(context.TODO(), tag)
+	_, err := ss.client.Servers.DeleteByTag(ss.ctx, tag)
 	return err
 }
 
 func (ss *serversService) Kernels(id int) (Kernels, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := ss.client.Servers.Kernels(context.TODO(), id, opt)
+		list, resp, err := ss.client.Servers.Kernels(ss.ctx, id, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -228,7 +230,7 @@ func (ss *serversService) Kernels(id int) (Kernels, error) {
 
 func (ss *serversService) Snapshots(id int) (Images, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := ss.client.Servers.Snapshots(context.TODO(), id, opt)
+		list, resp, err := ss.client.Servers.Snapshots(ss.ctx, id, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -257,7 +259,7 @@ func (ss *serversService) Snapshots(id int) (Images, error) {
 
 func (ss *serversService) Backups(id int) (Images, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := ss.client.Servers.Backups(context.TODO(), id, opt)
+		list, resp, err := ss.client.Servers.Backups(ss.ctx, id, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -286,7 +288,7 @@ func (ss *serversService) Backups(id int) (Images, error) {
 
 func (ss *serversService) Actions(id int) (Actions, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := ss.client.Servers.Actions(context.TODO(), id, opt)
+		list, resp, err := ss.client.Servers.Actions(ss.ctx, id, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -314,7 +316,7 @@ func (ss *serversService) Actions(id int) (Actions, error) {
 }
 
 func (ss *serversService) Neighbors(id int) (Servers, error) {
-	list, _, err := ss.client.Servers.Neighbors(context.TODO(), id)
+	list, _, err := ss.client.Servers.Neighbors(ss.ctx, id)
 	if err != nil {
 		return nil, err
 	}
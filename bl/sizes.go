@@ -34,20 +34,22 @@ type SizesService interface {
 
 type sizesService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ SizesService = &sizesService{}
 
 // NewSizesService builds an instance of SizesService.
-func NewSizesService(client *binarylane.Client) SizesService {
+func NewSizesService(ctx context.Context, client *binarylane.Client) SizesService {
 	return &sizesService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
 func (rs *sizesService) List() (Sizes, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := rs.client.Sizes.List(context.TODO(), opt)
+		list, resp, err := rs.client.Sizes.List(rs.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -38,20 +38,22 @@ type SnapshotsService interface {
 
 type snapshotsService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ SnapshotsService = &snapshotsService{}
 
 // NewSnapshotsService builds a SnapshotsService instance.
-func NewSnapshotsService(client *binarylane.Client) SnapshotsService {
+func NewSnapshotsService(ctx context.Context, client *binarylane.Client) SnapshotsService {
 	return &snapshotsService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
 func (ss *snapshotsService) List() (Snapshots, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := ss.client.Snapshots.List(context.TODO(), opt)
+		list, resp, err := ss.client.Snapshots.List(ss.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -80,7 +82,7 @@ func (ss *snapshotsService) List() (Snapshots, error) {
 
 func (ss *snapshotsService) ListVolume() (Snapshots, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := ss.client.Snapshots.ListVolume(context.TODO(), opt)
+		list, resp, err := ss.client.Snapshots.ListVolume(ss.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -109,7 +111,7 @@ func (ss *snapshotsService) ListVolume() (Snapshots, error) {
 
 func (ss *snapshotsService) ListServer() (Snapshots, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := ss.client.Snapshots.ListServer(context.TODO(), opt)
+		list, resp, err := ss.client.Snapshots.ListServer(ss.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -137,7 +139,7 @@ func (ss *snapshotsService) ListServer() (Snapshots, error) {
 }
 
 func (ss *snapshotsService) Get(snapshotID string) (*Snapshot, error) {
-	s, _, err := ss.client.Snapshots.Get(context.TODO(), snapshotID)
+	s, _, err := ss.client.Snapshots.Get(ss.ctx, snapshotID)
 	if err != nil {
 		return nil, err
 	}
@@ -146,6 +148,6 @@ func (ss *snapshotsService) Get(snapshotID string) (*Snapshot, error) {
 }
 
 func (ss *snapshotsService) Delete(snapshotID string) error {
-	_, err := ss.client.Snapshots.Delete(context.TODO(), snapshotID)
+	_, err := ss.client.Snapshots.Delete(ss.ctx, snapshotID)
 	return err
 }
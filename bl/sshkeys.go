@@ -40,20 +40,22 @@ type KeysService interface {
 
 type keysService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ KeysService = &keysService{}
 
 // NewKeysService builds an instance of KeysService.
-func NewKeysService(client *binarylane.Client) KeysService {
+func NewKeysService(ctx context.Context, client *binarylane.Client) KeysService {
 	return &keysService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
 func (ks *keysService) List() (SSHKeys, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := ks.client.Keys.List(context.TODO(), opt)
+		list, resp, err := ks.client.Keys.List(ks.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -85,10 +87,10 @@ func (ks *keysService) Get(id string) (*SSHKey, error) {
 	var k *binarylane.Key
 
 	if i, aerr := strconv.Atoi(id); aerr == nil {
-		k, _, err = ks.client.Keys.GetByID(context.TODO(), i)
+		k, _, err = ks.client.Keys.GetByID(ks.ctx, i)
 	} else {
 		if len(id) > 0 {
-			k, _, err = ks.client.Keys.GetByFingerprint(context.TODO(), id)
+			k, _, err = ks.client.Keys.GetByFingerprint(ks.ctx, id)
 		} else {
 			err = fmt.Errorf("missing key id or fingerprint")
 		}
@@ -102,7 +104,7 @@ func (ks *keysService) Get(id string) (*SSHKey, error) {
 }
 
 func (ks *keysService) Create(kcr *binarylane.KeyCreateRequest) (*SSHKey, error) {
-	k, _, err := ks.client.Keys.Create(context.TODO(), kcr)
+	k, _, err := ks.client.Keys.Create(ks.ctx, kcr)
 	if err != nil {
 		return nil, err
 	}
@@ -114,9 +116,9 @@ func (ks *keysService) Update(id string, kur *binarylane.KeyUpdateRequest) (*SSH
 	var k *binarylane.Key
 	var err error
 	if i, aerr := strconv.Atoi(id); aerr == nil {
-		k, _, err = ks.client.Keys.UpdateByID(context.TODO(), i, kur)
+		k, _, err = ks.client.Keys.UpdateByID(ks.ctx, i, kur)
 	} else {
-		k, _, err = ks.client.Keys.UpdateByFingerprint(context.TODO(), id, kur)
+		k, _, err = ks.client.Keys.UpdateByFingerprint(ks.ctx, id, kur)
 	}
 
 	if err != nil {
@@ -130,9 +132,9 @@ func (ks *keysService) Delete(id string) error {
 	var err error
 
 	if i, aerr := strconv.Atoi(id); aerr == nil {
-		_, err = ks.client.Keys.DeleteByID(context.TODO(), i)
+		_, err = ks.client.Keys.DeleteByID(ks.ctx, i)
 	} else {
-		_, err = ks.client.Keys.DeleteByFingerprint(context.TODO(), id)
+		_, err = ks.client.Keys.DeleteByFingerprint(ks.ctx, id)
 	}
 
 	return err
@@ -39,20 +39,22 @@ type TagsService interface {
 
 type tagsService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 var _ TagsService = (*tagsService)(nil)
 
 // NewTagsService builds a TagsService instance.
-func NewTagsService(client *binarylane.Client) TagsService {
+func NewTagsService(ctx context.Context, client *binarylane.Client) TagsService {
 	return &tagsService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
 func (ts *tagsService) List() (Tags, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := ts.client.Tags.List(context.TODO(), opt)
+		list, resp, err := ts.client.Tags.List(ts.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -80,7 +82,7 @@ func (ts *tagsService) List() (Tags, error) {
 }
 
 func (ts *tagsService) Get(name string) (*Tag, error) {
-	t, _, err := ts.client.Tags.Get(context.TODO(), name)
+	t, _, err := ts.client.Tags.Get(ts.ctx, name)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +91,7 @@ func (ts *tagsService) Get(name string) (*Tag, error) {
 }
 
 func (ts *tagsService) Create(tcr *binarylane.TagCreateRequest) (*Tag, error) {
-	t, _, err := ts.client.Tags.Create(context.TODO(), tcr)
+	t, _, err := ts.client.Tags.Create(ts.ctx, tcr)
 	if err != nil {
 		return nil, err
 	}
@@ -98,16 +100,16 @@ func (ts *tagsService) Create(tcr *binarylane.TagCreateRequest) (*Tag, error) {
 }
 
 func (ts *tagsService) Delete(name string) error {
-	_, err := ts.client.Tags.Delete(context.TODO(), name)
+	_, err := ts.client.Tags.Delete(ts.ctx, name)
 	return err
 }
 
 func (ts *tagsService) TagResources(name string, trr *binarylane.TagResourcesRequest) error {
-	_, err := ts.client.Tags.TagResources(context.TODO(), name, trr)
+	_, err := ts.client.Tags.TagResources(ts.ctx, name, trr)
 	return err
 }
 
 func (ts *tagsService) UntagResources(name string, urr *binarylane.UntagResourcesRequest) error {
-	_, err := ts.client.Tags.UntagResources(context.TODO(), name, urr)
+	_, err := ts.client.Tags.UntagResources(ts.ctx, name, urr)
 	return err
 }
@@ -40,17 +40,19 @@ var _ VPCsService = &vpcsService{}
 
 type vpcsService struct {
 	client *binarylane.Client
+	ctx    context.Context
 }
 
 // NewVPCsService builds an instance of VPCsService.
-func NewVPCsService(client *binarylane.Client) VPCsService {
+func NewVPCsService(ctx context.Context, client *binarylane.Client) VPCsService {
 	return &vpcsService{
 		client: client,
+		ctx:    ctx,
 	}
 }
 
 func (v *vpcsService) Get(id int) (*VPC, error) {
-	vpc, _, err := v.client.VPCs.Get(context.TODO(), id)
+	vpc, _, err := v.client.VPCs.Get(v.ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +62,7 @@ func (v *vpcsService) Get(id int) (*VPC, error) {
 
 func (v *vpcsService) List() (VPCs, error) {
 	f := func(opt *binarylane.ListOptions) ([]interface{}, *binarylane.Response, error) {
-		list, resp, err := v.client.VPCs.List(context.TODO(), opt)
+		list, resp, err := v.client.VPCs.List(v.ctx, opt)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -88,7 +90,7 @@ func (v *vpcsService) List() (VPCs, error) {
 }
 
 func (v *vpcsService) Create(vpcr *binarylane.VPCCreateRequest) (*VPC, error) {
-	vpc, _, err := v.client.VPCs.Create(context.TODO(), vpcr)
+	vpc, _, err := v.client.VPCs.Create(v.ctx, vpcr)
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +99,7 @@ func (v *vpcsService) Create(vpcr *binarylane.VPCCreateRequest) (*VPC, error) {
 }
 
 func (v *vpcsService) Update(id int, vpcr *binarylane.VPCUpdateRequest) (*VPC, error) {
-	vpc, _, err := v.client.VPCs.Update(context.TODO(), id, vpcr)
+	vpc, _, err := v.client.VPCs.Update(v.ctx, id, vpcr)
 	if err != nil {
 		return nil, err
 	}
@@ -106,6 +108,6 @@ func (v *vpcsService) Update(id int, vpcr *binarylane.VPCUpdateRequest) (*VPC, e
 }
 
 func (v *vpcsService) Delete(id int) error {
-	_, err := v.client.VPCs.Delete(context.TODO(), id)
+	_, err := v.client.VPCs.Delete(v.ctx, id)
 	return err
 }
@@ -0,0 +1,245 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blcli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type withoutRecordingKey struct{}
+
+// WithoutRecording returns a context whose API requests are left out of the
+// cassette written by --record.
+func WithoutRecording(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutRecordingKey{}, true)
+}
+
+// redacted replaces the access token wherever it appears in a cassette.
+const redacted = "REDACTED"
+
+// cassette is a file of recorded API requests and responses.
+type cassette struct {
+	Interactions []cassetteInteraction `json:"interactions"`
+}
+
+type cassetteInteraction struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+}
+
+type cassetteRequest struct {
+	Method string `json:"method"`
+	// URL is the path and query of the request, so that a cassette can be
+	// replayed against any API URL.
+	URL  string `json:"url"`
+	Body string `json:"body,omitempty"`
+}
+
+type cassetteResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+func readCassette(path string) (*cassette, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var c cassette
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("could not read cassette %s: %v", path, err)
+	}
+
+	return &c, nil
+}
+
+func (c *cassette) write(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0600)
+}
+
+// cassetteRecorder collects the requests and responses captured by a
+// recorder, like the one used by --trace, into a cassette. The cassette is
+// written to its file by save once the command finishes. The Authorization
+// header is not recorded and the access token is replaced wherever else it
+// appears.
+type cassetteRecorder struct {
+	path  string
+	token string
+
+	mu       sync.Mutex
+	cassette cassette
+	err      error
+}
+
+func newCassetteRecorder(path, token string) (*cassetteRecorder, error) {
+	cr := &cassetteRecorder{
+		path:  path,
+		token: token,
+	}
+
+	// Create the file up front, so that an unwritable path fails before any
+	// request is sent.
+	if err := cr.cassette.write(path); err != nil {
+		return nil, err
+	}
+
+	return cr, nil
+}
+
+// record adds a request and its response, as dumped by a recorder, to the
+// cassette.
+func (cr *cassetteRecorder) record(req *http.Request, reqDump, respDump []byte) {
+	if req.Context().Value(withoutRecordingKey{}) != nil {
+		return
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	interaction, err := cr.interaction(reqDump, respDump)
+	if err != nil {
+		if cr.err == nil {
+			cr.err = err
+		}
+		return
+	}
+	cr.cassette.Interactions = append(cr.cassette.Interactions, interaction)
+}
+
+func (cr *cassetteRecorder) interaction(reqDump, respDump []byte) (cassetteInteraction, error) {
+	req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(reqDump)))
+	if err != nil {
+		return cassetteInteraction{}, fmt.Errorf("could not record request: %v", err)
+	}
+	reqBody, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return cassetteInteraction{}, fmt.Errorf("could not record request: %v", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(respDump)), req)
+	if err != nil {
+		return cassetteInteraction{}, fmt.Errorf("could not record response: %v", err)
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return cassetteInteraction{}, fmt.Errorf("could not record response: %v", err)
+	}
+
+	headers := resp.Header.Clone()
+	headers.Del("Set-Cookie")
+	headers.Del("Date")
+
+	return cassetteInteraction{
+		Request: cassetteRequest{
+			Method: req.Method,
+			URL:    cr.sanitize(req.URL.RequestURI()),
+			Body:   cr.sanitize(string(reqBody)),
+		},
+		Response: cassetteResponse{
+			Status:  resp.StatusCode,
+			Headers: headers,
+			Body:    cr.sanitize(string(respBody)),
+		},
+	}, nil
+}
+
+// save writes the cassette to its file.
+func (cr *cassetteRecorder) save() error {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if cr.err != nil {
+		return cr.err
+	}
+	return cr.cassette.write(cr.path)
+}
+
+func (cr *cassetteRecorder) sanitize(s string) string {
+	if cr.token == "" {
+		return s
+	}
+	return strings.Replace(s, cr.token, redacted, -1)
+}
+
+// cassettePlayer answers requests from a cassette instead of the API.
+// Requests are matched by method, path and query, and requests that were
+// recorded more than once are answered in the order they were recorded.
+type cassettePlayer struct {
+	mu      sync.Mutex
+	pending map[string][]cassetteResponse
+}
+
+func newCassettePlayer(path string) (*cassettePlayer, error) {
+	c, err := readCassette(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := &cassettePlayer{pending: map[string][]cassetteResponse{}}
+	for _, i := range c.Interactions {
+		key := i.Request.Method + " " + i.Request.URL
+		cp.pending[key] = append(cp.pending[key], i.Response)
+	}
+
+	return cp, nil
+}
+
+func (cp *cassettePlayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	key := req.Method + " " + req.URL.RequestURI()
+
+	cp.mu.Lock()
+	responses := cp.pending[key]
+	if len(responses) == 0 {
+		cp.mu.Unlock()
+		return nil, fmt.Errorf("the cassette has no recorded response for %s", key)
+	}
+	r := responses[0]
+	cp.pending[key] = responses[1:]
+	cp.mu.Unlock()
+
+	header := r.Headers.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}, nil
+}
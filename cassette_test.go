@@ -0,0 +1,111 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blcli
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bl-cassette")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		count++
+		body, _ := ioutil.ReadAll(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Write([]byte(`{"count":` + strconv.Itoa(count) + `,"echo":` + string(body) + `}`))
+	}))
+	defer server.Close()
+
+	// Record two identical requests and a request containing the token.
+	cr, err := newCassetteRecorder(path, "some-magic-token")
+	require.NoError(t, err)
+	client := &http.Client{Transport: newRecorder(http.DefaultTransport, nil, cr.record)}
+
+	do := func(client *http.Client, method, url, body string) (int, string) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer some-magic-token")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(b)
+	}
+
+	_, body := do(client, http.MethodGet, server.URL+"/v2/servers?page=1", "null")
+	assert.Equal(t, `{"count":1,"echo":null}`, body)
+	do(client, http.MethodGet, server.URL+"/v2/servers?page=1", "null")
+	do(client, http.MethodPost, server.URL+"/v2/tags", `{"name":"some-magic-token"}`)
+
+	// Requests made without recording are left out.
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/v2/account", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req.WithContext(WithoutRecording(req.Context())))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// The cassette is only written when the command finishes.
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "/v2/servers")
+	require.NoError(t, cr.save())
+
+	b, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "some-magic-token")
+	assert.NotContains(t, string(b), "secret")
+	assert.Contains(t, string(b), `"body": "{\"name\":\"REDACTED\"}"`)
+	assert.NotContains(t, string(b), "/v2/account")
+
+	// Replay the cassette without a server.
+	server.Close()
+
+	player, err := newCassettePlayer(path)
+	require.NoError(t, err)
+	client = &http.Client{Transport: player}
+
+	status, body := do(client, http.MethodGet, "http://example.com/v2/servers?page=1", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"count":1,"echo":null}`, body)
+
+	_, body = do(client, http.MethodGet, "http://example.com/v2/servers?page=1", "")
+	assert.Equal(t, `{"count":2,"echo":null}`, body)
+
+	_, body = do(client, http.MethodPost, "http://example.com/v2/tags", "")
+	assert.Equal(t, `{"count":3,"echo":{"name":"REDACTED"}}`, body)
+
+	req, err = http.NewRequest(http.MethodGet, "http://example.com/v2/servers?page=1", nil)
+	require.NoError(t, err)
+	_, err = client.Do(req)
+	assert.EqualError(t, err, "Get \"http://example.com/v2/servers?page=1\": the cassette has no recorded response for GET /v2/servers?page=1")
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/go-binarylane"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// maxActionWaiters is the number of actions that are polled at once, so that
+// waiting on many actions doesn't exceed the API rate limit.
+const maxActionWaiters = 10
+
+// actionProgressOut is where the progress of actions being waited for is
+// reported. It can be replaced in tests.
+var actionProgressOut io.Writer = os.Stderr
+
+// actionWaitFlags adds the --wait and --wait-timeout flags to a command that
+// starts actions.
+func actionWaitFlags(cmd *Command) {
+	AddBoolFlag(cmd, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+	AddStringFlag(cmd, blcli.ArgWaitTimeout, "", "", "Maximum time to wait for the action to complete with --wait, such as 30s or 10m. Unlimited by default")
+}
+
+// getWaitTimeout returns the --wait-timeout of a command.
+func getWaitTimeout(c *CmdConfig) (time.Duration, error) {
+	s, err := c.Doit.GetString(c.NS, blcli.ArgWaitTimeout)
+	if err != nil || s == "" {
+		return 0, err
+	}
+
+	timeout, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, usageErr(fmt.Errorf("Invalid --%s %q: %v", blcli.ArgWaitTimeout, s, err))
+	}
+	return timeout, nil
+}
+
+// waitForActions polls actions until none of them are in progress, with up
+// to maxActionWaiters at once, and returns their last states. When more
+// than one action is waited for, their progress is reported as a table on a
+// terminal or as a line per change of status otherwise. It fails when the
+// timeout, if there is one, expires first, or when any action errored.
+func waitForActions(c *CmdConfig, actions bl.Actions, pollTime int, timeout time.Duration) (bl.Actions, error) {
+	out := make(bl.Actions, len(actions))
+	copy(out, actions)
+
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	var progress actionProgress = nopActionProgress{}
+	if len(actions) > 1 {
+		progress = newActionProgress(actionProgressOut, isTerminal(actionProgressOut))
+	}
+
+	var (
+		mu    sync.Mutex
+		errs  = make([]error, len(out))
+		wg    sync.WaitGroup
+		queue = make(chan int)
+	)
+
+	progress.update(out)
+
+	workers := maxActionWaiters
+	if len(out) < workers {
+		workers = len(out)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				errs[i] = pollAction(c, out[i].ID, pollTime, deadline, func(a *bl.Action) {
+					mu.Lock()
+					defer mu.Unlock()
+					out[i] = *a
+					progress.update(out)
+				})
+			}
+		}()
+	}
+	for i := range out {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	progress.done(out)
+
+	for _, err := range errs {
+		if err != nil {
+			return out, err
+		}
+	}
+
+	var pending, errored int
+	for _, a := range out {
+		switch a.Status {
+		case "in-progress":
+			pending++
+		case "errored":
+			errored++
+		}
+	}
+
+	switch {
+	case pending > 0:
+		return out, &cliError{
+			err:      fmt.Errorf("timed out after %s waiting for %d of %d actions", timeout, pending, len(out)),
+			code:     "timeout",
+			exitCode: ExitTimeout,
+		}
+	case errored > 0:
+		return out, &cliError{
+			err:      fmt.Errorf("%d of %d actions errored", errored, len(out)),
+			code:     "action_errored",
+			exitCode: ExitError,
+		}
+	}
+	return out, nil
+}
+
+// pollAction polls an action until it is no longer in progress or the
+// deadline, if there is one, passes. Each state of the action is passed to
+// update.
+func pollAction(c *CmdConfig, id, pollTime int, deadline time.Time, update func(*bl.Action)) error {
+	if id == 0 && c.dryRunIntercepted() {
+		// The action was never started, so there is nothing to wait for.
+		update(&bl.Action{Action: &binarylane.Action{Status: "completed"}})
+		return nil
+	}
+
+	for {
+		a, err := c.Actions().Get(id)
+		if err != nil {
+			return err
+		}
+		update(a)
+
+		if a.Status != "in-progress" {
+			return nil
+		}
+
+		wait := time.Duration(pollTime) * time.Second
+		if !deadline.IsZero() {
+			left := time.Until(deadline)
+			if left <= 0 {
+				return nil
+			}
+			if left < wait {
+				wait = left
+			}
+		}
+		time.Sleep(wait)
+	}
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && terminal.IsTerminal(int(f.Fd()))
+}
+
+// actionProgress reports the progress of the actions being waited for.
+type actionProgress interface {
+	update(actions bl.Actions)
+	done(actions bl.Actions)
+}
+
+// newActionProgress reports progress as a table on a terminal, or as lines
+// otherwise.
+func newActionProgress(w io.Writer, tty bool) actionProgress {
+	if tty {
+		return &actionProgressTable{w: w, start: time.Now()}
+	}
+	return &actionProgressLines{w: w, status: map[int]string{}}
+}
+
+type nopActionProgress struct{}
+
+func (nopActionProgress) update(bl.Actions) {}
+func (nopActionProgress) done(bl.Actions)   {}
+
+// actionProgressTable redraws a table of the actions each time one of them
+// is polled.
+type actionProgressTable struct {
+	w     io.Writer
+	start time.Time
+	lines int
+}
+
+func (p *actionProgressTable) update(actions bl.Actions) {
+	var buf bytes.Buffer
+	if p.lines > 0 {
+		// Move back to the start of the table to draw over it.
+		fmt.Fprintf(&buf, "\x1b[%dA", p.lines)
+	}
+
+	var table bytes.Buffer
+	tw := tabwriter.NewWriter(&table, 0, 4, 4, ' ', 0)
+	fmt.Fprintln(tw, "ID\tType\tResource\tStatus\t")
+	for _, a := range actions {
+		resource := ""
+		if a.ResourceType != "" {
+			resource = fmt.Sprintf("%s %d", a.ResourceType, a.ResourceID)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t\n", a.ID, a.Type, resource, a.Status)
+	}
+	tw.Flush()
+
+	p.lines = 0
+	for _, line := range bytes.SplitAfter(table.Bytes(), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		buf.WriteString("\x1b[2K")
+		buf.Write(line)
+		p.lines++
+	}
+	fmt.Fprintf(&buf, "\x1b[2K%s elapsed", time.Since(p.start).Round(time.Second))
+	buf.WriteString("\r")
+	p.w.Write(buf.Bytes())
+}
+
+func (p *actionProgressTable) done(actions bl.Actions) {
+	p.update(actions)
+	fmt.Fprintln(p.w)
+}
+
+// actionProgressLines writes a line each time the status of an action
+// changes. Actions that haven't been polled yet have no status.
+type actionProgressLines struct {
+	w      io.Writer
+	status map[int]string
+}
+
+func (p *actionProgressLines) update(actions bl.Actions) {
+	for _, a := range actions {
+		if a.Status == "" || p.status[a.ID] == a.Status {
+			continue
+		}
+		p.status[a.ID] = a.Status
+		fmt.Fprintf(p.w, "action %d (%s on %s %d): %s\n", a.ID, a.Type, a.ResourceType, a.ResourceID, a.Status)
+	}
+}
+
+func (p *actionProgressLines) done(bl.Actions) {}
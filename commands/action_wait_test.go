@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/go-binarylane"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withActionProgress(t *testing.T) *bytes.Buffer {
+	out := actionProgressOut
+	t.Cleanup(func() {
+		actionProgressOut = out
+	})
+
+	buf := &bytes.Buffer{}
+	actionProgressOut = buf
+	return buf
+}
+
+func testServerAction(id int, status string) *bl.Action {
+	return &bl.Action{Action: &binarylane.Action{ID: id, Status: status, Type: "reboot", ResourceID: id * 10, ResourceType: "server"}}
+}
+
+func TestRunCmdActionWaitMany(t *testing.T) {
+	progress := withActionProgress(t)
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.actions.EXPECT().Get(1).Return(testServerAction(1, "completed"), nil)
+		tm.actions.EXPECT().Get(2).Return(testServerAction(2, "completed"), nil)
+		tm.actions.EXPECT().Get(3).Return(testServerAction(3, "completed"), nil)
+
+		config.Args = []string{"1", "2", "3"}
+		config.Doit.Set(config.NS, blcli.ArgPollTime, 1)
+
+		err := RunCmdActionWait(config)
+		assert.NoError(t, err)
+
+		assert.Contains(t, progress.String(), "action 1 (reboot on server 10): completed\n")
+		assert.Contains(t, progress.String(), "action 3 (reboot on server 30): completed\n")
+	})
+}
+
+func TestRunCmdActionWaitMissingArgs(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		err := RunCmdActionWait(config)
+		assert.Error(t, err)
+	})
+}
+
+func TestWaitForActionsErrored(t *testing.T) {
+	withActionProgress(t)
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.actions.EXPECT().Get(1).Return(testServerAction(1, "completed"), nil)
+		tm.actions.EXPECT().Get(2).Return(testServerAction(2, "errored"), nil)
+
+		actions, err := waitForActions(config, bl.Actions{*testServerAction(1, "in-progress"), *testServerAction(2, "in-progress")}, 1, 0)
+		assert.EqualError(t, err, "1 of 2 actions errored")
+		assert.Equal(t, "action_errored", describeErr(err).Code)
+		require.Len(t, actions, 2)
+		assert.Equal(t, "completed", actions[0].Status)
+		assert.Equal(t, "errored", actions[1].Status)
+	})
+}
+
+func TestWaitForActionsTimeout(t *testing.T) {
+	withActionProgress(t)
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.actions.EXPECT().Get(1).Return(testServerAction(1, "in-progress"), nil).MinTimes(1)
+
+		start := time.Now()
+		actions, err := waitForActions(config, bl.Actions{*testServerAction(1, "in-progress")}, 5, 50*time.Millisecond)
+		assert.EqualError(t, err, "timed out after 50ms waiting for 1 of 1 actions")
+		assert.Equal(t, ExitTimeout, describeErr(err).ExitCode)
+		assert.True(t, time.Since(start) < 5*time.Second)
+		assert.Equal(t, "in-progress", actions[0].Status)
+	})
+}
+
+func TestWaitForActionsAPIError(t *testing.T) {
+	withActionProgress(t)
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.actions.EXPECT().Get(1).Return(nil, errors.New("boom"))
+
+		_, err := waitForActions(config, bl.Actions{*testServerAction(1, "in-progress")}, 1, 0)
+		assert.EqualError(t, err, "boom")
+	})
+}
+
+func TestActionProgressTable(t *testing.T) {
+	var buf bytes.Buffer
+	p := newActionProgress(&buf, true)
+
+	actions := bl.Actions{*testServerAction(1, "in-progress"), {Action: &binarylane.Action{ID: 2}}}
+	p.update(actions)
+	assert.Contains(t, buf.String(), "\x1b[2KID    Type      Resource     Status         \n")
+	assert.Contains(t, buf.String(), "\x1b[2K1     reboot    server 10    in-progress    \n")
+	assert.NotContains(t, buf.String(), "\x1b[3A")
+
+	buf.Reset()
+	p.done(actions)
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("\x1b[3A")))
+	assert.True(t, bytes.HasSuffix(buf.Bytes(), []byte("\r\n")))
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/bl-cli/commands/displayers"
+	"github.com/spf13/viper"
+)
+
+// The events written by action watch.
+const (
+	actionEventStart    = "start"
+	actionEventProgress = "progress"
+	actionEventComplete = "complete"
+)
+
+// RunCmdActionWatch polls the actions of the account and displays an event
+// each time one starts, changes status or completes. It returns when the
+// command is interrupted or --timeout expires.
+func RunCmdActionWatch(c *CmdConfig) error {
+	// The events of each poll are written as they are found, which only
+	// makes a valid stream in the formats written a line at a time.
+	switch output := viper.GetString(blcli.ArgOutput); output {
+	case "json", "yaml":
+		return usageErr(fmt.Errorf("action watch writes a stream of events, which the %s output type can't represent. Use --output ndjson instead", output))
+	}
+
+	filter, err := getActionFilter(c)
+	if err != nil {
+		return err
+	}
+
+	pollTime, err := c.Doit.GetInt(c.NS, blcli.ArgPollTime)
+	if err != nil {
+		return err
+	}
+
+	w := &actionWatcher{}
+	headerWritten := false
+	for {
+		actions, err := c.Actions().List()
+		if err != nil {
+			if c.Ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		var events bl.ActionEvents
+		for _, e := range w.events(actions, time.Now()) {
+			if filter.match(e.Action) {
+				events = append(events, e)
+			}
+		}
+
+		if len(events) > 0 {
+			// The events are one table, so its header is only written
+			// once.
+			if err := c.display(&displayers.ActionEvent{Events: events}, headerWritten); err != nil {
+				return err
+			}
+			headerWritten = true
+		}
+
+		if c.Ctx.Err() != nil {
+			return nil
+		}
+		select {
+		case <-c.Ctx.Done():
+			return nil
+		case <-time.After(time.Duration(pollTime) * time.Second):
+		}
+	}
+}
+
+// actionWatcher finds the events of actions by comparing each list of
+// actions with the one before.
+type actionWatcher struct {
+	status map[int]string
+}
+
+// events returns the events of the actions that started or changed since
+// the last list. The first list is only checked for actions in progress, as
+// the others finished before the watch began.
+func (w *actionWatcher) events(actions bl.Actions, now time.Time) bl.ActionEvents {
+	first := w.status == nil
+	if first {
+		w.status = map[int]string{}
+	}
+
+	sorted := make(bl.Actions, len(actions))
+	copy(sorted, actions)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	var events bl.ActionEvents
+	add := func(event string, a bl.Action) {
+		events = append(events, bl.ActionEvent{Event: event, Time: now, Action: a})
+	}
+
+	for _, a := range sorted {
+		last, seen := w.status[a.ID]
+		w.status[a.ID] = a.Status
+
+		finished := a.Status != "in-progress"
+		switch {
+		case !seen && first:
+			if !finished {
+				add(actionEventStart, a)
+			}
+		case !seen:
+			add(actionEventStart, a)
+			if finished {
+				add(actionEventComplete, a)
+			}
+		case last != a.Status && finished:
+			add(actionEventComplete, a)
+		case last != a.Status:
+			add(actionEventProgress, a)
+		}
+	}
+
+	return events
+}
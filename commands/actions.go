@@ -21,6 +21,7 @@ import (
 	"github.com/binarylane/bl-cli"
 	"github.com/binarylane/bl-cli/bl"
 	"github.com/binarylane/bl-cli/commands/displayers"
+	"github.com/binarylane/go-binarylane"
 	"github.com/spf13/cobra"
 )
 
@@ -59,11 +60,24 @@ This can be filtered to a specific action. For example, while ` + "`" + `bl comp
 	AddStringFlag(cmdActionList, blcli.ArgActionStatus, "", "", "Action status")
 	AddStringFlag(cmdActionList, blcli.ArgActionType, "", "", "Action type")
 
-	cmdActionWait := CmdBuilder(cmd, RunCmdActionWait, "wait <action-id>", "Block thread until an action completes", `The command blocks the current thread, returning when an action completes.
+	cmdActionWatch := CmdBuilder(cmd, RunCmdActionWatch, "watch", "Stream the actions taken on your resources as they happen", `This command polls the actions taken on your resources and writes an event each time one starts, changes status or completes, until it is interrupted with Ctrl-C or the `+"`"+`--timeout`+"`"+` expires.
 
-For example, if you find an action when calling `+"`"+`bl compute action list`+"`"+` that has a status of `+"`"+`in-progress`+"`"+`, you can note the action ID and call `+"`"+`bl compute action wait <action-id>`+"`"+`, and bl will appear to "hang" until the action has completed. This can be useful for scripting purposes.`, Writer,
+The events are `+"`"+`start`+"`"+`, `+"`"+`progress`+"`"+` and `+"`"+`complete`+"`"+`, the last of which is written for actions that completed or errored. Actions that were already in progress when the command starts are written as started. Use `+"`"+`--output ndjson`+"`"+` to write one JSON object per event; the json and yaml output types, which write a single document, are not supported.`, Writer,
+		displayerType(&displayers.ActionEvent{}))
+	AddStringFlag(cmdActionWatch, blcli.ArgActionResourceType, "", "", "Action resource type")
+	AddStringFlag(cmdActionWatch, blcli.ArgActionRegion, "", "", "Action region")
+	AddStringFlag(cmdActionWatch, blcli.ArgActionStatus, "", "", "Action status")
+	AddStringFlag(cmdActionWatch, blcli.ArgActionType, "", "", "Action type")
+	AddIntFlag(cmdActionWatch, blcli.ArgPollTime, "", 5, "Re-poll time in seconds")
+
+	cmdActionWait := CmdBuilder(cmd, RunCmdActionWait, "wait <action-id>...", "Block thread until actions complete", `The command blocks the current thread, returning when the actions complete.
+
+For example, if you find an action when calling `+"`"+`bl compute action list`+"`"+` that has a status of `+"`"+`in-progress`+"`"+`, you can note the action ID and call `+"`"+`bl compute action wait <action-id>`+"`"+`, and bl will appear to "hang" until the action has completed. This can be useful for scripting purposes.
+
+Several actions are polled at once. While more than one action is waited for, their progress is shown on stderr, as a table on a terminal or as a line each time the status of an action changes otherwise. The command fails when `+"`"+`--wait-timeout`+"`"+` expires first, or when any of the actions errored.`, Writer,
 		aliasOpt("w"), displayerType(&displayers.Action{}))
 	AddIntFlag(cmdActionWait, blcli.ArgPollTime, "", 5, "Re-poll time in seconds")
+	AddStringFlag(cmdActionWait, blcli.ArgWaitTimeout, "", "", "Maximum time to wait for the actions to complete, such as 30s or 10m. Unlimited by default")
 
 	return cmd
 }
@@ -98,7 +112,12 @@ func (a actionsByCompletedAt) Less(i, j int) bool {
 	return a[i].CompletedAt.Before(a[j].CompletedAt.Time)
 }
 
-func filterActionList(c *CmdConfig, in bl.Actions) (bl.Actions, error) {
+// actionFilter holds the filters that action list and action watch share.
+type actionFilter struct {
+	resourceType, region, status, actionType string
+}
+
+func getActionFilter(c *CmdConfig) (*actionFilter, error) {
 	resourceType, err := c.Doit.GetString(c.NS, blcli.ArgActionResourceType)
 	if err != nil {
 		return nil, err
@@ -119,6 +138,31 @@ func filterActionList(c *CmdConfig, in bl.Actions) (bl.Actions, error) {
 		return nil, err
 	}
 
+	return &actionFilter{resourceType: resourceType, region: region, status: status, actionType: actionType}, nil
+}
+
+// match reports whether an action matches the filters.
+func (f *actionFilter) match(a bl.Action) bool {
+	switch {
+	case f.resourceType != "" && a.ResourceType != f.resourceType:
+		return false
+	case f.region != "" && a.RegionSlug != f.region:
+		return false
+	case f.status != "" && a.Status != f.status:
+		return false
+	case f.actionType != "" && a.Type != f.actionType:
+		return false
+	default:
+		return true
+	}
+}
+
+func filterActionList(c *CmdConfig, in bl.Actions) (bl.Actions, error) {
+	filter, err := getActionFilter(c)
+	if err != nil {
+		return nil, err
+	}
+
 	var before, after time.Time
 	beforeStr, err := c.Doit.GetString(c.NS, blcli.ArgActionBefore)
 	if err != nil {
@@ -144,23 +188,7 @@ func filterActionList(c *CmdConfig, in bl.Actions) (bl.Actions, error) {
 	out := bl.Actions{}
 
 	for _, a := range in {
-		match := true
-
-		if resourceType != "" && a.ResourceType != resourceType {
-			match = false
-		}
-
-		if match && region != "" && a.RegionSlug != region {
-			match = false
-		}
-
-		if match && status != "" && a.Status != status {
-			match = false
-		}
-
-		if match && actionType != "" && a.Type != actionType {
-			match = false
-		}
+		match := filter.match(a)
 
 		if a.CompletedAt == nil {
 			match = false
@@ -208,16 +236,19 @@ func RunCmdActionGet(c *CmdConfig) error {
 	return c.Display(&displayers.Action{Actions: bl.Actions{*a}})
 }
 
-// RunCmdActionWait waits for an action to complete or error.
+// RunCmdActionWait waits for actions to complete or error.
 func RunCmdActionWait(c *CmdConfig) error {
-	err := ensureOneArg(c)
-	if err != nil {
-		return err
+	if len(c.Args) == 0 {
+		return blcli.NewMissingArgsErr(c.NS)
 	}
 
-	id, err := strconv.Atoi(c.Args[0])
-	if err != nil {
-		return err
+	actions := make(bl.Actions, 0, len(c.Args))
+	for _, arg := range c.Args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return err
+		}
+		actions = append(actions, bl.Action{Action: &binarylane.Action{ID: id}})
 	}
 
 	pollTime, err := c.Doit.GetInt(c.NS, blcli.ArgPollTime)
@@ -225,15 +256,24 @@ func RunCmdActionWait(c *CmdConfig) error {
 		return err
 	}
 
-	a, err := actionWait(c, id, pollTime)
+	timeout, err := getWaitTimeout(c)
 	if err != nil {
 		return err
 	}
 
-	return c.Display(&displayers.Action{Actions: bl.Actions{*a}})
+	actions, waitErr := waitForActions(c, actions, pollTime, timeout)
+	if err := c.Display(&displayers.Action{Actions: actions}); err != nil {
+		return err
+	}
+	return waitErr
 }
 
 func actionWait(c *CmdConfig, actionID, pollTime int) (*bl.Action, error) {
+	if actionID == 0 && c.dryRunIntercepted() {
+		// The action was never started, so there is nothing to wait for.
+		return &bl.Action{Action: &binarylane.Action{Status: "completed"}}, nil
+	}
+
 	as := c.Actions()
 
 	var a *bl.Action
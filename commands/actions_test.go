@@ -14,12 +14,16 @@ limitations under the License.
 package commands
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/binarylane/bl-cli"
 	"github.com/binarylane/bl-cli/bl"
 	"github.com/binarylane/go-binarylane"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -33,7 +37,7 @@ var (
 func TestActionsCommand(t *testing.T) {
 	cmd := Actions()
 	assert.NotNil(t, cmd)
-	assertCommandNames(t, cmd, "get", "list", "wait")
+	assertCommandNames(t, cmd, "get", "list", "wait", "watch")
 }
 
 func TestActionList(t *testing.T) {
@@ -101,3 +105,94 @@ func Test_filterActions(t *testing.T) {
 		})
 	}
 }
+
+func TestActionWatcherEvents(t *testing.T) {
+	action := func(id int, status string) bl.Action {
+		return bl.Action{Action: &binarylane.Action{ID: id, Status: status}}
+	}
+	events := func(list bl.ActionEvents) []string {
+		var out []string
+		for _, e := range list {
+			out = append(out, fmt.Sprintf("%s %d %s", e.Event, e.ID, e.Status))
+		}
+		return out
+	}
+
+	w := &actionWatcher{}
+	now := time.Now()
+
+	// Actions that finished before the watch began aren't events.
+	got := w.events(bl.Actions{action(2, "in-progress"), action(1, "completed")}, now)
+	assert.Equal(t, []string{"start 2 in-progress"}, events(got))
+
+	got = w.events(bl.Actions{action(3, "in-progress"), action(2, "completed"), action(1, "completed")}, now)
+	assert.Equal(t, []string{"complete 2 completed", "start 3 in-progress"}, events(got))
+
+	// An action that started and finished between polls has both events.
+	got = w.events(bl.Actions{action(4, "errored"), action(3, "in-progress"), action(2, "completed")}, now)
+	assert.Equal(t, []string{"start 4 errored", "complete 4 errored"}, events(got))
+	assert.Equal(t, now, got[0].Time)
+}
+
+func TestRunCmdActionWatch(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		ctx, cancel := context.WithCancel(context.Background())
+		config.Ctx = ctx
+
+		running := bl.Action{Action: &binarylane.Action{ID: 1, Status: "in-progress", Type: "reboot", ResourceType: "server"}}
+		other := bl.Action{Action: &binarylane.Action{ID: 2, Status: "in-progress", Type: "create", ResourceType: "load_balancer"}}
+		tm.actions.EXPECT().List().DoAndReturn(func() (bl.Actions, error) {
+			cancel()
+			return bl.Actions{running, other}, nil
+		})
+
+		buf := &bytes.Buffer{}
+		config.Out = buf
+		config.Doit.Set(config.NS, blcli.ArgActionResourceType, "server")
+		config.Doit.Set(config.NS, blcli.ArgFormat, "Event,ID,Type")
+		config.Doit.Set(config.NS, blcli.ArgPollTime, 5)
+
+		err := RunCmdActionWatch(config)
+		assert.NoError(t, err)
+		assert.Equal(t, "Event    ID    Type\nstart    1     reboot\n", buf.String())
+	})
+}
+
+func TestRunCmdActionWatchHeaderOnce(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		ctx, cancel := context.WithCancel(context.Background())
+		config.Ctx = ctx
+
+		running := bl.Action{Action: &binarylane.Action{ID: 1, Status: "in-progress", Type: "reboot", ResourceType: "server"}}
+		completed := bl.Action{Action: &binarylane.Action{ID: 1, Status: "completed", Type: "reboot", ResourceType: "server"}}
+		first := tm.actions.EXPECT().List().Return(bl.Actions{running}, nil)
+		tm.actions.EXPECT().List().DoAndReturn(func() (bl.Actions, error) {
+			cancel()
+			return bl.Actions{completed}, nil
+		}).After(first)
+
+		buf := &bytes.Buffer{}
+		config.Out = buf
+		config.Doit.Set(config.NS, blcli.ArgFormat, "Event,ID")
+		config.Doit.Set(config.NS, blcli.ArgPollTime, 0)
+
+		err := RunCmdActionWatch(config)
+		assert.NoError(t, err)
+		assert.Equal(t, "Event    ID\nstart    1\ncomplete    1\n", buf.String())
+
+		noHeader, err := config.Doit.GetBool(config.NS, blcli.ArgNoHeader)
+		assert.NoError(t, err)
+		assert.False(t, noHeader)
+	})
+}
+
+func TestRunCmdActionWatchJSON(t *testing.T) {
+	defer viper.Set(blcli.ArgOutput, viper.GetString(blcli.ArgOutput))
+	viper.Set(blcli.ArgOutput, "json")
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		err := RunCmdActionWatch(config)
+		assert.EqualError(t, err, "action watch writes a stream of events, which the json output type can't represent. Use --output ndjson instead")
+		assert.Equal(t, ExitUsage, describeErr(err).ExitCode)
+	})
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/bl-cli/commands/displayers"
+)
+
+const stackSpecDesc = `The stack spec is a YAML or JSON file listing the desired ` + "`" + `vpcs` + "`" + `, ` + "`" + `servers` + "`" + `, ` + "`" + `load_balancers` + "`" + `, ` + "`" + `firewalls` + "`" + ` and ` + "`" + `domains` + "`" + `, for example:
+
+	vpcs:
+	  - name: prod
+	    region: syd
+	servers:
+	  - name: web-1
+	    region: syd
+	    size: std-min
+	    image: ubuntu-20-04-lts
+	    vpc: prod
+	    tags: [web]
+	load_balancers:
+	  - name: web
+	    region: syd
+	    vpc: prod
+	    servers: [web-1]
+	    forwarding_rules:
+	      - {entry_protocol: http, entry_port: 80, target_protocol: http, target_port: 80}
+	firewalls:
+	  - name: web
+	    tags: [web]
+	    inbound_rules:
+	      - protocol: tcp
+	        ports: "22"
+	        sources: {addresses: [0.0.0.0/0]}
+	domains:
+	  - name: example.com
+	    records:
+	      - {type: A, name: www, data: 192.0.2.10}
+
+Resources are matched to live resources by name. References to other resources, such as a server's ` + "`" + `vpc` + "`" + ` or the ` + "`" + `servers` + "`" + ` behind a load balancer, may be names or IDs. Servers are not modified in place apart from their tags.
+
+The spec may also list ` + "`" + `ssh_keys` + "`" + `, ` + "`" + `tags` + "`" + `, ` + "`" + `projects` + "`" + ` and ` + "`" + `floating_ips` + "`" + `, as written by ` + "`" + `bl export` + "`" + `. Floating IPs are matched by address. Entries without an address are matched to a floating IP in their region that is assigned to their ` + "`" + `server` + "`" + `, or else to an unassigned one, and only allocate a new floating IP when there is none.`
+
+// Apply creates the apply command.
+func Apply(parent *Command) *Command {
+	cmdApply := CmdBuilder(parent, RunApply, "apply", "Create or update resources to match a stack spec", `Use this command to compare a stack spec with the resources on your account, display the changes required to bring them in line and then make those changes.
+
+Changes are made in dependency order: VPCs are created before the Servers inside them, and Servers before the load balancers and firewalls they are attached to. Deletions happen in the reverse order.
+
+`+stackSpecDesc, Writer, displayerType(&displayers.StackPlan{}))
+	AddStringFlag(cmdApply, blcli.ArgStackFile, "", "", "Path to a stack spec in YAML or JSON format. Use `-` to read from standard input.", requiredOpt())
+	AddBoolFlag(cmdApply, blcli.ArgStackPrune, "", false, "Delete resources of the kinds declared in the stack spec that the spec does not list")
+	AddBoolFlag(cmdApply, blcli.ArgCommandWait, "", false, "Wait for Servers to become active before continuing")
+	AddBoolFlag(cmdApply, blcli.ArgForce, blcli.ArgShortForce, false, "Apply the changes without a confirmation prompt")
+
+	return cmdApply
+}
+
+// Plan creates the plan command.
+func Plan(parent *Command) *Command {
+	cmdPlan := CmdBuilder(parent, RunPlan, "plan", "Display the changes required to match a stack spec", `Use this command to compare a stack spec with the resources on your account and display the changes `+"`"+`bl apply`+"`"+` would make, without making them.
+
+`+stackSpecDesc, Writer, displayerType(&displayers.StackPlan{}))
+	AddStringFlag(cmdPlan, blcli.ArgStackFile, "", "", "Path to a stack spec in YAML or JSON format. Use `-` to read from standard input.", requiredOpt())
+	AddBoolFlag(cmdPlan, blcli.ArgStackPrune, "", false, "Include deletions of resources of the kinds declared in the stack spec that the spec does not list")
+
+	return cmdPlan
+}
+
+// Destroy creates the destroy command.
+func Destroy(parent *Command) *Command {
+	cmdDestroy := CmdBuilder(parent, RunDestroy, "destroy", "Permanently delete the resources in a stack spec", `Use this command to permanently delete every resource listed in a stack spec that exists on your account. This is irreversible.
+
+Load balancers and firewalls are deleted before the Servers attached to them, and Servers before their VPCs.`, Writer, displayerType(&displayers.StackPlan{}))
+	AddStringFlag(cmdDestroy, blcli.ArgStackFile, "", "", "Path to a stack spec in YAML or JSON format. Use `-` to read from standard input.", requiredOpt())
+	AddBoolFlag(cmdDestroy, blcli.ArgForce, blcli.ArgShortForce, false, "Delete the resources without a confirmation prompt")
+
+	return cmdDestroy
+}
+
+// RunPlan displays the changes required to bring the live resources in line
+// with a stack spec.
+func RunPlan(c *CmdConfig) error {
+	_, plan, err := stackPlanFromArgs(c)
+	if err != nil {
+		return err
+	}
+
+	return c.Display(plan.displayable())
+}
+
+// RunApply brings the live resources in line with a stack spec.
+func RunApply(c *CmdConfig) error {
+	st, plan, err := stackPlanFromArgs(c)
+	if err != nil {
+		return err
+	}
+
+	wait, err := c.Doit.GetBool(c.NS, blcli.ArgCommandWait)
+	if err != nil {
+		return err
+	}
+	st.wait = wait
+
+	return confirmAndApplyStackPlan(c, st, plan, "apply")
+}
+
+// RunDestroy deletes the live resources declared in a stack spec.
+func RunDestroy(c *CmdConfig) error {
+	filename, err := c.Doit.GetString(c.NS, blcli.ArgStackFile)
+	if err != nil {
+		return err
+	}
+
+	spec, err := readStackSpec(filename)
+	if err != nil {
+		return err
+	}
+
+	st, err := loadStackState(c, spec)
+	if err != nil {
+		return err
+	}
+
+	plan, err := buildStackDestroyPlan(st)
+	if err != nil {
+		return err
+	}
+
+	return confirmAndApplyStackPlan(c, st, plan, "destroy")
+}
+
+func stackPlanFromArgs(c *CmdConfig) (*stackState, stackPlan, error) {
+	filename, err := c.Doit.GetString(c.NS, blcli.ArgStackFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prune, err := c.Doit.GetBool(c.NS, blcli.ArgStackPrune)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	spec, err := readStackSpec(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	st, err := loadStackState(c, spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plan, err := buildStackPlan(st, prune)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return st, plan, nil
+}
+
+func confirmAndApplyStackPlan(c *CmdConfig, st *stackState, plan stackPlan, verb string) error {
+	if err := c.Display(plan.displayable()); err != nil {
+		return err
+	}
+
+	if len(plan) == 0 {
+		notice("Nothing to %s: live resources match the stack spec", verb)
+		return nil
+	}
+
+	force, err := c.Doit.GetBool(c.NS, blcli.ArgForce)
+	if err != nil {
+		return err
+	}
+
+	change := "change"
+	if len(plan) > 1 {
+		change = "changes"
+	}
+	if !force && AskForConfirm(fmt.Sprintf("%s %d %s?", verb, len(plan), change)) != nil {
+		return blcli.ErrOperationAborted
+	}
+
+	return applyStackPlan(st, plan)
+}
@@ -0,0 +1,437 @@
+package commands
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/go-binarylane"
+	"github.com/stretchr/testify/assert"
+)
+
+const testStackSpec = `
+vpcs:
+  - name: prod
+    region: syd
+servers:
+  - name: web-1
+    region: syd
+    size: std-min
+    image: ubuntu-20-04-lts
+    vpc: prod
+    tags: [web]
+load_balancers:
+  - name: web-lb
+    region: syd
+    servers: [web-1]
+    forwarding_rules:
+      - {entry_protocol: http, entry_port: 80, target_protocol: http, target_port: 80}
+`
+
+func writeTestStackSpec(t *testing.T, spec string) string {
+	tmpFile, err := ioutil.TempFile(os.TempDir(), "blStackTest-*.yaml")
+	assert.NoError(t, err)
+
+	_, err = tmpFile.WriteString(spec)
+	assert.NoError(t, err)
+	assert.NoError(t, tmpFile.Close())
+
+	return tmpFile.Name()
+}
+
+func TestParseStackSpec(t *testing.T) {
+	spec, err := parseStackSpec([]byte(testStackSpec))
+	assert.NoError(t, err)
+	assert.Len(t, spec.Servers, 1)
+	assert.Equal(t, "prod", spec.Servers[0].VPC)
+	assert.Equal(t, 80, spec.LoadBalancers[0].ForwardingRules[0].EntryPort)
+
+	_, err = parseStackSpec([]byte(`{"servers": [{"name": "web-1", "region": "syd", "size": "std-min", "image": "ubuntu"}]}`))
+	assert.NoError(t, err)
+
+	_, err = parseStackSpec([]byte("servers:\n  - name: web-1\n    region: syd\n"))
+	assert.EqualError(t, err, `Invalid stack spec: server "web-1" is missing "image"`)
+
+	_, err = parseStackSpec([]byte("vpcs:\n  - {name: a, region: syd}\n  - {name: a, region: syd}\n"))
+	assert.Error(t, err)
+
+	_, err = parseStackSpec([]byte("servrs: []\n"))
+	assert.Error(t, err)
+}
+
+func TestPlanCreatesInDependencyOrder(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.vpcs.EXPECT().List().Return(bl.VPCs{}, nil)
+		tm.servers.EXPECT().List().Return(bl.Servers{}, nil)
+		tm.loadBalancers.EXPECT().List().Return(bl.LoadBalancers{}, nil)
+
+		spec, err := parseStackSpec([]byte(testStackSpec))
+		assert.NoError(t, err)
+
+		st, err := loadStackState(config, spec)
+		assert.NoError(t, err)
+
+		plan, err := buildStackPlan(st, false)
+		assert.NoError(t, err)
+
+		var got []string
+		for _, ch := range plan {
+			got = append(got, ch.Action+" "+ch.Kind+" "+ch.Name)
+		}
+		assert.Equal(t, []string{
+			"create vpc prod",
+			"create server web-1",
+			"create load_balancer web-lb",
+		}, got)
+	})
+}
+
+func TestApply(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.vpcs.EXPECT().List().Return(bl.VPCs{}, nil)
+		tm.servers.EXPECT().List().Return(bl.Servers{}, nil)
+		tm.loadBalancers.EXPECT().List().Return(bl.LoadBalancers{}, nil)
+
+		vcr := &binarylane.VPCCreateRequest{Name: "prod", RegionSlug: "syd"}
+		tm.vpcs.EXPECT().Create(vcr).Return(&bl.VPC{VPC: &binarylane.VPC{ID: 7, Name: "prod"}}, nil)
+
+		scr := &binarylane.ServerCreateRequest{
+			Name:    "web-1",
+			Region:  "syd",
+			Size:    "std-min",
+			Image:   binarylane.ServerCreateImage{Slug: "ubuntu-20-04-lts"},
+			SSHKeys: []binarylane.ServerCreateSSHKey{},
+			Tags:    []string{"web"},
+			VPCID:   7,
+		}
+		tm.servers.EXPECT().Create(scr, false).Return(&bl.Server{Server: &binarylane.Server{ID: 42, Name: "web-1"}}, nil)
+
+		lbr := &binarylane.LoadBalancerRequest{
+			Name:      "web-lb",
+			Region:    "syd",
+			ServerIDs: []int{42},
+			ForwardingRules: []binarylane.ForwardingRule{
+				{EntryProtocol: "http", EntryPort: 80, TargetProtocol: "http", TargetPort: 80},
+			},
+		}
+		tm.loadBalancers.EXPECT().Create(lbr).Return(&bl.LoadBalancer{LoadBalancer: &binarylane.LoadBalancer{ID: 9}}, nil)
+
+		path := writeTestStackSpec(t, testStackSpec)
+		defer os.Remove(path)
+
+		config.Doit.Set(config.NS, blcli.ArgStackFile, path)
+		config.Doit.Set(config.NS, blcli.ArgForce, true)
+
+		err := RunApply(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestApplyAPIErrorExitCode(t *testing.T) {
+	tests := []struct {
+		status int
+		exit   int
+	}{
+		{status: http.StatusNotFound, exit: ExitNotFound},
+		{status: http.StatusUnprocessableEntity, exit: ExitInvalid},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+				tm.vpcs.EXPECT().List().Return(bl.VPCs{}, nil).AnyTimes()
+				tm.servers.EXPECT().List().Return(bl.Servers{}, nil).AnyTimes()
+				tm.loadBalancers.EXPECT().List().Return(bl.LoadBalancers{}, nil).AnyTimes()
+
+				apiErr := &binarylane.ErrorResponse{Response: &http.Response{StatusCode: tt.status}}
+				vcr := &binarylane.VPCCreateRequest{Name: "prod", RegionSlug: "syd"}
+				tm.vpcs.EXPECT().Create(vcr).Return(nil, apiErr)
+
+				path := writeTestStackSpec(t, "vpcs:\n  - name: prod\n    region: syd\n")
+				defer os.Remove(path)
+
+				config.Doit.Set(config.NS, blcli.ArgStackFile, path)
+				config.Doit.Set(config.NS, blcli.ArgForce, true)
+
+				err := RunApply(config)
+				assert.Error(t, err)
+				assert.Equal(t, tt.exit, describeErr(err).ExitCode)
+			})
+		})
+	}
+}
+
+func TestPlanUpdatesAndPrunes(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		// The spec doesn't set a description, so the live one is kept.
+		liveVPC := bl.VPC{VPC: &binarylane.VPC{ID: 7, Name: "prod", RegionSlug: "syd", Description: "production"}}
+		liveServer := bl.Server{Server: &binarylane.Server{ID: 42, Name: "web-1", Tags: []string{"old"}}}
+		strayServer := bl.Server{Server: &binarylane.Server{ID: 43, Name: "stray"}}
+		liveLB := bl.LoadBalancer{LoadBalancer: &binarylane.LoadBalancer{
+			ID:        9,
+			Name:      "web-lb",
+			ServerIDs: []int{42},
+			ForwardingRules: []binarylane.ForwardingRule{
+				{EntryProtocol: "http", EntryPort: 8080, TargetProtocol: "http", TargetPort: 80},
+			},
+		}}
+
+		tm.vpcs.EXPECT().List().Return(bl.VPCs{liveVPC}, nil)
+		tm.servers.EXPECT().List().Return(bl.Servers{liveServer, strayServer}, nil)
+		tm.loadBalancers.EXPECT().List().Return(bl.LoadBalancers{liveLB}, nil)
+
+		spec, err := parseStackSpec([]byte(testStackSpec))
+		assert.NoError(t, err)
+
+		st, err := loadStackState(config, spec)
+		assert.NoError(t, err)
+
+		plan, err := buildStackPlan(st, true)
+		assert.NoError(t, err)
+
+		var got []string
+		for _, ch := range plan {
+			got = append(got, ch.Action+" "+ch.Kind+" "+ch.Name+" "+ch.Detail)
+		}
+		assert.Equal(t, []string{
+			"update server web-1 +tag:web -tag:old",
+			"update load_balancer web-lb forwarding_rules",
+			"delete server stray 43",
+		}, got)
+	})
+}
+
+func TestPlanDomainRecords(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.domains.EXPECT().List().Return(bl.Domains{testDomain}, nil)
+		tm.domains.EXPECT().Records("example.com").Return(bl.DomainRecords{
+			{DomainRecord: &binarylane.DomainRecord{ID: 1, Type: "NS", Name: "@", Data: "ns1.binarylane.com.au"}},
+			{DomainRecord: &binarylane.DomainRecord{ID: 2, Type: "A", Name: "www", Data: "192.0.2.10", TTL: 300}},
+			{DomainRecord: &binarylane.DomainRecord{ID: 3, Type: "A", Name: "old", Data: "192.0.2.11"}},
+			{DomainRecord: &binarylane.DomainRecord{ID: 4, Type: "CNAME", Name: "api", Data: "old.example.com."}},
+			{DomainRecord: &binarylane.DomainRecord{ID: 5, Type: "MX", Name: "@", Data: "mail1.example.com.", Priority: 10}},
+		}, nil)
+
+		spec, err := parseStackSpec([]byte(`
+domains:
+  - name: example.com
+    records:
+      - {type: A, name: www, data: 192.0.2.10, ttl: 600}
+      - {type: CNAME, name: api, data: www.example.com.}
+      - {type: MX, name: "@", data: mail2.example.com., priority: 10}
+      - {type: MX, name: "@", data: mail3.example.com., priority: 20}
+`))
+		assert.NoError(t, err)
+
+		st, err := loadStackState(config, spec)
+		assert.NoError(t, err)
+
+		plan, err := buildStackPlan(st, true)
+		assert.NoError(t, err)
+
+		var got []string
+		for _, ch := range plan {
+			got = append(got, ch.Action+" "+ch.Name+" "+ch.Detail)
+		}
+		assert.Equal(t, []string{
+			"update A www.example.com 192.0.2.10",
+			"update CNAME api.example.com www.example.com.",
+			"update MX @.example.com mail2.example.com.",
+			"create MX @.example.com mail3.example.com.",
+			"delete A old.example.com 192.0.2.11",
+		}, got)
+	})
+}
+
+func TestDestroy(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.vpcs.EXPECT().List().Return(bl.VPCs{{VPC: &binarylane.VPC{ID: 7, Name: "prod", RegionSlug: "syd"}}}, nil)
+		tm.servers.EXPECT().List().Return(bl.Servers{{Server: &binarylane.Server{ID: 42, Name: "web-1"}}}, nil)
+		tm.loadBalancers.EXPECT().List().Return(bl.LoadBalancers{{LoadBalancer: &binarylane.LoadBalancer{ID: 9, Name: "web-lb"}}}, nil)
+
+		pi := stackDeletePollInterval
+		stackDeletePollInterval = 0
+		defer func() {
+			stackDeletePollInterval = pi
+		}()
+
+		// The VPC is only deleted once the server is gone.
+		notFound := &binarylane.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+		lbDelete := tm.loadBalancers.EXPECT().Delete(9).Return(nil)
+		serverDelete := tm.servers.EXPECT().Delete(42).Return(nil).After(lbDelete)
+		serverGet := tm.servers.EXPECT().Get(42).Return(&bl.Server{Server: &binarylane.Server{ID: 42}}, nil).After(serverDelete)
+		serverGone := tm.servers.EXPECT().Get(42).Return(nil, notFound).After(serverGet)
+		tm.vpcs.EXPECT().Delete(7).Return(nil).After(serverGone)
+
+		path := writeTestStackSpec(t, testStackSpec)
+		defer os.Remove(path)
+
+		config.Doit.Set(config.NS, blcli.ArgStackFile, path)
+		config.Doit.Set(config.NS, blcli.ArgForce, true)
+
+		err := RunDestroy(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestPlanAccountResources(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.keys.EXPECT().List().Return(bl.SSHKeys{{Key: &binarylane.Key{ID: 1, Name: "old"}}}, nil)
+		tm.tags.EXPECT().List().Return(bl.Tags{{Tag: &binarylane.Tag{Name: "web"}}}, nil)
+		tm.servers.EXPECT().List().Return(bl.Servers{{Server: &binarylane.Server{ID: 42, Name: "web-1"}}}, nil)
+		syd := &binarylane.Region{Slug: "syd"}
+		tm.floatingIPs.EXPECT().List().Return(bl.FloatingIPs{
+			{FloatingIP: &binarylane.FloatingIP{IP: "192.0.2.1", Region: syd}},
+			{FloatingIP: &binarylane.FloatingIP{IP: "192.0.2.2", Region: syd, Server: &binarylane.Server{ID: 42}}},
+			{FloatingIP: &binarylane.FloatingIP{IP: "192.0.2.3", Region: syd}},
+			{FloatingIP: &binarylane.FloatingIP{IP: "192.0.2.4", Region: syd, Server: &binarylane.Server{ID: 43}}},
+		}, nil)
+
+		spec, err := parseStackSpec([]byte(`
+ssh_keys:
+  - {name: laptop, public_key: ssh-ed25519 AAAA}
+tags: [web, db]
+floating_ips:
+  - {ip: 192.0.2.1, region: syd, server: web-1}
+  - {region: syd, server: web-1}
+  - {region: syd}
+  - {region: mel}
+`))
+		assert.NoError(t, err)
+
+		st, err := loadStackState(config, spec)
+		assert.NoError(t, err)
+
+		plan, err := buildStackPlan(st, true)
+		assert.NoError(t, err)
+
+		var got []string
+		for _, ch := range plan {
+			got = append(got, ch.Action+" "+ch.Kind+" "+ch.Name+" "+ch.Detail)
+		}
+		assert.Equal(t, []string{
+			"create ssh_key laptop ",
+			"create tag db ",
+			"update floating_ip 192.0.2.1 web-1",
+			"create floating_ip mel ",
+			"delete floating_ip 192.0.2.4 ",
+			"delete ssh_key old 1",
+		}, got)
+
+		tm.floatingIPActions.EXPECT().Assign("192.0.2.1", 42).Return(&bl.Action{}, nil)
+		assert.NoError(t, plan[2].run(st))
+	})
+}
+
+func TestPlanLoadBalancerPartialHealthCheck(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		// The API fills in the interval, timeout and thresholds that the spec
+		// leaves out, which mustn't show up as changes.
+		liveLB := bl.LoadBalancer{LoadBalancer: &binarylane.LoadBalancer{
+			ID:   9,
+			Name: "web-lb",
+			ForwardingRules: []binarylane.ForwardingRule{
+				{EntryProtocol: "http", EntryPort: 80, TargetProtocol: "http", TargetPort: 80},
+			},
+			HealthCheck: &binarylane.HealthCheck{
+				Protocol: "http", Port: 80, Path: "/healthz",
+				CheckIntervalSeconds: 10, ResponseTimeoutSeconds: 5, HealthyThreshold: 5, UnhealthyThreshold: 3,
+			},
+			StickySessions: &binarylane.StickySessions{Type: "cookies", CookieName: "lb", CookieTtlSeconds: 300},
+		}}
+		tm.vpcs.EXPECT().List().Return(bl.VPCs{}, nil).Times(2)
+		tm.servers.EXPECT().List().Return(bl.Servers{}, nil).Times(2)
+		tm.loadBalancers.EXPECT().List().Return(bl.LoadBalancers{liveLB}, nil).Times(2)
+
+		lbSpec := func(path string) string {
+			return `
+load_balancers:
+  - name: web-lb
+    region: syd
+    forwarding_rules:
+      - {entry_protocol: http, entry_port: 80, target_protocol: http, target_port: 80}
+    health_check: {protocol: http, port: 80, path: ` + path + `}
+    sticky_sessions: {type: cookies}
+`
+		}
+
+		for _, tt := range []struct {
+			path string
+			want []stackFieldDiff
+		}{
+			{path: "/healthz"},
+			{path: "/ready", want: []stackFieldDiff{{Field: "health_check.path", Expected: "/ready", Actual: "/healthz"}}},
+		} {
+			spec, err := parseStackSpec([]byte(lbSpec(tt.path)))
+			assert.NoError(t, err)
+
+			st, err := loadStackState(config, spec)
+			assert.NoError(t, err)
+
+			diffs, err := diffLoadBalancer(st, &spec.LoadBalancers[0], liveLB)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, diffs, tt.path)
+		}
+	})
+}
+
+func TestDestroyFloatingIPs(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		syd := &binarylane.Region{Slug: "syd"}
+		tm.servers.EXPECT().List().Return(bl.Servers{
+			{Server: &binarylane.Server{ID: 42, Name: "web-1"}},
+			{Server: &binarylane.Server{ID: 43, Name: "web-2"}},
+		}, nil)
+		tm.floatingIPs.EXPECT().List().Return(bl.FloatingIPs{
+			{FloatingIP: &binarylane.FloatingIP{IP: "192.0.2.1", Region: syd}},
+			{FloatingIP: &binarylane.FloatingIP{IP: "192.0.2.2", Region: syd, Server: &binarylane.Server{ID: 42}}},
+			{FloatingIP: &binarylane.FloatingIP{IP: "192.0.2.3", Region: syd}},
+		}, nil)
+
+		// Only the floating IP attached to web-1 is identified for certain;
+		// the unassigned ones aren't in the spec.
+		spec, err := parseStackSpec([]byte(`
+floating_ips:
+  - {region: syd, server: web-1}
+  - {region: syd, server: web-2}
+  - {region: syd}
+`))
+		assert.NoError(t, err)
+
+		st, err := loadStackState(config, spec)
+		assert.NoError(t, err)
+
+		plan, err := buildStackDestroyPlan(st)
+		assert.NoError(t, err)
+
+		var got []string
+		for _, ch := range plan {
+			got = append(got, ch.Action+" "+ch.Kind+" "+ch.Name)
+		}
+		assert.Equal(t, []string{"delete floating_ip 192.0.2.2"}, got)
+	})
+}
+
+func TestDestroyDuplicateServers(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.vpcs.EXPECT().List().Return(bl.VPCs{}, nil)
+		tm.servers.EXPECT().List().Return(bl.Servers{
+			{Server: &binarylane.Server{ID: 42, Name: "web-1"}},
+			{Server: &binarylane.Server{ID: 43, Name: "web-1"}},
+		}, nil)
+		tm.loadBalancers.EXPECT().List().Return(bl.LoadBalancers{}, nil)
+
+		spec, err := parseStackSpec([]byte(testStackSpec))
+		assert.NoError(t, err)
+
+		st, err := loadStackState(config, spec)
+		assert.NoError(t, err)
+
+		_, err = buildStackDestroyPlan(st)
+		assert.EqualError(t, err, `There are multiple Servers with the name "web-1"; server names must be unique to be managed by a stack`)
+	})
+}
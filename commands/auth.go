@@ -14,16 +14,21 @@ limitations under the License.
 package commands
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/bl-cli/commands/displayers"
+	"github.com/binarylane/go-binarylane"
 
 	"golang.org/x/crypto/ssh/terminal"
 
@@ -81,7 +86,7 @@ To switch between multiple BinaryLane accounts, including team accounts, you can
 		},
 	}
 
-	cmdBuilderWithInit(cmd, RunAuthInit(retrieveUserTokenFromCommandLine), "init", "Initialize bl to use a specific account", `This command allows you to initialize bl with a token that allows it to query and manage your account details and resources.
+	cmdAuthInit := cmdBuilderWithInit(cmd, RunAuthInit(retrieveUserTokenFromCommandLine), "init", "Initialize bl to use a specific account", `This command allows you to initialize bl with a token that allows it to query and manage your account details and resources.
 
 You will need an API token, which you can generate in the control panel at https://home.binarylane.com.au/api-info
 
@@ -89,21 +94,36 @@ You can provide a name to this initialization via the `+"`"+`--context`+"`"+` fl
 
 If the `+"`"+`--context`+"`"+` flag is not specified, a default authentication context will be created during initialization.
 
-If bl is never initialized, you will need to specify an API token whenever you use a `+"`"+`bl`+"`"+` command via the `+"`"+`--access-token`+"`"+` flag.`, Writer, false)
+If bl is never initialized, you will need to specify an API token whenever you use a `+"`"+`bl`+"`"+` command via the `+"`"+`--access-token`+"`"+` flag.
+
+By default the token is saved in the config file. To keep it out of the config file, use `+"`"+`--credential-store keyring`+"`"+` to save it in the OS keyring (the Keychain on macOS, the Credential Manager on Windows, or the Secret Service through `+"`"+`secret-tool`+"`"+` on Linux), or `+"`"+`--credential-store file`+"`"+` to save it in a file encrypted with a passphrase, for systems without a keyring. The passphrase is read from the `+"`"+`BINARYLANE_CREDENTIALS_PASSPHRASE`+"`"+` environment variable, or prompted for.
+
+To fetch the token from another program, such as a password manager, use `+"`"+`--credential-helper <command>`+"`"+`. Like a git credential helper, the command is run with `+"`"+`get`+"`"+`, `+"`"+`store`+"`"+` or `+"`"+`erase`+"`"+` appended and `+"`"+`context=<name>`+"`"+` on its input, and prints the token as `+"`"+`token=<token>`+"`"+` or on its own.
+
+Each authentication context keeps its own setting, which is remembered when `+"`"+`bl auth init`+"`"+` is run again for the context.`, Writer, false)
+	AddStringFlag(cmdAuthInit, blcli.ArgCredentialStore, "", "", "Where to save the token: config, keyring or file. The context's current setting is kept by default")
+	AddStringFlag(cmdAuthInit, blcli.ArgCredentialHelper, "", "", "A command to fetch the token with, instead of saving it")
+
 	cmdBuilderWithInit(cmd, RunAuthSwitch, "switch", "Switches between authentication contexts", `This command allows you to switch between accounts with authentication contexts you've already created.
 
 To see a list of available authentication contexts, call `+"`"+`bl auth list`+"`"+`.
 
 For details on creating an authentication context, see the help for `+"`"+`bl auth init`+"`"+`.`, Writer, false)
-	cmdAuthList := cmdBuilderWithInit(cmd, RunAuthList, "list", "List available authentication contexts", `List named authentication contexts that you created with `+"`"+`bl auth init`+"`"+`.
+	cmdBuilderWithInit(cmd, RunAuthList, "list", "List available authentication contexts", `List named authentication contexts that you created with `+"`"+`bl auth init`+"`"+`, and check whether their tokens are valid.
+
+For each context, the list shows whether it is the current context, where its token comes from (the `+"`"+`--access-token`+"`"+` flag, the `+"`"+`BINARYLANE_ACCESS_TOKEN`+"`"+` environment variable, the config file, the OS keyring, the encrypted credentials file or a credential helper), whether the token is valid, and the email address of the account it belongs to.
+
+To switch between the contexts use `+"`"+`bl auth switch --context <name>`+"`"+`, where `+"`"+`<name>`+"`"+` is one of the contexts listed.
+
+To create new contexts, see the help for `+"`"+`bl auth init`+"`"+`.`, Writer, false, aliasOpt("ls"),
+		displayerType(&displayers.AuthContexts{}))
+	cmdAuthRemove := cmdBuilderWithInit(cmd, RunAuthRemove, "remove <context>...", "Remove authentication contexts", `Remove authentication contexts from the config file, along with their tokens, wherever the tokens are stored.
 
-To switch between the contexts use `+"`"+`bl switch <name>`+"`"+`, where `+"`"+`<name>`+"`"+` is one of the contexts listed.
+The `+"`"+`default`+"`"+` context always exists; removing it removes its token.`, Writer, false, aliasOpt("rm"))
+	AddBoolFlag(cmdAuthRemove, blcli.ArgForce, blcli.ArgShortForce, false, "Remove the contexts without a confirmation prompt")
+	cmdBuilderWithInit(cmd, RunAuthRename, "rename <context> <new-name>", "Rename an authentication context", `Rename an authentication context, moving its token.
 
-To create new contexts, see the help for `+"`"+`bl auth init`+"`"+`.`, Writer, false, aliasOpt("ls"))
-	// The command runner expects that any command named "list" accepts a
-	// format flag, so we include here despite only supporting text output for
-	// this command.
-	AddStringFlag(cmdAuthList, blcli.ArgFormat, "", "", "Columns for output in a comma-separated list. Possible values: `text`")
+If the context is the one used by default, the new name is used by default instead.`, Writer, false)
 
 	return cmd
 }
@@ -112,15 +132,64 @@ To create new contexts, see the help for `+"`"+`bl auth init`+"`"+`.`, Writer, f
 // XDG_CONFIG_HOME is not set, use $HOME/.config. On Windows use %APPDATA%/bl/config.
 func RunAuthInit(retrieveUserTokenFunc func() (string, error)) func(c *CmdConfig) error {
 	return func(c *CmdConfig) error {
-		token := c.getContextAccessToken()
+		context := currentContext()
 
+		storeName, err := c.Doit.GetString(c.NS, blcli.ArgCredentialStore)
+		if err != nil {
+			return err
+		}
+
+		helper, err := c.Doit.GetString(c.NS, blcli.ArgCredentialHelper)
+		if err != nil {
+			return err
+		}
+
+		if storeName != "" && helper != "" {
+			return fmt.Errorf("The --%s and --%s flags can't be used together", blcli.ArgCredentialStore, blcli.ArgCredentialHelper)
+		}
+		previousStore := viper.GetStringMapString(credentialStoresKey)[context]
+		previousHelper := viper.GetStringMapString(credentialHelpersKey)[context]
+		if storeName == "" && helper == "" {
+			// Keep the context's token where it is already stored.
+			storeName, helper = previousStore, previousHelper
+		}
+
+		// When the token moves out of a credential store, it is removed
+		// from there once it has been stored anew. Helpers manage their
+		// tokens themselves, so their tokens are left alone.
+		var previous blcli.CredentialStore
+		if previousHelper == "" && previousStore != "" && (helper != "" || previousStore != storeName) {
+			if previous, err = newCredentialStore(previousStore); err != nil {
+				return err
+			}
+		}
+
+		var store blcli.CredentialStore
+		if helper != "" {
+			store = &blcli.CredentialHelper{Command: helper}
+		} else if store, err = newCredentialStore(storeName); err != nil {
+			return err
+		}
+
+		var token string
+		if helper != "" {
+			// The token is fetched from the new helper, so that it is
+			// checked before the helper is saved.
+			if token, err = store.Get(context); err != nil && err != blcli.ErrCredentialNotFound {
+				return err
+			}
+		} else if token, err = c.getContextAccessToken(); err != nil {
+			return err
+		}
+
+		fromHelper := helper != "" && token != ""
 		if token == "" {
 			in, err := retrieveUserTokenFunc()
 			if err != nil {
 				return fmt.Errorf("Unable to read BinaryLane access token: %s", err)
 			}
 			token = strings.TrimSpace(in)
-		} else {
+		} else if store == nil {
 			fmt.Fprintf(c.Out, "Using token [%v]", token)
 			fmt.Fprintln(c.Out)
 		}
@@ -144,43 +213,227 @@ func RunAuthInit(retrieveUserTokenFunc func() (string, error)) func(c *CmdConfig
 		fmt.Fprintln(c.Out, "OK")
 		fmt.Fprintln(c.Out)
 
-		return writeConfig()
+		if store != nil {
+			// The token is kept out of the config file. A token that came
+			// from the helper doesn't need to be given back to it.
+			if !fromHelper {
+				if err := store.Set(context, token); err != nil {
+					return fmt.Errorf("Unable to store access token: %w", err)
+				}
+			}
+			c.setContextAccessToken("")
+		}
+		setContextCredentialStore(context, storeName, helper)
+
+		if err := writeConfig(); err != nil {
+			return err
+		}
+
+		if previous != nil {
+			if err := previous.Delete(context); err != nil {
+				warn("Unable to remove the access token from the %s credential store: %v", previousStore, err)
+			}
+		}
+		return nil
 	}
 }
 
-// RunAuthList lists all available auth contexts from the user's bl config.
+// accountForToken gets the account that an access token belongs to, with a
+// client that may have been created for another token. It can be replaced
+// in tests.
+var accountForToken = func(ctx context.Context, client *binarylane.Client, token string) (*bl.Account, error) {
+	return bl.NewAccountService(blcli.WithAccessToken(ctx, token), client).Get()
+}
+
+// RunAuthList lists all available auth contexts from the user's bl config,
+// and checks whether their access tokens are valid.
 func RunAuthList(c *CmdConfig) error {
-	context := Context
-	if context == "" {
-		context = viper.GetString("context")
+	current := currentContext()
+	names := authContextNames()
+
+	// The tokens are read one at a time, as reading them can prompt for the
+	// passphrase of the credentials file, then checked concurrently.
+	contexts := make([]displayers.AuthContext, len(names))
+	tokens := make([]string, len(names))
+	for i, name := range names {
+		contexts[i] = displayers.AuthContext{
+			Name:    name,
+			Current: name == current,
+			Source:  contextTokenSource(name),
+		}
+
+		token, err := contextAccessToken(name)
+		switch {
+		case err != nil:
+			contexts[i].Status, contexts[i].Error = "error", err.Error()
+		case token == "":
+			contexts[i].Status = "no token"
+		}
+		tokens[i] = token
 	}
-	contexts := viper.GetStringMap("auth-contexts")
 
-	displayAuthContexts(c.Out, context, contexts)
-	return nil
+	// All the tokens are checked with one client, and the checks are left
+	// out of a cassette recorded with --record.
+	var client *binarylane.Client
+	for _, token := range tokens {
+		if token == "" {
+			continue
+		}
+		var err error
+		if client, err = c.Doit.GetClient(Trace, token, c.dryRun); err != nil {
+			return err
+		}
+		break
+	}
+	ctx := blcli.WithoutRecording(c.Ctx)
+
+	var wg sync.WaitGroup
+	for i := range contexts {
+		if tokens[i] == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(ac *displayers.AuthContext, token string) {
+			defer wg.Done()
+			checkAuthContext(ctx, client, ac, token)
+		}(&contexts[i], tokens[i])
+	}
+	wg.Wait()
+
+	return c.Display(&displayers.AuthContexts{AuthContexts: contexts})
 }
 
-func displayAuthContexts(out io.Writer, currentContext string, contexts map[string]interface{}) {
-	// Because the default context isn't present on the auth-contexts field,
-	// we add it manually so that it's always included in the output, and so
-	// we can check if it's the current context.
-	contexts[blcli.ArgDefaultContext] = true
+// checkAuthContext sets the status of an auth context by getting the account
+// its token belongs to.
+func checkAuthContext(ctx context.Context, client *binarylane.Client, ac *displayers.AuthContext, token string) {
+	account, err := accountForToken(ctx, client, token)
+	if err == nil {
+		ac.Status, ac.Email = "valid", account.Email
+		return
+	}
+
+	var apiErr *binarylane.ErrorResponse
+	if errors.As(err, &apiErr) && apiErr.Response != nil &&
+		(apiErr.Response.StatusCode == http.StatusUnauthorized || apiErr.Response.StatusCode == http.StatusForbidden) {
+		ac.Status = "invalid"
+		return
+	}
+	ac.Status, ac.Error = "error", err.Error()
+}
 
-	// Extract and sort the map keys so that the order that we display the
-	// auth contexts is consistent.
-	keys := make([]string, 0)
-	for ctx := range contexts {
-		keys = append(keys, ctx)
+// RunAuthRemove removes auth contexts from the user's bl config, along with
+// their access tokens.
+func RunAuthRemove(c *CmdConfig) error {
+	if len(c.Args) < 1 {
+		return blcli.NewMissingArgsErr(c.NS)
 	}
-	sort.Strings(keys)
 
-	for _, ctx := range keys {
-		if ctx == currentContext {
-			fmt.Fprintln(out, ctx, "(current)")
-			continue
+	force, err := c.Doit.GetBool(c.NS, blcli.ArgForce)
+	if err != nil {
+		return err
+	}
+
+	for _, context := range c.Args {
+		if !authContextExists(context) {
+			return fmt.Errorf("Authentication context %q doesn't exist", context)
+		}
+	}
+
+	if !force && AskForConfirmDelete("authentication context", len(c.Args)) != nil {
+		return blcli.ErrOperationAborted
+	}
+
+	for _, context := range c.Args {
+		store, err := contextCredentialStore(context)
+		if err == nil && store != nil {
+			err = store.Delete(context)
+		}
+		if err != nil {
+			warn("Unable to remove the access token of context %q from its credential store: %v", context, err)
+		}
+
+		// The default context can't be removed, only its token.
+		if context == blcli.ArgDefaultContext {
+			viper.Set(blcli.ArgAccessToken, "")
+		} else {
+			contexts := viper.GetStringMapString("auth-contexts")
+			delete(contexts, context)
+			viper.Set("auth-contexts", contexts)
+			setContextDefaults(context, nil)
+		}
+		setContextCredentialStore(context, "", "")
+
+		if viper.GetString("context") == context {
+			viper.Set("context", blcli.ArgDefaultContext)
+		}
+	}
+
+	return writeConfig()
+}
+
+// RunAuthRename renames an auth context, moving its access token.
+func RunAuthRename(c *CmdConfig) error {
+	switch {
+	case len(c.Args) < 2:
+		return blcli.NewMissingArgsErr(c.NS)
+	case len(c.Args) > 2:
+		return blcli.NewTooManyArgsErr(c.NS)
+	}
+
+	context, newName := c.Args[0], c.Args[1]
+	switch {
+	case context == blcli.ArgDefaultContext || newName == blcli.ArgDefaultContext:
+		return fmt.Errorf("The %s authentication context can't be renamed", blcli.ArgDefaultContext)
+	case !authContextExists(context):
+		return fmt.Errorf("Authentication context %q doesn't exist", context)
+	case authContextExists(newName):
+		return fmt.Errorf("Authentication context %q already exists", newName)
+	}
+
+	storeName := viper.GetStringMapString(credentialStoresKey)[context]
+	helper := viper.GetStringMapString(credentialHelpersKey)[context]
+
+	// Tokens in the keyring or credentials file are stored under the
+	// context's name, so they are moved. Credential helpers are given the
+	// new name from now on.
+	if helper == "" {
+		store, err := newCredentialStore(storeName)
+		if err != nil {
+			return err
+		}
+		if store != nil {
+			token, err := store.Get(context)
+			if err != nil && err != blcli.ErrCredentialNotFound {
+				return err
+			}
+			if err == nil {
+				if err := store.Set(newName, token); err != nil {
+					return err
+				}
+				if err := store.Delete(context); err != nil {
+					return err
+				}
+			}
 		}
-		fmt.Fprintln(out, ctx)
 	}
+
+	contexts := viper.GetStringMapString("auth-contexts")
+	contexts[newName] = contexts[context]
+	delete(contexts, context)
+	viper.Set("auth-contexts", contexts)
+
+	setContextCredentialStore(context, "", "")
+	setContextCredentialStore(newName, storeName, helper)
+	setContextDefaults(newName, contextDefaults(context))
+	setContextDefaults(context, nil)
+
+	if viper.GetString("context") == context {
+		viper.Set("context", newName)
+	}
+
+	fmt.Fprintf(c.Out, "Renamed context [%s] to [%s]\n", context, newName)
+	return writeConfig()
 }
 
 // RunAuthSwitch changes the default context and writes it to the
@@ -205,7 +458,30 @@ func writeConfig() error {
 
 	defer f.Close()
 
-	b, err := yaml.Marshal(viper.AllSettings())
+	// AllSettings merges the config file's values into maps that have been
+	// changed, which would bring back entries removed from the maps of
+	// contexts, so those maps are written as they were set.
+	settings := viper.AllSettings()
+	for _, key := range []string{"auth-contexts", credentialStoresKey, credentialHelpersKey, contextDefaultsKey} {
+		if v := viper.Get(key); v != nil {
+			settings[key] = v
+		}
+	}
+	// The output format of the project config file stays out of the user's
+	// config file.
+	if project.Output != "" && viper.GetString(blcli.ArgOutput) == project.Output {
+		file, err := readConfigFile()
+		if err != nil {
+			return err
+		}
+		if v, ok := file[blcli.ArgOutput]; ok {
+			settings[blcli.ArgOutput] = v
+		} else {
+			delete(settings, blcli.ArgOutput)
+		}
+	}
+
+	b, err := yaml.Marshal(settings)
 	if err != nil {
 		return errors.New("Unable to encode configuration to YAML format.")
 	}
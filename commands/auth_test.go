@@ -15,22 +15,27 @@ package commands
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"strings"
 	"testing"
 
 	"errors"
 
 	"github.com/binarylane/bl-cli"
 	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/go-binarylane"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAuthCommand(t *testing.T) {
 	cmd := Auth()
 	assert.NotNil(t, cmd)
-	assertCommandNames(t, cmd, "init", "list", "switch")
+	assertCommandNames(t, cmd, "init", "list", "remove", "rename", "switch")
 }
 
 func TestAuthInit(t *testing.T) {
@@ -76,69 +81,142 @@ func TestAuthInitWithProvidedToken(t *testing.T) {
 	})
 }
 
-func TestAuthList(t *testing.T) {
-	buf := &bytes.Buffer{}
-	config := &CmdConfig{Out: buf}
+func TestAuthInitMovesToken(t *testing.T) {
+	keyring := withKeyring(t)
+	keyring[blcli.ArgDefaultContext] = "keyring-token"
+	viper.Set(credentialStoresKey, map[string]string{blcli.ArgDefaultContext: blcli.CredentialStoreKeyring})
 
-	err := RunAuthList(config)
-	assert.NoError(t, err)
-}
+	cfw := cfgFileWriter
+	defer func() {
+		cfgFileWriter = cfw
+	}()
+	cfgFileWriter = func() (io.WriteCloser, error) { return &nopWriteCloser{Writer: ioutil.Discard}, nil }
 
-func Test_displayAuthContexts(t *testing.T) {
-	testCases := []struct {
-		Name     string
-		Out      *bytes.Buffer
-		Context  string
-		Contexts map[string]interface{}
-		Expected string
-	}{
-		{
-			Name:    "default context only",
-			Out:     &bytes.Buffer{},
-			Context: blcli.ArgDefaultContext,
-			Contexts: map[string]interface{}{
-				blcli.ArgDefaultContext: true,
-			},
-			Expected: "default (current)\n",
-		},
-		{
-			Name:    "default context and additional context",
-			Out:     &bytes.Buffer{},
-			Context: blcli.ArgDefaultContext,
-			Contexts: map[string]interface{}{
-				blcli.ArgDefaultContext: true,
-				"test":                  true,
-			},
-			Expected: "default (current)\ntest\n",
-		},
-		{
-			Name:    "default context and additional context set to addditional context",
-			Out:     &bytes.Buffer{},
-			Context: "test",
-			Contexts: map[string]interface{}{
-				blcli.ArgDefaultContext: true,
-				"test":                  true,
-			},
-			Expected: "default\ntest (current)\n",
-		},
-		{
-			Name:    "unset context",
-			Out:     &bytes.Buffer{},
-			Context: "missing",
-			Contexts: map[string]interface{}{
-				blcli.ArgDefaultContext: true,
-				"test":                  true,
-			},
-			Expected: "default\ntest\n",
-		},
+	retrieveUserTokenFunc := func() (string, error) {
+		return "valid-token", nil
 	}
 
-	for _, tc := range testCases {
-		t.Run(tc.Name, func(t *testing.T) {
-			displayAuthContexts(tc.Out, tc.Context, tc.Contexts)
-			assert.Equal(t, tc.Expected, tc.Out.String())
-		})
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.account.EXPECT().Get().Return(&bl.Account{}, nil)
+		config.Doit.Set(config.NS, blcli.ArgCredentialStore, blcli.CredentialStoreConfig)
+
+		err := RunAuthInit(retrieveUserTokenFunc)(config)
+		assert.NoError(t, err)
+	})
+
+	assert.Empty(t, viper.GetStringMapString(credentialStoresKey))
+	assert.NotContains(t, keyring, blcli.ArgDefaultContext)
+}
+
+func TestAuthList(t *testing.T) {
+	withKeyring(t)
+	viper.Set(blcli.ArgAccessToken, "valid-token")
+	viper.Set("auth-contexts", map[string]string{"stale": "stale-token", "empty": "", "broken": ""})
+	viper.Set(credentialHelpersKey, map[string]string{"broken": "echo failed >&2; false"})
+	viper.Set(credentialStoresKey, map[string]string{"empty": blcli.CredentialStoreKeyring})
+
+	afa := accountForToken
+	defer func() {
+		accountForToken = afa
+	}()
+	accountForToken = func(ctx context.Context, client *binarylane.Client, token string) (*bl.Account, error) {
+		if token == "valid-token" {
+			return &bl.Account{Account: &binarylane.Account{Email: "sammy@example.com"}}, nil
+		}
+		return nil, &binarylane.ErrorResponse{Response: &http.Response{StatusCode: http.StatusUnauthorized}}
 	}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		buf := &bytes.Buffer{}
+		config.Out = buf
+
+		err := RunAuthList(config)
+		require.NoError(t, err)
+
+		expected := `
+Name       Current    Source     Status      Email
+broken     false      helper     error       
+default    true       config     valid       sammy@example.com
+empty      false      keyring    no token    
+stale      false      config     invalid     
+`
+		assert.Equal(t, strings.TrimLeft(expected, "\n"), buf.String())
+	})
+}
+
+func TestAuthRemove(t *testing.T) {
+	keyring := withKeyring(t)
+	keyring["next"] = "keyring-token"
+
+	viper.Set("context", "next")
+	viper.Set("auth-contexts", map[string]string{"next": "", "other": "other-token"})
+	viper.Set(credentialStoresKey, map[string]string{"next": blcli.CredentialStoreKeyring})
+	viper.Set(contextDefaultsKey, map[string]interface{}{"next": map[string]interface{}{"server.create.region": "syd"}})
+	defer viper.Set("context", nil)
+
+	cfw := cfgFileWriter
+	defer func() {
+		cfgFileWriter = cfw
+	}()
+	cfgFileWriter = func() (io.WriteCloser, error) { return &nopWriteCloser{Writer: ioutil.Discard}, nil }
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = []string{"missing"}
+		config.Doit.Set(config.NS, blcli.ArgForce, true)
+
+		err := RunAuthRemove(config)
+		assert.EqualError(t, err, `Authentication context "missing" doesn't exist`)
+
+		config.Args = []string{"next"}
+		err = RunAuthRemove(config)
+		require.NoError(t, err)
+
+		assert.Empty(t, keyring)
+		assert.Equal(t, []string{"default", "other"}, authContextNames())
+		assert.Equal(t, blcli.ArgDefaultContext, viper.GetString("context"))
+		assert.Empty(t, viper.GetStringMap(contextDefaultsKey))
+	})
+}
+
+func TestAuthRename(t *testing.T) {
+	keyring := withKeyring(t)
+	keyring["next"] = "keyring-token"
+
+	viper.Set("context", "next")
+	viper.Set("auth-contexts", map[string]string{"next": "", "other": "other-token"})
+	viper.Set(credentialStoresKey, map[string]string{"next": blcli.CredentialStoreKeyring})
+	viper.Set(contextDefaultsKey, map[string]interface{}{"next": map[string]interface{}{"server.create.region": "syd"}})
+	defer viper.Set("context", nil)
+
+	cfw := cfgFileWriter
+	defer func() {
+		cfgFileWriter = cfw
+	}()
+	cfgFileWriter = func() (io.WriteCloser, error) { return &nopWriteCloser{Writer: ioutil.Discard}, nil }
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = []string{"next", "other"}
+		err := RunAuthRename(config)
+		assert.EqualError(t, err, `Authentication context "other" already exists`)
+
+		config.Args = []string{"default", "main"}
+		err = RunAuthRename(config)
+		assert.EqualError(t, err, `The default authentication context can't be renamed`)
+
+		config.Args = []string{"next", "prod"}
+		err = RunAuthRename(config)
+		require.NoError(t, err)
+
+		assert.Equal(t, memoryStore{"prod": "keyring-token"}, keyring)
+		assert.Equal(t, []string{"default", "other", "prod"}, authContextNames())
+		assert.Equal(t, "prod", viper.GetString("context"))
+		assert.Equal(t, map[string]interface{}{"server.create.region": "syd"}, contextDefaults("prod"))
+		assert.Empty(t, contextDefaults("next"))
+
+		token, err := contextAccessToken("prod")
+		require.NoError(t, err)
+		assert.Equal(t, "keyring-token", token)
+	})
 }
 
 type nopWriteCloser struct {
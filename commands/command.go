@@ -29,6 +29,7 @@ type Command struct {
 	*cobra.Command
 
 	fmtCols []string
+	listCmd bool
 
 	childCommands []*Command
 }
@@ -57,7 +58,11 @@ func cmdBuilderWithInit(parent *Command, cr CmdRunner, cliText, shortdesc string
 		Short: shortdesc,
 		Long:  longdesc,
 		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := commandContext()
+			defer cancel()
+
 			c, err := NewCmdConfig(
+				ctx,
 				cmdNS(cmd),
 				&blcli.LiveConfig{},
 				out,
@@ -67,7 +72,10 @@ func cmdBuilderWithInit(parent *Command, cr CmdRunner, cliText, shortdesc string
 			checkErr(err)
 
 			err = cr(c)
-			checkErr(err)
+			if cerr := c.Doit.Close(); err == nil {
+				err = cerr
+			}
+			checkErr(contextErr(ctx, err))
 		},
 	}
 
@@ -86,8 +94,22 @@ func cmdBuilderWithInit(parent *Command, cr CmdRunner, cliText, shortdesc string
 			strings.Join(cols, "`"+", "+"`"))
 		AddStringFlag(c, blcli.ArgFormat, "", "", formatHelp)
 		AddBoolFlag(c, blcli.ArgNoHeader, "", false, "Return raw data with no headers")
+
+		if c.listCmd || isListCommand(cliText) {
+			sortHelp := fmt.Sprintf("Columns to sort by in a comma-separated list. Prefix a column with `-` to sort in descending order. Possible values: `%s`",
+				strings.Join(cols, "`"+", "+"`"))
+			AddStringFlag(c, blcli.ArgSortBy, "", "", sortHelp)
+			AddStringFlag(c, blcli.ArgFilter, "", "", "Only list rows matching a comma-separated list of conditions, for example `Status=active,Memory>=2048,Region in (syd,mel)`. Supported operators are `=`, `!=`, `<`, `<=`, `>`, `>=`, `in` and `not in`; `=`, `!=` and lists accept glob patterns")
+		}
 	}
 
 	return c
 
 }
+
+// isListCommand reports whether a command's use line names a listing, such
+// as `list` or `list-by-server <server-id>`.
+func isListCommand(cliText string) bool {
+	verb := strings.SplitN(cliText, " ", 2)[0]
+	return verb == "list" || strings.HasPrefix(verb, "list-")
+}
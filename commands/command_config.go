@@ -14,8 +14,11 @@ limitations under the License.
 package commands
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"strings"
 
 	"github.com/binarylane/bl-cli"
 	"github.com/binarylane/bl-cli/bl"
@@ -30,8 +33,15 @@ type CmdConfig struct {
 	Out  io.Writer
 	Args []string
 
+	// Ctx is passed to every API call made by the services. It is canceled
+	// when the command is interrupted or the --timeout elapses.
+	Ctx context.Context
+
+	// dryRun is set when the command is run with --dry-run.
+	dryRun *blcli.DryRun
+
 	initServices          func(*CmdConfig) error
-	getContextAccessToken func() string
+	getContextAccessToken func() (string, error)
 	setContextAccessToken func(string)
 
 	// services
@@ -59,72 +69,57 @@ type CmdConfig struct {
 }
 
 // NewCmdConfig creates an instance of a CmdConfig.
-func NewCmdConfig(ns string, dc blcli.Config, out io.Writer, args []string, initClient bool) (*CmdConfig, error) {
+func NewCmdConfig(ctx context.Context, ns string, dc blcli.Config, out io.Writer, args []string, initClient bool) (*CmdConfig, error) {
 
 	cmdConfig := &CmdConfig{
 		NS:   ns,
 		Doit: dc,
 		Out:  out,
 		Args: args,
+		Ctx:  ctx,
 
 		initServices: func(c *CmdConfig) error {
-			accessToken := c.getContextAccessToken()
-			client, err := c.Doit.GetClient(Trace, accessToken)
+			accessToken, err := c.getContextAccessToken()
+			if err != nil {
+				return err
+			}
+			client, err := c.Doit.GetClient(Trace, accessToken, c.dryRun)
 			if err != nil {
 				return fmt.Errorf("Unable to initialize BinaryLane API client: %s", err)
 			}
-
-			c.Keys = func() bl.KeysService { return bl.NewKeysService(client) }
-			c.Sizes = func() bl.SizesService { return bl.NewSizesService(client) }
-			c.Regions = func() bl.RegionsService { return bl.NewRegionsService(client) }
-			c.Images = func() bl.ImagesService { return bl.NewImagesService(client) }
-			c.ImageActions = func() bl.ImageActionsService { return bl.NewImageActionsService(client) }
-			c.FloatingIPs = func() bl.FloatingIPsService { return bl.NewFloatingIPsService(client) }
-			c.FloatingIPActions = func() bl.FloatingIPActionsService { return bl.NewFloatingIPActionsService(client) }
-			c.Servers = func() bl.ServersService { return bl.NewServersService(client) }
-			c.ServerActions = func() bl.ServerActionsService { return bl.NewServerActionsService(client) }
-			c.Domains = func() bl.DomainsService { return bl.NewDomainsService(client) }
-			c.Actions = func() bl.ActionsService { return bl.NewActionsService(client) }
-			c.Account = func() bl.AccountService { return bl.NewAccountService(client) }
-			c.Balance = func() bl.BalanceService { return bl.NewBalanceService(client) }
-			c.BillingHistory = func() bl.BillingHistoryService { return bl.NewBillingHistoryService(client) }
-			c.Invoices = func() bl.InvoicesService { return bl.NewInvoicesService(client) }
-			c.Tags = func() bl.TagsService { return bl.NewTagsService(client) }
-			c.Snapshots = func() bl.SnapshotsService { return bl.NewSnapshotsService(client) }
-			c.LoadBalancers = func() bl.LoadBalancersService { return bl.NewLoadBalancersService(client) }
-			c.Firewalls = func() bl.FirewallsService { return bl.NewFirewallsService(client) }
-			c.Projects = func() bl.ProjectsService { return bl.NewProjectsService(client) }
-			c.VPCs = func() bl.VPCsService { return bl.NewVPCsService(client) }
+			bl.PaginationWorkers = viper.GetInt(blcli.ArgPaginationWorkers)
+
+			c.Keys = func() bl.KeysService { return bl.NewKeysService(c.Ctx, client) }
+			c.Sizes = func() bl.SizesService { return bl.NewSizesService(c.Ctx, client) }
+			c.Regions = func() bl.RegionsService { return bl.NewRegionsService(c.Ctx, client) }
+			c.Images = func() bl.ImagesService { return bl.NewImagesService(c.Ctx, client) }
+			c.ImageActions = func() bl.ImageActionsService { return bl.NewImageActionsService(c.Ctx, client) }
+			c.FloatingIPs = func() bl.FloatingIPsService { return bl.NewFloatingIPsService(c.Ctx, client) }
+			c.FloatingIPActions = func() bl.FloatingIPActionsService { return bl.NewFloatingIPActionsService(c.Ctx, client) }
+			c.Servers = func() bl.ServersService { return bl.NewServersService(c.Ctx, client) }
+			c.ServerActions = func() bl.ServerActionsService { return bl.NewServerActionsService(c.Ctx, client) }
+			c.Domains = func() bl.DomainsService { return bl.NewDomainsService(c.Ctx, client) }
+			c.Actions = func() bl.ActionsService { return bl.NewActionsService(c.Ctx, client) }
+			c.Account = func() bl.AccountService { return bl.NewAccountService(c.Ctx, client) }
+			c.Balance = func() bl.BalanceService { return bl.NewBalanceService(c.Ctx, client) }
+			c.BillingHistory = func() bl.BillingHistoryService { return bl.NewBillingHistoryService(c.Ctx, client) }
+			c.Invoices = func() bl.InvoicesService { return bl.NewInvoicesService(c.Ctx, client) }
+			c.Tags = func() bl.TagsService { return bl.NewTagsService(c.Ctx, client) }
+			c.Snapshots = func() bl.SnapshotsService { return bl.NewSnapshotsService(c.Ctx, client) }
+			c.LoadBalancers = func() bl.LoadBalancersService { return bl.NewLoadBalancersService(c.Ctx, client) }
+			c.Firewalls = func() bl.FirewallsService { return bl.NewFirewallsService(c.Ctx, client) }
+			c.Projects = func() bl.ProjectsService { return bl.NewProjectsService(c.Ctx, client) }
+			c.VPCs = func() bl.VPCsService { return bl.NewVPCsService(c.Ctx, client) }
 
 			return nil
 		},
 
-		getContextAccessToken: func() string {
-			context := Context
-			if context == "" {
-				context = viper.GetString("context")
-			}
-			token := ""
-
-			switch context {
-			case blcli.ArgDefaultContext:
-				token = viper.GetString(blcli.ArgAccessToken)
-			default:
-				contexts := viper.GetStringMapString("auth-contexts")
-
-				token = contexts[context]
-			}
-
-			return token
+		getContextAccessToken: func() (string, error) {
+			return contextAccessToken(currentContext())
 		},
 
 		setContextAccessToken: func(token string) {
-			context := Context
-			if context == "" {
-				context = viper.GetString("context")
-			}
-
-			switch context {
+			switch context := currentContext(); context {
 			case blcli.ArgDefaultContext:
 				viper.Set(blcli.ArgAccessToken, token)
 			default:
@@ -136,6 +131,11 @@ func NewCmdConfig(ns string, dc blcli.Config, out io.Writer, args []string, init
 		},
 	}
 
+	if viper.GetBool(blcli.ArgDryRun) {
+		output := viper.GetString(blcli.ArgOutput)
+		cmdConfig.dryRun = blcli.NewDryRun(out, output == "json" || output == "ndjson")
+	}
+
 	if initClient {
 		if err := cmdConfig.initServices(cmdConfig); err != nil {
 			return nil, err
@@ -150,6 +150,17 @@ type CmdRunner func(*CmdConfig) error
 
 // Display displays the output from a command.
 func (c *CmdConfig) Display(d displayers.Displayable) error {
+	return c.display(d, false)
+}
+
+// display displays the output from a command, without the header of the
+// table when noHeader is set, as well as when --no-header is.
+func (c *CmdConfig) display(d displayers.Displayable, noHeader bool) error {
+	if c.dryRunIntercepted() {
+		// The resources returned for intercepted requests are empty.
+		return nil
+	}
+
 	dc := &displayers.Displayer{
 		Item: d,
 		Out:  c.Out,
@@ -165,9 +176,75 @@ func (c *CmdConfig) Display(d displayers.Displayable) error {
 		return err
 	}
 
-	dc.NoHeaders = withHeaders
+	outputType, tmpl, err := parseOutputType(viper.GetString(blcli.ArgOutput))
+	if err != nil {
+		return err
+	}
+
+	if dc.Item, err = c.query(d); err != nil {
+		return err
+	}
+
+	dc.NoHeaders = withHeaders || noHeader
 	dc.ColumnList = columnList
-	dc.OutputType = Output
+	dc.OutputType = outputType
+	dc.Template = tmpl
 
 	return dc.Display()
 }
+
+// dryRunIntercepted reports whether the command is a dry run that has
+// intercepted a request, so that the API responses it has seen are
+// synthetic.
+func (c *CmdConfig) dryRunIntercepted() bool {
+	return c.dryRun != nil && c.dryRun.Intercepted()
+}
+
+// query applies the --filter and --sort-by flags of list commands to the
+// rows being displayed.
+func (c *CmdConfig) query(d displayers.Displayable) (displayers.Displayable, error) {
+	filter, err := c.Doit.GetString(c.NS, blcli.ArgFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	sortBy, err := c.Doit.GetString(c.NS, blcli.ArgSortBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter == "" && sortBy == "" {
+		return d, nil
+	}
+
+	return displayers.NewQuery(d, filter, sortBy)
+}
+
+// parseOutputType splits the go-template=, go-template-file= and jsonpath=
+// output types into the type and its template.
+func parseOutputType(output string) (string, string, error) {
+	parts := strings.SplitN(output, "=", 2)
+	switch parts[0] {
+	case "go-template", "jsonpath":
+		if len(parts) != 2 || parts[1] == "" {
+			return "", "", fmt.Errorf("The %s output type requires a template, for example `-o %s=%s`", parts[0], parts[0], outputTypeExamples[parts[0]])
+		}
+		return parts[0], parts[1], nil
+	case "go-template-file":
+		if len(parts) != 2 || parts[1] == "" {
+			return "", "", fmt.Errorf("The go-template-file output type requires a path, for example `-o go-template-file=servers.tmpl`")
+		}
+		b, err := ioutil.ReadFile(parts[1])
+		if err != nil {
+			return "", "", err
+		}
+		return "go-template", string(b), nil
+	default:
+		return output, "", nil
+	}
+}
+
+var outputTypeExamples = map[string]string{
+	"go-template": "'{{range .}}{{.name}}{{\"\\n\"}}{{end}}'",
+	"jsonpath":    "'{.[*].id}'",
+}
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOutputType(t *testing.T) {
+	outputType, tmpl, err := parseOutputType("text")
+	assert.NoError(t, err)
+	assert.Equal(t, "text", outputType)
+	assert.Equal(t, "", tmpl)
+
+	outputType, tmpl, err = parseOutputType("jsonpath={.[*].id}")
+	assert.NoError(t, err)
+	assert.Equal(t, "jsonpath", outputType)
+	assert.Equal(t, "{.[*].id}", tmpl)
+
+	outputType, tmpl, err = parseOutputType("go-template={{.name}}={{.id}}")
+	assert.NoError(t, err)
+	assert.Equal(t, "go-template", outputType)
+	assert.Equal(t, "{{.name}}={{.id}}", tmpl)
+
+	f, err := ioutil.TempFile("", "bl-template-*.tmpl")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("{{range .}}{{.id}}{{end}}")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	outputType, tmpl, err = parseOutputType("go-template-file=" + f.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, "go-template", outputType)
+	assert.Equal(t, "{{range .}}{{.id}}{{end}}", tmpl)
+
+	_, _, err = parseOutputType("jsonpath")
+	assert.Error(t, err)
+
+	_, _, err = parseOutputType("go-template-file=/does/not/exist")
+	assert.Error(t, err)
+}
@@ -36,9 +36,32 @@ func displayerType(d displayers.Displayable) cmdOption {
 	}
 }
 
+// listOpt marks a command as listing resources, so that it accepts the
+// --sort-by and --filter flags even though its name is not `list`.
+func listOpt() cmdOption {
+	return func(c *Command) {
+		c.listCmd = true
+	}
+}
+
 // hiddenCmd make a command hidden.
 func hiddenCmd() cmdOption {
 	return func(c *Command) {
 		c.Hidden = true
 	}
 }
+
+// brokenConfigAnnotation marks the commands that run when the config file
+// can't be read.
+const brokenConfigAnnotation = "bl.broken-config"
+
+// brokenConfigOpt lets a command run when the config file can't be read,
+// so that the file can be found and checked.
+func brokenConfigOpt() cmdOption {
+	return func(c *Command) {
+		if c.Annotations == nil {
+			c.Annotations = map[string]string{}
+		}
+		c.Annotations[brokenConfigAnnotation] = "true"
+	}
+}
@@ -14,6 +14,7 @@ limitations under the License.
 package commands
 
 import (
+	"context"
 	"io/ioutil"
 	"sort"
 	"testing"
@@ -203,12 +204,13 @@ func withTestClient(t *testing.T, tFn testFn) {
 		NS:   "test",
 		Doit: blcli.NewTestConfig(),
 		Out:  ioutil.Discard,
+		Ctx:  context.Background(),
 
 		// can stub this out, since the return is dictated by the mocks.
 		initServices: func(c *CmdConfig) error { return nil },
 
-		getContextAccessToken: func() string {
-			return viper.GetString(blcli.ArgAccessToken)
+		getContextAccessToken: func() (string, error) {
+			return viper.GetString(blcli.ArgAccessToken), nil
 		},
 
 		setContextAccessToken: func(token string) {},
@@ -0,0 +1,298 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// The kinds of resources that the arguments and flags of commands complete.
+const (
+	completeServers   = "servers"
+	completeServerIDs = "server-ids"
+	completeImages    = "images"
+	completeRegions   = "regions"
+	completeSizes     = "sizes"
+	completeSSHKeys   = "ssh-keys"
+	completeDomains   = "domains"
+)
+
+// completers list the completions of each kind of resource.
+var completers = map[string]func(c *CmdConfig) ([]string, error){
+	completeServers: func(c *CmdConfig) ([]string, error) {
+		list, err := c.Servers().List()
+		if err != nil {
+			return nil, err
+		}
+		var values []string
+		for _, s := range list {
+			values = append(values, strconv.Itoa(s.ID), s.Name)
+		}
+		return values, nil
+	},
+	completeServerIDs: func(c *CmdConfig) ([]string, error) {
+		list, err := c.Servers().List()
+		if err != nil {
+			return nil, err
+		}
+		var values []string
+		for _, s := range list {
+			values = append(values, strconv.Itoa(s.ID))
+		}
+		return values, nil
+	},
+	completeImages: func(c *CmdConfig) ([]string, error) {
+		list, err := c.Images().ListDistribution(true)
+		if err != nil {
+			return nil, err
+		}
+		var values []string
+		for _, i := range list {
+			if i.Slug != "" {
+				values = append(values, i.Slug)
+			} else {
+				values = append(values, strconv.Itoa(i.ID))
+			}
+		}
+		return values, nil
+	},
+	completeRegions: func(c *CmdConfig) ([]string, error) {
+		list, err := c.Regions().List()
+		if err != nil {
+			return nil, err
+		}
+		var values []string
+		for _, r := range list {
+			values = append(values, r.Slug)
+		}
+		return values, nil
+	},
+	completeSizes: func(c *CmdConfig) ([]string, error) {
+		list, err := c.Sizes().List()
+		if err != nil {
+			return nil, err
+		}
+		var values []string
+		for _, s := range list {
+			values = append(values, s.Slug)
+		}
+		return values, nil
+	},
+	completeSSHKeys: func(c *CmdConfig) ([]string, error) {
+		list, err := c.Keys().List()
+		if err != nil {
+			return nil, err
+		}
+		var values []string
+		for _, k := range list {
+			values = append(values, strconv.Itoa(k.ID), k.Fingerprint)
+		}
+		return values, nil
+	},
+	completeDomains: func(c *CmdConfig) ([]string, error) {
+		list, err := c.Domains().List()
+		if err != nil {
+			return nil, err
+		}
+		var values []string
+		for _, d := range list {
+			values = append(values, d.Name)
+		}
+		return values, nil
+	},
+}
+
+// completeArgsAnnotation is the annotation of the kind of resource that the
+// arguments of a command complete.
+const completeArgsAnnotation = "bl.complete-args"
+
+// completeArgsOpt completes the arguments of a command with a kind of
+// resource.
+func completeArgsOpt(kind string) cmdOption {
+	return func(c *Command) {
+		if c.Annotations == nil {
+			c.Annotations = map[string]string{}
+		}
+		c.Annotations[completeArgsAnnotation] = kind
+	}
+}
+
+// completeFlagOpt completes the value of a flag with a kind of resource.
+func completeFlagOpt(kind string) flagOpt {
+	return func(c *Command, name, key string) {
+		c.Flags().SetAnnotation(name, cobra.BashCompCustom, []string{"__bl_complete " + kind})
+	}
+}
+
+// completionCacheTTL is how long completions are cached for, so that
+// pressing TAB again doesn't wait for the API.
+const completionCacheTTL = time.Minute
+
+// completionCacheDir returns the directory completions are cached in. It
+// can be replaced in tests.
+var completionCacheDir = func() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bl", "completion"), nil
+}
+
+// Complete creates the hidden command that the shell completion scripts
+// run to complete the names and IDs of resources.
+func Complete(parent *Command) *Command {
+	return cmdBuilderWithInit(parent, RunComplete, "__complete <kind>", "Complete the names and IDs of resources",
+		"Used by the shell completion scripts to list the names and IDs of a kind of resource, one per line.",
+		Writer, false, hiddenCmd())
+}
+
+// RunComplete lists the completions of a kind of resource, from the cache
+// when it is fresh.
+func RunComplete(c *CmdConfig) error {
+	if err := ensureOneArg(c); err != nil {
+		return err
+	}
+	kind := c.Args[0]
+
+	complete, ok := completers[kind]
+	if !ok {
+		return fmt.Errorf("Unknown kind of resource %q", kind)
+	}
+
+	values, ok := readCompletionCache(kind)
+	if !ok {
+		if err := c.initServices(c); err != nil {
+			return err
+		}
+
+		var err error
+		if values, err = complete(c); err != nil {
+			return err
+		}
+		// The cache only saves time, so failing to write it isn't an
+		// error.
+		writeCompletionCache(kind, values)
+	}
+
+	for _, v := range values {
+		fmt.Fprintln(c.Out, v)
+	}
+	return nil
+}
+
+// completionCachePath is the path of the cached completions of a kind of
+// resource, for the auth context in use.
+func completionCachePath(kind string) (string, error) {
+	dir, err := completionCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, url.PathEscape(currentContext())+"."+kind), nil
+}
+
+// readCompletionCache reads the cached completions of a kind of resource,
+// unless they are missing or stale.
+func readCompletionCache(kind string) ([]string, bool) {
+	path, err := completionCachePath(kind)
+	if err != nil {
+		return nil, false
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil || time.Since(fi.ModTime()) > completionCacheTTL {
+		return nil, false
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	if len(b) == 0 {
+		return nil, true
+	}
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n"), true
+}
+
+// writeCompletionCache caches the completions of a kind of resource.
+func writeCompletionCache(kind string, values []string) error {
+	path, err := completionCachePath(kind)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, v := range values {
+		fmt.Fprintln(&buf, v)
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// completionBashFunction generates the bash functions that complete the
+// arguments and flags of commands with resources. Cobra calls
+// __custom_func when a command has no completions of its own.
+func completionBashFunction(root *cobra.Command) string {
+	commands := map[string][]string{}
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		for _, child := range cmd.Commands() {
+			if kind := child.Annotations[completeArgsAnnotation]; kind != "" {
+				name := strings.Replace(strings.Replace(child.CommandPath(), " ", "_", -1), ":", "__", -1)
+				commands[kind] = append(commands[kind], name)
+			}
+			walk(child)
+		}
+	}
+	walk(root)
+
+	kinds := make([]string, 0, len(commands))
+	for kind := range commands {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	// Standard input is closed so that completing never waits for a
+	// credentials passphrase.
+	var buf bytes.Buffer
+	buf.WriteString(`__bl_complete()
+{
+    local IFS=$'\n'
+    COMPREPLY=( $(compgen -W "$(bl __complete "$1" 2>/dev/null </dev/null)" -- "$cur") )
+}
+
+__custom_func()
+{
+    case ${last_command} in
+`)
+	for _, kind := range kinds {
+		sort.Strings(commands[kind])
+		fmt.Fprintf(&buf, "        %s)\n            __bl_complete %s\n            ;;\n", strings.Join(commands[kind], "|"), kind)
+	}
+	buf.WriteString("    esac\n}\n")
+
+	return buf.String()
+}
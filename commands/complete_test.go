@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/go-binarylane"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunComplete(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ccd := completionCacheDir
+	defer func() {
+		completionCacheDir = ccd
+	}()
+	completionCacheDir = func() (string, error) { return dir, nil }
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		buf := &bytes.Buffer{}
+		config.Out = buf
+
+		// The servers are listed once, then read from the cache.
+		tm.servers.EXPECT().List().Return(testServerList, nil).Times(1)
+		config.Args = []string{completeServers}
+		require.NoError(t, RunComplete(config))
+		require.NoError(t, RunComplete(config))
+		assert.Equal(t, "1\na-server\n3\nanother-server\n1\na-server\n3\nanother-server\n", buf.String())
+
+		buf.Reset()
+		tm.keys.EXPECT().List().Return(bl.SSHKeys{{Key: &binarylane.Key{ID: 7, Fingerprint: "aa:bb"}}}, nil)
+		config.Args = []string{completeSSHKeys}
+		require.NoError(t, RunComplete(config))
+		assert.Equal(t, "7\naa:bb\n", buf.String())
+
+		config.Args = []string{"widgets"}
+		assert.EqualError(t, RunComplete(config), `Unknown kind of resource "widgets"`)
+	})
+}
+
+func TestCompletionBashFunction(t *testing.T) {
+	fn := completionBashFunction(DoitCmd.Command)
+	assert.Contains(t, fn, "bl_compute_server_delete|bl_compute_server_get|bl_compute_server_tag|bl_compute_server_untag|bl_compute_ssh)\n            __bl_complete servers\n")
+	assert.Contains(t, fn, "|bl_compute_server-action_reboot|")
+
+	var buf bytes.Buffer
+	DoitCmd.BashCompletionFunction = fn
+	require.NoError(t, DoitCmd.GenBashCompletion(&buf))
+	assert.Contains(t, buf.String(), `flags_completion+=("__bl_complete regions")`)
+}
+
+func TestGenFishCompletion(t *testing.T) {
+	var buf bytes.Buffer
+	genFishCompletion(&buf, DoitCmd.Command)
+	out := buf.String()
+
+	assert.Contains(t, out, "complete -c bl -n '__bl_at_command compute' -a server -d 'Display commands to manage servers'\n")
+	assert.Contains(t, out, "complete -c bl -n '__bl_using_command compute server delete' -a '(__bl_complete servers)'\n")
+	assert.Contains(t, out, "complete -c bl -n '__bl_using_command compute server create' -l region -x -a '(__bl_complete regions)' -d ")
+	assert.Contains(t, out, "complete -c bl -s o -l output -r -d 'Desired output format")
+	assert.Contains(t, out, "        case 'compute server rm'\n            echo delete\n")
+	assert.Regexp(t, `set -g __bl_value_flags .* --region .* -o`, out)
+}
+
+func TestGenPowerShellCompletion(t *testing.T) {
+	var buf bytes.Buffer
+	genPowerShellCompletion(&buf, DoitCmd.Command)
+	out := buf.String()
+
+	assert.Contains(t, out, "Register-ArgumentCompleter -Native -CommandName 'bl' -ScriptBlock {\n")
+	assert.Contains(t, out, "        @{ Name = '-o'; Description = 'Desired output format")
+	assert.Contains(t, out, "        'compute server delete' = @{\n")
+	assert.Contains(t, out, "'rm' = 'delete';")
+	assert.Contains(t, out, "                @{ Name = '--region'; Description = 'A slug indicating the region where the Server will be created")
+	assert.Contains(t, out, "Complete = 'servers'\n")
+	assert.Equal(t, `'it''s'`, powershellQuote("it's"))
+}
@@ -16,18 +16,22 @@ package commands
 import (
 	"bytes"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 const (
-	completionLong = "`" + `bl completion` + "`" + ` helps you configure your terminal's shell so that bl commands autocomplete when you press the TAB key.
+	completionLong = "`" + `bl completion` + "`" + ` helps you configure your terminal's shell so that bl commands autocomplete when you press the TAB key. The names and IDs of resources, such as servers, regions and sizes, are completed too, by listing them with the current authentication context. They are cached for a minute.
 
 Supported shells:
 
 - bash
 - zsh
+- fish
+- powershell
 `
 	bashLong = `
 Use ` + "`" + `bl completion bash` + "`" + ` to configure your bash shell so that bl commands autocomplete when you press the TAB key.
@@ -60,6 +64,29 @@ To enable the configuration, add the following line to your .profile or .bashrc.
 Note:
 
 - zsh completions requires zsh 5.2 or newer.
+`
+
+	fishLong = `
+Use ` + "`" + `bl completion fish` + "`" + ` to configure your fish shell so that bl commands autocomplete when you press the TAB key.
+
+To review the configuration, run ` + "`" + `bl completion fish` + "`" + `.
+
+To enable the configuration, save it to your fish completions directory:
+
+	bl completion fish > ~/.config/fish/completions/bl.fish
+`
+	powershellLong = `
+Use ` + "`" + `bl completion powershell` + "`" + ` to configure PowerShell so that bl commands autocomplete when you press the TAB key.
+
+To review the configuration, run ` + "`" + `bl completion powershell` + "`" + `.
+
+To enable the configuration, add the following line to your PowerShell profile, which is found with ` + "`" + `$PROFILE` + "`" + `:
+
+	bl completion powershell | Out-String | Invoke-Expression
+
+Note:
+
+- PowerShell completions require PowerShell 5 or newer.
 `
 
 	blLicense = `# Copyright 2018 The Doctl Authors All rights reserved.
@@ -87,6 +114,8 @@ func Completion() *Command {
 
 	cmdBuilderWithInit(cmd, RunCompletionBash, "bash", "Generate completion code for bash", bashLong, Writer, false)
 	cmdBuilderWithInit(cmd, RunCompletionZsh, "zsh", "Generate completion code for zsh", zshLong, Writer, false)
+	cmdBuilderWithInit(cmd, RunCompletionFish, "fish", "Generate completion code for fish", fishLong, Writer, false)
+	cmdBuilderWithInit(cmd, RunCompletionPowerShell, "powershell", "Generate completion code for PowerShell", powershellLong, Writer, false)
 
 	return cmd
 }
@@ -100,6 +129,7 @@ func RunCompletionBash(c *CmdConfig) error {
 		return fmt.Errorf("Error while generating bash completion: %v", err)
 	}
 
+	DoitCmd.BashCompletionFunction = completionBashFunction(DoitCmd.Command)
 	err = DoitCmd.GenBashCompletion(&buf)
 	if err != nil {
 		return fmt.Errorf("Error while generating bash completion: %v", err)
@@ -291,6 +321,7 @@ _complete bl 2>/dev/null
 		return fmt.Errorf("Error while generating zsh completion: %v", err)
 	}
 
+	DoitCmd.BashCompletionFunction = completionBashFunction(DoitCmd.Command)
 	err = DoitCmd.GenBashCompletion(&buf)
 	if err != nil {
 		return fmt.Errorf("error wheil generating zsh completion: %v", err)
@@ -308,3 +339,53 @@ _complete bl 2>/dev/null
 	fmt.Print(code)
 	return nil
 }
+
+// completionChildren returns the subcommands that the completion scripts
+// complete. The completion command itself is left out, as it is for bash.
+func completionChildren(cmd *cobra.Command) []*cobra.Command {
+	var children []*cobra.Command
+	for _, child := range cmd.Commands() {
+		if !child.IsAvailableCommand() || child.Name() == "help" || cmd == cmd.Root() && child.Name() == "completion" {
+			continue
+		}
+		children = append(children, child)
+	}
+	return children
+}
+
+// completionFlags returns the flags of a command that the completion
+// scripts complete. The flags of the root command are the global flags.
+func completionFlags(cmd *cobra.Command) []*pflag.Flag {
+	var flags []*pflag.Flag
+	cmd.NonInheritedFlags().VisitAll(func(f *pflag.Flag) {
+		if !f.Hidden && f.Name != "help" {
+			flags = append(flags, f)
+		}
+	})
+	return flags
+}
+
+// flagTakesValue reports whether a flag is followed by a value, unlike a
+// boolean flag.
+func flagTakesValue(f *pflag.Flag) bool {
+	return f.NoOptDefVal == ""
+}
+
+// flagCompleteKind returns the kind of resource a flag's value completes,
+// as set by completeFlagOpt.
+func flagCompleteKind(f *pflag.Flag) string {
+	if v := f.Annotations[cobra.BashCompCustom]; len(v) > 0 {
+		return strings.TrimPrefix(v[0], "__bl_complete ")
+	}
+	return ""
+}
+
+// ansiEscapeRE matches the color codes in the usage of required flags.
+var ansiEscapeRE = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// completionDescription returns the first line of a description, without
+// color codes.
+func completionDescription(s string) string {
+	s = ansiEscapeRE.ReplaceAllString(s, "")
+	return strings.TrimSpace(strings.SplitN(s, "\n", 2)[0])
+}
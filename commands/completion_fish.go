@@ -0,0 +1,183 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// fishHead holds the functions of the fish completion script. The
+// subcommands given so far are found by skipping the flags and their
+// values, and resolving aliases.
+const fishHead = `
+function __bl_command_path --description 'Print the subcommands given so far'
+    set -l tokens (commandline -opc)
+    set -e tokens[1]
+    set -l path
+    set -l skip 0
+    for token in $tokens
+        if test $skip -eq 1
+            set skip 0
+            continue
+        end
+        switch $token
+            case '--*=*'
+            case '-*'
+                if contains -- $token $__bl_value_flags
+                    set skip 1
+                end
+            case '*'
+                set path $path (__bl_command_name $path $token)
+        end
+    end
+    if set -q path[1]
+        printf '%s\n' $path
+    end
+end
+
+function __bl_at_command --description 'Test whether the subcommands given are exactly the arguments'
+    set -l path (__bl_command_path)
+    test "$path" = "$argv"
+end
+
+function __bl_using_command --description 'Test whether the subcommands given start with the arguments'
+    set -l path (__bl_command_path)
+    test (count $path) -ge (count $argv); or return 1
+    for i in (seq (count $argv))
+        test "$path[$i]" = "$argv[$i]"; or return 1
+    end
+end
+
+function __bl_complete --description 'List the names and IDs of a kind of resource'
+    bl __complete $argv 2>/dev/null </dev/null
+end
+
+complete -c bl -f
+complete -c bl -s h -l help -d 'Help for the command'
+`
+
+// RunCompletionFish outputs completion code for fish.
+func RunCompletionFish(c *CmdConfig) error {
+	var buf bytes.Buffer
+
+	_, err := buf.Write([]byte(blLicense))
+	if err != nil {
+		return fmt.Errorf("Error while generating fish completion: %v", err)
+	}
+
+	genFishCompletion(&buf, DoitCmd.Command)
+
+	_, err = c.Out.Write(buf.Bytes())
+	return err
+}
+
+// genFishCompletion writes the fish completion script of a command tree.
+func genFishCompletion(w io.Writer, root *cobra.Command) {
+	var (
+		valueFlags []string
+		aliases    = map[string]string{}
+		completes  bytes.Buffer
+	)
+
+	addFlags := func(cmd *cobra.Command, condition string) {
+		for _, f := range completionFlags(cmd) {
+			line := "complete -c bl"
+			if condition != "" {
+				line += " -n " + fishQuote(condition)
+			}
+			if f.Shorthand != "" {
+				line += " -s " + f.Shorthand
+			}
+			line += " -l " + f.Name
+
+			if flagTakesValue(f) {
+				valueFlags = append(valueFlags, "--"+f.Name)
+				if f.Shorthand != "" {
+					valueFlags = append(valueFlags, "-"+f.Shorthand)
+				}
+
+				if kind := flagCompleteKind(f); kind != "" {
+					line += " -x -a " + fishQuote("(__bl_complete "+kind+")")
+				} else {
+					line += " -r"
+				}
+			}
+			fmt.Fprintln(&completes, line+" -d "+fishQuote(completionDescription(f.Usage)))
+		}
+	}
+
+	var walk func(cmd *cobra.Command, path []string)
+	walk = func(cmd *cobra.Command, path []string) {
+		for _, child := range completionChildren(cmd) {
+			fmt.Fprintf(&completes, "complete -c bl -n %s -a %s -d %s\n",
+				fishQuote(strings.TrimSpace("__bl_at_command "+strings.Join(path, " "))),
+				child.Name(), fishQuote(completionDescription(child.Short)))
+
+			childPath := append(append([]string{}, path...), child.Name())
+			for _, alias := range child.Aliases {
+				aliases[strings.Join(append(append([]string{}, path...), alias), " ")] = child.Name()
+			}
+
+			condition := "__bl_using_command " + strings.Join(childPath, " ")
+			if kind := child.Annotations[completeArgsAnnotation]; kind != "" {
+				fmt.Fprintf(&completes, "complete -c bl -n %s -a %s\n", fishQuote(condition), fishQuote("(__bl_complete "+kind+")"))
+			}
+			addFlags(child, condition)
+
+			walk(child, childPath)
+		}
+	}
+
+	addFlags(root, "")
+	walk(root, nil)
+
+	fmt.Fprintf(w, "\nset -g __bl_value_flags %s\n", strings.Join(sortedUnique(valueFlags), " "))
+
+	fmt.Fprint(w, "\nfunction __bl_command_name --description 'Resolve the alias of a subcommand'\n    switch \"$argv\"\n")
+	keys := make([]string, 0, len(aliases))
+	for k := range aliases {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "        case %s\n            echo %s\n", fishQuote(k), aliases[k])
+	}
+	fmt.Fprint(w, "        case '*'\n            echo $argv[-1]\n    end\nend\n")
+
+	io.WriteString(w, fishHead)
+	completes.WriteTo(w)
+}
+
+// fishQuote quotes a string for fish.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// sortedUnique sorts a list of strings and removes the duplicates.
+func sortedUnique(list []string) []string {
+	sort.Strings(list)
+	var out []string
+	for i, s := range list {
+		if i == 0 || s != list[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// powershellCompleter is the argument completer of the PowerShell completion
+// script. It finds the command being completed by skipping the flags and
+// their values and resolving aliases, then completes its flags, its
+// subcommands or the resources of its arguments or of the flag before the
+// cursor.
+const powershellCompleter = `
+    $words = @($commandAst.CommandElements |
+        Where-Object { $_.Extent.StartOffset -lt $cursorPosition } |
+        ForEach-Object { $_.Extent.Text })
+    if ($wordToComplete -ne '') {
+        $words = @($words | Select-Object -First ($words.Count - 1))
+    }
+
+    $path = ''
+    $pending = $null
+    foreach ($word in @($words | Select-Object -Skip 1)) {
+        if ($pending) {
+            $pending = $null
+            continue
+        }
+        if ($word.StartsWith('-')) {
+            if (-not $word.Contains('=')) {
+                $pending = @($commands[$path].Flags + $globalFlags) |
+                    Where-Object { $_.Name -eq $word -and $_.Value } |
+                    Select-Object -First 1
+            }
+            continue
+        }
+        $name = $word
+        if ($commands[$path].Aliases.ContainsKey($word)) {
+            $name = $commands[$path].Aliases[$word]
+        }
+        $next = ($path + ' ' + $name).Trim()
+        if ($commands.ContainsKey($next)) {
+            $path = $next
+        }
+    }
+
+    $command = $commands[$path]
+    $complete = {
+        param($kind)
+        & bl __complete $kind 2>$null | ForEach-Object {
+            [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+        }
+    }
+
+    $results = @()
+    if ($pending) {
+        if ($pending.Complete) {
+            $results = & $complete $pending.Complete
+        }
+    } elseif ($wordToComplete.StartsWith('-')) {
+        $results = @($command.Flags + $globalFlags) | ForEach-Object {
+            [System.Management.Automation.CompletionResult]::new($_.Name, $_.Name, 'ParameterName', $_.Description)
+        }
+    } else {
+        $results = @($command.Commands) | ForEach-Object {
+            [System.Management.Automation.CompletionResult]::new($_.Name, $_.Name, 'ParameterValue', $_.Description)
+        }
+        if ($command.Complete) {
+            $results = @($results) + @(& $complete $command.Complete)
+        }
+    }
+
+    $results | Where-Object { $_.CompletionText -like "$wordToComplete*" }
+}
+`
+
+// RunCompletionPowerShell outputs completion code for PowerShell.
+func RunCompletionPowerShell(c *CmdConfig) error {
+	var buf bytes.Buffer
+
+	_, err := buf.Write([]byte(blLicense))
+	if err != nil {
+		return fmt.Errorf("Error while generating PowerShell completion: %v", err)
+	}
+
+	genPowerShellCompletion(&buf, DoitCmd.Command)
+
+	_, err = c.Out.Write(buf.Bytes())
+	return err
+}
+
+// genPowerShellCompletion writes the PowerShell completion script of a
+// command tree. The subcommands, aliases, flags and completed resources of
+// each command are kept in a hashtable keyed by the command's path.
+func genPowerShellCompletion(w io.Writer, root *cobra.Command) {
+	fmt.Fprint(w, "\nRegister-ArgumentCompleter -Native -CommandName 'bl' -ScriptBlock {\n")
+	fmt.Fprint(w, "    param($wordToComplete, $commandAst, $cursorPosition)\n\n")
+
+	fmt.Fprint(w, "    $globalFlags = @(\n")
+	fmt.Fprint(w, "        @{ Name = '--help'; Description = 'Help for the command'; Value = $false; Complete = '' }\n")
+	fmt.Fprint(w, "        @{ Name = '-h'; Description = 'Help for the command'; Value = $false; Complete = '' }\n")
+	writePowerShellFlags(w, "        ", completionFlags(root))
+	fmt.Fprint(w, "    )\n\n")
+
+	fmt.Fprint(w, "    $commands = @{\n")
+	var walk func(cmd *cobra.Command, path []string)
+	walk = func(cmd *cobra.Command, path []string) {
+		children := completionChildren(cmd)
+
+		fmt.Fprintf(w, "        %s = @{\n", powershellQuote(strings.Join(path, " ")))
+		fmt.Fprint(w, "            Commands = @(\n")
+		for _, child := range children {
+			fmt.Fprintf(w, "                @{ Name = %s; Description = %s }\n",
+				powershellQuote(child.Name()), powershellQuote(powershellDescription(child.Short, child.Name())))
+		}
+		fmt.Fprint(w, "            )\n")
+
+		fmt.Fprint(w, "            Aliases = @{")
+		for _, child := range children {
+			for _, alias := range child.Aliases {
+				fmt.Fprintf(w, " %s = %s;", powershellQuote(alias), powershellQuote(child.Name()))
+			}
+		}
+		fmt.Fprint(w, " }\n")
+
+		fmt.Fprint(w, "            Flags = @(\n")
+		if cmd != root {
+			writePowerShellFlags(w, "                ", completionFlags(cmd))
+		}
+		fmt.Fprint(w, "            )\n")
+		fmt.Fprintf(w, "            Complete = %s\n", powershellQuote(cmd.Annotations[completeArgsAnnotation]))
+		fmt.Fprint(w, "        }\n")
+
+		for _, child := range children {
+			walk(child, append(append([]string{}, path...), child.Name()))
+		}
+	}
+	walk(root, nil)
+	fmt.Fprint(w, "    }\n")
+
+	fmt.Fprint(w, powershellCompleter)
+}
+
+// writePowerShellFlags writes the entries of flags, and of their
+// shorthands, in a list of flags of the PowerShell completion script.
+func writePowerShellFlags(w io.Writer, indent string, flags []*pflag.Flag) {
+	for _, f := range flags {
+		names := []string{"--" + f.Name}
+		if f.Shorthand != "" {
+			names = append(names, "-"+f.Shorthand)
+		}
+
+		value := "$false"
+		if flagTakesValue(f) {
+			value = "$true"
+		}
+
+		for _, name := range names {
+			fmt.Fprintf(w, "%s@{ Name = %s; Description = %s; Value = %s; Complete = %s }\n",
+				indent, powershellQuote(name), powershellQuote(powershellDescription(f.Usage, name)), value, powershellQuote(flagCompleteKind(f)))
+		}
+	}
+}
+
+// powershellDescription returns the tooltip of a completion, which can't be
+// empty.
+func powershellDescription(s, name string) string {
+	if d := completionDescription(s); d != "" {
+		return d
+	}
+	return name
+}
+
+// powershellQuote quotes a string for PowerShell, which also takes the
+// typographic single quotes for quotes.
+func powershellQuote(s string) string {
+	for _, q := range []string{"'", "\u2018", "\u2019", "\u201a", "\u201b"} {
+		s = strings.Replace(s, q, q+q, -1)
+	}
+	return "'" + s + "'"
+}
@@ -0,0 +1,305 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/bl-cli/commands/displayers"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Config creates the config command.
+func Config() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "config",
+			Short: "Display commands for managing the bl configuration",
+			Long:  "The subcommands of `bl config` manage the settings in the bl config file.",
+		},
+	}
+
+	cmdBuilderWithInit(cmd, RunConfigView, "view", "Display the configuration", `Use this command to display the settings that are in effect, and whether each comes from a flag, a `+"`"+`BINARYLANE_`+"`"+` environment variable or the config file. Settings left at their defaults are not shown. Access tokens are redacted.`, Writer, false,
+		displayerType(&displayers.ConfigSettings{}))
+	cmdBuilderWithInit(cmd, RunConfigGet, "get <key>", "Display the value of a setting", `Use this command to display the value in effect for a setting, such as `+"`"+`output`+"`"+` or `+"`"+`server.create.region`+"`"+`, and where it comes from. Use `+"`"+`--format Value --no-header`+"`"+` to display only the value.`, Writer, false,
+		displayerType(&displayers.ConfigSettings{}))
+	cmdBuilderWithInit(cmd, RunConfigSet, "set <key> <value>", "Set a setting in the config file", `Use this command to set a setting in the config file. The value is checked against the type of the setting, and the values of a list, such as `+"`"+`server.create.ssh-keys`+"`"+`, are separated with commas.`, Writer, false)
+	cmdBuilderWithInit(cmd, RunConfigUnset, "unset <key>", "Remove a setting from the config file", `Use this command to remove a setting from the config file, so that its default is used.`, Writer, false)
+	cmdBuilderWithInit(cmd, RunConfigPath, "path", "Display the path of the config file", `Use this command to display the path of the config file in use.`, Writer, false,
+		brokenConfigOpt())
+	cmdBuilderWithInit(cmd, RunConfigValidate, "validate", "Check the config file", `Use this command to check the config file for settings that bl doesn't know and for values of the wrong type, along with the values of `+"`"+`BINARYLANE_`+"`"+` environment variables. It exits with a non-zero status when there are problems.`, Writer, false,
+		brokenConfigOpt())
+
+	cmdBuilderWithInit(cmd, RunConfigSetDefault, "set-default <key> <value>", "Set a flag default for the authentication context", `Use this command to set the default value of a command's flag for the current authentication context, or the one given with `+"`"+`--context`+"`"+`. The default is used whenever the flag isn't given while the context is in use, so that each account can have its own region, size, image, SSH keys and VPC.
+
+The key is the command's path and the flag's name joined by dots, such as `+"`"+`compute.server.create.region`+"`"+` for the `+"`"+`--region`+"`"+` flag of `+"`"+`bl compute server create`+"`"+`. The flag's key in the config file, such as `+"`"+`server.create.region`+"`"+`, is also accepted. Separate the values of a list flag, such as `+"`"+`--ssh-keys`+"`"+`, with commas.
+
+A flag given on the command line or in a BINARYLANE_ environment variable takes precedence over the context's default, which takes precedence over a default set at the top level of the config file.`, Writer, false)
+	cmdBuilderWithInit(cmd, RunConfigUnsetDefault, "unset-default <key>", "Remove a flag default from the authentication context", `Use this command to remove a default set with `+"`"+`bl config set-default`+"`"+` from the current authentication context, or the one given with `+"`"+`--context`+"`"+`.`, Writer, false)
+
+	return cmd
+}
+
+// RunConfigSetDefault sets a flag default for the current auth context.
+func RunConfigSetDefault(c *CmdConfig) error {
+	switch {
+	case len(c.Args) < 2:
+		return blcli.NewMissingArgsErr(c.NS)
+	case len(c.Args) > 2:
+		return blcli.NewTooManyArgsErr(c.NS)
+	}
+
+	context := currentContext()
+	if !authContextExists(context) {
+		return fmt.Errorf("Authentication context %q doesn't exist", context)
+	}
+
+	key, f, err := findDefaultFlag(DoitCmd.Command, c.Args[0])
+	if err != nil {
+		return err
+	}
+
+	value, err := parseDefaultValue(f, c.Args[1])
+	if err != nil {
+		return fmt.Errorf("Invalid value %q for --%s: %v", c.Args[1], f.Name, err)
+	}
+
+	setContextDefault(context, key, value)
+	return writeConfig()
+}
+
+// RunConfigUnsetDefault removes a flag default from the current auth context.
+func RunConfigUnsetDefault(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+
+	context := currentContext()
+	key, _, err := findDefaultFlag(DoitCmd.Command, c.Args[0])
+	if err != nil {
+		return err
+	}
+
+	if _, ok := contextDefaults(context)[key]; !ok {
+		return fmt.Errorf("Authentication context %q has no default for %q", context, c.Args[0])
+	}
+
+	setContextDefault(context, key, nil)
+	return writeConfig()
+}
+
+// RunConfigView displays the settings that are in effect.
+func RunConfigView(c *CmdConfig) error {
+	file, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+	settings := configSettings()
+
+	keys := map[string]bool{}
+	for key := range file {
+		keys[key] = true
+	}
+	for key := range project.settings() {
+		keys[key] = true
+	}
+	for key, s := range settings {
+		if s.kind == settingMap {
+			continue
+		}
+		if source := settingSource(key, file); source == "flag" || source == "env" {
+			keys[key] = true
+		}
+	}
+
+	var rows []displayers.ConfigSetting
+	for key := range keys {
+		s, known := findSetting(settings, key)
+		source := settingSource(key, file)
+
+		if known && s.kind == settingMap {
+			rows = append(rows, mapSettingRows(s, file[key])...)
+			continue
+		}
+
+		value := viper.Get(key)
+		if key == blcli.ArgContext {
+			value = currentContext()
+		}
+		if known && source == "file" && isDefaultSetting(s, value) {
+			continue
+		}
+
+		text := settingValueString(value)
+		if s.secret && text != "" {
+			text = redactedSetting
+		}
+		rows = append(rows, displayers.ConfigSetting{Key: key, Value: text, Source: source})
+	}
+
+	sortConfigSettings(rows)
+	return c.Display(&displayers.ConfigSettings{ConfigSettings: rows})
+}
+
+// RunConfigGet displays the value of a setting.
+func RunConfigGet(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+
+	key := strings.ToLower(c.Args[0])
+	file, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+
+	source := settingSource(key, file)
+	s, ok := findSetting(configSettings(), key)
+	switch {
+	case !ok && source == "default":
+		return fmt.Errorf("Unknown config key %q", key)
+	case s.kind == settingMap && viper.IsSet(key):
+		source = "file"
+	}
+
+	value := viper.Get(key)
+	if key == blcli.ArgContext {
+		value = currentContext()
+	}
+
+	return c.Display(&displayers.ConfigSettings{ConfigSettings: []displayers.ConfigSetting{
+		{Key: key, Value: settingValueString(value), Source: source},
+	}})
+}
+
+// RunConfigSet sets a setting in the config file.
+func RunConfigSet(c *CmdConfig) error {
+	switch {
+	case len(c.Args) < 2:
+		return blcli.NewMissingArgsErr(c.NS)
+	case len(c.Args) > 2:
+		return blcli.NewTooManyArgsErr(c.NS)
+	}
+
+	key := strings.ToLower(c.Args[0])
+	s, ok := findSetting(configSettings(), key)
+	switch {
+	case !ok:
+		return fmt.Errorf("Unknown config key %q", key)
+	case s.kind == settingMap:
+		return fmt.Errorf("%q can't be set with bl config set. Use bl auth to manage authentication contexts, and bl config set-default for their defaults", key)
+	case key == "config":
+		return fmt.Errorf("The path of the config file can't be set in the config file")
+	}
+
+	value, err := parseSettingValue(s.kind, c.Args[1])
+	if err != nil {
+		return fmt.Errorf("Invalid value %q for %s: %v", c.Args[1], key, err)
+	}
+
+	file, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+	file[key] = value
+	return writeConfigFile(file)
+}
+
+// RunConfigUnset removes a setting from the config file.
+func RunConfigUnset(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+
+	key := strings.ToLower(c.Args[0])
+	file, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+	if _, ok := file[key]; !ok {
+		return fmt.Errorf("%q isn't set in the config file", key)
+	}
+
+	delete(file, key)
+	return writeConfigFile(file)
+}
+
+// RunConfigPath displays the path of the config file.
+func RunConfigPath(c *CmdConfig) error {
+	fmt.Fprintln(c.Out, viper.GetString("config"))
+	return nil
+}
+
+// RunConfigValidate checks the config file, the project config file and the
+// BINARYLANE_ environment variables for unknown settings and values of the
+// wrong type.
+func RunConfigValidate(c *CmdConfig) error {
+	cfgFile := viper.GetString("config")
+	file, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+	settings := configSettings()
+
+	var problems []string
+	for _, key := range sortedKeys(file) {
+		s, ok := findSetting(settings, key)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: unknown key", key))
+			continue
+		}
+		if err := checkSettingValue(s, file[key]); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		problems = append(problems, checkMapSetting(s, file[key])...)
+	}
+
+	for _, key := range sortedSettingKeys(settings) {
+		s := settings[key]
+		env := settingEnv(key)
+		value, ok := os.LookupEnv(env)
+		if !ok || s.kind == settingMap {
+			continue
+		}
+		if err := checkSettingValue(s, value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s (from %s): %v", key, env, err))
+		}
+	}
+
+	if wd, err := os.Getwd(); err == nil {
+		if path := findProjectConfig(wd); path != "" {
+			if _, err := readProjectConfig(path); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", path, err))
+			}
+		}
+	}
+
+	for _, p := range problems {
+		fmt.Fprintln(c.Out, p)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("The configuration in %s has %d problem(s)", cfgFile, len(problems))
+	}
+
+	fmt.Fprintf(c.Out, "The configuration in %s is valid\n", cfgFile)
+	return nil
+}
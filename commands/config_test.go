@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigCommand(t *testing.T) {
+	cmd := Config()
+	assert.NotNil(t, cmd)
+	assertCommandNames(t, cmd, "get", "path", "set", "set-default", "unset", "unset-default", "validate", "view")
+}
+
+func TestConfigSetAndValidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("bogus: 1\nmax-retries: lots\nserver:\n  create:\n    region: syd\n"), 0600))
+
+	cfg := viper.GetString("config")
+	cfw := cfgFileWriter
+	defer func() {
+		viper.Set("config", cfg)
+		cfgFileWriter = cfw
+	}()
+	viper.Set("config", path)
+	cfgFileWriter = func() (io.WriteCloser, error) { return os.Create(path) }
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		buf := &bytes.Buffer{}
+		config.Out = buf
+
+		err := RunConfigValidate(config)
+		assert.EqualError(t, err, "The configuration in "+path+" has 2 problem(s)")
+		assert.Equal(t, "bogus: unknown key\nmax-retries: expected a value of type int: strconv.Atoi: parsing \"lots\": invalid syntax\n", buf.String())
+
+		config.Args = []string{"max-retries", "often"}
+		assert.EqualError(t, RunConfigSet(config), `Invalid value "often" for max-retries: strconv.Atoi: parsing "often": invalid syntax`)
+
+		config.Args = []string{"colour", "blue"}
+		assert.EqualError(t, RunConfigSet(config), `Unknown config key "colour"`)
+
+		config.Args = []string{"auth-contexts", "blue"}
+		assert.Error(t, RunConfigSet(config))
+
+		config.Args = []string{"max-retries", "3"}
+		require.NoError(t, RunConfigSet(config))
+		config.Args = []string{"bogus"}
+		require.NoError(t, RunConfigUnset(config))
+		assert.EqualError(t, RunConfigUnset(config), `"bogus" isn't set in the config file`)
+
+		b, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "max-retries: 3\nserver:\n  create:\n    region: syd\n", string(b))
+
+		buf.Reset()
+		config.Args = nil
+		require.NoError(t, RunConfigValidate(config))
+		assert.Equal(t, "The configuration in "+path+" is valid\n", buf.String())
+	})
+}
+
+func TestConfigSetDefault(t *testing.T) {
+	withKeyring(t)
+	viper.Set("auth-contexts", map[string]string{"team": "team-token"})
+
+	cfw := cfgFileWriter
+	defer func() {
+		cfgFileWriter = cfw
+	}()
+	cfgFileWriter = func() (io.WriteCloser, error) { return &nopWriteCloser{Writer: ioutil.Discard}, nil }
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = []string{"compute.server.create.region", "syd"}
+		require.NoError(t, RunConfigSetDefault(config))
+
+		config.Args = []string{"server.create.ssh-keys", "123, 456"}
+		require.NoError(t, RunConfigSetDefault(config))
+
+		config.Args = []string{"compute.server.create.enable-backups", "true"}
+		require.NoError(t, RunConfigSetDefault(config))
+
+		assert.Equal(t, map[string]interface{}{
+			"server.create.region":         "syd",
+			"server.create.ssh-keys":       []string{"123", "456"},
+			"server.create.enable-backups": true,
+		}, contextDefaults("default"))
+		assert.Empty(t, contextDefaults("team"))
+
+		config.Args = []string{"compute.server.create.enable-backups", "sometimes"}
+		err := RunConfigSetDefault(config)
+		assert.EqualError(t, err, `Invalid value "sometimes" for --enable-backups: strconv.ParseBool: parsing "sometimes": invalid syntax`)
+
+		config.Args = []string{"compute.server.create.colour", "blue"}
+		err = RunConfigSetDefault(config)
+		assert.EqualError(t, err, `"compute.server.create.colour" isn't a command flag. Defaults are named after a command and one of its flags, such as compute.server.create.region`)
+
+		config.Args = []string{"compute.server.create.region"}
+		require.NoError(t, RunConfigUnsetDefault(config))
+		assert.NotContains(t, contextDefaults("default"), "server.create.region")
+
+		err = RunConfigUnsetDefault(config)
+		assert.EqualError(t, err, `Authentication context "default" has no default for "compute.server.create.region"`)
+	})
+}
+
+func TestApplyContextDefaults(t *testing.T) {
+	withKeyring(t)
+	viper.Set(contextDefaultsKey, map[string]interface{}{
+		"default": map[string]interface{}{
+			"widget.create.region":   "syd",
+			"widget.create.size":     "std-min",
+			"widget.create.image":    "ubuntu",
+			"widget.create.ssh-keys": []interface{}{"123", 456},
+		},
+		// Contexts read from the config file have nested maps.
+		"team": map[interface{}]interface{}{
+			"gadget": map[interface{}]interface{}{
+				"create": map[interface{}]interface{}{"region": "bne"},
+			},
+		},
+	})
+
+	newCreate := func(parent string) *cobra.Command {
+		create := &cobra.Command{Use: "create"}
+		create.Flags().String("region", "", "")
+		create.Flags().String("size", "", "")
+		create.Flags().String("image", "", "")
+		create.Flags().StringSlice("ssh-keys", nil, "")
+		(&cobra.Command{Use: parent}).AddCommand(create)
+		create.Flags().VisitAll(func(f *pflag.Flag) {
+			viper.BindPFlag(parent+".create."+f.Name, f)
+		})
+		return create
+	}
+
+	// A flag takes precedence over the context's default, and so does an
+	// environment variable.
+	os.Setenv("BINARYLANE_WIDGET.CREATE.IMAGE", "debian")
+	defer os.Unsetenv("BINARYLANE_WIDGET.CREATE.IMAGE")
+
+	create := newCreate("widget")
+	require.NoError(t, create.Flags().Set("size", "std-1vcpu"))
+	require.NoError(t, applyContextDefaults(create, nil))
+
+	assert.Equal(t, "syd", viper.GetString("widget.create.region"))
+	assert.Equal(t, "std-1vcpu", viper.GetString("widget.create.size"))
+	assert.Equal(t, "debian", viper.GetString("widget.create.image"))
+	assert.Equal(t, []string{"123", "456"}, viper.GetStringSlice("widget.create.ssh-keys"))
+
+	Context = "team"
+	defer func() {
+		Context = ""
+	}()
+
+	create = newCreate("gadget")
+	require.NoError(t, applyContextDefaults(create, nil))
+	assert.Equal(t, "bne", viper.GetString("gadget.create.region"))
+	assert.Equal(t, "", viper.GetString("gadget.create.size"))
+}
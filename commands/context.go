@@ -0,0 +1,82 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/spf13/viper"
+)
+
+// commandContext returns the context for a command's API calls. It is
+// canceled by the first SIGINT or SIGTERM, after which the signals are
+// handled by default again so that a second Ctrl-C exits immediately, and
+// has a deadline when --timeout is set.
+func commandContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if timeout := viper.GetDuration(blcli.ArgTimeout); timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+
+		cancelParent := cancel
+		cancel = func() {
+			cancelTimeout()
+			cancelParent()
+		}
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sigs:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sigs)
+	}()
+
+	return ctx, cancel
+}
+
+// contextErr replaces the error of a command whose context ended with an
+// error saying why it ended.
+func contextErr(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return &cliError{
+			err:      fmt.Errorf("timed out after %s: %v", viper.GetDuration(blcli.ArgTimeout), err),
+			code:     "timeout",
+			exitCode: ExitTimeout,
+		}
+	case context.Canceled:
+		return &cliError{
+			err:      fmt.Errorf("interrupted: %v", err),
+			code:     "interrupted",
+			exitCode: ExitInterrupted,
+		}
+	default:
+		return err
+	}
+}
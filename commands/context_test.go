@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandContext(t *testing.T) {
+	defer viper.Set(blcli.ArgTimeout, viper.Get(blcli.ArgTimeout))
+
+	viper.Set(blcli.ArgTimeout, time.Duration(0))
+	ctx, cancel := commandContext()
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+	cancel()
+	assert.Equal(t, context.Canceled, ctx.Err())
+
+	viper.Set(blcli.ArgTimeout, "1ms")
+	ctx, cancel = commandContext()
+	defer cancel()
+	<-ctx.Done()
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+
+	err := contextErr(ctx, errors.New("Get https://api.binarylane.com.au/v2/servers: context deadline exceeded"))
+	assert.EqualError(t, err, "timed out after 1ms: Get https://api.binarylane.com.au/v2/servers: context deadline exceeded")
+	assert.NoError(t, contextErr(ctx, nil))
+
+	viper.Set(blcli.ArgTimeout, "1h")
+	ctx, cancel = commandContext()
+	cancel()
+	assert.Equal(t, context.Canceled, ctx.Err())
+}
+
+func TestContextErr(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := errors.New("not found")
+	assert.Equal(t, err, contextErr(ctx, err))
+
+	cancel()
+	assert.EqualError(t, contextErr(ctx, err), "interrupted: not found")
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+
+	"github.com/binarylane/bl-cli"
+	"golang.org/x/crypto/ssh/terminal"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// credentialStoresKey and credentialHelpersKey are the config file maps
+	// of auth contexts to the credential store or helper that holds their
+	// access token. Contexts that aren't in either keep their token in the
+	// config file.
+	credentialStoresKey  = "credential-stores"
+	credentialHelpersKey = "credential-helpers"
+
+	// credentialsFileName is the encrypted credentials file, which is kept
+	// next to the config file.
+	credentialsFileName = "credentials.enc"
+)
+
+var (
+	// newKeyringStore returns the OS keyring. It can be replaced in tests.
+	newKeyringStore = blcli.NewKeyringStore
+
+	// passphrase is the passphrase of the encrypted credentials file, once
+	// it has been prompted for.
+	passphrase string
+)
+
+// currentContext returns the name of the auth context in use. The project
+// config file's context takes precedence over the config file's, but not
+// over --context or BINARYLANE_CONTEXT.
+func currentContext() string {
+	if Context != "" {
+		return Context
+	}
+	if _, ok := os.LookupEnv(settingEnv(blcli.ArgContext)); !ok && project.Context != "" {
+		return project.Context
+	}
+	return viper.GetString("context")
+}
+
+// contextAccessToken resolves the access token of an auth context. A token in
+// the config file, or given with --access-token for the default context, is
+// used first, then the context's credential helper or credential store. An
+// empty token is returned when the context has none.
+func contextAccessToken(context string) (string, error) {
+	var token string
+	switch context {
+	case blcli.ArgDefaultContext:
+		token = viper.GetString(blcli.ArgAccessToken)
+	default:
+		token = viper.GetStringMapString("auth-contexts")[context]
+	}
+	if token != "" {
+		return token, nil
+	}
+
+	store, err := contextCredentialStore(context)
+	if err != nil || store == nil {
+		return "", err
+	}
+
+	token, err = store.Get(context)
+	if err == blcli.ErrCredentialNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("Unable to read the access token of context %q: %w", context, err)
+	}
+	return token, nil
+}
+
+// contextTokenSource describes where the access token of an auth context
+// comes from, following the same order as contextAccessToken.
+func contextTokenSource(context string) string {
+	switch {
+	case context == blcli.ArgDefaultContext && Token != "":
+		return "flag"
+	case context == blcli.ArgDefaultContext && os.Getenv("BINARYLANE_ACCESS_TOKEN") != "":
+		return "env"
+	case context == blcli.ArgDefaultContext && viper.GetString(blcli.ArgAccessToken) != "":
+		return blcli.CredentialStoreConfig
+	case context != blcli.ArgDefaultContext && viper.GetStringMapString("auth-contexts")[context] != "":
+		return blcli.CredentialStoreConfig
+	case viper.GetStringMapString(credentialHelpersKey)[context] != "":
+		return "helper"
+	}
+
+	if store := viper.GetStringMapString(credentialStoresKey)[context]; store != "" {
+		return store
+	}
+	return "none"
+}
+
+// authContextNames returns the sorted names of the auth contexts in the
+// config file. The default context is always included.
+func authContextNames() []string {
+	seen := map[string]bool{blcli.ArgDefaultContext: true}
+	names := []string{blcli.ArgDefaultContext}
+	for _, key := range []string{"auth-contexts", credentialStoresKey, credentialHelpersKey} {
+		for name := range viper.GetStringMapString(key) {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// authContextExists reports whether an auth context is in the config file.
+func authContextExists(context string) bool {
+	for _, name := range authContextNames() {
+		if name == context {
+			return true
+		}
+	}
+	return false
+}
+
+// contextCredentialStore returns the store that holds the access token of an
+// auth context, or nil when the token is kept in the config file.
+func contextCredentialStore(context string) (blcli.CredentialStore, error) {
+	if helper := viper.GetStringMapString(credentialHelpersKey)[context]; helper != "" {
+		return &blcli.CredentialHelper{Command: helper}, nil
+	}
+	return newCredentialStore(viper.GetStringMapString(credentialStoresKey)[context])
+}
+
+// newCredentialStore returns a credential store by name, or nil for the
+// config file.
+func newCredentialStore(name string) (blcli.CredentialStore, error) {
+	switch name {
+	case "", blcli.CredentialStoreConfig:
+		return nil, nil
+	case blcli.CredentialStoreKeyring:
+		return newKeyringStore(), nil
+	case blcli.CredentialStoreFile:
+		return blcli.NewFileStore(credentialsFile(), credentialsPassphrase), nil
+	default:
+		return nil, fmt.Errorf("Unknown credential store %q. Possible values: %s, %s, %s",
+			name, blcli.CredentialStoreConfig, blcli.CredentialStoreKeyring, blcli.CredentialStoreFile)
+	}
+}
+
+// setContextCredentialStore records where the access token of an auth
+// context is stored. The token itself is stored by the caller.
+func setContextCredentialStore(context, store, helper string) {
+	setContextValue(credentialHelpersKey, context, helper)
+	if store == blcli.CredentialStoreConfig || helper != "" {
+		store = ""
+	}
+	setContextValue(credentialStoresKey, context, store)
+}
+
+// setContextValue sets the value of a context in one of the config file's
+// maps of contexts, removing it when it is empty.
+func setContextValue(key, context, value string) {
+	values := viper.GetStringMapString(key)
+	if values[context] == value {
+		return
+	}
+
+	if value == "" {
+		delete(values, context)
+	} else {
+		values[context] = value
+	}
+	viper.Set(key, values)
+}
+
+func credentialsFile() string {
+	return filepath.Join(filepath.Dir(viper.GetString("config")), credentialsFileName)
+}
+
+// credentialsPassphrase returns the passphrase of the encrypted credentials
+// file, from the environment or by prompting for it. It is only prompted for
+// once, however many contexts are read from the file.
+func credentialsPassphrase() (string, error) {
+	if os.Getenv(blcli.CredentialsPassphraseEnv) != "" || !terminal.IsTerminal(int(syscall.Stdin)) {
+		return blcli.PassphraseFromEnv()
+	}
+	if passphrase != "" {
+		return passphrase, nil
+	}
+
+	fmt.Fprint(os.Stderr, "Enter the passphrase of the credentials file: ")
+	b, err := terminal.ReadPassword(int(syscall.Stdin))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	passphrase = string(b)
+	return passphrase, nil
+}
@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is a credential store that stands in for the OS keyring.
+type memoryStore map[string]string
+
+func (s memoryStore) Get(context string) (string, error) {
+	token, ok := s[context]
+	if !ok {
+		return "", blcli.ErrCredentialNotFound
+	}
+	return token, nil
+}
+
+func (s memoryStore) Set(context, token string) error {
+	s[context] = token
+	return nil
+}
+
+func (s memoryStore) Delete(context string) error {
+	delete(s, context)
+	return nil
+}
+
+// withKeyring replaces the OS keyring and resets the auth settings when the
+// test is done.
+func withKeyring(t *testing.T) memoryStore {
+	keyring := memoryStore{}
+
+	nks := newKeyringStore
+	newKeyringStore = func() blcli.CredentialStore { return keyring }
+
+	t.Cleanup(func() {
+		newKeyringStore = nks
+		for _, key := range []string{blcli.ArgAccessToken, "auth-contexts", credentialStoresKey, credentialHelpersKey, contextDefaultsKey} {
+			viper.Set(key, nil)
+		}
+	})
+
+	return keyring
+}
+
+func TestContextAccessToken(t *testing.T) {
+	keyring := withKeyring(t)
+	keyring["next"] = "keyring-token"
+
+	viper.Set("auth-contexts", map[string]string{"old": "config-token", "next": "", "helped": ""})
+	viper.Set(credentialStoresKey, map[string]string{"next": blcli.CredentialStoreKeyring, "missing": blcli.CredentialStoreKeyring})
+	viper.Set(credentialHelpersKey, map[string]string{"helped": "echo helper-token; true"})
+
+	tests := []struct {
+		context string
+		token   string
+	}{
+		{context: "old", token: "config-token"},
+		{context: "next", token: "keyring-token"},
+		{context: "helped", token: "helper-token"},
+		{context: "missing", token: ""},
+		{context: "unknown", token: ""},
+	}
+
+	for _, tt := range tests {
+		token, err := contextAccessToken(tt.context)
+		require.NoError(t, err, tt.context)
+		assert.Equal(t, tt.token, token, tt.context)
+	}
+
+	viper.Set(credentialStoresKey, map[string]string{"next": "vault"})
+	_, err := contextAccessToken("next")
+	assert.EqualError(t, err, `Unknown credential store "vault". Possible values: config, keyring, file`)
+}
+
+func TestAuthInitWithKeyring(t *testing.T) {
+	keyring := withKeyring(t)
+
+	cfw := cfgFileWriter
+	defer func() {
+		cfgFileWriter = cfw
+	}()
+	cfgFileWriter = func() (io.WriteCloser, error) { return &nopWriteCloser{Writer: ioutil.Discard}, nil }
+
+	retrieveUserTokenFunc := func() (string, error) {
+		return "valid-token", nil
+	}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.setContextAccessToken = func(token string) {
+			viper.Set(blcli.ArgAccessToken, token)
+		}
+		config.Doit.Set(config.NS, blcli.ArgCredentialStore, blcli.CredentialStoreKeyring)
+
+		tm.account.EXPECT().Get().Return(&bl.Account{}, nil)
+
+		err := RunAuthInit(retrieveUserTokenFunc)(config)
+		require.NoError(t, err)
+
+		assert.Equal(t, "valid-token", keyring[blcli.ArgDefaultContext])
+		assert.Empty(t, viper.GetString(blcli.ArgAccessToken))
+		assert.Equal(t, blcli.CredentialStoreKeyring, viper.GetStringMapString(credentialStoresKey)[blcli.ArgDefaultContext])
+
+		token, err := contextAccessToken(blcli.ArgDefaultContext)
+		require.NoError(t, err)
+		assert.Equal(t, "valid-token", token)
+	})
+}
+
+func TestAuthInitWithCredentialHelper(t *testing.T) {
+	withKeyring(t)
+
+	cfw := cfgFileWriter
+	defer func() {
+		cfgFileWriter = cfw
+	}()
+	cfgFileWriter = func() (io.WriteCloser, error) { return &nopWriteCloser{Writer: ioutil.Discard}, nil }
+
+	retrieveUserTokenFunc := func() (string, error) {
+		t.Fatal("the token should come from the helper")
+		return "", nil
+	}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Doit.Set(config.NS, blcli.ArgCredentialHelper, "echo token=helper-token; true")
+
+		tm.account.EXPECT().Get().Return(&bl.Account{}, nil)
+
+		err := RunAuthInit(retrieveUserTokenFunc)(config)
+		require.NoError(t, err)
+
+		assert.Equal(t, "echo token=helper-token; true", viper.GetStringMapString(credentialHelpersKey)[blcli.ArgDefaultContext])
+		assert.Empty(t, viper.GetStringMapString(credentialStoresKey))
+	})
+}
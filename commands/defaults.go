@@ -0,0 +1,213 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// contextDefaultsKey is the config file map of auth contexts to their flag
+// defaults, which are keyed like the flags' own config keys, such as
+// server.create.region.
+const contextDefaultsKey = "defaults"
+
+// contextDefaults returns the flag defaults of an auth context.
+func contextDefaults(context string) map[string]interface{} {
+	defaults := map[string]interface{}{}
+	flattenDefaults(defaults, "", viper.GetStringMap(contextDefaultsKey)[context])
+	return defaults
+}
+
+// flattenDefaults adds the values of a nested map to out, keyed by their
+// dotted paths. Defaults are written as dotted keys, but viper nests them
+// when it writes the config file.
+func flattenDefaults(out map[string]interface{}, prefix string, v interface{}) {
+	var m map[string]interface{}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m = v
+	case map[interface{}]interface{}:
+		m = map[string]interface{}{}
+		for k, val := range v {
+			m[fmt.Sprint(k)] = val
+		}
+	default:
+		if prefix != "" {
+			out[prefix] = v
+		}
+		return
+	}
+
+	for k, val := range m {
+		key := strings.ToLower(k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		flattenDefaults(out, key, val)
+	}
+}
+
+// setContextDefault sets or, when value is nil, removes a flag default of an
+// auth context.
+func setContextDefault(context, key string, value interface{}) {
+	defaults := contextDefaults(context)
+	if value == nil {
+		delete(defaults, key)
+	} else {
+		defaults[key] = value
+	}
+	setContextDefaults(context, defaults)
+}
+
+// setContextDefaults replaces the flag defaults of an auth context, removing
+// the context from the defaults when there are none.
+func setContextDefaults(context string, defaults map[string]interface{}) {
+	all := viper.GetStringMap(contextDefaultsKey)
+	if _, ok := all[context]; !ok && len(defaults) == 0 {
+		return
+	}
+
+	if len(defaults) == 0 {
+		delete(all, context)
+	} else {
+		all[context] = defaults
+	}
+	viper.Set(contextDefaultsKey, all)
+}
+
+// applyContextDefaults gives the flags of the command being run the current
+// auth context's defaults. The defaults are merged into the config file's
+// values, so that a flag given on the command line or a BINARYLANE_
+// environment variable takes precedence over them, while they take
+// precedence over the top level of the config file. A default set with
+// viper.SetDefault would be hidden by the flag values that writeConfig
+// saves to the file. Required flags are checked against the merged values,
+// so a default can stand in for a required flag such as --region.
+func applyContextDefaults(cmd *cobra.Command, args []string) error {
+	context := currentContext()
+	defaults := contextDefaults(context)
+	if len(defaults) == 0 {
+		return nil
+	}
+
+	ns := cmdNS(cmd)
+	values := map[string]interface{}{}
+	var err error
+	cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+		v, ok := defaults[ns+"."+f.Name]
+		if !ok || err != nil {
+			return
+		}
+
+		value, e := parseDefaultValue(f, defaultValueString(v))
+		if e != nil {
+			err = fmt.Errorf("Invalid default %q for --%s in context [%s]: %v", defaultValueString(v), f.Name, context, e)
+			return
+		}
+		// Lists read from the config file are []interface{}, and viper
+		// only merges values of the same type.
+		if list, ok := value.([]string); ok {
+			items := make([]interface{}, len(list))
+			for i, item := range list {
+				items[i] = item
+			}
+			value = items
+		}
+		setNested(values, strings.Split(ns+"."+f.Name, "."), value)
+	})
+	if err != nil || len(values) == 0 {
+		return err
+	}
+
+	return viper.MergeConfigMap(values)
+}
+
+// setNested sets a value in nested maps, creating the maps of the path as
+// needed.
+func setNested(m map[string]interface{}, path []string, value interface{}) {
+	for _, k := range path[:len(path)-1] {
+		next, ok := m[k].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[k] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = value
+}
+
+func defaultValueString(v interface{}) string {
+	if list, ok := v.([]interface{}); ok {
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			values = append(values, fmt.Sprint(item))
+		}
+		return strings.Join(values, ",")
+	}
+	if list, ok := v.([]string); ok {
+		return strings.Join(list, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+// findDefaultFlag finds the command flag that a default is for. The key is
+// the path of the command and the name of the flag, such as
+// compute.server.create.region, or the flag's config key, such as
+// server.create.region. It returns the config key and the flag.
+func findDefaultFlag(root *cobra.Command, key string) (string, *pflag.Flag, error) {
+	key = strings.ToLower(key)
+	i := strings.LastIndex(key, ".")
+	if i < 1 {
+		return "", nil, fmt.Errorf("%q isn't a command flag. Defaults are named after a command and one of its flags, such as compute.server.create.region", key)
+	}
+	path, name := key[:i], key[i+1:]
+
+	var (
+		found *pflag.Flag
+		ns    string
+	)
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		for _, child := range cmd.Commands() {
+			if found != nil {
+				return
+			}
+			full := strings.Replace(strings.TrimPrefix(child.CommandPath(), root.Name()+" "), " ", ".", -1)
+			if full == path || cmdNS(child) == path {
+				if f := child.LocalNonPersistentFlags().Lookup(name); f != nil {
+					found, ns = f, cmdNS(child)
+					return
+				}
+			}
+			walk(child)
+		}
+	}
+	walk(root)
+
+	if found == nil {
+		return "", nil, fmt.Errorf("%q isn't a command flag. Defaults are named after a command and one of its flags, such as compute.server.create.region", key)
+	}
+	return ns + "." + name, found, nil
+}
+
+// parseDefaultValue converts a default to the type of its flag, so that it
+// is checked when it is set rather than when it is used.
+func parseDefaultValue(f *pflag.Flag, value string) (interface{}, error) {
+	return parseSettingValue(f.Value.Type(), value)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/bl-cli/pkg/fakeapi"
+	"github.com/spf13/cobra"
+)
+
+// Dev creates the dev command.
+func Dev() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "dev",
+			Short: "Display commands for developing and testing with bl",
+			Long:  "The subcommands of `bl dev` help you develop and test programs that use bl or the BinaryLane API.",
+		},
+	}
+
+	cmdFakeAPI := cmdBuilderWithInit(cmd, RunDevFakeAPI, "fake-api", "Serve a fake BinaryLane API", `Use this command to serve an in-memory fake of the BinaryLane API, for testing scripts and automation without touching real resources. Point bl at it with the `+"`"+`--api-url`+"`"+` flag.
+
+The fake supports servers, actions, domains, firewalls, load balancers, VPCs, SSH keys and tags, and starts out empty. Actions stay in progress for the action duration before completing, lists are paginated and every response carries rate limit headers, like the real API. Its state is lost when the command exits.`, Writer, false)
+	AddStringFlag(cmdFakeAPI, blcli.ArgListen, "", ":8080", "The address to listen on")
+	AddStringFlag(cmdFakeAPI, blcli.ArgFakeAPIToken, "", "", "The only access token to accept. By default any token is accepted")
+	AddStringFlag(cmdFakeAPI, blcli.ArgActionDuration, "", fakeapi.DefaultActionDuration.String(), "How long actions stay in progress, such as 500ms or 1m. Actions complete immediately when it is 0")
+	AddIntFlag(cmdFakeAPI, blcli.ArgRateLimit, "", fakeapi.DefaultRateLimit, "The number of requests allowed per hour. Requests are not limited when it is 0")
+
+	return cmd
+}
+
+// RunDevFakeAPI serves a fake API until the command is interrupted.
+func RunDevFakeAPI(c *CmdConfig) error {
+	listen, err := c.Doit.GetString(c.NS, blcli.ArgListen)
+	if err != nil {
+		return err
+	}
+
+	token, err := c.Doit.GetString(c.NS, blcli.ArgFakeAPIToken)
+	if err != nil {
+		return err
+	}
+
+	duration, err := c.Doit.GetString(c.NS, blcli.ArgActionDuration)
+	if err != nil {
+		return err
+	}
+
+	rateLimit, err := c.Doit.GetInt(c.NS, blcli.ArgRateLimit)
+	if err != nil {
+		return err
+	}
+
+	api := fakeapi.New()
+	api.Token = token
+	api.RateLimit = rateLimit
+	api.ActionDuration, err = time.ParseDuration(duration)
+	if err != nil {
+		return fmt.Errorf("invalid action duration %q: %v", duration, err)
+	}
+
+	l, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+
+	if token == "" {
+		token = "<any token>"
+	}
+	url := "http://" + displayAddr(l.Addr())
+	fmt.Fprintf(c.Out, "Serving a fake BinaryLane API at %s\n", url)
+	fmt.Fprintf(c.Out, "Use it with: bl --api-url %s --access-token %s <command>\n", url, token)
+
+	srv := &http.Server{Handler: api}
+	go func() {
+		<-c.Ctx.Done()
+		srv.Close()
+	}()
+
+	if err := srv.Serve(l); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// displayAddr is the address to connect to a listener on, which is the
+// loopback address when the listener accepts connections on any address.
+func displayAddr(addr net.Addr) string {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, port)
+}
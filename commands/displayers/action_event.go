@@ -0,0 +1,63 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"io"
+
+	"github.com/binarylane/bl-cli/bl"
+)
+
+type ActionEvent struct {
+	Events bl.ActionEvents
+}
+
+var _ Displayable = &ActionEvent{}
+
+func (a *ActionEvent) JSON(out io.Writer) error {
+	return writeJSON(a.Events, out)
+}
+
+func (a *ActionEvent) Cols() []string {
+	return []string{
+		"Time", "Event", "ID", "Status", "Type", "ResourceID", "ResourceType", "Region",
+	}
+}
+
+func (a *ActionEvent) ColMap() map[string]string {
+	return map[string]string{
+		"Time": "Time", "Event": "Event", "ID": "ID", "Status": "Status", "Type": "Type",
+		"ResourceID": "Resource ID", "ResourceType": "Resource Type", "Region": "Region",
+	}
+}
+
+func (a *ActionEvent) KV() []map[string]interface{} {
+	out := []map[string]interface{}{}
+
+	for _, x := range a.Events {
+		region := ""
+		if x.Region != nil {
+			region = x.Region.Slug
+		}
+		o := map[string]interface{}{
+			"Time": x.Time.Format("2006-01-02 15:04:05"), "Event": x.Event,
+			"ID": x.ID, "Status": x.Status, "Type": x.Type,
+			"ResourceID": x.ResourceID, "ResourceType": x.ResourceType,
+			"Region": region,
+		}
+		out = append(out, o)
+	}
+
+	return out
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"io"
+)
+
+// AuthContext is an authentication context and the state of its access
+// token.
+type AuthContext struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current"`
+	// Source is where the access token comes from: flag, env, config,
+	// keyring, file, helper or none.
+	Source string `json:"source"`
+	// Status is valid, invalid, no token or error.
+	Status string `json:"status"`
+	Email  string `json:"email,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type AuthContexts struct {
+	AuthContexts []AuthContext
+}
+
+var _ Displayable = &AuthContexts{}
+
+func (a *AuthContexts) JSON(out io.Writer) error {
+	return writeJSON(a.AuthContexts, out)
+}
+
+func (a *AuthContexts) Cols() []string {
+	return []string{
+		"Name", "Current", "Source", "Status", "Email",
+	}
+}
+
+func (a *AuthContexts) ColMap() map[string]string {
+	return map[string]string{
+		"Name": "Name", "Current": "Current", "Source": "Source",
+		"Status": "Status", "Email": "Email", "Error": "Error",
+	}
+}
+
+func (a *AuthContexts) KV() []map[string]interface{} {
+	out := []map[string]interface{}{}
+
+	for _, ac := range a.AuthContexts {
+		o := map[string]interface{}{
+			"Name": ac.Name, "Current": ac.Current, "Source": ac.Source,
+			"Status": ac.Status, "Email": ac.Email, "Error": ac.Error,
+		}
+		out = append(out, o)
+	}
+
+	return out
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"io"
+)
+
+// ConfigSetting is a configuration setting and where its value comes from.
+type ConfigSetting struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	// Source is where the value comes from: flag, env, file or default.
+	Source string `json:"source"`
+}
+
+type ConfigSettings struct {
+	ConfigSettings []ConfigSetting
+}
+
+var _ Displayable = &ConfigSettings{}
+
+func (c *ConfigSettings) JSON(out io.Writer) error {
+	return writeJSON(c.ConfigSettings, out)
+}
+
+func (c *ConfigSettings) Cols() []string {
+	return []string{
+		"Key", "Value", "Source",
+	}
+}
+
+func (c *ConfigSettings) ColMap() map[string]string {
+	return map[string]string{
+		"Key": "Key", "Value": "Value", "Source": "Source",
+	}
+}
+
+func (c *ConfigSettings) KV() []map[string]interface{} {
+	out := []map[string]interface{}{}
+
+	for _, s := range c.ConfigSettings {
+		o := map[string]interface{}{
+			"Key": s.Key, "Value": s.Value, "Source": s.Source,
+		}
+		out = append(out, o)
+	}
+
+	return out
+}
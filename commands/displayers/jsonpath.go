@@ -0,0 +1,555 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DisplayJSONPath evaluates a JSONPath template against the JSON
+// representation of the item. The syntax follows kubectl: expressions are
+// wrapped in braces and may be mixed with literal text, for example
+// `{.[*].id}` or `{range .[*]}{.id}{"\t"}{.name}{"\n"}{end}`. Supported
+// expressions are fields, `*`, indexes, slices, `[?(@.field op value)]`
+// filters, quoted string literals and range/end.
+func DisplayJSONPath(item Displayable, out io.Writer, text string) error {
+	nodes, err := parseJSONPathTemplate(text)
+	if err != nil {
+		return err
+	}
+
+	data, err := jsonData(item)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	if err := executeJSONPath(&b, nodes, data, data); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(out, b.String())
+	return err
+}
+
+type jsonPathNode struct {
+	text   string
+	path   *jsonPath
+	isText bool
+
+	// A range node repeats its body for each result of its path.
+	isRange bool
+	body    []jsonPathNode
+}
+
+type jsonPath struct {
+	root  bool
+	steps []jsonPathStep
+}
+
+const (
+	stepField = iota
+	stepWildcard
+	stepIndex
+	stepSlice
+	stepFilter
+)
+
+type jsonPathStep struct {
+	kind       int
+	field      string
+	index      int
+	start, end *int
+	filter     *jsonPathFilter
+}
+
+type jsonPathFilter struct {
+	path  *jsonPath
+	op    string
+	value interface{}
+}
+
+func parseJSONPathTemplate(text string) ([]jsonPathNode, error) {
+	type frame struct {
+		nodes []jsonPathNode
+		rng   jsonPathNode
+	}
+	stack := []frame{{}}
+	top := func() *frame { return &stack[len(stack)-1] }
+
+	for len(text) > 0 {
+		open := strings.IndexByte(text, '{')
+		if open < 0 {
+			top().nodes = append(top().nodes, jsonPathNode{text: text, isText: true})
+			break
+		}
+		if open > 0 {
+			top().nodes = append(top().nodes, jsonPathNode{text: text[:open], isText: true})
+		}
+
+		end := closingBrace(text, open)
+		if end < 0 {
+			return nil, fmt.Errorf("jsonpath: unclosed expression in %q", text[open:])
+		}
+		expr := strings.TrimSpace(text[open+1 : end])
+		text = text[end+1:]
+
+		switch {
+		case expr == "end":
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("jsonpath: {end} without {range}")
+			}
+			f := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			f.rng.body = f.nodes
+			top().nodes = append(top().nodes, f.rng)
+		case strings.HasPrefix(expr, "range "):
+			p, err := parseJSONPath(strings.TrimSpace(strings.TrimPrefix(expr, "range ")))
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, frame{rng: jsonPathNode{path: p, isRange: true}})
+		case strings.HasPrefix(expr, `"`):
+			s, err := strconv.Unquote(expr)
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath: invalid string %s", expr)
+			}
+			top().nodes = append(top().nodes, jsonPathNode{text: s, isText: true})
+		default:
+			p, err := parseJSONPath(expr)
+			if err != nil {
+				return nil, err
+			}
+			top().nodes = append(top().nodes, jsonPathNode{path: p})
+		}
+	}
+
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("jsonpath: {range} without {end}")
+	}
+	return stack[0].nodes, nil
+}
+
+// closingBrace returns the index of the brace closing the one at open,
+// ignoring braces inside quoted strings.
+func closingBrace(text string, open int) int {
+	var quote byte
+	for i := open + 1; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+func parseJSONPath(expr string) (*jsonPath, error) {
+	p := &jsonPath{}
+	s := expr
+
+	switch {
+	case strings.HasPrefix(s, "$"):
+		p.root = true
+		s = s[1:]
+	case strings.HasPrefix(s, "@"):
+		s = s[1:]
+	}
+
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			if strings.HasPrefix(s, "*") {
+				p.steps = append(p.steps, jsonPathStep{kind: stepWildcard})
+				s = s[1:]
+				continue
+			}
+			n := 0
+			for n < len(s) && isJSONPathIdent(s[n]) {
+				n++
+			}
+			if n > 0 {
+				p.steps = append(p.steps, jsonPathStep{kind: stepField, field: s[:n]})
+				s = s[n:]
+			}
+		case '[':
+			end := closingBracket(s)
+			if end < 0 {
+				return nil, fmt.Errorf("jsonpath: unclosed bracket in %q", expr)
+			}
+			step, err := parseJSONPathBracket(strings.TrimSpace(s[1:end]))
+			if err != nil {
+				return nil, err
+			}
+			p.steps = append(p.steps, step)
+			s = s[end+1:]
+		default:
+			return nil, fmt.Errorf("jsonpath: unexpected %q in %q", s[0], expr)
+		}
+	}
+
+	return p, nil
+}
+
+func isJSONPathIdent(c byte) bool {
+	return c == '_' || c == '-' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func closingBracket(s string) int {
+	var quote byte
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseJSONPathBracket(s string) (jsonPathStep, error) {
+	switch {
+	case s == "*":
+		return jsonPathStep{kind: stepWildcard}, nil
+	case strings.HasPrefix(s, "?(") && strings.HasSuffix(s, ")"):
+		f, err := parseJSONPathFilter(strings.TrimSpace(s[2 : len(s)-1]))
+		if err != nil {
+			return jsonPathStep{}, err
+		}
+		return jsonPathStep{kind: stepFilter, filter: f}, nil
+	case strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`):
+		return jsonPathStep{kind: stepField, field: strings.Trim(s, `'"`)}, nil
+	case strings.Contains(s, ":"):
+		parts := strings.SplitN(s, ":", 2)
+		step := jsonPathStep{kind: stepSlice}
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return jsonPathStep{}, fmt.Errorf("jsonpath: invalid slice [%s]", s)
+			}
+			if i == 0 {
+				step.start = &n
+			} else {
+				step.end = &n
+			}
+		}
+		return step, nil
+	default:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return jsonPathStep{}, fmt.Errorf("jsonpath: invalid index [%s]", s)
+		}
+		return jsonPathStep{kind: stepIndex, index: n}, nil
+	}
+}
+
+func parseJSONPathFilter(s string) (*jsonPathFilter, error) {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		i := strings.Index(s, op)
+		if i < 0 {
+			continue
+		}
+
+		p, err := parseJSONPath(strings.TrimSpace(s[:i]))
+		if err != nil {
+			return nil, err
+		}
+
+		literal := strings.TrimSpace(s[i+len(op):])
+		var value interface{}
+		switch {
+		case strings.HasPrefix(literal, "'") || strings.HasPrefix(literal, `"`):
+			value = strings.Trim(literal, `'"`)
+		case literal == "true" || literal == "false":
+			value = literal == "true"
+		default:
+			f, err := strconv.ParseFloat(literal, 64)
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath: invalid value %q in filter", literal)
+			}
+			value = f
+		}
+
+		return &jsonPathFilter{path: p, op: op, value: value}, nil
+	}
+
+	p, err := parseJSONPath(s)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonPathFilter{path: p}, nil
+}
+
+func executeJSONPath(b *strings.Builder, nodes []jsonPathNode, root, current interface{}) error {
+	for _, n := range nodes {
+		if n.isText {
+			b.WriteString(n.text)
+			continue
+		}
+
+		results, err := n.path.eval(root, current)
+		if err != nil {
+			return err
+		}
+
+		if n.isRange {
+			for _, r := range results {
+				if err := executeJSONPath(b, n.body, root, r); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		for i, r := range results {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			s, err := formatJSONPathValue(r)
+			if err != nil {
+				return err
+			}
+			b.WriteString(s)
+		}
+	}
+	return nil
+}
+
+func (p *jsonPath) eval(root, current interface{}) ([]interface{}, error) {
+	values := []interface{}{current}
+	if p.root {
+		values = []interface{}{root}
+	}
+
+	for _, step := range p.steps {
+		var next []interface{}
+		for _, v := range values {
+			results, err := step.apply(root, v)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, results...)
+		}
+		values = next
+	}
+
+	return values, nil
+}
+
+func (s jsonPathStep) apply(root, v interface{}) ([]interface{}, error) {
+	switch s.kind {
+	case stepField:
+		if m, ok := v.(map[string]interface{}); ok {
+			if field, ok := m[s.field]; ok {
+				return []interface{}{field}, nil
+			}
+		}
+		return nil, nil
+	case stepWildcard:
+		return jsonPathChildren(v), nil
+	case stepIndex:
+		l, ok := v.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		i := s.index
+		if i < 0 {
+			i += len(l)
+		}
+		if i < 0 || i >= len(l) {
+			return nil, fmt.Errorf("jsonpath: index %d out of range", s.index)
+		}
+		return []interface{}{l[i]}, nil
+	case stepSlice:
+		l, ok := v.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		start, end := 0, len(l)
+		if s.start != nil {
+			start = clampJSONPathIndex(*s.start, len(l))
+		}
+		if s.end != nil {
+			end = clampJSONPathIndex(*s.end, len(l))
+		}
+		if start >= end {
+			return nil, nil
+		}
+		return l[start:end], nil
+	case stepFilter:
+		var out []interface{}
+		for _, child := range jsonPathChildren(v) {
+			ok, err := s.filter.match(root, child)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				out = append(out, child)
+			}
+		}
+		return out, nil
+	}
+	return nil, nil
+}
+
+func clampJSONPathIndex(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+	if i < 0 {
+		return 0
+	}
+	if i > n {
+		return n
+	}
+	return i
+}
+
+// jsonPathChildren returns the elements of a list, or the values of an
+// object ordered by key.
+func jsonPathChildren(v interface{}) []interface{} {
+	switch t := v.(type) {
+	case []interface{}:
+		return t
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make([]interface{}, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, t[k])
+		}
+		return out
+	}
+	return nil
+}
+
+func (f *jsonPathFilter) match(root, v interface{}) (bool, error) {
+	results, err := f.path.eval(root, v)
+	if err != nil {
+		return false, err
+	}
+	if f.op == "" {
+		return len(results) > 0, nil
+	}
+	if len(results) == 0 {
+		return false, nil
+	}
+
+	cmp, ok := compareJSONPathValues(results[0], f.value)
+	if !ok {
+		return f.op == "!=", nil
+	}
+
+	switch f.op {
+	case "==":
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+// compareJSONPathValues compares a JSON value with a filter literal. The
+// second return value is false when the two cannot be compared.
+func compareJSONPathValues(v, literal interface{}) (int, bool) {
+	switch l := literal.(type) {
+	case float64:
+		n, ok := v.(json.Number)
+		if !ok {
+			return 0, false
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return 0, false
+		}
+		switch {
+		case f < l:
+			return -1, true
+		case f > l:
+			return 1, true
+		}
+		return 0, true
+	case bool:
+		b, ok := v.(bool)
+		if !ok || b != l {
+			return 1, ok
+		}
+		return 0, true
+	case string:
+		s, ok := v.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(s, l), true
+	}
+	return 0, false
+}
+
+func formatJSONPathValue(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	case json.Number:
+		return t.String(), nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	default:
+		b, err := json.Marshal(t)
+		return string(b), err
+	}
+}
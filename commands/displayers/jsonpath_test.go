@@ -0,0 +1,47 @@
+package displayers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplayJSONPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{name: "wildcard", template: `{.[*].id}`, expected: "1234567890 2"},
+		{name: "index", template: `{.[0].name}`, expected: "web-1"},
+		{name: "negative index", template: `{.[-1].name}`, expected: "db-1"},
+		{name: "slice", template: `{.[0:1].name}`, expected: "web-1"},
+		{name: "root", template: `{$[1].status}`, expected: "off"},
+		{name: "nested list", template: `{.[0].networks.v4[*].ip_address}`, expected: "10.240.0.2 192.0.2.10"},
+		{name: "object", template: `{.[0].tags}`, expected: `["web","prod"]`},
+		{name: "string filter", template: `{.[?(@.status=="active")].name}`, expected: "web-1"},
+		{name: "number filter", template: `{.[?(@.memory >= 4096)].name}`, expected: "db-1"},
+		{name: "existence filter", template: `{.[?(@.tags)].name}`, expected: "web-1"},
+		{
+			name:     "range with literals",
+			template: `{range .[*]}{.id}{"\t"}{.name}{"\n"}{end}`,
+			expected: "1234567890\tweb-1\n2\tdb-1\n",
+		},
+		{name: "text around expressions", template: `first: {.[0].name}.`, expected: "first: web-1."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			err := (&Displayer{OutputType: "jsonpath", Template: tt.template, Item: testTemplateServers, Out: out}).Display()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	for _, invalid := range []string{`{.name`, `{range .[*]}{.id}`, `{end}`, `{.[x]}`, `{.[5].id}`} {
+		err := DisplayJSONPath(testTemplateServers, &bytes.Buffer{}, invalid)
+		assert.Error(t, err, invalid)
+	}
+}
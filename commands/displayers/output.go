@@ -15,12 +15,15 @@ package displayers
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
 	"strings"
 	"text/tabwriter"
+
+	yaml "gopkg.in/yaml.v2"
 )
 
 // Displayable is a displable entity. These are used for printing results.
@@ -37,11 +40,16 @@ type Displayer struct {
 	ColumnList string
 	NoHeaders  bool
 
+	// Template is the template used by the go-template and jsonpath output
+	// types.
+	Template string
+
 	Item Displayable
 	Out  io.Writer
 }
 
-// Display ends up rendering the content in one of two formats (text|json)
+// Display renders the item in the requested output type: text, json, yaml,
+// csv, ndjson, go-template or jsonpath.
 func (d *Displayer) Display() error {
 	switch d.OutputType {
 	case "json":
@@ -51,19 +59,33 @@ func (d *Displayer) Display() error {
 		}
 		return d.Item.JSON(d.Out)
 	case "text":
-		var cols []string
-		for _, c := range strings.Split(strings.Join(strings.Fields(d.ColumnList), ""), ",") {
-			if c != "" {
-				cols = append(cols, c)
-			}
-		}
-
-		return DisplayText(d.Item, d.Out, d.NoHeaders, cols)
+		return DisplayText(d.Item, d.Out, d.NoHeaders, d.columns())
+	case "yaml":
+		return DisplayYAML(d.Item, d.Out)
+	case "csv":
+		return DisplayCSV(d.Item, d.Out, d.NoHeaders, d.columns())
+	case "ndjson":
+		return DisplayNDJSON(d.Item, d.Out)
+	case "go-template":
+		return DisplayTemplate(d.Item, d.Out, d.Template)
+	case "jsonpath":
+		return DisplayJSONPath(d.Item, d.Out, d.Template)
 	default:
 		return fmt.Errorf("unknown output type")
 	}
 }
 
+// columns returns the columns selected with --format.
+func (d *Displayer) columns() []string {
+	var cols []string
+	for _, c := range strings.Split(strings.Join(strings.Fields(d.ColumnList), ""), ",") {
+		if c != "" {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
 // DisplayText writes tabbed content to the passed in io.Writer
 // while potentially adding or removing headers.
 func DisplayText(item Displayable, out io.Writer, noHeaders bool, includeCols []string) error {
@@ -117,6 +139,159 @@ func DisplayText(item Displayable, out io.Writer, noHeaders bool, includeCols []
 	return w.Flush()
 }
 
+// DisplayCSV writes the selected columns as comma separated values, with a
+// header row unless noHeaders is set.
+func DisplayCSV(item Displayable, out io.Writer, noHeaders bool, includeCols []string) error {
+	w := csv.NewWriter(out)
+
+	cols := item.Cols()
+	if len(includeCols) > 0 && includeCols[0] != "" {
+		cols = includeCols
+	}
+
+	headers := []string{}
+	for _, k := range cols {
+		col := item.ColMap()[k]
+		if col == "" {
+			return fmt.Errorf("unknown column %q", k)
+		}
+
+		headers = append(headers, col)
+	}
+	if !noHeaders {
+		if err := w.Write(headers); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range item.KV() {
+		record := make([]string, 0, len(cols))
+		for _, col := range cols {
+			record = append(record, fmt.Sprint(r[col]))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// DisplayYAML writes the JSON representation of the item as YAML. Object keys
+// keep the order of the JSON output.
+func DisplayYAML(item Displayable, out io.Writer) error {
+	var buf bytes.Buffer
+	if !containsOnlyNilSlice(item) {
+		if err := item.JSON(&buf); err != nil {
+			return err
+		}
+	}
+	if buf.Len() == 0 {
+		buf.WriteString("[]")
+	}
+
+	dec := json.NewDecoder(&buf)
+	dec.UseNumber()
+	v, err := decodeYAMLValue(dec)
+	if err != nil {
+		return err
+	}
+
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(b)
+	return err
+}
+
+// decodeYAMLValue reads the next JSON value from dec, decoding objects as
+// yaml.MapSlice so that their keys stay in order.
+func decodeYAMLValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			m := yaml.MapSlice{}
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				v, err := decodeYAMLValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				m = append(m, yaml.MapItem{Key: key, Value: v})
+			}
+			_, err := dec.Token()
+			return m, err
+		}
+
+		l := []interface{}{}
+		for dec.More() {
+			v, err := decodeYAMLValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, v)
+		}
+		_, err := dec.Token()
+		return l, err
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i, nil
+		}
+		return t.Float64()
+	default:
+		return t, nil
+	}
+}
+
+// DisplayNDJSON writes the JSON representation of the item with one compact
+// object per line. Lists are written one element per line.
+func DisplayNDJSON(item Displayable, out io.Writer) error {
+	if containsOnlyNilSlice(item) {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := item.JSON(&buf); err != nil {
+		return err
+	}
+
+	var raw json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &raw); err != nil {
+		return err
+	}
+
+	items := []json.RawMessage{raw}
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		items = nil
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return err
+		}
+	}
+
+	for _, i := range items {
+		var line bytes.Buffer
+		if err := json.Compact(&line, i); err != nil {
+			return err
+		}
+		line.WriteByte('\n')
+		if _, err := line.WriteTo(out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func writeJSON(item interface{}, w io.Writer) error {
 	b, err := json.Marshal(item)
 	if err != nil {
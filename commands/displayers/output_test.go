@@ -46,3 +46,82 @@ func TestDisplayerDisplay(t *testing.T) {
 		})
 	}
 }
+
+func TestDisplayerOutputTypes(t *testing.T) {
+	plan := &StackPlan{Changes: []StackChange{
+		{Action: "create", Kind: "server", Name: "web-1", Detail: "syd, std-min"},
+		{Action: "delete", Kind: "vpc", Name: "old"},
+	}}
+
+	tests := []struct {
+		name       string
+		outputType string
+		columnList string
+		noHeaders  bool
+		item       Displayable
+		expected   string
+	}{
+		{
+			name:       "yaml keeps the order of the json keys",
+			outputType: "yaml",
+			item:       plan,
+			expected: `- action: create
+  kind: server
+  name: web-1
+  detail: syd, std-min
+- action: delete
+  kind: vpc
+  name: old
+`,
+		},
+		{
+			name:       "yaml of a nil slice is an empty list",
+			outputType: "yaml",
+			item:       &Image{},
+			expected:   "[]\n",
+		},
+		{
+			name:       "csv quotes values and has a header",
+			outputType: "csv",
+			item:       plan,
+			expected:   "Action,Kind,Name,Detail\ncreate,server,web-1,\"syd, std-min\"\ndelete,vpc,old,\n",
+		},
+		{
+			name:       "csv respects the column list and no-header",
+			outputType: "csv",
+			columnList: "Name, Action",
+			noHeaders:  true,
+			item:       plan,
+			expected:   "web-1,create\nold,delete\n",
+		},
+		{
+			name:       "ndjson writes one object per line",
+			outputType: "ndjson",
+			item:       plan,
+			expected: `{"action":"create","kind":"server","name":"web-1","detail":"syd, std-min"}
+{"action":"delete","kind":"vpc","name":"old"}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+
+			displayer := Displayer{
+				OutputType: tt.outputType,
+				ColumnList: tt.columnList,
+				NoHeaders:  tt.noHeaders,
+				Item:       tt.item,
+				Out:        out,
+			}
+
+			err := displayer.Display()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	err := (&Displayer{OutputType: "csv", ColumnList: "Nope", Item: plan, Out: &bytes.Buffer{}}).Display()
+	assert.EqualError(t, err, `unknown column "Nope"`)
+}
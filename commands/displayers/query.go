@@ -0,0 +1,331 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// Query is a Displayable that filters and sorts the rows of another
+// Displayable. Rows are compared using the values of their KV columns, so
+// every Displayable supports the same expressions.
+type Query struct {
+	Item Displayable
+
+	rows     []map[string]interface{}
+	selected []int
+}
+
+var _ Displayable = &Query{}
+
+// NewQuery filters the rows of item with a filter expression such as
+// `status=active,memory>=2048,region in (syd,mel)` and then sorts them by a
+// comma-separated list of columns, each optionally prefixed with `-` for
+// descending order. Either expression may be empty.
+func NewQuery(item Displayable, filter, sortBy string) (*Query, error) {
+	q := &Query{Item: item, rows: item.KV()}
+
+	conditions, err := q.parseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := q.parseSortBy(sortBy)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, row := range q.rows {
+		keep := true
+		for _, c := range conditions {
+			if !c.match(row[c.col]) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			q.selected = append(q.selected, i)
+		}
+	}
+
+	sort.SliceStable(q.selected, func(i, j int) bool {
+		a, b := q.rows[q.selected[i]], q.rows[q.selected[j]]
+		for _, k := range keys {
+			cmp := compareValues(a[k.col], b[k.col])
+			if cmp == 0 {
+				continue
+			}
+			if k.desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+
+	return q, nil
+}
+
+func (q *Query) Cols() []string {
+	return q.Item.Cols()
+}
+
+func (q *Query) ColMap() map[string]string {
+	return q.Item.ColMap()
+}
+
+func (q *Query) KV() []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(q.selected))
+	for _, i := range q.selected {
+		out = append(out, q.rows[i])
+	}
+	return out
+}
+
+// JSON writes the elements of the item's JSON list that correspond to the
+// selected rows. This relies on the item having one row per list element.
+func (q *Query) JSON(out io.Writer) error {
+	if containsOnlyNilSlice(q.Item) {
+		_, err := out.Write([]byte("[]"))
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := q.Item.JSON(&buf); err != nil {
+		return err
+	}
+
+	var elements []json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &elements); err != nil || len(elements) != len(q.rows) {
+		return fmt.Errorf("filtering and sorting are only supported with text and csv output for this command")
+	}
+
+	selected := make([]json.RawMessage, 0, len(q.selected))
+	for _, i := range q.selected {
+		selected = append(selected, elements[i])
+	}
+
+	return writeJSON(selected, out)
+}
+
+// column finds the column with the given name, ignoring case.
+func (q *Query) column(name string) (string, error) {
+	for _, c := range q.Item.Cols() {
+		if strings.EqualFold(c, name) {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("unknown column %q. Possible values: %s", name, strings.Join(q.Item.Cols(), ", "))
+}
+
+type sortKey struct {
+	col  string
+	desc bool
+}
+
+func (q *Query) parseSortBy(sortBy string) ([]sortKey, error) {
+	var keys []sortKey
+	for _, part := range strings.Split(sortBy, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		desc := strings.HasPrefix(part, "-")
+		col, err := q.column(strings.TrimPrefix(part, "-"))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, sortKey{col: col, desc: desc})
+	}
+	return keys, nil
+}
+
+type condition struct {
+	col    string
+	op     string
+	values []string
+	globs  []glob.Glob
+}
+
+// filterOps are checked in order, so that `>=` is found before `>` and `=`.
+var filterOps = []string{" not in ", " in ", "!=", ">=", "<=", "=", ">", "<"}
+
+func (q *Query) parseFilter(filter string) ([]condition, error) {
+	var conditions []condition
+	for _, expr := range splitFilter(filter) {
+		c, err := q.parseCondition(expr)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, nil
+}
+
+// splitFilter splits a filter on the commas that are not inside the
+// parentheses of an `in` list.
+func splitFilter(filter string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i, r := range filter {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, filter[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, filter[start:])
+
+	out := parts[:0]
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (q *Query) parseCondition(expr string) (condition, error) {
+	lower := strings.ToLower(expr)
+	for _, op := range filterOps {
+		i := strings.Index(lower, op)
+		if i <= 0 {
+			continue
+		}
+
+		col, err := q.column(strings.TrimSpace(expr[:i]))
+		if err != nil {
+			return condition{}, err
+		}
+
+		c := condition{col: col, op: strings.TrimSpace(op)}
+		value := strings.TrimSpace(expr[i+len(op):])
+		if c.op == "in" || c.op == "not in" {
+			if !strings.HasPrefix(value, "(") || !strings.HasSuffix(value, ")") {
+				return condition{}, fmt.Errorf("invalid filter %q: expected a list such as `(syd,mel)`", expr)
+			}
+			for _, v := range strings.Split(value[1:len(value)-1], ",") {
+				c.values = append(c.values, strings.TrimSpace(v))
+			}
+		} else {
+			c.values = []string{value}
+		}
+
+		if c.op == "=" || c.op == "!=" || c.op == "in" || c.op == "not in" {
+			for _, v := range c.values {
+				g, err := glob.Compile(v)
+				if err != nil {
+					return condition{}, fmt.Errorf("invalid filter %q: %v", expr, err)
+				}
+				c.globs = append(c.globs, g)
+			}
+		}
+
+		return c, nil
+	}
+
+	return condition{}, fmt.Errorf("invalid filter %q: expected a comparison such as `status=active`", expr)
+}
+
+func (c condition) match(v interface{}) bool {
+	switch c.op {
+	case "=", "in":
+		return c.matchAny(v)
+	case "!=", "not in":
+		return !c.matchAny(v)
+	}
+
+	cmp := compareValues(v, c.values[0])
+	switch c.op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp <= 0
+	}
+}
+
+// matchAny reports whether the value matches any of the condition's glob
+// patterns. Lists, including the comma-separated lists that columns such as
+// Tags display, match when the whole list or any of its elements does.
+func (c condition) matchAny(v interface{}) bool {
+	s := formatValue(v)
+	candidates := []string{s}
+	if strings.Contains(s, ",") {
+		candidates = append(candidates, strings.Split(s, ",")...)
+	}
+
+	for _, s := range candidates {
+		for _, g := range c.globs {
+			if g.Match(s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compareValues compares two values numerically when both are numbers and
+// as strings otherwise.
+func compareValues(a, b interface{}) int {
+	as, bs := formatValue(a), formatValue(b)
+
+	af, errA := strconv.ParseFloat(as, 64)
+	bf, errB := strconv.ParseFloat(bs, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+
+	return strings.Compare(as, bs)
+}
+
+func formatValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case []string:
+		return strings.Join(t, ",")
+	default:
+		return fmt.Sprint(t)
+	}
+}
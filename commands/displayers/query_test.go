@@ -0,0 +1,107 @@
+package displayers
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/go-binarylane"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testQueryServers() *Server {
+	server := func(id int, name, region, status string, memory int, tags ...string) bl.Server {
+		return bl.Server{Server: &binarylane.Server{
+			ID:     id,
+			Name:   name,
+			Memory: memory,
+			Status: status,
+			Region: &binarylane.Region{Slug: region},
+			Image:  &binarylane.Image{},
+			Tags:   tags,
+		}}
+	}
+
+	return &Server{Servers: bl.Servers{
+		server(1, "web-1", "syd", "active", 2048, "web"),
+		server(2, "web-2", "mel", "active", 4096, "web", "prod"),
+		server(3, "db-1", "syd", "off", 8192, "db"),
+		server(4, "web-3", "per", "active", 1024, "web"),
+	}}
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		sortBy string
+		ids    []int
+	}{
+		{name: "no expressions", ids: []int{1, 2, 3, 4}},
+		{name: "equality", filter: "status=active", ids: []int{1, 2, 4}},
+		{name: "column names ignore case", filter: "STATUS=off", ids: []int{3}},
+		{name: "inequality", filter: "Status!=active", ids: []int{3}},
+		{name: "glob", filter: "Name=web-*", ids: []int{1, 2, 4}},
+		{name: "numeric comparison", filter: "Memory>=2048", ids: []int{1, 2, 3}},
+		{name: "numbers compare by value", filter: "Memory<10000", ids: []int{1, 2, 3, 4}},
+		{name: "in list", filter: "Region in (syd, mel)", ids: []int{1, 2, 3}},
+		{name: "not in list", filter: "Region not in (syd,mel)", ids: []int{4}},
+		{name: "list element", filter: "Tags=prod", ids: []int{2}},
+		{name: "conditions are combined", filter: "status=active,memory>=2048,region in (syd,mel)", ids: []int{1, 2}},
+		{name: "sort ascending", sortBy: "Memory", ids: []int{4, 1, 2, 3}},
+		{name: "sort descending", sortBy: "-memory", ids: []int{3, 2, 1, 4}},
+		{name: "sort by several columns", sortBy: "Status,-Name", ids: []int{4, 2, 1, 3}},
+		{name: "filter and sort", filter: "Region=syd", sortBy: "-Memory", ids: []int{3, 1}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := NewQuery(testQueryServers(), tt.filter, tt.sortBy)
+			assert.NoError(t, err)
+
+			ids := []int{}
+			for _, row := range q.KV() {
+				ids = append(ids, row["ID"].(int))
+			}
+			assert.Equal(t, tt.ids, ids)
+		})
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	_, err := NewQuery(testQueryServers(), "Size=small", "")
+	assert.EqualError(t, err, `unknown column "Size". Possible values: ID, Name, PublicIPv4, PrivateIPv4, PublicIPv6, Memory, VCPUs, Disk, Region, Image, VPCID, Status, Tags, Features, Volumes`)
+
+	_, err = NewQuery(testQueryServers(), "", "-Size")
+	assert.Error(t, err)
+
+	_, err = NewQuery(testQueryServers(), "active", "")
+	assert.EqualError(t, err, "invalid filter \"active\": expected a comparison such as `status=active`")
+
+	_, err = NewQuery(testQueryServers(), "Region in syd", "")
+	assert.EqualError(t, err, "invalid filter \"Region in syd\": expected a list such as `(syd,mel)`")
+}
+
+func TestQueryJSON(t *testing.T) {
+	q, err := NewQuery(testQueryServers(), "Region=syd", "-Memory")
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	assert.NoError(t, q.JSON(&out))
+
+	var servers []binarylane.Server
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &servers))
+	assert.Len(t, servers, 2)
+	assert.Equal(t, 3, servers[0].ID)
+	assert.Equal(t, 1, servers[1].ID)
+
+	q, err = NewQuery(&Server{}, "Region=syd", "")
+	assert.NoError(t, err)
+
+	out.Reset()
+	assert.NoError(t, q.JSON(&out))
+	assert.Equal(t, "[]", out.String())
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"io"
+)
+
+// StackChange is a single change of a stack plan.
+type StackChange struct {
+	Action string `json:"action"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type StackPlan struct {
+	Changes []StackChange
+}
+
+var _ Displayable = &StackPlan{}
+
+func (p *StackPlan) JSON(out io.Writer) error {
+	return writeJSON(p.Changes, out)
+}
+
+func (p *StackPlan) Cols() []string {
+	return []string{
+		"Action",
+		"Kind",
+		"Name",
+		"Detail",
+	}
+}
+
+func (p *StackPlan) ColMap() map[string]string {
+	return map[string]string{
+		"Action": "Action",
+		"Kind":   "Kind",
+		"Name":   "Name",
+		"Detail": "Detail",
+	}
+}
+
+func (p *StackPlan) KV() []map[string]interface{} {
+	out := []map[string]interface{}{}
+
+	for _, c := range p.Changes {
+		o := map[string]interface{}{
+			"Action": c.Action,
+			"Kind":   c.Kind,
+			"Name":   c.Name,
+			"Detail": c.Detail,
+		}
+		out = append(out, o)
+	}
+
+	return out
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"io"
+)
+
+// StackDifference is a field of a live resource that differs from a stack
+// spec.
+type StackDifference struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Field    string `json:"field,omitempty"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// StackDrift displays the differences found by bl drift, one row for each
+// field that differs.
+type StackDrift struct {
+	Differences []StackDifference
+}
+
+var _ Displayable = &StackDrift{}
+
+// JSON writes the differences as a JSON array.
+func (d *StackDrift) JSON(out io.Writer) error {
+	return writeJSON(d.Differences, out)
+}
+
+// Cols returns the columns shown by default.
+func (d *StackDrift) Cols() []string {
+	return []string{
+		"Kind",
+		"Name",
+		"Field",
+		"Expected",
+		"Actual",
+	}
+}
+
+// ColMap returns the headers of the columns.
+func (d *StackDrift) ColMap() map[string]string {
+	return map[string]string{
+		"Kind":     "Kind",
+		"Name":     "Name",
+		"Field":    "Field",
+		"Expected": "Expected",
+		"Actual":   "Actual",
+	}
+}
+
+// KV returns a row for each difference.
+func (d *StackDrift) KV() []map[string]interface{} {
+	out := []map[string]interface{}{}
+
+	for _, diff := range d.Differences {
+		o := map[string]interface{}{
+			"Kind":     diff.Kind,
+			"Name":     diff.Name,
+			"Field":    diff.Field,
+			"Expected": diff.Expected,
+			"Actual":   diff.Actual,
+		}
+		out = append(out, o)
+	}
+
+	return out
+}
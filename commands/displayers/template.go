@@ -0,0 +1,124 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// TemplateFuncs are the helper functions available to go-template output.
+var TemplateFuncs = template.FuncMap{
+	"join":      templateJoin,
+	"publicIP":  func(server interface{}) string { return serverIP(server, "public") },
+	"privateIP": func(server interface{}) string { return serverIP(server, "private") },
+	"toJSON":    templateToJSON,
+}
+
+// DisplayTemplate executes a Go template against the JSON representation
+// of the item, so fields are referred to by their JSON names, for example
+// `{{range .}}{{.name}}{{"\n"}}{{end}}`.
+func DisplayTemplate(item Displayable, out io.Writer, text string) error {
+	t, err := template.New("output").Funcs(TemplateFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	data, err := jsonData(item)
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(out, data)
+}
+
+// jsonData decodes the JSON representation of the item into maps and
+// slices. Numbers are kept as json.Number so that IDs print unchanged.
+func jsonData(item Displayable) (interface{}, error) {
+	if containsOnlyNilSlice(item) {
+		return []interface{}{}, nil
+	}
+
+	var buf bytes.Buffer
+	if err := item.JSON(&buf); err != nil {
+		return nil, err
+	}
+
+	var data interface{}
+	dec := json.NewDecoder(&buf)
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func templateJoin(list interface{}, sep string) (string, error) {
+	switch l := list.(type) {
+	case nil:
+		return "", nil
+	case []string:
+		return strings.Join(l, sep), nil
+	case []interface{}:
+		parts := make([]string, 0, len(l))
+		for _, v := range l {
+			parts = append(parts, fmt.Sprint(v))
+		}
+		return strings.Join(parts, sep), nil
+	default:
+		return "", fmt.Errorf("join: cannot join %T", list)
+	}
+}
+
+func templateToJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// serverIP returns the first IPv4 address of the given type ("public" or
+// "private") from the JSON representation of a server.
+func serverIP(server interface{}, ipType string) string {
+	s, ok := server.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	networks, ok := s["networks"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	v4, ok := networks["v4"].([]interface{})
+	if !ok {
+		return ""
+	}
+
+	for _, n := range v4 {
+		network, ok := n.(map[string]interface{})
+		if !ok || network["type"] != ipType {
+			continue
+		}
+		if ip, ok := network["ip_address"].(string); ok {
+			return ip
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,56 @@
+package displayers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/go-binarylane"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTemplateServers = &Server{Servers: bl.Servers{
+	{Server: &binarylane.Server{
+		ID:     1234567890,
+		Name:   "web-1",
+		Status: "active",
+		Memory: 2048,
+		Tags:   []string{"web", "prod"},
+		Networks: &binarylane.Networks{V4: []binarylane.NetworkV4{
+			{IPAddress: "10.240.0.2", Type: "private"},
+			{IPAddress: "192.0.2.10", Type: "public"},
+		}},
+	}},
+	{Server: &binarylane.Server{ID: 2, Name: "db-1", Status: "off", Memory: 4096}},
+}}
+
+func TestDisplayTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "fields use their json names",
+			template: `{{range .}}{{.id}} {{.name}}{{"\n"}}{{end}}`,
+			expected: "1234567890 web-1\n2 db-1\n",
+		},
+		{
+			name:     "helpers",
+			template: `{{with index . 0}}{{join .tags ","}} {{publicIP .}} {{privateIP .}} {{toJSON .tags}}{{end}}`,
+			expected: `web,prod 192.0.2.10 10.240.0.2 ["web","prod"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			err := (&Displayer{OutputType: "go-template", Template: tt.template, Item: testTemplateServers, Out: out}).Display()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	err := DisplayTemplate(testTemplateServers, &bytes.Buffer{}, "{{.name")
+	assert.Error(t, err)
+}
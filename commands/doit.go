@@ -15,12 +15,13 @@ package commands
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/bl-cli/bl"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -51,8 +52,22 @@ var (
 	Token string
 	//Trace toggles http tracing output
 	Trace bool
+	//MaxRetries is how many times a rate limited or failed request is retried
+	MaxRetries int
+	//Timeout is the time allowed for a command's API calls
+	Timeout time.Duration
+	//DryRun prints requests that would change resources instead of sending them
+	DryRun bool
+	//Record is the cassette file API requests and responses are recorded to
+	Record string
+	//Replay is the cassette file API responses are replayed from
+	Replay string
 
 	requiredColor = color.New(color.Bold).SprintfFunc()
+
+	// configErr is the error reading the config file, which is reported
+	// when a command is run.
+	configErr error
 )
 
 func init() {
@@ -71,14 +86,31 @@ func init() {
 	rootPFlagSet.StringVarP(&Token, blcli.ArgAccessToken, "t", "", "API V2 access token")
 	viper.BindPFlag(blcli.ArgAccessToken, rootPFlagSet.Lookup(blcli.ArgAccessToken))
 
-	rootPFlagSet.StringVarP(&Output, blcli.ArgOutput, "o", "text", "Desired output format [text|json]")
+	rootPFlagSet.StringVarP(&Output, blcli.ArgOutput, "o", "text", "Desired output format [text|json|yaml|csv|ndjson|go-template=...|go-template-file=...|jsonpath=...]")
 	viper.BindPFlag("output", rootPFlagSet.Lookup(blcli.ArgOutput))
 
 	rootPFlagSet.StringVarP(&Context, blcli.ArgContext, "", "", "Specify a custom authentication context name")
 	rootPFlagSet.BoolVarP(&Trace, "trace", "", false, "Show a log of network activity while performing a command")
 
+	rootPFlagSet.IntVarP(&MaxRetries, blcli.ArgMaxRetries, "", blcli.DefaultMaxRetries, "Number of times to retry a request that is rate limited or fails with a server error")
+	viper.BindPFlag(blcli.ArgMaxRetries, rootPFlagSet.Lookup(blcli.ArgMaxRetries))
+
+	rootPFlagSet.DurationVarP(&Timeout, blcli.ArgTimeout, "", 0, "Maximum time to allow for a command's API calls, such as 30s or 5m. Unlimited by default")
+	viper.BindPFlag(blcli.ArgTimeout, rootPFlagSet.Lookup(blcli.ArgTimeout))
+
+	rootPFlagSet.BoolVarP(&DryRun, blcli.ArgDryRun, "", false, "Print the requests that would create, change or delete resources instead of sending them")
+	viper.BindPFlag(blcli.ArgDryRun, rootPFlagSet.Lookup(blcli.ArgDryRun))
+
+	rootPFlagSet.StringVarP(&Record, blcli.ArgRecord, "", "", "Record API requests and responses, without the access token, to a cassette file")
+	viper.BindPFlag(blcli.ArgRecord, rootPFlagSet.Lookup(blcli.ArgRecord))
+
+	rootPFlagSet.StringVarP(&Replay, blcli.ArgReplay, "", "", "Answer API requests from a cassette file written by --record instead of the API")
+	viper.BindPFlag(blcli.ArgReplay, rootPFlagSet.Lookup(blcli.ArgReplay))
+
 	addCommands()
 
+	DoitCmd.PersistentPreRunE = preRun
+
 	cobra.OnInitialize(initConfig)
 }
 
@@ -93,14 +125,43 @@ func initConfig() {
 
 	viper.SetDefault("output", "text")
 	viper.SetDefault(blcli.ArgContext, blcli.ArgDefaultContext)
+	viper.SetDefault(blcli.ArgMaxRetries, blcli.DefaultMaxRetries)
+	viper.SetDefault(blcli.ArgPaginationWorkers, bl.DefaultPaginationWorkers)
 
+	configErr = nil
 	if _, err := os.Stat(cfgFile); err == nil {
 		if err := viper.ReadInConfig(); err != nil {
-			log.Fatalln("Config initialization failed:", err)
+			configErr = &cliError{
+				err:      fmt.Errorf("Unable to read the config file %s: %v. Run bl config validate to check it", cfgFile, err),
+				code:     "config",
+				exitCode: ExitError,
+			}
+		}
+	}
+
+	if err := loadProjectConfig(); err != nil && configErr == nil {
+		configErr = &cliError{
+			err:      fmt.Errorf("%v. Run bl config validate to check it", err),
+			code:     "config",
+			exitCode: ExitError,
 		}
 	}
 }
 
+// preRun runs before every command. It reports a config file that can't be
+// read, unless the command can run without it, then applies the flag
+// defaults of the auth context and the project's region, once the context is
+// known and before the command checks its flags.
+func preRun(cmd *cobra.Command, args []string) error {
+	if configErr != nil && cmd.Annotations[brokenConfigAnnotation] == "" {
+		checkErr(configErr)
+	}
+	if err := applyContextDefaults(cmd, args); err != nil {
+		return err
+	}
+	return applyProjectDefaults(cmd, args)
+}
+
 // in case we ever want to change this, or let folks configure it...
 func defaultConfigHome() string {
 	cfgDir, err := os.UserConfigDir()
@@ -119,9 +180,12 @@ func configHome() string {
 
 // Execute executes the current command using DoitCmd.
 func Execute() {
+	// Commands report their own errors, so the only errors returned here are
+	// cobra's, for unknown commands and flags. They are reported like any
+	// other error rather than by cobra.
+	DoitCmd.SilenceErrors = true
 	if err := DoitCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(-1)
+		checkErr(usageErr(err))
 	}
 }
 
@@ -134,9 +198,23 @@ func addCommands() {
 	DoitCmd.AddCommand(Invoices())
 	DoitCmd.AddCommand(Completion())
 	DoitCmd.AddCommand(computeCmd())
+	DoitCmd.AddCommand(Config())
+	DoitCmd.AddCommand(Dev())
 	DoitCmd.AddCommand(Projects())
 	DoitCmd.AddCommand(Version())
 	DoitCmd.AddCommand(VPCs())
+
+	// The stack commands don't have any subcommands, so like SSH they are
+	// given a parent at init time.
+	Apply(DoitCmd)
+	Plan(DoitCmd)
+	Destroy(DoitCmd)
+	Drift(DoitCmd)
+	Export(DoitCmd)
+
+	// The hidden command that completes resources for the completion
+	// scripts.
+	Complete(DoitCmd)
 }
 
 func computeCmd() *Command {
@@ -43,9 +43,9 @@ func Domain() *Command {
 		aliasOpt("ls"), displayerType(&displayers.Domain{}))
 
 	CmdBuilder(cmd, RunDomainGet, "get <domain>", "Retrieve information about a domain", `Use this command to retrieve information about the specified domain on your account.`, Writer,
-		aliasOpt("g"), displayerType(&displayers.Domain{}))
+		aliasOpt("g"), displayerType(&displayers.Domain{}), completeArgsOpt(completeDomains))
 
-	cmdRunDomainDelete := CmdBuilder(cmd, RunDomainDelete, "delete <domain>", "Permanently delete a domain from your account", `Use this command to delete a domain from your account. This is irreversible.`, Writer, aliasOpt("d", "rm"))
+	cmdRunDomainDelete := CmdBuilder(cmd, RunDomainDelete, "delete <domain>", "Permanently delete a domain from your account", `Use this command to delete a domain from your account. This is irreversible.`, Writer, aliasOpt("d", "rm"), completeArgsOpt(completeDomains))
 	AddBoolFlag(cmdRunDomainDelete, blcli.ArgForce, blcli.ArgShortForce, false, "Delete domain without confirmation prompt")
 
 	cmdRecord := &Command{
@@ -58,10 +58,10 @@ func Domain() *Command {
 	cmd.AddCommand(cmdRecord)
 
 	CmdBuilder(cmdRecord, RunRecordList, "list <domain>", "List the DNS records for a domain", `Use this command to list the DNS records for a domain.`, Writer,
-		aliasOpt("ls"), displayerType(&displayers.DomainRecord{}))
+		aliasOpt("ls"), displayerType(&displayers.DomainRecord{}), completeArgsOpt(completeDomains))
 
 	cmdRecordCreate := CmdBuilder(cmdRecord, RunRecordCreate, "create <domain>", "Create a DNS record", `Use this command to create DNS records for a domain.`, Writer,
-		aliasOpt("c"), displayerType(&displayers.DomainRecord{}))
+		aliasOpt("c"), displayerType(&displayers.DomainRecord{}), completeArgsOpt(completeDomains))
 	AddStringFlag(cmdRecordCreate, blcli.ArgRecordType, "", "", "The type of DNS record")
 	AddStringFlag(cmdRecordCreate, blcli.ArgRecordName, "", "", "The host name, alias, or service being defined by the record")
 	AddStringFlag(cmdRecordCreate, blcli.ArgRecordData, "", "", "Record data; varies depending on record type")
@@ -73,11 +73,11 @@ func Domain() *Command {
 	AddStringFlag(cmdRecordCreate, blcli.ArgRecordTag, "", "", "The parameter tag for CAA records. Valid values are `issue`, `issuewild`, or `iodef`")
 
 	cmdRunRecordDelete := CmdBuilder(cmdRecord, RunRecordDelete, "delete <domain> <record-id>...", "Delete a DNS record", `Use this command to delete DNS records for a domain.`, Writer,
-		aliasOpt("d"))
+		aliasOpt("d"), completeArgsOpt(completeDomains))
 	AddBoolFlag(cmdRunRecordDelete, blcli.ArgForce, blcli.ArgShortForce, false, "Delete record without confirmation prompt")
 
 	cmdRecordUpdate := CmdBuilder(cmdRecord, RunRecordUpdate, "update <domain>", "Update a DNS record", `Use this command to update or change DNS records for a domain.`, Writer,
-		aliasOpt("u"), displayerType(&displayers.DomainRecord{}))
+		aliasOpt("u"), displayerType(&displayers.DomainRecord{}), completeArgsOpt(completeDomains))
 	AddIntFlag(cmdRecordUpdate, blcli.ArgRecordID, "", 0, "Record ID")
 	AddStringFlag(cmdRecordUpdate, blcli.ArgRecordType, "", "", "The type of DNS record")
 	AddStringFlag(cmdRecordUpdate, blcli.ArgRecordName, "", "", "The host name, alias, or service being defined by the record")
@@ -181,7 +181,7 @@ func RunDomainDelete(c *CmdConfig) error {
 		return err
 	}
 
-	return fmt.Errorf("Operation aborted.")
+	return blcli.ErrOperationAborted
 }
 
 // RunRecordList list records for a domain.
@@ -320,7 +320,7 @@ func RunRecordDelete(c *CmdConfig) error {
 			}
 		}
 	} else {
-		return fmt.Errorf("Operation aborted.")
+		return blcli.ErrOperationAborted
 	}
 
 	return nil
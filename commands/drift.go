@@ -0,0 +1,158 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/bl-cli/commands/displayers"
+)
+
+// Drift creates the drift command.
+func Drift(parent *Command) *Command {
+	cmdDrift := CmdBuilder(parent, RunDrift, "drift", "Report differences between a stack spec and live resources", `Use this command to compare the firewalls, load balancers and domain records in a stack spec with the live resources on your account, and list every field that differs.
+
+Each difference is listed with the value from the spec and the live value. Domain records on a domain in the spec that the spec does not list are reported as unexpected, apart from the NS and SOA records managed by BinaryLane.
+
+The command exits with status 10 when any differences are found, so it can be run on a schedule to detect changes made outside of `+"`"+`bl apply`+"`"+`.
+
+`+stackSpecDesc, Writer, displayerType(&displayers.StackDrift{}))
+	AddStringFlag(cmdDrift, blcli.ArgAppSpec, "", "", "Path to a stack spec in YAML or JSON format. Use `-` to read from standard input.", requiredOpt())
+
+	return cmdDrift
+}
+
+// RunDrift reports the differences between a stack spec and the live
+// firewalls, load balancers and domain records.
+func RunDrift(c *CmdConfig) error {
+	filename, err := c.Doit.GetString(c.NS, blcli.ArgAppSpec)
+	if err != nil {
+		return err
+	}
+
+	spec, err := readStackSpec(filename)
+	if err != nil {
+		return err
+	}
+
+	// The VPCs and Servers are kept so that references to them resolve, but
+	// are not themselves compared.
+	spec = &stackSpec{
+		VPCs:          spec.VPCs,
+		Servers:       spec.Servers,
+		LoadBalancers: spec.LoadBalancers,
+		Firewalls:     spec.Firewalls,
+		Domains:       spec.Domains,
+	}
+
+	st, err := loadStackState(c, spec)
+	if err != nil {
+		return err
+	}
+
+	drift, err := findStackDrift(st)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Display(&displayers.StackDrift{Differences: drift}); err != nil {
+		return err
+	}
+
+	if len(drift) == 0 {
+		notice("No drift: live resources match the stack spec")
+		return nil
+	}
+	return &driftError{count: len(drift)}
+}
+
+// driftError is returned when live resources differ from the stack spec, so
+// that scripts can tell drift apart from a failure to check for it.
+type driftError struct {
+	count int
+}
+
+func (e *driftError) Error() string {
+	if e.count == 1 {
+		return "Found 1 difference between the stack spec and live resources"
+	}
+	return fmt.Sprintf("Found %d differences between the stack spec and live resources", e.count)
+}
+
+// findStackDrift lists the fields of the live firewalls, load balancers and
+// domain records that differ from the spec.
+func findStackDrift(st *stackState) ([]displayers.StackDifference, error) {
+	drift := []displayers.StackDifference{}
+	add := func(kind, name string, diffs ...stackFieldDiff) {
+		for _, d := range diffs {
+			drift = append(drift, displayers.StackDifference{
+				Kind:     kind,
+				Name:     name,
+				Field:    d.Field,
+				Expected: d.Expected,
+				Actual:   d.Actual,
+			})
+		}
+	}
+	absent := stackFieldDiff{Expected: "present", Actual: "absent"}
+
+	for _, lb := range st.spec.LoadBalancers {
+		lb := lb
+		live, ok := st.loadBalancers[lb.Name]
+		if !ok {
+			add(stackKindLoadBalancer, lb.Name, absent)
+			continue
+		}
+
+		diffs, err := diffLoadBalancer(st, &lb, live)
+		if err != nil {
+			return nil, fmt.Errorf("Load balancer %q: %w", lb.Name, err)
+		}
+		add(stackKindLoadBalancer, lb.Name, diffs...)
+	}
+
+	for _, fw := range st.spec.Firewalls {
+		fw := fw
+		live, ok := st.firewalls[fw.Name]
+		if !ok {
+			add(stackKindFirewall, fw.Name, absent)
+			continue
+		}
+
+		add(stackKindFirewall, fw.Name, diffFirewall(st, &fw, live)...)
+	}
+
+	for _, d := range st.spec.Domains {
+		if _, ok := st.domains[d.Name]; !ok {
+			add(stackKindDomain, d.Name, absent)
+			continue
+		}
+
+		matches, unmatched := matchDomainRecords(d, st.records[d.Name])
+		for i, r := range d.Records {
+			name := recordName(d.Name, r.Type, r.Name)
+			if matches[i] == nil {
+				add(stackKindRecord, name, stackFieldDiff{Field: "data", Expected: r.Data})
+				continue
+			}
+			add(stackKindRecord, name, diffRecord(matches[i], r)...)
+		}
+		for _, lr := range unmatched {
+			add(stackKindRecord, recordName(d.Name, lr.Type, lr.Name), stackFieldDiff{Field: "data", Actual: lr.Data})
+		}
+	}
+
+	return drift, nil
+}
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"os"
+	"testing"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/bl-cli/commands/displayers"
+	"github.com/binarylane/go-binarylane"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDriftSpec = `
+load_balancers:
+  - name: web-lb
+    region: syd
+    servers: [web-1]
+    forwarding_rules:
+      - {entry_protocol: http, entry_port: 80, target_protocol: http, target_port: 80}
+firewalls:
+  - name: web
+    servers: [web-1]
+    inbound_rules:
+      - {protocol: tcp, ports: "22", sources: {addresses: [192.0.2.0/24]}}
+domains:
+  - name: example.com
+    records:
+      - {type: A, name: www, data: 192.0.2.10, ttl: 300}
+`
+
+func TestFindStackDrift(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.vpcs.EXPECT().List().Return(bl.VPCs{}, nil)
+		tm.servers.EXPECT().List().Return(bl.Servers{
+			{Server: &binarylane.Server{ID: 42, Name: "web-1"}},
+			{Server: &binarylane.Server{ID: 43, Name: "web-2"}},
+		}, nil)
+		tm.loadBalancers.EXPECT().List().Return(bl.LoadBalancers{{LoadBalancer: &binarylane.LoadBalancer{
+			ID:        9,
+			Name:      "web-lb",
+			ServerIDs: []int{42, 43},
+			ForwardingRules: []binarylane.ForwardingRule{
+				{EntryProtocol: "http", EntryPort: 80, TargetProtocol: "http", TargetPort: 80},
+			},
+		}}}, nil)
+		tm.firewalls.EXPECT().List().Return(bl.Firewalls{{Firewall: &binarylane.Firewall{
+			ID:        "fw",
+			Name:      "web",
+			ServerIDs: []int{42},
+			InboundRules: []binarylane.InboundRule{
+				{Protocol: "tcp", PortRange: "22", Sources: &binarylane.Sources{Addresses: []string{"0.0.0.0/0"}}},
+			},
+		}}}, nil)
+		tm.domains.EXPECT().List().Return(bl.Domains{testDomain}, nil)
+		tm.domains.EXPECT().Records("example.com").Return(bl.DomainRecords{
+			{DomainRecord: &binarylane.DomainRecord{ID: 1, Type: "SOA", Name: "@", Data: "ns1.binarylane.com.au"}},
+			{DomainRecord: &binarylane.DomainRecord{ID: 2, Type: "A", Name: "www", Data: "192.0.2.10", TTL: 3600}},
+			{DomainRecord: &binarylane.DomainRecord{ID: 3, Type: "TXT", Name: "@", Data: "hello"}},
+		}, nil)
+
+		spec, err := parseStackSpec([]byte(testDriftSpec))
+		assert.NoError(t, err)
+
+		st, err := loadStackState(config, spec)
+		assert.NoError(t, err)
+
+		drift, err := findStackDrift(st)
+		assert.NoError(t, err)
+		assert.Equal(t, []displayers.StackDifference{
+			{Kind: "load_balancer", Name: "web-lb", Field: "servers", Expected: "web-1", Actual: "web-1, web-2"},
+			{Kind: "firewall", Name: "web", Field: "inbound_rules", Expected: "tcp:22 addresses=192.0.2.0/24"},
+			{Kind: "firewall", Name: "web", Field: "inbound_rules", Actual: "tcp:22 addresses=0.0.0.0/0"},
+			{Kind: "domain_record", Name: "A www.example.com", Field: "ttl", Expected: "300", Actual: "3600"},
+			{Kind: "domain_record", Name: "TXT @.example.com", Field: "data", Actual: "hello"},
+		}, drift)
+	})
+}
+
+func TestRunDrift(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.vpcs.EXPECT().List().Return(bl.VPCs{}, nil)
+		tm.servers.EXPECT().List().Return(bl.Servers{}, nil)
+		tm.loadBalancers.EXPECT().List().Return(bl.LoadBalancers{}, nil)
+		tm.firewalls.EXPECT().List().Return(bl.Firewalls{}, nil)
+		tm.domains.EXPECT().List().Return(bl.Domains{testDomain}, nil)
+		tm.domains.EXPECT().Records("example.com").Return(bl.DomainRecords{
+			{DomainRecord: &binarylane.DomainRecord{ID: 2, Type: "A", Name: "www", Data: "192.0.2.10", TTL: 300}},
+		}, nil)
+
+		path := writeTestStackSpec(t, testDriftSpec)
+		defer os.Remove(path)
+
+		config.Doit.Set(config.NS, blcli.ArgAppSpec, path)
+
+		err := RunDrift(config)
+		assert.EqualError(t, err, "Found 2 differences between the stack spec and live resources")
+		assert.Equal(t, ExitDrift, describeErr(err).ExitCode)
+	})
+}
@@ -17,23 +17,32 @@ import (
 	"bufio"
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
+	"strconv"
 	"testing"
 
+	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/go-binarylane"
 	"github.com/fatih/color"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
 
 func Test_checkErr(t *testing.T) {
-	defer func(a func()) { errAction = a }(errAction)
+	defer func(a func(int)) { errAction = a }(errAction)
 	defer func(a io.Writer) { color.Output = a }(color.Output)
 
 	var b bytes.Buffer
 	w := bufio.NewWriter(&b)
 	color.Output = w
 
-	errAction = func() {
+	var exitCode int
+	errAction = func(code int) {
+		exitCode = code
 	}
 
 	e := errors.New("an error")
@@ -43,4 +52,73 @@ func Test_checkErr(t *testing.T) {
 
 	re := regexp.MustCompile(`an error`)
 	assert.True(t, re.Match(b.Bytes()))
+	assert.Equal(t, ExitError, exitCode)
+}
+
+func Test_checkErrJSON(t *testing.T) {
+	defer func(a func(int)) { errAction = a }(errAction)
+	defer func(a io.Writer) { color.Output = a }(color.Output)
+	defer viper.Set("output", viper.GetString("output"))
+
+	var b bytes.Buffer
+	color.Output = &b
+
+	var exitCode int
+	errAction = func(code int) {
+		exitCode = code
+	}
+	viper.Set("output", "json")
+
+	req := httptest.NewRequest(http.MethodGet, "https://api.binarylane.com.au/v2/servers/1", nil)
+	checkErr(&binarylane.ErrorResponse{
+		Response:  &http.Response{Request: req, StatusCode: http.StatusNotFound, Header: http.Header{}},
+		Message:   "The resource you were accessing could not be found.",
+		RequestID: "abc123",
+	})
+
+	assert.Equal(t, ExitNotFound, exitCode)
+	assert.JSONEq(t, `{
+		"code": "not_found",
+		"message": "GET https://api.binarylane.com.au/v2/servers/1: 404 (request \"abc123\") The resource you were accessing could not be found.",
+		"request_id": "abc123",
+		"status": 404,
+		"exit_code": 5
+	}`, b.String())
+}
+
+func Test_describeErr(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://api.binarylane.com.au/v2/servers/1", nil)
+	apiErr := func(status int) error {
+		return &binarylane.ErrorResponse{Response: &http.Response{Request: req, StatusCode: status, Header: http.Header{}}}
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		code     string
+		exitCode int
+	}{
+		{name: "other errors", err: errors.New("boom"), code: "error", exitCode: ExitError},
+		{name: "missing arguments", err: blcli.NewMissingArgsErr("server.get"), code: "usage", exitCode: ExitUsage},
+		{name: "too many arguments", err: blcli.NewTooManyArgsErr("server.get"), code: "usage", exitCode: ExitUsage},
+		{name: "invalid numbers", err: func() error { _, err := strconv.Atoi("web"); return err }(), code: "usage", exitCode: ExitUsage},
+		{name: "unknown flags", err: usageErr(errors.New("unknown flag: --bogus")), code: "usage", exitCode: ExitUsage},
+		{name: "refused confirmations", err: blcli.ErrOperationAborted, code: "aborted", exitCode: ExitAborted},
+		{name: "unauthorized", err: apiErr(http.StatusUnauthorized), code: "unauthorized", exitCode: ExitUnauthorized},
+		{name: "forbidden", err: apiErr(http.StatusForbidden), code: "unauthorized", exitCode: ExitUnauthorized},
+		{name: "not found", err: apiErr(http.StatusNotFound), code: "not_found", exitCode: ExitNotFound},
+		{name: "wrapped not found", err: fmt.Errorf("could not assign IP to server: %w", apiErr(http.StatusNotFound)), code: "not_found", exitCode: ExitNotFound},
+		{name: "unprocessable", err: apiErr(http.StatusUnprocessableEntity), code: "invalid", exitCode: ExitInvalid},
+		{name: "rate limited", err: apiErr(http.StatusTooManyRequests), code: "rate_limited", exitCode: ExitRateLimited},
+		{name: "server errors", err: apiErr(http.StatusBadGateway), code: "server_error", exitCode: ExitServerError},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			oe := describeErr(tt.err)
+			assert.Equal(t, tt.code, oe.Code)
+			assert.Equal(t, tt.exitCode, oe.ExitCode)
+		})
+	}
 }
@@ -15,23 +15,58 @@ package commands
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"strconv"
 
 	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/go-binarylane"
 	"github.com/fatih/color"
 	"github.com/shiena/ansicolor"
 	"github.com/spf13/viper"
 )
 
+// Exit codes returned by bl. Scripts depend on them, so they must not
+// change.
+const (
+	// ExitError is returned for errors without a more specific exit code.
+	ExitError = 1
+	// ExitUsage is returned for unknown commands and flags, and for missing
+	// or invalid arguments.
+	ExitUsage = 2
+	// ExitAborted is returned when the user doesn't confirm an operation.
+	ExitAborted = 3
+	// ExitUnauthorized is returned when the API refuses the access token
+	// (401 or 403).
+	ExitUnauthorized = 4
+	// ExitNotFound is returned when a resource doesn't exist (404).
+	ExitNotFound = 5
+	// ExitInvalid is returned when the API rejects a request as invalid or
+	// conflicting (400, 409 or 422).
+	ExitInvalid = 6
+	// ExitRateLimited is returned when the API rate limit is exceeded (429).
+	ExitRateLimited = 7
+	// ExitServerError is returned when the API fails (5xx).
+	ExitServerError = 8
+	// ExitTimeout is returned when a command runs longer than --timeout.
+	ExitTimeout = 9
+	// ExitDrift is returned by bl drift when live resources differ from the
+	// stack spec.
+	ExitDrift = 10
+	// ExitInterrupted is returned when a command is interrupted by a signal.
+	ExitInterrupted = 130
+)
+
 var (
 	colorErr    = color.RedString("Error")
 	colorWarn   = color.YellowString("Warning")
 	colorNotice = color.GreenString("Notice")
 
 	// errAction specifies what should happen when an error occurs
-	errAction = func() {
-		os.Exit(1)
+	errAction = func(code int) {
+		os.Exit(code)
 	}
 )
 
@@ -39,36 +74,107 @@ func init() {
 	color.Output = ansicolor.NewAnsiColorWriter(os.Stderr)
 }
 
-type outputErrors struct {
-	Errors []outputError `json:"errors"`
+// cliError is an error with the code and exit code it is reported with.
+type cliError struct {
+	err      error
+	code     string
+	exitCode int
+}
+
+func (e *cliError) Error() string {
+	return e.err.Error()
+}
+
+func (e *cliError) Unwrap() error {
+	return e.err
+}
+
+// usageErr marks an error as a usage error.
+func usageErr(err error) error {
+	return &cliError{err: err, code: "usage", exitCode: ExitUsage}
 }
 
+// outputError is the JSON representation of an error.
 type outputError struct {
-	Detail string `json:"detail"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+	Status    int    `json:"status,omitempty"`
+	ExitCode  int    `json:"exit_code"`
 }
 
+// describeErr classifies an error, giving it a code and an exit code.
+func describeErr(err error) outputError {
+	oe := outputError{Code: "error", Message: err.Error(), ExitCode: ExitError}
+
+	var (
+		ce       *cliError
+		drift    *driftError
+		apiErr   *binarylane.ErrorResponse
+		argErr   *binarylane.ArgError
+		missing  *blcli.MissingArgsErr
+		tooMany  *blcli.TooManyArgsErr
+		numError *strconv.NumError
+	)
+
+	switch {
+	case errors.As(err, &ce):
+		oe.Code, oe.ExitCode = ce.code, ce.exitCode
+	case errors.As(err, &drift):
+		oe.Code, oe.ExitCode = "drift", ExitDrift
+	case errors.Is(err, blcli.ErrOperationAborted):
+		oe.Code, oe.ExitCode = "aborted", ExitAborted
+	case errors.As(err, &missing), errors.As(err, &tooMany), errors.As(err, &argErr), errors.As(err, &numError):
+		oe.Code, oe.ExitCode = "usage", ExitUsage
+	case errors.As(err, &apiErr):
+		oe.RequestID = apiErr.RequestID
+		if resp := apiErr.Response; resp != nil {
+			oe.Status = resp.StatusCode
+			if oe.RequestID == "" {
+				oe.RequestID = resp.Header.Get("X-Request-Id")
+			}
+		}
+		oe.Code, oe.ExitCode = describeStatus(oe.Status)
+	}
+
+	return oe
+}
+
+func describeStatus(status int) (string, int) {
+	switch {
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return "unauthorized", ExitUnauthorized
+	case status == http.StatusNotFound:
+		return "not_found", ExitNotFound
+	case status == http.StatusBadRequest, status == http.StatusConflict, status == http.StatusUnprocessableEntity:
+		return "invalid", ExitInvalid
+	case status == http.StatusTooManyRequests:
+		return "rate_limited", ExitRateLimited
+	case status >= 500:
+		return "server_error", ExitServerError
+	default:
+		return "api_error", ExitError
+	}
+}
+
+// checkErr reports the error, if there is one, and exits with its exit code.
+// With JSON output the error is written to stderr as a JSON object.
 func checkErr(err error) {
 	if err == nil {
 		return
 	}
 
-	output := viper.GetString("output")
+	oe := describeErr(err)
 
-	switch output {
+	switch viper.GetString("output") {
 	default:
 		fmt.Fprintf(color.Output, "%s: %v\n", colorErr, err)
-	case "json":
-		es := outputErrors{
-			Errors: []outputError{
-				{Detail: err.Error()},
-			},
-		}
-
-		b, _ := json.Marshal(&es)
-		fmt.Println(string(b))
+	case "json", "ndjson":
+		b, _ := json.Marshal(&oe)
+		fmt.Fprintf(color.Output, "%s\n", b)
 	}
 
-	errAction()
+	errAction(oe.ExitCode)
 }
 
 func ensureOneArg(c *CmdConfig) error {
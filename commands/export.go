@@ -0,0 +1,481 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/go-binarylane"
+	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Export creates the export command.
+func Export(parent *Command) *Command {
+	return CmdBuilder(parent, RunExport, "export", "Export the resources on your account as a stack spec", `Use this command to write a stack spec describing the SSH keys, tags, projects, VPCs, Servers, floating IPs, load balancers, firewalls and domains on your account.
+
+The spec is written as YAML, or as JSON with `+"`"+`--output json`+"`"+`. Resources are sorted by name and refer to each other by name rather than ID, so that exports taken at different times can be compared with `+"`"+`diff`+"`"+` and the spec can be passed to `+"`"+`bl plan`+"`"+` and `+"`"+`bl apply`+"`"+`. Resources that share a name are referred to by ID, and a warning is shown for them and for resources without a region, as they must be fixed before the spec can be applied.
+
+NS and SOA records are managed by BinaryLane and are not exported.`, Writer)
+}
+
+// RunExport writes a stack spec describing every resource on the account.
+func RunExport(c *CmdConfig) error {
+	// The spec is a document rather than a list, so it is only written in
+	// the formats that it can be read back from.
+	output := viper.GetString("output")
+	switch output {
+	case "text", "yaml", "json":
+	default:
+		return usageErr(fmt.Errorf("Unsupported output format %q. The stack spec can only be written as yaml or json", output))
+	}
+
+	spec, err := exportStackSpec(c)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range exportWarnings(spec) {
+		warn("%s", w)
+	}
+
+	var b []byte
+	if output == "json" {
+		b, err = json.MarshalIndent(spec, "", "  ")
+		b = append(b, '\n')
+	} else {
+		b, err = yaml.Marshal(spec)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Out.Write(b)
+	return err
+}
+
+// exportNames maps resource IDs to the references used for them in an
+// exported spec.
+type exportNames struct {
+	names  map[int]string
+	counts map[string]int
+}
+
+func newExportNames() *exportNames {
+	return &exportNames{names: map[int]string{}, counts: map[string]int{}}
+}
+
+func (n *exportNames) add(id int, name string) {
+	n.names[id] = name
+	n.counts[name]++
+}
+
+// ref returns the name of the resource, or its ID when the name is shared by
+// another resource of the same kind or the resource is unknown.
+func (n *exportNames) ref(id int) string {
+	name, ok := n.names[id]
+	if !ok || n.counts[name] > 1 {
+		return strconv.Itoa(id)
+	}
+	return name
+}
+
+func (n *exportNames) refs(ids []int) []string {
+	var refs []string
+	for _, id := range ids {
+		refs = append(refs, n.ref(id))
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+func exportStackSpec(c *CmdConfig) (*stackSpec, error) {
+	spec := &stackSpec{}
+
+	keys, err := c.Keys().List()
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		spec.SSHKeys = append(spec.SSHKeys, stackSSHKey{Name: k.Name, PublicKey: k.PublicKey})
+	}
+	sort.SliceStable(spec.SSHKeys, func(i, j int) bool { return spec.SSHKeys[i].Name < spec.SSHKeys[j].Name })
+
+	tags, err := c.Tags().List()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		spec.Tags = append(spec.Tags, t.Name)
+	}
+	sort.Strings(spec.Tags)
+
+	projects, err := c.Projects().List()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range projects {
+		spec.Projects = append(spec.Projects, stackProject{
+			Name:        p.Name,
+			Description: p.Description,
+			Purpose:     p.Purpose,
+			Environment: p.Environment,
+			IsDefault:   p.IsDefault,
+		})
+	}
+	sort.SliceStable(spec.Projects, func(i, j int) bool { return spec.Projects[i].Name < spec.Projects[j].Name })
+
+	vpcs, err := c.VPCs().List()
+	if err != nil {
+		return nil, err
+	}
+	vpcNames := newExportNames()
+	for _, v := range vpcs {
+		vpcNames.add(v.ID, v.Name)
+		spec.VPCs = append(spec.VPCs, stackVPC{
+			Name:        v.Name,
+			Region:      v.RegionSlug,
+			IPRange:     v.IPRange,
+			Description: v.Description,
+		})
+	}
+	sort.SliceStable(spec.VPCs, func(i, j int) bool { return spec.VPCs[i].Name < spec.VPCs[j].Name })
+
+	servers, err := c.Servers().List()
+	if err != nil {
+		return nil, err
+	}
+	serverNames := newExportNames()
+	for _, s := range servers {
+		serverNames.add(s.ID, s.Name)
+	}
+	for _, s := range servers {
+		spec.Servers = append(spec.Servers, exportServer(s, vpcNames))
+	}
+	sort.SliceStable(spec.Servers, func(i, j int) bool { return spec.Servers[i].Name < spec.Servers[j].Name })
+
+	fips, err := c.FloatingIPs().List()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fips {
+		fip := stackFloatingIP{IP: f.IP}
+		if f.Region != nil {
+			fip.Region = f.Region.Slug
+		}
+		if f.Server != nil {
+			fip.Server = serverNames.ref(f.Server.ID)
+		}
+		spec.FloatingIPs = append(spec.FloatingIPs, fip)
+	}
+	sort.SliceStable(spec.FloatingIPs, func(i, j int) bool { return spec.FloatingIPs[i].IP < spec.FloatingIPs[j].IP })
+
+	lbs, err := c.LoadBalancers().List()
+	if err != nil {
+		return nil, err
+	}
+	lbNames := newExportNames()
+	for _, lb := range lbs {
+		lbNames.add(lb.ID, lb.Name)
+	}
+	for _, lb := range lbs {
+		spec.LoadBalancers = append(spec.LoadBalancers, exportLoadBalancer(lb, vpcNames, serverNames))
+	}
+	sort.SliceStable(spec.LoadBalancers, func(i, j int) bool { return spec.LoadBalancers[i].Name < spec.LoadBalancers[j].Name })
+
+	fws, err := c.Firewalls().List()
+	if err != nil {
+		return nil, err
+	}
+	for _, fw := range fws {
+		spec.Firewalls = append(spec.Firewalls, exportFirewall(fw, serverNames, lbNames))
+	}
+	sort.SliceStable(spec.Firewalls, func(i, j int) bool { return spec.Firewalls[i].Name < spec.Firewalls[j].Name })
+
+	domains, err := c.Domains().List()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range domains {
+		records, err := c.Domains().Records(d.Name)
+		if err != nil {
+			return nil, err
+		}
+		spec.Domains = append(spec.Domains, exportDomain(d.Name, records))
+	}
+	sort.SliceStable(spec.Domains, func(i, j int) bool { return spec.Domains[i].Name < spec.Domains[j].Name })
+
+	return spec, nil
+}
+
+// exportWarnings lists what keeps an exported spec from being applied as it
+// is: resources of the same kind that share a name, and resources without a
+// region.
+func exportWarnings(spec *stackSpec) []string {
+	var warnings []string
+
+	duplicates := func(kind string, referenced bool, names []string) {
+		counts := map[string]int{}
+		for _, name := range names {
+			counts[name]++
+		}
+		for _, name := range names {
+			count := counts[name]
+			if count < 2 {
+				continue
+			}
+			counts[name] = 0
+
+			w := fmt.Sprintf("%d %s are named %q. ", count, kind, name)
+			if referenced {
+				w += "References to them use their IDs, and they"
+			} else {
+				w += "They"
+			}
+			warnings = append(warnings, w+" must be renamed before the spec can be applied.")
+		}
+	}
+	missingRegion := func(kind, name, region string) {
+		if region == "" {
+			warnings = append(warnings, fmt.Sprintf("The %s %q has no region, which must be added before the spec can be applied.", kind, name))
+		}
+	}
+
+	var names []string
+	for _, k := range spec.SSHKeys {
+		names = append(names, k.Name)
+	}
+	duplicates("SSH keys", false, names)
+
+	names = nil
+	for _, p := range spec.Projects {
+		names = append(names, p.Name)
+	}
+	duplicates("projects", false, names)
+
+	names = nil
+	for _, v := range spec.VPCs {
+		names = append(names, v.Name)
+		missingRegion("VPC", v.Name, v.Region)
+	}
+	duplicates("VPCs", true, names)
+
+	names = nil
+	for _, s := range spec.Servers {
+		names = append(names, s.Name)
+		missingRegion("Server", s.Name, s.Region)
+	}
+	duplicates("Servers", true, names)
+
+	for _, f := range spec.FloatingIPs {
+		missingRegion("floating IP", f.IP, f.Region)
+	}
+
+	names = nil
+	for _, lb := range spec.LoadBalancers {
+		names = append(names, lb.Name)
+		missingRegion("load balancer", lb.Name, lb.Region)
+	}
+	duplicates("load balancers", true, names)
+
+	names = nil
+	for _, fw := range spec.Firewalls {
+		names = append(names, fw.Name)
+	}
+	duplicates("firewalls", false, names)
+
+	return warnings
+}
+
+func exportServer(s bl.Server, vpcNames *exportNames) stackServer {
+	out := stackServer{
+		Name: s.Name,
+		Size: s.SizeSlug,
+		Tags: append([]string(nil), s.Tags...),
+	}
+	sort.Strings(out.Tags)
+
+	if s.Region != nil {
+		out.Region = s.Region.Slug
+	}
+	if s.Image != nil {
+		out.Image = s.Image.Slug
+		if out.Image == "" {
+			out.Image = strconv.Itoa(s.Image.ID)
+		}
+	}
+	if s.VPCID != 0 {
+		out.VPC = vpcNames.ref(s.VPCID)
+	}
+
+	for _, f := range s.Features {
+		switch f {
+		case "backups":
+			out.Backups = true
+		case "ipv6":
+			out.IPv6 = true
+		case "private_networking":
+			out.PrivateNetworking = true
+		case "monitoring":
+			out.Monitoring = true
+		}
+	}
+
+	return out
+}
+
+func exportLoadBalancer(lb bl.LoadBalancer, vpcNames, serverNames *exportNames) stackLoadBalancer {
+	out := stackLoadBalancer{
+		Name:                   lb.Name,
+		Size:                   lb.SizeSlug,
+		Algorithm:              lb.Algorithm,
+		Servers:                serverNames.refs(lb.ServerIDs),
+		Tag:                    lb.Tag,
+		RedirectHTTPToHTTPS:    lb.RedirectHttpToHttps,
+		EnableProxyProtocol:    lb.EnableProxyProtocol,
+		EnableBackendKeepalive: lb.EnableBackendKeepalive,
+	}
+
+	if lb.Region != nil {
+		out.Region = lb.Region.Slug
+	}
+	if lb.VPCID != 0 {
+		out.VPC = vpcNames.ref(lb.VPCID)
+	}
+
+	for _, fr := range lb.ForwardingRules {
+		out.ForwardingRules = append(out.ForwardingRules, stackForwardingRule{
+			EntryProtocol:  fr.EntryProtocol,
+			EntryPort:      fr.EntryPort,
+			TargetProtocol: fr.TargetProtocol,
+			TargetPort:     fr.TargetPort,
+			CertificateID:  fr.CertificateID,
+			TLSPassthrough: fr.TlsPassthrough,
+		})
+	}
+
+	if hc := lb.HealthCheck; hc != nil {
+		out.HealthCheck = &stackHealthCheck{
+			Protocol:               hc.Protocol,
+			Port:                   hc.Port,
+			Path:                   hc.Path,
+			CheckIntervalSeconds:   hc.CheckIntervalSeconds,
+			ResponseTimeoutSeconds: hc.ResponseTimeoutSeconds,
+			HealthyThreshold:       hc.HealthyThreshold,
+			UnhealthyThreshold:     hc.UnhealthyThreshold,
+		}
+	}
+
+	if ss := lb.StickySessions; ss != nil {
+		out.StickySessions = &stackStickySessions{
+			Type:             ss.Type,
+			CookieName:       ss.CookieName,
+			CookieTTLSeconds: ss.CookieTtlSeconds,
+		}
+	}
+
+	return out
+}
+
+func exportFirewall(fw bl.Firewall, serverNames, lbNames *exportNames) stackFirewall {
+	out := stackFirewall{
+		Name:    fw.Name,
+		Servers: serverNames.refs(fw.ServerIDs),
+		Tags:    append([]string(nil), fw.Tags...),
+	}
+	sort.Strings(out.Tags)
+
+	for _, r := range fw.InboundRules {
+		out.InboundRules = append(out.InboundRules, stackFirewallRule{
+			Protocol: r.Protocol,
+			Ports:    r.PortRange,
+			Sources:  exportFirewallTargets((*binarylane.Destinations)(r.Sources), serverNames, lbNames),
+		})
+	}
+	for _, r := range fw.OutboundRules {
+		out.OutboundRules = append(out.OutboundRules, stackFirewallRule{
+			Protocol:     r.Protocol,
+			Ports:        r.PortRange,
+			Destinations: exportFirewallTargets(r.Destinations, serverNames, lbNames),
+		})
+	}
+
+	return out
+}
+
+func exportFirewallTargets(d *binarylane.Destinations, serverNames, lbNames *exportNames) *stackFirewallTargets {
+	if d == nil {
+		return nil
+	}
+
+	var lbIDs []int
+	for _, uid := range d.LoadBalancerUIDs {
+		if id, err := strconv.Atoi(uid); err == nil {
+			lbIDs = append(lbIDs, id)
+		}
+	}
+
+	out := &stackFirewallTargets{
+		Addresses:     append([]string(nil), d.Addresses...),
+		Tags:          append([]string(nil), d.Tags...),
+		Servers:       serverNames.refs(d.ServerIDs),
+		LoadBalancers: lbNames.refs(lbIDs),
+	}
+	sort.Strings(out.Addresses)
+	sort.Strings(out.Tags)
+
+	if len(out.Addresses)+len(out.Tags)+len(out.Servers)+len(out.LoadBalancers) == 0 {
+		return nil
+	}
+	return out
+}
+
+func exportDomain(name string, records bl.DomainRecords) stackDomain {
+	out := stackDomain{Name: name}
+
+	for _, r := range records {
+		// NS and SOA records are managed by BinaryLane.
+		if r.Type == "NS" || r.Type == "SOA" {
+			continue
+		}
+		out.Records = append(out.Records, stackDomainRecord{
+			Type:     r.Type,
+			Name:     r.Name,
+			Data:     r.Data,
+			Priority: r.Priority,
+			Port:     r.Port,
+			TTL:      r.TTL,
+			Weight:   r.Weight,
+			Flags:    r.Flags,
+			Tag:      r.Tag,
+		})
+	}
+
+	sort.SliceStable(out.Records, func(i, j int) bool {
+		a, b := out.Records[i], out.Records[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Data < b.Data
+	})
+
+	return out
+}
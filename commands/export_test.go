@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/go-binarylane"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.keys.EXPECT().List().Return(bl.SSHKeys{{Key: &binarylane.Key{ID: 1, Name: "laptop", PublicKey: "ssh-ed25519 AAAA"}}}, nil)
+		tm.tags.EXPECT().List().Return(bl.Tags{{Tag: &binarylane.Tag{Name: "web"}}, {Tag: &binarylane.Tag{Name: "db"}}}, nil)
+		tm.projects.EXPECT().List().Return(bl.Projects{{Project: &binarylane.Project{ID: "p1", Name: "default", IsDefault: true}}}, nil)
+		tm.vpcs.EXPECT().List().Return(bl.VPCs{{VPC: &binarylane.VPC{ID: 7, Name: "prod", RegionSlug: "syd"}}}, nil)
+		tm.servers.EXPECT().List().Return(bl.Servers{
+			{Server: &binarylane.Server{
+				ID:       42,
+				Name:     "web-1",
+				SizeSlug: "std-min",
+				Region:   &binarylane.Region{Slug: "syd"},
+				Image:    &binarylane.Image{ID: 5, Slug: "ubuntu-20-04-lts"},
+				VPCID:    7,
+				Tags:     []string{"web"},
+				Features: []string{"backups"},
+			}},
+		}, nil)
+		tm.floatingIPs.EXPECT().List().Return(bl.FloatingIPs{
+			{FloatingIP: &binarylane.FloatingIP{IP: "192.0.2.1", Region: &binarylane.Region{Slug: "syd"}, Server: &binarylane.Server{ID: 42}}},
+		}, nil)
+		tm.loadBalancers.EXPECT().List().Return(bl.LoadBalancers{
+			{LoadBalancer: &binarylane.LoadBalancer{ID: 9, Name: "web-lb", Region: &binarylane.Region{Slug: "syd"}, ServerIDs: []int{42, 99}}},
+		}, nil)
+		tm.firewalls.EXPECT().List().Return(bl.Firewalls{
+			{Firewall: &binarylane.Firewall{
+				ID:   "fw",
+				Name: "web",
+				InboundRules: []binarylane.InboundRule{
+					{Protocol: "tcp", PortRange: "80", Sources: &binarylane.Sources{LoadBalancerUIDs: []string{"9"}}},
+				},
+			}},
+		}, nil)
+		tm.domains.EXPECT().List().Return(bl.Domains{testDomain}, nil)
+		tm.domains.EXPECT().Records("example.com").Return(bl.DomainRecords{
+			{DomainRecord: &binarylane.DomainRecord{ID: 1, Type: "NS", Name: "@", Data: "ns1.binarylane.com.au"}},
+			{DomainRecord: &binarylane.DomainRecord{ID: 2, Type: "A", Name: "www", Data: "192.0.2.1"}},
+		}, nil)
+
+		buf := &bytes.Buffer{}
+		config.Out = buf
+
+		err := RunExport(config)
+		assert.NoError(t, err)
+
+		spec, err := parseStackSpec(buf.Bytes())
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"db", "web"}, spec.Tags)
+		assert.Equal(t, stackServer{
+			Name:    "web-1",
+			Region:  "syd",
+			Size:    "std-min",
+			Image:   "ubuntu-20-04-lts",
+			VPC:     "prod",
+			Tags:    []string{"web"},
+			Backups: true,
+		}, spec.Servers[0])
+		assert.Equal(t, "web-1", spec.FloatingIPs[0].Server)
+		assert.Equal(t, []string{"99", "web-1"}, spec.LoadBalancers[0].Servers)
+		assert.Equal(t, []string{"web-lb"}, spec.Firewalls[0].InboundRules[0].Sources.LoadBalancers)
+		assert.Equal(t, []stackDomainRecord{{Type: "A", Name: "www", Data: "192.0.2.1"}}, spec.Domains[0].Records)
+	})
+}
+
+func TestExportNames(t *testing.T) {
+	names := newExportNames()
+	names.add(1, "web")
+	names.add(2, "web")
+	names.add(3, "db")
+
+	assert.Equal(t, "db", names.ref(3))
+	assert.Equal(t, "1", names.ref(1))
+	assert.Equal(t, "4", names.ref(4))
+	assert.Equal(t, []string{"1", "2", "db"}, names.refs([]int{3, 2, 1}))
+}
+
+func TestExportWarnings(t *testing.T) {
+	tests := []struct {
+		desc     string
+		spec     stackSpec
+		warnings []string
+	}{
+		{
+			desc: "appliable",
+			spec: stackSpec{
+				VPCs:          []stackVPC{{Name: "prod", Region: "syd"}},
+				Servers:       []stackServer{{Name: "web-1", Region: "syd", Size: "std-min", Image: "ubuntu"}},
+				LoadBalancers: []stackLoadBalancer{{Name: "web", Region: "syd"}},
+				Firewalls:     []stackFirewall{{Name: "web"}},
+			},
+		},
+		{
+			desc: "duplicate names",
+			spec: stackSpec{
+				VPCs:          []stackVPC{{Name: "prod", Region: "syd"}, {Name: "prod", Region: "mel"}},
+				LoadBalancers: []stackLoadBalancer{{Name: "web", Region: "syd"}, {Name: "web", Region: "syd"}},
+				Firewalls:     []stackFirewall{{Name: "web"}, {Name: "web"}, {Name: "web"}},
+			},
+			warnings: []string{
+				`2 VPCs are named "prod". References to them use their IDs, and they must be renamed before the spec can be applied.`,
+				`2 load balancers are named "web". References to them use their IDs, and they must be renamed before the spec can be applied.`,
+				`3 firewalls are named "web". They must be renamed before the spec can be applied.`,
+			},
+		},
+		{
+			desc: "missing regions",
+			spec: stackSpec{
+				Servers:       []stackServer{{Name: "web-1", Size: "std-min", Image: "ubuntu"}},
+				LoadBalancers: []stackLoadBalancer{{Name: "web"}},
+			},
+			warnings: []string{
+				`The Server "web-1" has no region, which must be added before the spec can be applied.`,
+				`The load balancer "web" has no region, which must be added before the spec can be applied.`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.warnings, exportWarnings(&tt.spec))
+
+			// Every spec that validate rejects has been warned about.
+			if err := tt.spec.validate(); len(tt.warnings) == 0 {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestExportOutput(t *testing.T) {
+	defer viper.Set("output", nil)
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.keys.EXPECT().List().Return(bl.SSHKeys{}, nil)
+		tm.tags.EXPECT().List().Return(bl.Tags{}, nil)
+		tm.projects.EXPECT().List().Return(bl.Projects{}, nil)
+		tm.vpcs.EXPECT().List().Return(bl.VPCs{{VPC: &binarylane.VPC{ID: 7, Name: "prod", RegionSlug: "syd"}}}, nil)
+		tm.servers.EXPECT().List().Return(bl.Servers{}, nil)
+		tm.floatingIPs.EXPECT().List().Return(bl.FloatingIPs{}, nil)
+		tm.loadBalancers.EXPECT().List().Return(bl.LoadBalancers{}, nil)
+		tm.firewalls.EXPECT().List().Return(bl.Firewalls{}, nil)
+		tm.domains.EXPECT().List().Return(bl.Domains{}, nil)
+
+		buf := &bytes.Buffer{}
+		config.Out = buf
+
+		viper.Set("output", "json")
+		assert.NoError(t, RunExport(config))
+		assert.True(t, json.Valid(buf.Bytes()))
+
+		spec, err := parseStackSpec(buf.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, []stackVPC{{Name: "prod", Region: "syd"}}, spec.VPCs)
+
+		viper.Set("output", "csv")
+		err = RunExport(config)
+		assert.EqualError(t, err, `Unsupported output format "csv". The stack spec can only be written as yaml or json`)
+		assert.Equal(t, ExitUsage, describeErr(err).ExitCode)
+	})
+}
@@ -78,7 +78,7 @@ Inbound access rules specify the protocol (TCP, UDP, or ICMP), ports, and source
 
 	CmdBuilder(cmd, RunFirewallList, "list", "List the cloud firewalls on your account", `Use this command to retrieve a list of cloud firewalls.`, Writer, aliasOpt("ls"), displayerType(&displayers.Firewall{}))
 
-	CmdBuilder(cmd, RunFirewallListByServer, "list-by-server <server_id>", "List firewalls by Server", `Use this command to list cloud firewalls by the ID of a Server assigned to the firewall.`, Writer, displayerType(&displayers.Firewall{}))
+	CmdBuilder(cmd, RunFirewallListByServer, "list-by-server <server_id>", "List firewalls by Server", `Use this command to list cloud firewalls by the ID of a Server assigned to the firewall.`, Writer, displayerType(&displayers.Firewall{}), completeArgsOpt(completeServerIDs))
 
 	cmdRunRecordDelete := CmdBuilder(cmd, RunFirewallDelete, "delete <id>...", "Permanently delete a cloud firewall", `Use this command to permanently delete a cloud firewall. This is irreversable, but does not delete any Servers assigned to the cloud firewall.`, Writer, aliasOpt("d", "rm"))
 	AddBoolFlag(cmdRunRecordDelete, blcli.ArgForce, blcli.ArgShortForce, false, "Delete firewall without confirmation prompt")
@@ -215,7 +215,7 @@ func RunFirewallDelete(c *CmdConfig) error {
 			}
 		}
 	} else {
-		return fmt.Errorf("Operation aborted.")
+		return blcli.ErrOperationAborted
 	}
 
 	return nil
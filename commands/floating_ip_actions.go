@@ -100,7 +100,7 @@ func RunFloatingIPActionsAssign(c *CmdConfig) error {
 
 	a, err := fia.Assign(ip, serverID)
 	if err != nil {
-		checkErr(fmt.Errorf("could not assign IP to server: %v", err))
+		checkErr(fmt.Errorf("could not assign IP to server: %w", err))
 	}
 
 	item := &displayers.Action{Actions: bl.Actions{*a}}
@@ -120,7 +120,7 @@ func RunFloatingIPActionsUnassign(c *CmdConfig) error {
 
 	a, err := fia.Unassign(ip)
 	if err != nil {
-		checkErr(fmt.Errorf("could not unassign IP to server: %v", err))
+		checkErr(fmt.Errorf("could not unassign IP to server: %w", err))
 	}
 
 	item := &displayers.Action{Actions: bl.Actions{*a}}
@@ -43,7 +43,7 @@ A floating IP address must be either assigned to a Server or reserved to a regio
 		aliasOpt("c"), displayerType(&displayers.FloatingIP{}))
 	AddStringFlag(cmdFloatingIPCreate, blcli.ArgRegionSlug, "", "",
 		fmt.Sprintf("Region where to create the floating IP address. (mutually exclusive with `--%s`)",
-			blcli.ArgServerID))
+			blcli.ArgServerID), completeFlagOpt(completeRegions))
 	AddIntFlag(cmdFloatingIPCreate, blcli.ArgServerID, "", 0,
 		fmt.Sprintf("The ID of the Server to assign the floating IP to (mutually exclusive with `--%s`).",
 			blcli.ArgRegionSlug))
@@ -84,7 +84,6 @@ func RunFloatingIPCreate(c *CmdConfig) error {
 
 	ip, err := fis.Create(req)
 	if err != nil {
-		fmt.Println(err)
 		return err
 	}
 
@@ -135,7 +134,7 @@ func RunFloatingIPDelete(c *CmdConfig) error {
 		return fis.Delete(ip)
 	}
 
-	return fmt.Errorf("Operation aborted.")
+	return blcli.ErrOperationAborted
 }
 
 // RunFloatingIPList runs floating IP create.
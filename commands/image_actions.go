@@ -52,7 +52,7 @@ func ImageAction() *Command {
 	cmdImageActionsTransfer := CmdBuilder(cmd, RunImageActionsTransfer,
 		"transfer <image-id>", "Transfer an image to another datacenter region", `Use this command to transfer an image to a different datacenter region. Also outputs the following details:`+actionDetail, Writer,
 		displayerType(&displayers.Action{}))
-	AddStringFlag(cmdImageActionsTransfer, blcli.ArgRegionSlug, "", "", "region", requiredOpt())
+	AddStringFlag(cmdImageActionsTransfer, blcli.ArgRegionSlug, "", "", "region", requiredOpt(), completeFlagOpt(completeRegions))
 	AddBoolFlag(cmdImageActionsTransfer, blcli.ArgCommandWait, "", false, "Wait for action to complete")
 
 	return cmd
@@ -112,7 +112,7 @@ func RunImageActionsTransfer(c *CmdConfig) error {
 
 	a, err := ias.Transfer(id, req)
 	if err != nil {
-		checkErr(fmt.Errorf("Could not transfer image: %v", err))
+		checkErr(fmt.Errorf("Could not transfer image: %w", err))
 	}
 
 	wait, err := c.Doit.GetBool(c.NS, blcli.ArgCommandWait)
@@ -77,7 +77,7 @@ Currently, there are five types of images: snapshots, backups, custom images, di
 	AddBoolFlag(cmdImagesListUser, blcli.ArgImagePublic, "", false, "List public images")
 
 	CmdBuilder(cmd, RunImagesGet, "get <image-id|image-slug>", "Retrieve information about an image", `Use this command to get the following information about the specified image:`+imageDetail, Writer,
-		displayerType(&displayers.Image{}))
+		displayerType(&displayers.Image{}), completeArgsOpt(completeImages))
 
 	cmdImagesUpdate := CmdBuilder(cmd, RunImagesUpdate, "update <image-id>", "Update an image's metadata", `Use this command to change an image's metadata, including its name, description, and distribution.`, Writer,
 		displayerType(&displayers.Image{}))
@@ -88,7 +88,7 @@ Currently, there are five types of images: snapshots, backups, custom images, di
 
 	cmdRunImagesCreate := CmdBuilder(cmd, RunImagesCreate, "create <image-name>", "Create custom image", `This command creates an image in your BinaryLane account. You can specify a URL for the image contents, the region at which to store the image, and image metadata.`, Writer)
 	AddStringFlag(cmdRunImagesCreate, blcli.ArgImageExternalURL, "", "", "Custom image retrieval URL", requiredOpt())
-	AddStringFlag(cmdRunImagesCreate, blcli.ArgRegionSlug, "", "", "Region slug identifier", requiredOpt())
+	AddStringFlag(cmdRunImagesCreate, blcli.ArgRegionSlug, "", "", "Region slug identifier", requiredOpt(), completeFlagOpt(completeRegions))
 	AddStringFlag(cmdRunImagesCreate, blcli.ArgImageDistro, "", "Unknown", "Custom image distribution")
 	AddStringFlag(cmdRunImagesCreate, blcli.ArgImageDescription, "", "", "Description of image")
 	AddStringSliceFlag(cmdRunImagesCreate, blcli.ArgTagNames, "", []string{}, "List of tags applied to image")
@@ -262,7 +262,7 @@ func RunImagesDelete(c *CmdConfig) error {
 		}
 
 	} else {
-		return fmt.Errorf("Operation aborted.")
+		return blcli.ErrOperationAborted
 	}
 
 	return nil
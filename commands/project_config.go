@@ -0,0 +1,169 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// projectConfigName is the name of the project config file, which is found
+// in the current directory or one of its parents.
+const projectConfigName = ".bl.yaml"
+
+// projectConfig is the project config file. It pins the auth context, the
+// region, the project and the output format used in a directory, over the
+// user's config file. It can't hold access tokens or change the API URL,
+// as it is usually checked in with the code it is for.
+type projectConfig struct {
+	Context   string `yaml:"context"`
+	Region    string `yaml:"region"`
+	ProjectID string `yaml:"project-id"`
+	Output    string `yaml:"output"`
+
+	path string
+}
+
+// project is the project config file in effect, or an empty projectConfig
+// when there is none.
+var project = &projectConfig{}
+
+// findProjectConfig looks for the project config file in dir and its
+// parents. It returns an empty path when there is none.
+func findProjectConfig(dir string) string {
+	for {
+		path := filepath.Join(dir, projectConfigName)
+		if fi, err := os.Stat(path); err == nil && !fi.IsDir() {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// projectConfigKeys are the keys a project config file can set.
+var projectConfigKeys = []string{blcli.ArgContext, blcli.ArgRegionSlug, "project-id", blcli.ArgOutput}
+
+// readProjectConfig reads a project config file. Unknown keys are errors, so
+// that a mistyped key doesn't leave a setting unpinned.
+func readProjectConfig(path string) (*projectConfig, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	for _, key := range sortedKeys(raw) {
+		known := false
+		for _, k := range projectConfigKeys {
+			known = known || k == key
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown key %q. A project config file can only set %s", key, strings.Join(projectConfigKeys, ", "))
+		}
+	}
+
+	pc := &projectConfig{path: path}
+	if err := yaml.Unmarshal(b, pc); err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+// loadProjectConfig finds the project config file of the current directory
+// and merges its output format over the user's config file, so that flags
+// and BINARYLANE_ environment variables still take precedence.
+func loadProjectConfig() error {
+	project = &projectConfig{}
+
+	// Without a current directory there is no project to look for.
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	path := findProjectConfig(wd)
+	if path == "" {
+		return nil
+	}
+
+	pc, err := readProjectConfig(path)
+	if err != nil {
+		return fmt.Errorf("Unable to read the project config file %s: %v", path, err)
+	}
+	project = pc
+
+	if pc.Output != "" {
+		return viper.MergeConfigMap(map[string]interface{}{blcli.ArgOutput: pc.Output})
+	}
+	return nil
+}
+
+// settings returns the settings of the user's config file that the project
+// config file pins.
+func (pc *projectConfig) settings() map[string]string {
+	settings := map[string]string{}
+	if pc.Context != "" {
+		settings[blcli.ArgContext] = pc.Context
+	}
+	if pc.Output != "" {
+		settings[blcli.ArgOutput] = pc.Output
+	}
+	return settings
+}
+
+// applyProjectDefaults gives the --region flag of the command being run the
+// project's region. It takes precedence over the auth context's defaults,
+// but not over the flag or its environment variable. The --region filters
+// of list commands are left alone.
+func applyProjectDefaults(cmd *cobra.Command, args []string) error {
+	if project.Region == "" || cmd.Name() == "list" {
+		return nil
+	}
+
+	f := cmd.LocalNonPersistentFlags().Lookup(blcli.ArgRegionSlug)
+	if f == nil || f.Value.Type() != "string" {
+		return nil
+	}
+
+	values := map[string]interface{}{}
+	setNested(values, strings.Split(cmdNS(cmd)+"."+f.Name, "."), project.Region)
+	return viper.MergeConfigMap(values)
+}
+
+// projectID returns the project ID given as the first argument, or else the
+// project config file's project ID.
+func projectID(c *CmdConfig) (string, error) {
+	if len(c.Args) == 0 && project.ProjectID != "" {
+		return project.ProjectID, nil
+	}
+	if err := ensureOneArg(c); err != nil {
+		return "", err
+	}
+	return c.Args[0], nil
+}
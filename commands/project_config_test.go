@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProjectConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	sub := filepath.Join(dir, "repo", "a", "b")
+	require.NoError(t, os.MkdirAll(sub, 0755))
+	assert.Equal(t, "", findProjectConfig(sub))
+
+	path := filepath.Join(dir, "repo", projectConfigName)
+	require.NoError(t, ioutil.WriteFile(path, []byte("context: team\nregion: syd\nproject-id: abc\noutput: json\n"), 0600))
+	assert.Equal(t, path, findProjectConfig(sub))
+	assert.Equal(t, path, findProjectConfig(filepath.Join(dir, "repo")))
+	assert.Equal(t, "", findProjectConfig(dir))
+
+	pc, err := readProjectConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, &projectConfig{Context: "team", Region: "syd", ProjectID: "abc", Output: "json", path: path}, pc)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("context: team\naccess-token: secret\n"), 0600))
+	_, err = readProjectConfig(path)
+	assert.EqualError(t, err, `unknown key "access-token". A project config file can only set context, region, project-id, output`)
+}
+
+func TestProjectConfigPins(t *testing.T) {
+	defer func() {
+		project = &projectConfig{}
+	}()
+	project = &projectConfig{Context: "team", Region: "bne", ProjectID: "abc"}
+
+	assert.Equal(t, "team", currentContext())
+
+	os.Setenv("BINARYLANE_CONTEXT", "other")
+	assert.Equal(t, "other", currentContext())
+	os.Unsetenv("BINARYLANE_CONTEXT")
+
+	Context = "flag"
+	assert.Equal(t, "flag", currentContext())
+	Context = ""
+
+	newCmd := func(parent, name string) *cobra.Command {
+		cmd := &cobra.Command{Use: name}
+		cmd.Flags().String("region", "", "")
+		(&cobra.Command{Use: parent}).AddCommand(cmd)
+		viper.BindPFlag(parent+"."+name+".region", cmd.Flags().Lookup("region"))
+		return cmd
+	}
+
+	// The project's region takes precedence over the context's default.
+	viper.MergeConfigMap(map[string]interface{}{"gizmo": map[string]interface{}{"create": map[string]interface{}{"region": "syd"}}})
+	require.NoError(t, applyProjectDefaults(newCmd("gizmo", "create"), nil))
+	assert.Equal(t, "bne", viper.GetString("gizmo.create.region"))
+
+	cmd := newCmd("doodad", "create")
+	require.NoError(t, cmd.Flags().Set("region", "per"))
+	require.NoError(t, applyProjectDefaults(cmd, nil))
+	assert.Equal(t, "per", viper.GetString("doodad.create.region"))
+
+	require.NoError(t, applyProjectDefaults(newCmd("gadget", "list"), nil))
+	assert.Equal(t, "", viper.GetString("gadget.list.region"))
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		id, err := projectID(config)
+		require.NoError(t, err)
+		assert.Equal(t, "abc", id)
+
+		config.Args = []string{"def"}
+		id, err = projectID(config)
+		require.NoError(t, err)
+		assert.Equal(t, "def", id)
+	})
+}
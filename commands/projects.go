@@ -113,17 +113,17 @@ A valid URN has the format: ` + "`" + `do:resource_type:resource_id` + "`" + `.
   - ` + "`" + `do:volume:6fc4c277-ea5c-448a-93cd-dd496cfef71f` + "`" + `
 `
 
-	CmdBuilder(cmd, RunProjectResourcesList, "list <project-id>", "List resources assigned to a project",
-		"List all of the resources assigned to the specified project displaying their uniform resource names (\"URNs\").",
+	CmdBuilder(cmd, RunProjectResourcesList, "list [<project-id>]", "List resources assigned to a project",
+		"List all of the resources assigned to the specified project displaying their uniform resource names (\"URNs\"). The project ID can be left out in a directory with a `.bl.yaml` project config file that sets `project-id`.",
 		Writer, aliasOpt("ls"), displayerType(&displayers.ProjectResource{}))
 	CmdBuilder(cmd, RunProjectResourcesGet, "get <urn>", "Retrieve a resource by its URN",
 		"Retrieve information about a resource by specifying its uniform resource name (\"URN\"). Currently, ony Servers, floating IPs, load balancers, domains, and volumes are supported."+urnDesc,
 		Writer, aliasOpt("g"), displayerType(&displayers.ProjectResource{}))
 
 	cmdProjectResourcesAssign := CmdBuilder(cmd, RunProjectResourcesAssign,
-		"assign <project-id> --resource=<urn> [--resource=<urn> ...]",
+		"assign [<project-id>] --resource=<urn> [--resource=<urn> ...]",
 		"Assign one or more resources to a project",
-		"Assign one or more resources to a project by specifying the resource's uniform resource name (\"URN\")."+urnDesc,
+		"Assign one or more resources to a project by specifying the resource's uniform resource name (\"URN\"). The project ID can be left out in a directory with a `.bl.yaml` project config file that sets `project-id`."+urnDesc,
 		Writer, aliasOpt("a"))
 	AddStringSliceFlag(cmdProjectResourcesAssign, blcli.ArgProjectResource, "",
 		[]string{}, "URNs specifying resources to assign to the project")
@@ -220,16 +220,15 @@ func RunProjectsDelete(c *CmdConfig) error {
 		return nil
 	}
 
-	return fmt.Errorf("operation aborted")
+	return blcli.ErrOperationAborted
 }
 
 // RunProjectResourcesList lists the Projects.
 func RunProjectResourcesList(c *CmdConfig) error {
-	err := ensureOneArg(c)
+	id, err := projectID(c)
 	if err != nil {
 		return err
 	}
-	id := c.Args[0]
 
 	ps := c.Projects()
 	list, err := ps.ListResources(id)
@@ -270,11 +269,10 @@ func RunProjectResourcesGet(c *CmdConfig) error {
 
 // RunProjectResourcesAssign assigns a Project Resource.
 func RunProjectResourcesAssign(c *CmdConfig) error {
-	err := ensureOneArg(c)
+	projectUUID, err := projectID(c)
 	if err != nil {
 		return err
 	}
-	projectUUID := c.Args[0]
 
 	urns, err := c.Doit.GetStringSlice(c.NS, blcli.ArgProjectResource)
 	if err != nil {
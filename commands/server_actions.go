@@ -38,16 +38,62 @@ func performAction(c *CmdConfig, fn actionFn) error {
 		return err
 	}
 
-	if wait {
-		a, err = actionWait(c, a.ID, 5)
-		if err != nil {
-			return err
-		}
+	return displayActions(c, bl.Actions{*a}, wait)
+}
+
+type tagActionFn func(das bl.ServerActionsService, tag string) (bl.Actions, error)
+
+// performTagAction performs an action on the Servers with the tag given by
+// --tag-name, or else on the Server given by the argument.
+func performTagAction(c *CmdConfig, fn actionFn, tagFn tagActionFn) error {
+	tag, err := c.Doit.GetString(c.NS, blcli.ArgTagName)
+	if err != nil {
+		return err
+	}
+	if tag == "" {
+		return performAction(c, fn)
+	}
+	if len(c.Args) > 0 {
+		return fmt.Errorf("Please specify Server identifier or a tag name.")
+	}
 
+	actions, err := tagFn(c.ServerActions(), tag)
+	if err != nil {
+		return err
 	}
 
-	item := &displayers.Action{Actions: bl.Actions{*a}}
-	return c.Display(item)
+	wait, err := c.Doit.GetBool(c.NS, blcli.ArgCommandWait)
+	if err != nil {
+		return err
+	}
+
+	return displayActions(c, actions, wait)
+}
+
+// displayActions displays the actions a command started, after waiting for
+// them to complete with --wait. When waiting fails, the actions are still
+// displayed before the error is returned.
+func displayActions(c *CmdConfig, actions bl.Actions, wait bool) error {
+	if !wait {
+		return c.Display(&displayers.Action{Actions: actions})
+	}
+
+	timeout, err := getWaitTimeout(c)
+	if err != nil {
+		return err
+	}
+
+	actions, waitErr := waitForActions(c, actions, 5, timeout)
+	if err := c.Display(&displayers.Action{Actions: actions}); err != nil {
+		return err
+	}
+	return waitErr
+}
+
+// tagActionFlag adds the --tag-name flag to an action that can be performed
+// on all the Servers with a tag.
+func tagActionFlag(cmd *Command) {
+	AddStringFlag(cmd, blcli.ArgTagName, "", "", "Perform the action on all the Servers with this tag instead of a single Server")
 }
 
 // ServerAction creates the server-action command.
@@ -59,69 +105,79 @@ func ServerAction() *Command {
 			Short:   "Display server action commands",
 			Long: `Use the subcommands of ` + "`" + `bl compute server-action` + "`" + ` to perform actions on servers.
 
-Servers actions are tasks that can be executed on a server, such as rebooting, resizing, or snapshotting a server.`,
+Servers actions are tasks that can be executed on a server, such as rebooting, resizing, or snapshotting a server.
+
+The power, backup, networking and snapshot actions can also be performed on every Server with a tag, by giving the tag with the ` + "`" + `--tag-name` + "`" + ` flag instead of a Server ID. With ` + "`" + `--wait` + "`" + `, the command waits for all of their actions to complete.`,
 		},
 	}
 
 	cmdServerActionGet := CmdBuilder(cmd, RunServerActionGet, "get <server-id>", "Retrieve a specific Server action", `Use this command to retrieve a Server action.`, Writer,
-		aliasOpt("g"), displayerType(&displayers.Action{}))
+		aliasOpt("g"), displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
 	AddIntFlag(cmdServerActionGet, blcli.ArgActionID, "", 0, "Action ID", requiredOpt())
 
 	cmdServerActionEnableBackups := CmdBuilder(cmd, RunServerActionEnableBackups,
-		"enable-backups <server-id>", "Enable backups on a Server", `Use this command to enable backups on a Server.`, Writer,
-		displayerType(&displayers.Action{}))
-	AddBoolFlag(cmdServerActionEnableBackups, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+		"enable-backups [<server-id>]", "Enable backups on a Server", `Use this command to enable backups on a Server.`, Writer,
+		displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
+	actionWaitFlags(cmdServerActionEnableBackups)
+	tagActionFlag(cmdServerActionEnableBackups)
 
 	cmdServerActionDisableBackups := CmdBuilder(cmd, RunServerActionDisableBackups,
-		"disable-backups <server-id>", "Disable backups on a Server", `Use this command to disable backups on a Server. This does not delete existing backups.`, Writer,
-		displayerType(&displayers.Action{}))
-	AddBoolFlag(cmdServerActionDisableBackups, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+		"disable-backups [<server-id>]", "Disable backups on a Server", `Use this command to disable backups on a Server. This does not delete existing backups.`, Writer,
+		displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
+	actionWaitFlags(cmdServerActionDisableBackups)
+	tagActionFlag(cmdServerActionDisableBackups)
 
 	cmdServerActionReboot := CmdBuilder(cmd, RunServerActionReboot,
 		"reboot <server-id>", "Reboot a Server", `Use this command to reboot a Server.`, Writer,
-		displayerType(&displayers.Action{}))
-	AddBoolFlag(cmdServerActionReboot, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+		displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
+	actionWaitFlags(cmdServerActionReboot)
 
 	cmdServerActionPowerCycle := CmdBuilder(cmd, RunServerActionPowerCycle,
-		"power-cycle <server-id>", "Powercycle a Server", `Use this command to powercycle a Server.`, Writer,
-		displayerType(&displayers.Action{}))
-	AddBoolFlag(cmdServerActionPowerCycle, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+		"power-cycle [<server-id>]", "Powercycle a Server", `Use this command to powercycle a Server.`, Writer,
+		displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
+	actionWaitFlags(cmdServerActionPowerCycle)
+	tagActionFlag(cmdServerActionPowerCycle)
 
 	cmdServerActionShutdown := CmdBuilder(cmd, RunServerActionShutdown,
-		"shutdown <server-id>", "Shut down a Server", `Use this command to shut down a Server. Servers that are powered off are still billable. To stop billing, destroy the Server.`, Writer,
-		displayerType(&displayers.Action{}))
-	AddBoolFlag(cmdServerActionShutdown, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+		"shutdown [<server-id>]", "Shut down a Server", `Use this command to shut down a Server. Servers that are powered off are still billable. To stop billing, destroy the Server.`, Writer,
+		displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
+	actionWaitFlags(cmdServerActionShutdown)
+	tagActionFlag(cmdServerActionShutdown)
 
 	cmdServerActionPowerOff := CmdBuilder(cmd, RunServerActionPowerOff,
-		"power-off <server-id>", "Power off a Server", `Use this command to power off a Server. Servers that are powered off are still billable. To stop billing, destroy the Server.`, Writer,
-		displayerType(&displayers.Action{}))
-	AddBoolFlag(cmdServerActionPowerOff, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+		"power-off [<server-id>]", "Power off a Server", `Use this command to power off a Server. Servers that are powered off are still billable. To stop billing, destroy the Server.`, Writer,
+		displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
+	actionWaitFlags(cmdServerActionPowerOff)
+	tagActionFlag(cmdServerActionPowerOff)
 
 	cmdServerActionPowerOn := CmdBuilder(cmd, RunServerActionPowerOn,
-		"power-on <server-id>", "Power on a Server", `Use this command to power on a Server.`, Writer,
-		displayerType(&displayers.Action{}))
-	AddBoolFlag(cmdServerActionPowerOn, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+		"power-on [<server-id>]", "Power on a Server", `Use this command to power on a Server.`, Writer,
+		displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
+	actionWaitFlags(cmdServerActionPowerOn)
+	tagActionFlag(cmdServerActionPowerOn)
 
 	cmdServerActionPasswordReset := CmdBuilder(cmd, RunServerActionPasswordReset,
 		"password-reset <server-id>", "Reset the root password for a Server", `Use this command to initiate a root password reset on a Server. This also powercycles the Server.`, Writer,
-		displayerType(&displayers.Action{}))
-	AddBoolFlag(cmdServerActionPasswordReset, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+		displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
+	actionWaitFlags(cmdServerActionPasswordReset)
 
 	cmdServerActionEnableIPv6 := CmdBuilder(cmd, RunServerActionEnableIPv6,
-		"enable-ipv6 <server-id>", "Enable IPv6 on a Server", `Use this command to enable IPv6 networking on a Server. BinaryLane will automatically assign an IPv6 address to the Server.`, Writer,
-		displayerType(&displayers.Action{}))
-	AddBoolFlag(cmdServerActionEnableIPv6, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+		"enable-ipv6 [<server-id>]", "Enable IPv6 on a Server", `Use this command to enable IPv6 networking on a Server. BinaryLane will automatically assign an IPv6 address to the Server.`, Writer,
+		displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
+	actionWaitFlags(cmdServerActionEnableIPv6)
+	tagActionFlag(cmdServerActionEnableIPv6)
 
 	cmdServerActionEnablePrivateNetworking := CmdBuilder(cmd, RunServerActionEnablePrivateNetworking,
-		"enable-private-networking <server-id>", "Enable private networking on a Server", `Use this command to enable private networking on a Server. This adds a private IPv4 address to the Server that other Servers inside the network can access. The Server will require additional internal network configuration for it to become accessible through the private network.`, Writer,
-		displayerType(&displayers.Action{}))
-	AddBoolFlag(cmdServerActionEnablePrivateNetworking, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+		"enable-private-networking [<server-id>]", "Enable private networking on a Server", `Use this command to enable private networking on a Server. This adds a private IPv4 address to the Server that other Servers inside the network can access. The Server will require additional internal network configuration for it to become accessible through the private network.`, Writer,
+		displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
+	actionWaitFlags(cmdServerActionEnablePrivateNetworking)
+	tagActionFlag(cmdServerActionEnablePrivateNetworking)
 
 	cmdServerActionRestore := CmdBuilder(cmd, RunServerActionRestore,
 		"restore <server-id>", "Restore a Server from a backup", `Use this command to restore a Server from a backup.`, Writer,
-		displayerType(&displayers.Action{}))
+		displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
 	AddIntFlag(cmdServerActionRestore, blcli.ArgImageID, "", 0, "Image ID", requiredOpt())
-	AddBoolFlag(cmdServerActionRestore, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+	actionWaitFlags(cmdServerActionRestore)
 
 	serverResizeDesc := `Use this command to resize a Server to a different plan.
 
@@ -132,34 +188,35 @@ To also increase the Server's disk size, pass the ` + "`--resize-disk`" + ` flag
 In order to resize a Server, it must first be powered off.`
 	cmdServerActionResize := CmdBuilder(cmd, RunServerActionResize,
 		"resize <server-id>", "Resize a Server", serverResizeDesc, Writer,
-		displayerType(&displayers.Action{}))
+		displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
 	AddBoolFlag(cmdServerActionResize, blcli.ArgResizeDisk, "", false, "Resize the Server's disk size in addition to its RAM and CPU.")
-	AddStringFlag(cmdServerActionResize, blcli.ArgSizeSlug, "", "", "A slug indicating the new size for the Server (e.g. `s-2vcpu-2gb`). Run `bl compute size list` for a list of valid sizes.", requiredOpt())
-	AddBoolFlag(cmdServerActionResize, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+	AddStringFlag(cmdServerActionResize, blcli.ArgSizeSlug, "", "", "A slug indicating the new size for the Server (e.g. `s-2vcpu-2gb`). Run `bl compute size list` for a list of valid sizes.", requiredOpt(), completeFlagOpt(completeSizes))
+	actionWaitFlags(cmdServerActionResize)
 
 	cmdServerActionRebuild := CmdBuilder(cmd, RunServerActionRebuild,
 		"rebuild <server-id>", "Rebuild a Server", `Use this command to rebuild a Server from an image.`, Writer,
-		displayerType(&displayers.Action{}))
-	AddStringFlag(cmdServerActionRebuild, blcli.ArgImage, "", "", "Image ID or Slug", requiredOpt())
-	AddBoolFlag(cmdServerActionRebuild, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+		displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
+	AddStringFlag(cmdServerActionRebuild, blcli.ArgImage, "", "", "Image ID or Slug", requiredOpt(), completeFlagOpt(completeImages))
+	actionWaitFlags(cmdServerActionRebuild)
 
 	cmdServerActionRename := CmdBuilder(cmd, RunServerActionRename,
 		"rename <server-id>", "Rename a Server", `Use this command to rename a Server. When using a fully qualified domain name (FQDN) this also updates the pointer (PTR) record.`, Writer,
-		displayerType(&displayers.Action{}))
+		displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
 	AddStringFlag(cmdServerActionRename, blcli.ArgServerName, "", "", "Server name", requiredOpt())
-	AddBoolFlag(cmdServerActionRename, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+	actionWaitFlags(cmdServerActionRename)
 
 	cmdServerActionChangeKernel := CmdBuilder(cmd, RunServerActionChangeKernel,
 		"change-kernel <server-id>", "Change a Server's kernel", `Use this command to change a Server's kernel.`, Writer,
-		displayerType(&displayers.Action{}))
+		displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
 	AddIntFlag(cmdServerActionChangeKernel, blcli.ArgKernelID, "", 0, "Kernel ID", requiredOpt())
-	AddBoolFlag(cmdServerActionChangeKernel, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+	actionWaitFlags(cmdServerActionChangeKernel)
 
 	cmdServerActionSnapshot := CmdBuilder(cmd, RunServerActionSnapshot,
-		"snapshot <server-id>", "Take a Server snapshot", `Use this command to take a snapshot of a Server. We recommend that you power off the Server before taking a snapshot to ensure data consistency.`, Writer,
-		displayerType(&displayers.Action{}))
+		"snapshot [<server-id>]", "Take a Server snapshot", `Use this command to take a snapshot of a Server. We recommend that you power off the Server before taking a snapshot to ensure data consistency.`, Writer,
+		displayerType(&displayers.Action{}), completeArgsOpt(completeServerIDs))
 	AddStringFlag(cmdServerActionSnapshot, blcli.ArgSnapshotName, "", "", "Snapshot name", requiredOpt())
-	AddBoolFlag(cmdServerActionSnapshot, blcli.ArgCommandWait, "", false, "Wait for action to complete")
+	actionWaitFlags(cmdServerActionSnapshot)
+	tagActionFlag(cmdServerActionSnapshot)
 
 	return cmd
 }
@@ -204,7 +261,11 @@ func RunServerActionEnableBackups(c *CmdConfig) error {
 		return a, err
 	}
 
-	return performAction(c, fn)
+	tagFn := func(das bl.ServerActionsService, tag string) (bl.Actions, error) {
+		return das.EnableBackupsByTag(tag)
+	}
+
+	return performTagAction(c, fn, tagFn)
 }
 
 // RunServerActionDisableBackups disables backups for a server.
@@ -223,7 +284,11 @@ func RunServerActionDisableBackups(c *CmdConfig) error {
 		return a, err
 	}
 
-	return performAction(c, fn)
+	tagFn := func(das bl.ServerActionsService, tag string) (bl.Actions, error) {
+		return das.DisableBackupsByTag(tag)
+	}
+
+	return performTagAction(c, fn, tagFn)
 }
 
 // RunServerActionReboot reboots a server.
@@ -262,7 +327,11 @@ func RunServerActionPowerCycle(c *CmdConfig) error {
 		return a, err
 	}
 
-	return performAction(c, fn)
+	tagFn := func(das bl.ServerActionsService, tag string) (bl.Actions, error) {
+		return das.PowerCycleByTag(tag)
+	}
+
+	return performTagAction(c, fn, tagFn)
 }
 
 // RunServerActionShutdown shuts a server down.
@@ -281,7 +350,11 @@ func RunServerActionShutdown(c *CmdConfig) error {
 		return a, err
 	}
 
-	return performAction(c, fn)
+	tagFn := func(das bl.ServerActionsService, tag string) (bl.Actions, error) {
+		return das.ShutdownByTag(tag)
+	}
+
+	return performTagAction(c, fn, tagFn)
 }
 
 // RunServerActionPowerOff turns server power off.
@@ -301,7 +374,11 @@ func RunServerActionPowerOff(c *CmdConfig) error {
 		return a, err
 	}
 
-	return performAction(c, fn)
+	tagFn := func(das bl.ServerActionsService, tag string) (bl.Actions, error) {
+		return das.PowerOffByTag(tag)
+	}
+
+	return performTagAction(c, fn, tagFn)
 }
 
 // RunServerActionPowerOn turns server power on.
@@ -321,7 +398,11 @@ func RunServerActionPowerOn(c *CmdConfig) error {
 		return a, err
 	}
 
-	return performAction(c, fn)
+	tagFn := func(das bl.ServerActionsService, tag string) (bl.Actions, error) {
+		return das.PowerOnByTag(tag)
+	}
+
+	return performTagAction(c, fn, tagFn)
 }
 
 // RunServerActionPasswordReset resets the server root password.
@@ -361,7 +442,11 @@ func RunServerActionEnableIPv6(c *CmdConfig) error {
 		return a, err
 	}
 
-	return performAction(c, fn)
+	tagFn := func(das bl.ServerActionsService, tag string) (bl.Actions, error) {
+		return das.EnableIPv6ByTag(tag)
+	}
+
+	return performTagAction(c, fn, tagFn)
 }
 
 // RunServerActionEnablePrivateNetworking enables private networking for a server.
@@ -381,7 +466,11 @@ func RunServerActionEnablePrivateNetworking(c *CmdConfig) error {
 		return a, err
 	}
 
-	return performAction(c, fn)
+	tagFn := func(das bl.ServerActionsService, tag string) (bl.Actions, error) {
+		return das.EnablePrivateNetworkingByTag(tag)
+	}
+
+	return performTagAction(c, fn, tagFn)
 }
 
 // RunServerActionRestore restores a server using an image id.
@@ -542,5 +631,14 @@ func RunServerActionSnapshot(c *CmdConfig) error {
 		return a, err
 	}
 
-	return performAction(c, fn)
+	tagFn := func(das bl.ServerActionsService, tag string) (bl.Actions, error) {
+		name, err := c.Doit.GetString(c.NS, blcli.ArgSnapshotName)
+		if err != nil {
+			return nil, err
+		}
+
+		return das.SnapshotByTag(tag, name)
+	}
+
+	return performTagAction(c, fn, tagFn)
 }
@@ -17,6 +17,8 @@ import (
 	"testing"
 
 	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/go-binarylane"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -236,3 +238,41 @@ func TestServerActionsSnapshot(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestServerActionsShutdownByTag(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.serverActions.EXPECT().ShutdownByTag("web").Return(bl.Actions{testAction, testAction}, nil)
+
+		config.Doit.Set(config.NS, blcli.ArgTagName, "web")
+
+		err := RunServerActionShutdown(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestServerActionsSnapshotByTagWait(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		first := bl.Action{Action: &binarylane.Action{ID: 10}}
+		second := bl.Action{Action: &binarylane.Action{ID: 11}}
+		tm.serverActions.EXPECT().SnapshotByTag("web", "nightly").Return(bl.Actions{first, second}, nil)
+		tm.actions.EXPECT().Get(10).Return(&bl.Action{Action: &binarylane.Action{ID: 10, Status: "completed"}}, nil)
+		tm.actions.EXPECT().Get(11).Return(&bl.Action{Action: &binarylane.Action{ID: 11, Status: "completed"}}, nil)
+
+		config.Doit.Set(config.NS, blcli.ArgTagName, "web")
+		config.Doit.Set(config.NS, blcli.ArgSnapshotName, "nightly")
+		config.Doit.Set(config.NS, blcli.ArgCommandWait, true)
+
+		err := RunServerActionSnapshot(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestServerActionsTagAndServerID(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Doit.Set(config.NS, blcli.ArgTagName, "web")
+		config.Args = append(config.Args, "1")
+
+		err := RunServerActionPowerOn(config)
+		assert.EqualError(t, err, "Please specify Server identifier or a tag name.")
+	})
+}
@@ -0,0 +1,288 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/binarylane/go-binarylane"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// maxServerNames is the most Servers a name pattern can expand to, so that
+// a mistyped range doesn't create thousands of them.
+const maxServerNames = 1000
+
+// serverSpecFile is a file of the Servers for server create to create.
+type serverSpecFile struct {
+	Servers []serverSpec `yaml:"servers"`
+}
+
+// serverSpec describes Servers to create. The name can be a pattern that
+// expands to the names of several Servers, and the fields that are left
+// out are taken from the flags of server create.
+type serverSpec struct {
+	Name              string   `yaml:"name"`
+	Region            string   `yaml:"region"`
+	Size              string   `yaml:"size"`
+	Image             string   `yaml:"image"`
+	SSHKeys           []string `yaml:"ssh_keys"`
+	UserData          string   `yaml:"user_data"`
+	UserDataFile      fileList `yaml:"user_data_file"`
+	Tags              []string `yaml:"tags"`
+	VPCID             int      `yaml:"vpc_id"`
+	Volumes           []string `yaml:"volumes"`
+	Backups           *bool    `yaml:"backups"`
+	IPv6              *bool    `yaml:"ipv6"`
+	PrivateNetworking *bool    `yaml:"private_networking"`
+	Monitoring        *bool    `yaml:"monitoring"`
+}
+
+// readServerSpecFile reads a file of server specs in YAML or JSON format. A
+// filename of "-" reads the specs from standard input.
+func readServerSpecFile(filename string) (*serverSpecFile, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if filename == "-" {
+		b, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		b, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f serverSpecFile
+	if err := yaml.UnmarshalStrict(b, &f); err != nil {
+		return nil, fmt.Errorf("Unable to parse server spec file: %v", err)
+	}
+	if len(f.Servers) == 0 {
+		return nil, fmt.Errorf("Invalid server spec file: no servers are declared")
+	}
+	return &f, nil
+}
+
+// fileList is one filename, or a list of them.
+type fileList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (l *fileList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var filename string
+	if err := unmarshal(&filename); err == nil {
+		*l = fileList{filename}
+		return nil
+	}
+	return unmarshal((*[]string)(l))
+}
+
+// serverCreateRequests builds the requests to create the Servers of the
+// specs. base and ud hold the values of the flags, which the specs
+// override. The user-data files of the specs are relative to dir, and the
+// user-data is rendered for each Server.
+func (f *serverSpecFile) serverCreateRequests(base *binarylane.ServerCreateRequest, ud *userData, dir string) ([]*binarylane.ServerCreateRequest, error) {
+	var requests []*binarylane.ServerCreateRequest
+	seen := map[string]bool{}
+
+	for i, spec := range f.Servers {
+		if spec.Name == "" {
+			return nil, fmt.Errorf("Invalid server spec file: server %d requires a name", i+1)
+		}
+
+		names, err := expandServerName(spec.Name)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid server spec file: %v", err)
+		}
+
+		dcr, parts, err := spec.serverCreateRequest(base, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		specUserData := ud
+		if parts != nil {
+			specUserData = ud.withParts(parts)
+		}
+
+		for _, name := range names {
+			if seen[name] {
+				return nil, fmt.Errorf("Invalid server spec file: server %q is declared more than once", name)
+			}
+			seen[name] = true
+
+			r := *dcr
+			r.Name = name
+			if r.UserData, err = specUserData.render(&r, len(requests)); err != nil {
+				return nil, err
+			}
+			requests = append(requests, &r)
+		}
+	}
+
+	return requests, nil
+}
+
+// serverCreateRequest builds the request of a spec, without its name or
+// user-data. The parts of the spec's user-data are nil when it has none.
+func (s *serverSpec) serverCreateRequest(base *binarylane.ServerCreateRequest, dir string) (*binarylane.ServerCreateRequest, []userDataPart, error) {
+	dcr := *base
+
+	if s.Region != "" {
+		dcr.Region = s.Region
+	}
+	if s.Size != "" {
+		dcr.Size = s.Size
+	}
+	if s.Image != "" {
+		dcr.Image = serverCreateImage(s.Image)
+	}
+	if len(s.SSHKeys) > 0 {
+		dcr.SSHKeys = extractSSHKeys(s.SSHKeys)
+	}
+	if len(s.Tags) > 0 {
+		dcr.Tags = s.Tags
+	}
+	if s.VPCID != 0 {
+		dcr.VPCID = s.VPCID
+	}
+	if len(s.Volumes) > 0 {
+		dcr.Volumes = extractVolumes(s.Volumes)
+	}
+	if s.Backups != nil {
+		dcr.Backups = *s.Backups
+	}
+	if s.IPv6 != nil {
+		dcr.IPv6 = *s.IPv6
+	}
+	if s.PrivateNetworking != nil {
+		dcr.PrivateNetworking = *s.PrivateNetworking
+	}
+	if s.Monitoring != nil {
+		dcr.Monitoring = *s.Monitoring
+	}
+
+	var parts []userDataPart
+	if s.UserData != "" || len(s.UserDataFile) > 0 {
+		var err error
+		if parts, err = readUserDataParts(s.UserData, s.UserDataFile, dir); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	image := dcr.Image.Slug
+	if dcr.Image.ID != 0 {
+		image = strconv.Itoa(dcr.Image.ID)
+	}
+	required := []struct{ field, value string }{
+		{"region", dcr.Region},
+		{"size", dcr.Size},
+		{"image", image},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			return nil, nil, fmt.Errorf("Invalid server spec file: server %q is missing %q and the --%s flag isn't set", s.Name, r.field, r.field)
+		}
+	}
+
+	return &dcr, parts, nil
+}
+
+// serverCreateImage returns the image of a create request, which is given by
+// its ID or slug.
+func serverCreateImage(image string) binarylane.ServerCreateImage {
+	if i, err := strconv.Atoi(image); err == nil {
+		return binarylane.ServerCreateImage{ID: i}
+	}
+	return binarylane.ServerCreateImage{Slug: image}
+}
+
+var (
+	namePatternRE = regexp.MustCompile(`\{([^{}]*)\}`)
+	nameRangeRE   = regexp.MustCompile(`^(\d+)\.\.(\d+)$`)
+)
+
+// expandServerName expands the patterns in a Server name. {01..12} is a
+// range of numbers, zero-padded when either end is, and {a,b} is a list of
+// alternatives. The names of several patterns are every combination of
+// them.
+func expandServerName(pattern string) ([]string, error) {
+	if strings.ContainsAny(namePatternRE.ReplaceAllString(pattern, ""), "{}") {
+		return nil, fmt.Errorf("name %q has an unmatched brace", pattern)
+	}
+
+	names, err := expandNamePatterns(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("name %q %v", pattern, err)
+	}
+	return names, nil
+}
+
+func expandNamePatterns(pattern string) ([]string, error) {
+	loc := namePatternRE.FindStringSubmatchIndex(pattern)
+	if loc == nil {
+		return []string{pattern}, nil
+	}
+	prefix, inner, suffix := pattern[:loc[0]], pattern[loc[2]:loc[3]], pattern[loc[1]:]
+
+	var alternatives []string
+	if m := nameRangeRE.FindStringSubmatch(inner); m != nil {
+		start, _ := strconv.Atoi(m[1])
+		end, _ := strconv.Atoi(m[2])
+
+		width := 0
+		if (len(m[1]) > 1 && m[1][0] == '0') || (len(m[2]) > 1 && m[2][0] == '0') {
+			width = len(m[1])
+			if len(m[2]) > width {
+				width = len(m[2])
+			}
+		}
+
+		step := 1
+		if end < start {
+			step = -1
+		}
+		for i := start; ; i += step {
+			alternatives = append(alternatives, fmt.Sprintf("%0*d", width, i))
+			if i == end || len(alternatives) > maxServerNames {
+				break
+			}
+		}
+	} else if strings.Contains(inner, ",") {
+		alternatives = strings.Split(inner, ",")
+	} else {
+		return nil, fmt.Errorf("has an invalid pattern {%s}. Use a range such as {1..3} or a list such as {a,b}", inner)
+	}
+
+	rest, err := expandNamePatterns(suffix)
+	if err != nil {
+		return nil, err
+	}
+	if len(alternatives)*len(rest) > maxServerNames {
+		return nil, fmt.Errorf("expands to more than %d servers", maxServerNames)
+	}
+
+	var names []string
+	for _, a := range alternatives {
+		for _, r := range rest {
+			names = append(names, prefix+a+r)
+		}
+	}
+	return names, nil
+}
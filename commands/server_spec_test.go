@@ -0,0 +1,212 @@
+package commands
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/go-binarylane"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandServerName(t *testing.T) {
+	tests := []struct {
+		pattern string
+		names   []string
+		err     string
+	}{
+		{pattern: "web.example.com", names: []string{"web.example.com"}},
+		{pattern: "web-{1..3}", names: []string{"web-1", "web-2", "web-3"}},
+		{pattern: "web-{08..10}", names: []string{"web-08", "web-09", "web-10"}},
+		{pattern: "web-{3..1}", names: []string{"web-3", "web-2", "web-1"}},
+		{pattern: "{app,db}-{1..2}", names: []string{"app-1", "app-2", "db-1", "db-2"}},
+		{pattern: "web-{1..3", err: `name "web-{1..3" has an unmatched brace`},
+		{pattern: "web-{x}", err: `name "web-{x}" has an invalid pattern {x}. Use a range such as {1..3} or a list such as {a,b}`},
+		{pattern: "web-{1..5000}", err: `name "web-{1..5000}" expands to more than 1000 servers`},
+		{pattern: "web-{1..100}-{1..100}", err: `name "web-{1..100}-{1..100}" expands to more than 1000 servers`},
+	}
+
+	for _, tt := range tests {
+		names, err := expandServerName(tt.pattern)
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err, tt.pattern)
+			continue
+		}
+		require.NoError(t, err, tt.pattern)
+		assert.Equal(t, tt.names, names, tt.pattern)
+	}
+}
+
+func writeServerSpecFile(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "bl-server-spec")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	return filepath.Join(dir, "servers.yaml")
+}
+
+func TestServerCreateFile(t *testing.T) {
+	filename := writeServerSpecFile(t, map[string]string{
+		"servers.yaml": `servers:
+  - name: web-{1..2}
+    tags: [web]
+  - name: db
+    size: std-4vcpu
+    image: "42"
+    user_data_file: db-init.yaml
+    backups: true
+    ipv6: false
+`,
+		"db-init.yaml": "#cloud-config\n",
+	})
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		web := func(name string) *binarylane.ServerCreateRequest {
+			return &binarylane.ServerCreateRequest{
+				Name:    name,
+				Region:  "syd",
+				Size:    "std-min",
+				Image:   binarylane.ServerCreateImage{Slug: "ubuntu"},
+				SSHKeys: []binarylane.ServerCreateSSHKey{},
+				Tags:    []string{"web"},
+				IPv6:    true,
+			}
+		}
+		tm.servers.EXPECT().Create(web("web-1"), true).Return(&testServer, nil)
+		tm.servers.EXPECT().Create(web("web-2"), true).Return(&testServer, nil)
+		tm.servers.EXPECT().Create(&binarylane.ServerCreateRequest{
+			Name:     "db",
+			Region:   "syd",
+			Size:     "std-4vcpu",
+			Image:    binarylane.ServerCreateImage{ID: 42},
+			SSHKeys:  []binarylane.ServerCreateSSHKey{},
+			UserData: "#cloud-config\n",
+			Backups:  true,
+		}, true).Return(&testServer, nil)
+
+		config.Doit.Set(config.NS, blcli.ArgStackFile, filename)
+		config.Doit.Set(config.NS, blcli.ArgRegionSlug, "syd")
+		config.Doit.Set(config.NS, blcli.ArgSizeSlug, "std-min")
+		config.Doit.Set(config.NS, blcli.ArgImage, "ubuntu")
+		config.Doit.Set(config.NS, blcli.ArgCommandWait, true)
+		config.Doit.Set(config.NS, blcli.ArgConcurrency, 2)
+		// The db spec turns off the IPv6 of the flag.
+		config.Doit.Set(config.NS, blcli.ArgIPv6, true)
+
+		err := RunServerCreate(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestServerCreateFilePartialFailure(t *testing.T) {
+	filename := writeServerSpecFile(t, map[string]string{
+		"servers.yaml": `servers:
+  - name: web-{1..3}
+    region: syd
+    size: std-min
+    image: ubuntu
+`,
+	})
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.servers.EXPECT().Create(gomock.Any(), false).DoAndReturn(func(dcr *binarylane.ServerCreateRequest, wait bool) (*bl.Server, error) {
+			if dcr.Name == "web-2" {
+				return nil, errors.New("size unavailable")
+			}
+			return &testServer, nil
+		}).Times(3)
+
+		config.Doit.Set(config.NS, blcli.ArgStackFile, filename)
+
+		err := RunServerCreate(config)
+		assert.EqualError(t, err, "1 of 3 Servers could not be created")
+	})
+}
+
+func TestServerCreateFileInvalid(t *testing.T) {
+	tests := []struct {
+		spec    string
+		noFlags bool
+		err     string
+	}{
+		{spec: "servers: []\n", err: "Invalid server spec file: no servers are declared"},
+		{spec: "servers:\n  - name: web\n    colour: blue\n", err: "Unable to parse server spec file: yaml: unmarshal errors:\n  line 3: field colour not found in type commands.serverSpec"},
+		{spec: "servers:\n  - region: syd\n", err: "Invalid server spec file: server 1 requires a name"},
+		{spec: "servers:\n  - name: web\n    size: std-min\n    image: ubuntu\n", noFlags: true, err: `Invalid server spec file: server "web" is missing "region" and the --region flag isn't set`},
+		{spec: "servers:\n  - name: web-{1..2}\n  - name: web-2\n", err: `Invalid server spec file: server "web-2" is declared more than once`},
+	}
+
+	for _, tt := range tests {
+		filename := writeServerSpecFile(t, map[string]string{"servers.yaml": tt.spec})
+
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			config.Doit.Set(config.NS, blcli.ArgStackFile, filename)
+			if !tt.noFlags {
+				config.Doit.Set(config.NS, blcli.ArgRegionSlug, "syd")
+				config.Doit.Set(config.NS, blcli.ArgSizeSlug, "std-min")
+				config.Doit.Set(config.NS, blcli.ArgImage, "ubuntu")
+			}
+
+			err := RunServerCreate(config)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestServerCreateFileAndNames(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = []string{"web"}
+		config.Doit.Set(config.NS, blcli.ArgStackFile, "servers.yaml")
+
+		err := RunServerCreate(config)
+		assert.Error(t, err)
+		assert.Equal(t, ExitUsage, describeErr(err).ExitCode)
+	})
+}
+
+func TestServerCreateFileUserData(t *testing.T) {
+	filename := writeServerSpecFile(t, map[string]string{
+		"servers.yaml": `servers:
+  - name: web-{1..2}
+    user_data_file: [cloud.yaml, setup.sh]
+  - name: db
+`,
+		"cloud.yaml": "#cloud-config\nhostname: {{.Name}}\n",
+		"setup.sh":   "#!/bin/sh\necho {{.Index}}\n",
+		"flags.yaml": "#cloud-config\nfqdn: {{.Name}}.example.com\n",
+	})
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		userData := map[string]string{}
+		tm.servers.EXPECT().Create(gomock.Any(), false).DoAndReturn(func(dcr *binarylane.ServerCreateRequest, wait bool) (*bl.Server, error) {
+			userData[dcr.Name] = dcr.UserData
+			return &testServer, nil
+		}).Times(3)
+
+		config.Doit.Set(config.NS, blcli.ArgStackFile, filename)
+		config.Doit.Set(config.NS, blcli.ArgRegionSlug, "syd")
+		config.Doit.Set(config.NS, blcli.ArgSizeSlug, "std-min")
+		config.Doit.Set(config.NS, blcli.ArgImage, "ubuntu")
+		config.Doit.Set(config.NS, blcli.ArgUserDataFile, []string{filepath.Join(filepath.Dir(filename), "flags.yaml")})
+		config.Doit.Set(config.NS, blcli.ArgUserDataTemplate, true)
+		config.Doit.Set(config.NS, blcli.ArgConcurrency, 1)
+
+		err := RunServerCreate(config)
+		require.NoError(t, err)
+
+		for i, name := range []string{"web-1", "web-2"} {
+			_, contents := readMultipartUserData(t, userData[name])
+			assert.Equal(t, []string{"#cloud-config\nhostname: " + name + "\n", "#!/bin/sh\necho " + string(rune('0'+i)) + "\n"}, contents)
+		}
+		assert.Equal(t, "#cloud-config\nfqdn: db.example.com\n", userData["db"])
+	})
+}
@@ -14,8 +14,9 @@ limitations under the License.
 package commands
 
 import (
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -58,49 +59,74 @@ func Server() *Command {
 	- The IDs of block storage volumes attached to the Server
 	`
 	CmdBuilder(cmd, RunServerActions, "actions <server-id>", "List Server actions", `Use this command to list the available actions that can be taken on a Server. These can be things like rebooting, resizing, and snapshotting the Server.`, Writer,
-		aliasOpt("a"), displayerType(&displayers.Action{}))
+		aliasOpt("a"), displayerType(&displayers.Action{}), listOpt(), completeArgsOpt(completeServerIDs))
 
 	CmdBuilder(cmd, RunServerBackups, "backups <server-id>", "List Server backups", `Use this command to list Server backups.`, Writer,
-		aliasOpt("b"), displayerType(&displayers.Image{}))
+		aliasOpt("b"), displayerType(&displayers.Image{}), listOpt(), completeArgsOpt(completeServerIDs))
 
 	serverCreateLongDesc := `Use this command to create a new Server. Required values are name, region, size, and image. For example, to create an Ubuntu 20.04 with 1 vCPU and 1 GB of RAM in the Sydney region, run:
 
 	bl compute server create --image ubuntu-20-04-lts --size std-min --region syd example.com
+
+To create several different Servers at once, list them in a YAML or JSON file and pass it with the ` + "`" + `--file` + "`" + ` (` + "`" + `-f` + "`" + `) flag instead of names. Each server in the file takes the keys name, region, size, image, ssh_keys, user_data, user_data_file, tags, vpc_id, volumes, backups, ipv6, private_networking and monitoring; those that are left out are taken from the flags. A name such as ` + "`" + `web-{01..12}.example.com` + "`" + ` or ` + "`" + `{app,db}.example.com` + "`" + ` creates a Server for each name it expands to:
+
+	servers:
+	  - name: web-{01..12}.example.com
+	    size: std-1vcpu
+	    tags: [web]
+	  - name: db.example.com
+	    size: std-4vcpu
+	    user_data_file: db-init.yaml
+
+	bl compute server create --file servers.yaml --image ubuntu-20-04-lts --region syd --wait
+
+Up to ` + "`" + `--concurrency` + "`" + ` Servers are created at once. The Servers that were created are displayed even when some of them could not be, and each failure is reported on standard error.
+
+With ` + "`" + `--user-data-template` + "`" + `, the user-data is rendered as a Go template for each Server. Templates can use the Server's ` + "`" + `{{.Name}}` + "`" + `, ` + "`" + `{{.Index}}` + "`" + ` among those being created starting at 0, ` + "`" + `{{.Region}}` + "`" + `, ` + "`" + `{{.Size}}` + "`" + `, ` + "`" + `{{.Image}}` + "`" + `, ` + "`" + `{{.Tags}}` + "`" + ` and the public keys of its ` + "`" + `{{.SSHKeys}}` + "`" + `, the environment as ` + "`" + `{{.Env.NAME}}` + "`" + `, and the ` + "`" + `{{.Values}}` + "`" + ` of ` + "`" + `--user-data-values` + "`" + ` and ` + "`" + `--user-data-var` + "`" + `. Cloud-config user-data must be valid YAML. Several user-data files, given by repeating ` + "`" + `--user-data-file` + "`" + ` or as a list in a spec file, are combined in a multipart MIME document, and ` + "`" + `--user-data-gzip` + "`" + ` compresses them:
+
+	bl compute server create --file servers.yaml --user-data-file cloud.yaml --user-data-file setup.sh --user-data-var domain=example.com
 `
 
-	cmdServerCreate := CmdBuilder(cmd, RunServerCreate, "create <server-name>...", "Create a new Server", serverCreateLongDesc, Writer,
+	cmdServerCreate := CmdBuilder(cmd, RunServerCreate, "create [<server-name>...]", "Create a new Server", serverCreateLongDesc, Writer,
 		aliasOpt("c"), displayerType(&displayers.Server{}))
-	AddStringSliceFlag(cmdServerCreate, blcli.ArgSSHKeys, "", []string{}, "A list of SSH key fingerprints or IDs of the SSH keys to embed in the Server's root account upon creation")
+	AddStringSliceFlag(cmdServerCreate, blcli.ArgSSHKeys, "", []string{}, "A list of SSH key fingerprints or IDs of the SSH keys to embed in the Server's root account upon creation",
+		completeFlagOpt(completeSSHKeys))
 	AddStringFlag(cmdServerCreate, blcli.ArgUserData, "", "", "User-data to configure the Server on first boot")
-	AddStringFlag(cmdServerCreate, blcli.ArgUserDataFile, "", "", "The path to a file containing user-data to configure the Server on first boot")
+	AddStringSliceFlag(cmdServerCreate, blcli.ArgUserDataFile, "", []string{}, "The path to a file containing user-data to configure the Server on first boot. Pass more than one file, or a file and --user-data, to combine them in a multipart MIME document")
+	AddBoolFlag(cmdServerCreate, blcli.ArgUserDataTemplate, "", false, "Render the user-data as a Go template for each Server")
+	AddStringSliceFlag(cmdServerCreate, blcli.ArgUserDataVar, "", []string{}, "A value for user-data templates, in the format `key=value`. Implies --user-data-template")
+	AddStringFlag(cmdServerCreate, blcli.ArgUserDataValues, "", "", "The path to a YAML or JSON file of values for user-data templates. Implies --user-data-template")
+	AddBoolFlag(cmdServerCreate, blcli.ArgUserDataGzip, "", false, "Compress the user-data with gzip, in a multipart MIME document")
 	AddBoolFlag(cmdServerCreate, blcli.ArgCommandWait, "", false, "Wait for Server creation to complete before returning")
 	AddStringFlag(cmdServerCreate, blcli.ArgRegionSlug, "", "", "A slug indicating the region where the Server will be created (e.g. `syd`). Run `bl compute region list` for a list of valid regions.",
-		requiredOpt())
+		requiredOpt(), completeFlagOpt(completeRegions))
 	AddStringFlag(cmdServerCreate, blcli.ArgSizeSlug, "", "", "A slug indicating the size of the Server (e.g. `std-min`). Run `bl compute size list` for a list of valid sizes.",
-		requiredOpt())
+		requiredOpt(), completeFlagOpt(completeSizes))
 	AddBoolFlag(cmdServerCreate, blcli.ArgBackups, "", false, "Enables backups for the Server")
 	AddBoolFlag(cmdServerCreate, blcli.ArgIPv6, "", false, "Enables IPv6 support and assigns an IPv6 address")
 	AddBoolFlag(cmdServerCreate, blcli.ArgPrivateNetworking, "", false, "Enables private networking for the Server by provisioning it inside of your account's default VPC for the region")
 	AddBoolFlag(cmdServerCreate, blcli.ArgMonitoring, "", false, "Install the BinaryLane agent for additional monitoring")
 	AddStringFlag(cmdServerCreate, blcli.ArgImage, "", "", "An ID or slug indicating the image the Server will be based-on (e.g. `ubuntu-20-04-lts`). Use the commands under `bl compute image` to find additional images.",
-		requiredOpt())
+		requiredOpt(), completeFlagOpt(completeImages))
 	AddStringFlag(cmdServerCreate, blcli.ArgTagName, "", "", "A tag name to be applied to the Server")
 	AddStringFlag(cmdServerCreate, blcli.ArgVPCID, "", "", "The ID of a non-default VPC to create the Server in")
 	AddStringSliceFlag(cmdServerCreate, blcli.ArgTagNames, "", []string{}, "A list of tag names to be applied to the Server")
 
 	AddStringSliceFlag(cmdServerCreate, blcli.ArgVolumeList, "", []string{}, "A list of block storage volume IDs to attach to the Server")
+	AddStringFlag(cmdServerCreate, blcli.ArgStackFile, blcli.ArgShortFile, "", "The path to a YAML or JSON file of the Servers to create, or `-` to read it from standard input")
+	AddIntFlag(cmdServerCreate, blcli.ArgConcurrency, "", 5, "The number of Servers to create at once")
 
 	cmdRunServerDelete := CmdBuilder(cmd, RunServerDelete, "delete <server-id|server-name>...", "Permanently delete a Server", `Use this command to permanently delete a Server. This is irreversible.`, Writer,
-		aliasOpt("d", "del", "rm"))
+		aliasOpt("d", "del", "rm"), completeArgsOpt(completeServers))
 	AddBoolFlag(cmdRunServerDelete, blcli.ArgForce, blcli.ArgShortForce, false, "Delete the Server without a confirmation prompt")
 	AddStringFlag(cmdRunServerDelete, blcli.ArgTagName, "", "", "Tag name")
 
 	cmdRunServerGet := CmdBuilder(cmd, RunServerGet, "get <server-id|server-name>", "Retrieve information about a Server", `Use this command to retrieve information about a Server, including:`+serverDetails, Writer,
-		aliasOpt("g"), displayerType(&displayers.Server{}))
+		aliasOpt("g"), displayerType(&displayers.Server{}), completeArgsOpt(completeServers))
 	AddStringFlag(cmdRunServerGet, blcli.ArgTemplate, "", "", "Go template format. Sample values: `{{.ID}}`, `{{.Name}}`, `{{.Memory}}`, `{{.Region.Name}}`, `{{.Image}}`, `{{.Tags}}`")
 
 	CmdBuilder(cmd, RunServerKernels, "kernels <server-id>", "List available Server kernels", `Use this command to retrieve a list of all kernels available to a Server.`, Writer,
-		aliasOpt("k"), displayerType(&displayers.Kernel{}))
+		aliasOpt("k"), displayerType(&displayers.Kernel{}), listOpt(), completeArgsOpt(completeServerIDs))
 
 	cmdRunServerList := CmdBuilder(cmd, RunServerList, "list [GLOB]", "List Servers on your account", `Use this command to retrieve a list of Servers, including the following information about each:`+serverDetails, Writer,
 		aliasOpt("ls"), displayerType(&displayers.Server{}))
@@ -108,16 +134,18 @@ func Server() *Command {
 	AddStringFlag(cmdRunServerList, blcli.ArgTagName, "", "", "Tag name")
 
 	CmdBuilder(cmd, RunServerNeighbors, "neighbors <server-id>", "List a Server's neighbors on your account", `Use this command to get a list of your Servers that are on the same physical hardware, including the following details:`+serverDetails, Writer,
-		aliasOpt("n"), displayerType(&displayers.Server{}))
+		aliasOpt("n"), displayerType(&displayers.Server{}), listOpt(), completeArgsOpt(completeServerIDs))
 
 	CmdBuilder(cmd, RunServerSnapshots, "snapshots <server-id>", "List all snapshots for a Server", `Use this command to get a list of snapshots created from this Server.`, Writer,
-		aliasOpt("s"), displayerType(&displayers.Image{}))
+		aliasOpt("s"), displayerType(&displayers.Image{}), listOpt(), completeArgsOpt(completeServerIDs))
 
-	cmdRunServerTag := CmdBuilder(cmd, RunServerTag, "tag <server-id|server-name>", "Add a tag to a Server", "Use this command to tag a Server. Specify the tag with the `--tag-name` flag.", Writer)
+	cmdRunServerTag := CmdBuilder(cmd, RunServerTag, "tag <server-id|server-name>", "Add a tag to a Server", "Use this command to tag a Server. Specify the tag with the `--tag-name` flag.", Writer,
+		completeArgsOpt(completeServers))
 	AddStringFlag(cmdRunServerTag, blcli.ArgTagName, "", "", "Tag name to use; can be a new or existing tag",
 		requiredOpt())
 
-	cmdRunServerUntag := CmdBuilder(cmd, RunServerUntag, "untag <server-id|server-name>", "Remove a tag from a Server", "Use this command to remove a tag from a Server, specified with the `--tag-name` flag.", Writer)
+	cmdRunServerUntag := CmdBuilder(cmd, RunServerUntag, "untag <server-id|server-name>", "Remove a tag from a Server", "Use this command to remove a tag from a Server, specified with the `--tag-name` flag.", Writer,
+		completeArgsOpt(completeServers))
 	AddStringSliceFlag(cmdRunServerUntag, blcli.ArgTagName, "", []string{}, "Tag name to remove from Server")
 
 	return cmd
@@ -162,59 +190,111 @@ func RunServerBackups(c *CmdConfig) error {
 
 // RunServerCreate creates a server.
 func RunServerCreate(c *CmdConfig) error {
+	filename, err := c.Doit.GetString(c.NS, blcli.ArgStackFile)
+	if err != nil {
+		return err
+	}
 
-	if len(c.Args) < 1 {
+	if filename != "" && len(c.Args) > 0 {
+		return usageErr(fmt.Errorf("Please specify Server names or a server spec file, not both."))
+	} else if filename == "" && len(c.Args) < 1 {
 		return blcli.NewMissingArgsErr(c.NS)
 	}
 
-	region, err := c.Doit.GetString(c.NS, blcli.ArgRegionSlug)
+	base, err := serverCreateFlags(c, filename != "")
 	if err != nil {
 		return err
 	}
 
-	size, err := c.Doit.GetString(c.NS, blcli.ArgSizeSlug)
+	ud, err := userDataFlags(c)
 	if err != nil {
 		return err
 	}
 
+	var requests []*binarylane.ServerCreateRequest
+	if filename != "" {
+		f, err := readServerSpecFile(filename)
+		if err != nil {
+			return err
+		}
+		if requests, err = f.serverCreateRequests(base, ud, filepath.Dir(filename)); err != nil {
+			return err
+		}
+	} else {
+		for i, name := range c.Args {
+			dcr := *base
+			dcr.Name = name
+			if dcr.UserData, err = ud.render(&dcr, i); err != nil {
+				return err
+			}
+			requests = append(requests, &dcr)
+		}
+	}
+
+	return createServers(c, requests)
+}
+
+// serverCreateFlags builds a create request, without a name or user-data,
+// from the flags of server create. With a server spec file, the flags are only defaults,
+// so the required flags can be left out.
+func serverCreateFlags(c *CmdConfig, defaults bool) (*binarylane.ServerCreateRequest, error) {
+	getString := func(key string) (string, error) {
+		v, err := c.Doit.GetString(c.NS, key)
+		var missing *blcli.MissingArgsErr
+		if defaults && errors.As(err, &missing) {
+			return "", nil
+		}
+		return v, err
+	}
+
+	region, err := getString(blcli.ArgRegionSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := getString(blcli.ArgSizeSlug)
+	if err != nil {
+		return nil, err
+	}
+
 	backups, err := c.Doit.GetBool(c.NS, blcli.ArgBackups)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	ipv6, err := c.Doit.GetBool(c.NS, blcli.ArgIPv6)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	privateNetworking, err := c.Doit.GetBool(c.NS, blcli.ArgPrivateNetworking)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	monitoring, err := c.Doit.GetBool(c.NS, blcli.ArgMonitoring)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	keys, err := c.Doit.GetStringSlice(c.NS, blcli.ArgSSHKeys)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	tagName, err := c.Doit.GetString(c.NS, blcli.ArgTagName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	vpcID, err := c.Doit.GetInt(c.NS, blcli.ArgVPCID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	tagNames, err := c.Doit.GetStringSlice(c.NS, blcli.ArgTagNames)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if len(tagName) > 0 {
 		tagNames = append(tagNames, tagName)
@@ -222,93 +302,114 @@ func RunServerCreate(c *CmdConfig) error {
 
 	sshKeys := extractSSHKeys(keys)
 
-	userData, err := c.Doit.GetString(c.NS, blcli.ArgUserData)
-	if err != nil {
-		return err
-	}
-
 	volumeList, err := c.Doit.GetStringSlice(c.NS, blcli.ArgVolumeList)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	volumes := extractVolumes(volumeList)
 
-	filename, err := c.Doit.GetString(c.NS, blcli.ArgUserDataFile)
+	imageStr, err := getString(blcli.ArgImage)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	userData, err = extractUserData(userData, filename)
-	if err != nil {
-		return err
-	}
+	var createImage binarylane.ServerCreateImage
+	if imageStr != "" {
+		createImage = serverCreateImage(imageStr)
+	}
+
+	return &binarylane.ServerCreateRequest{
+		Region:            region,
+		Size:              size,
+		Image:             createImage,
+		Volumes:           volumes,
+		Backups:           backups,
+		IPv6:              ipv6,
+		PrivateNetworking: privateNetworking,
+		Monitoring:        monitoring,
+		SSHKeys:           sshKeys,
+		VPCID:             vpcID,
+		Tags:              tagNames,
+	}, nil
+}
 
-	imageStr, err := c.Doit.GetString(c.NS, blcli.ArgImage)
+// createServers creates Servers with up to --concurrency requests at once,
+// waiting for each to become active with --wait. The Servers that were
+// created are displayed even when others failed, and each failure is
+// reported before an error saying how many there were is returned.
+func createServers(c *CmdConfig, requests []*binarylane.ServerCreateRequest) error {
+	wait, err := c.Doit.GetBool(c.NS, blcli.ArgCommandWait)
 	if err != nil {
 		return err
 	}
 
-	createImage := binarylane.ServerCreateImage{Slug: imageStr}
-
-	i, err := strconv.Atoi(imageStr)
-	if err == nil {
-		createImage = binarylane.ServerCreateImage{ID: i}
-	}
-
-	wait, err := c.Doit.GetBool(c.NS, blcli.ArgCommandWait)
+	concurrency, err := c.Doit.GetInt(c.NS, blcli.ArgConcurrency)
 	if err != nil {
 		return err
 	}
+	if concurrency < 1 || concurrency > len(requests) {
+		concurrency = len(requests)
+	}
 
 	ds := c.Servers()
 
-	var wg sync.WaitGroup
-	var createdList bl.Servers
-	errs := make(chan error, len(c.Args))
-	for _, name := range c.Args {
-		dcr := &binarylane.ServerCreateRequest{
-			Name:              name,
-			Region:            region,
-			Size:              size,
-			Image:             createImage,
-			Volumes:           volumes,
-			Backups:           backups,
-			IPv6:              ipv6,
-			PrivateNetworking: privateNetworking,
-			Monitoring:        monitoring,
-			SSHKeys:           sshKeys,
-			UserData:          userData,
-			VPCID:             vpcID,
-			Tags:              tagNames,
-		}
+	// created and errs are indexed by request, so that the Servers are
+	// displayed in the order they were requested in.
+	created := make([]*bl.Server, len(requests))
+	errs := make([]error, len(requests))
 
+	var wg sync.WaitGroup
+	queue := make(chan int)
+	for w := 0; w < concurrency; w++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-
-			d, err := ds.Create(dcr, wait)
-			if err != nil {
-				errs <- err
-				return
+			for i := range queue {
+				created[i], errs[i] = ds.Create(requests[i], wait)
 			}
-
-			createdList = append(createdList, *d)
 		}()
 	}
-
+	for i := range requests {
+		queue <- i
+	}
+	close(queue)
 	wg.Wait()
-	close(errs)
-
-	item := &displayers.Server{Servers: createdList}
 
-	for err := range errs {
+	var (
+		createdList bl.Servers
+		failed      int
+		firstErr    error
+	)
+	for i, err := range errs {
 		if err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		createdList = append(createdList, *created[i])
+	}
+
+	if len(requests) == 1 && firstErr != nil {
+		return firstErr
+	}
+
+	if len(createdList) > 0 {
+		if err := c.Display(&displayers.Server{Servers: createdList}); err != nil {
 			return err
 		}
 	}
-	c.Display(item)
 
-	return nil
+	if failed == 0 {
+		return nil
+	}
+	for i, err := range errs {
+		if err != nil {
+			warn("Could not create Server %q: %v", requests[i].Name, err)
+		}
+	}
+	return fmt.Errorf("%d of %d Servers could not be created", failed, len(requests))
 }
 
 // RunServerTag adds a tag to a server.
@@ -401,18 +502,6 @@ func extractSSHKeys(keys []string) []binarylane.ServerCreateSSHKey {
 	return sshKeys
 }
 
-func extractUserData(userData, filename string) (string, error) {
-	if userData == "" && filename != "" {
-		data, err := ioutil.ReadFile(filename)
-		if err != nil {
-			return "", err
-		}
-		userData = string(data)
-	}
-
-	return userData, nil
-}
-
 func extractVolumes(volumeList []string) []binarylane.ServerCreateVolume {
 	var volumes []binarylane.ServerCreateVolume
 
@@ -491,7 +580,7 @@ func RunServerDelete(c *CmdConfig) error {
 		if force || AskForConfirm(fmt.Sprintf("delete %d %s tagged \"%s\"? [affected %s: %s]", len(list), resourceType, tagName, resourceType, affectedIDs)) == nil {
 			return ds.DeleteByTag(tagName)
 		}
-		return fmt.Errorf("Operation aborted.")
+		return blcli.ErrOperationAborted
 	}
 
 	if force || AskForConfirmDelete("Server", len(c.Args)) == nil {
@@ -499,14 +588,14 @@ func RunServerDelete(c *CmdConfig) error {
 		fn := func(ids []int) error {
 			for _, id := range ids {
 				if err := ds.Delete(id); err != nil {
-					return fmt.Errorf("Unable to delete Server %d: %v", id, err)
+					return fmt.Errorf("Unable to delete Server %d: %w", id, err)
 				}
 			}
 			return nil
 		}
 		return matchServers(c.Args, ds, fn)
 	}
-	return fmt.Errorf("Operation aborted.")
+	return blcli.ErrOperationAborted
 }
 
 type matchServersFn func(ids []int) error
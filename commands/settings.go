@@ -0,0 +1,413 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/bl-cli/commands/displayers"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// settingMap is the kind of the config file settings that are maps of auth
+// contexts, rather than a single value.
+const settingMap = "map"
+
+// configSetting is a key that can be set in the config file.
+type configSetting struct {
+	key string
+	// kind is the type of the flag the setting is for, such as string,
+	// bool, int, duration or stringSlice, or settingMap.
+	kind string
+	// def is the value used when the setting isn't set, as given by its
+	// flag.
+	def string
+	// secret settings are redacted by bl config view.
+	secret bool
+}
+
+// mapSettings are the settings that map auth contexts to their values.
+// required is where bl keeps track of required flags, and is written to
+// the config file along with the other settings.
+var mapSettings = []configSetting{
+	{key: "auth-contexts", kind: settingMap, secret: true},
+	{key: credentialStoresKey, kind: settingMap},
+	{key: credentialHelpersKey, kind: settingMap},
+	{key: contextDefaultsKey, kind: settingMap},
+	{key: "required", kind: settingMap},
+}
+
+// configSettings returns the settings of the config file: the global
+// flags, the maps of auth contexts and the flags of every command, keyed
+// like the flags' config keys, such as server.create.region.
+func configSettings() map[string]configSetting {
+	settings := map[string]configSetting{
+		blcli.ArgPaginationWorkers: {key: blcli.ArgPaginationWorkers, kind: "int", def: strconv.Itoa(bl.DefaultPaginationWorkers)},
+	}
+	for _, s := range mapSettings {
+		settings[s.key] = s
+	}
+
+	DoitCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		settings[f.Name] = configSetting{key: f.Name, kind: f.Value.Type(), def: f.DefValue, secret: f.Name == blcli.ArgAccessToken}
+	})
+
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		for _, child := range cmd.Commands() {
+			ns := cmdNS(child)
+			child.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+				key := ns + "." + f.Name
+				settings[key] = configSetting{key: key, kind: f.Value.Type(), def: f.DefValue}
+			})
+			walk(child)
+		}
+	}
+	walk(DoitCmd.Command)
+
+	return settings
+}
+
+// findSetting finds the setting of a key. The key of an entry of a map
+// setting, such as auth-contexts.team, finds the map setting.
+func findSetting(settings map[string]configSetting, key string) (configSetting, bool) {
+	key = strings.ToLower(key)
+	if s, ok := settings[key]; ok {
+		return s, true
+	}
+	for _, s := range mapSettings {
+		if strings.HasPrefix(key, s.key+".") {
+			return s, true
+		}
+	}
+	return configSetting{}, false
+}
+
+// isMapSetting reports whether a key is one of the maps of auth contexts.
+func isMapSetting(key string) bool {
+	for _, s := range mapSettings {
+		if s.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// settingSource describes where the value of a setting comes from: flag,
+// env, project, file or default.
+func settingSource(key string, file map[string]interface{}) string {
+	if f := DoitCmd.PersistentFlags().Lookup(key); f != nil && f.Changed {
+		return "flag"
+	}
+	if _, ok := os.LookupEnv(settingEnv(key)); ok {
+		return "env"
+	}
+	if _, ok := project.settings()[key]; ok {
+		return "project"
+	}
+	if _, ok := file[key]; ok {
+		return "file"
+	}
+	return "default"
+}
+
+// settingEnv is the environment variable of a setting, following viper's
+// AutomaticEnv.
+func settingEnv(key string) string {
+	return "BINARYLANE_" + strings.ToUpper(strings.Replace(key, "-", "_", -1))
+}
+
+// checkSettingValue checks that a value read from the config file has the
+// type of its setting.
+func checkSettingValue(s configSetting, v interface{}) error {
+	if _, ok := v.(map[string]interface{}); ok && s.kind != settingMap {
+		return fmt.Errorf("expected a value of type %s, not a map", s.kind)
+	}
+	if v == nil {
+		return nil
+	}
+
+	switch s.kind {
+	case settingMap:
+		if _, ok := v.(map[string]interface{}); !ok {
+			return fmt.Errorf("expected a map of authentication contexts")
+		}
+	case "stringSlice":
+		if list, ok := v.([]interface{}); ok {
+			for _, item := range list {
+				if _, ok := item.(map[string]interface{}); ok {
+					return fmt.Errorf("expected a list of values")
+				}
+			}
+			return nil
+		}
+		fallthrough
+	default:
+		if _, ok := v.([]interface{}); ok {
+			return fmt.Errorf("expected a value of type %s, not a list", s.kind)
+		}
+		if _, err := parseSettingValue(s.kind, fmt.Sprint(v)); err != nil {
+			return fmt.Errorf("expected a value of type %s: %v", s.kind, err)
+		}
+	}
+	return nil
+}
+
+// parseSettingValue converts a value given on the command line to the type
+// of its setting.
+func parseSettingValue(kind, value string) (interface{}, error) {
+	switch kind {
+	case "bool":
+		return strconv.ParseBool(value)
+	case "int":
+		return strconv.Atoi(value)
+	case "duration":
+		if _, err := time.ParseDuration(value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case "stringSlice":
+		var values []string
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return values, nil
+	default:
+		return value, nil
+	}
+}
+
+// settingValueString formats the value of a setting for display.
+func settingValueString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case map[string]interface{}, map[interface{}]interface{}:
+		b, _ := yaml.Marshal(v)
+		return strings.TrimSpace(string(b))
+	default:
+		return defaultValueString(v)
+	}
+}
+
+// readConfigFile reads the settings in the config file, keyed by their
+// dotted paths down to the values that aren't maps, or down to the maps of
+// auth contexts. An empty map is returned when there is no config file.
+func readConfigFile() (map[string]interface{}, error) {
+	cfgFile := viper.GetString("config")
+	b, err := ioutil.ReadFile(cfgFile)
+	if os.IsNotExist(err) {
+		return map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("Unable to read the config file %s: %v", cfgFile, err)
+	}
+
+	settings := map[string]interface{}{}
+	flattenSettings(settings, "", raw)
+	return settings, nil
+}
+
+// flattenSettings adds the values of the nested maps of a config file to
+// out, keyed by their dotted paths. The maps of auth contexts are kept
+// whole.
+func flattenSettings(out map[string]interface{}, prefix string, m map[string]interface{}) {
+	for k, v := range m {
+		key := strings.ToLower(k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		nested, ok := stringMap(v)
+		if !ok {
+			out[key] = v
+			continue
+		}
+		if isMapSetting(key) {
+			out[key] = nested
+			continue
+		}
+		flattenSettings(out, key, nested)
+	}
+}
+
+// stringMap converts a map read from YAML to a map with string keys, along
+// with the maps nested in it.
+func stringMap(v interface{}) (map[string]interface{}, bool) {
+	var m map[string]interface{}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m = v
+	case map[interface{}]interface{}:
+		m = make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = val
+		}
+	default:
+		return nil, false
+	}
+
+	for k, val := range m {
+		if nested, ok := stringMap(val); ok {
+			m[k] = nested
+		}
+	}
+	return m, true
+}
+
+// writeConfigFile writes settings, keyed by their dotted paths, to the
+// config file.
+func writeConfigFile(settings map[string]interface{}) error {
+	nested := map[string]interface{}{}
+	for key, v := range settings {
+		setNested(nested, strings.Split(key, "."), v)
+	}
+
+	b, err := yaml.Marshal(nested)
+	if err != nil {
+		return err
+	}
+
+	f, err := cfgFileWriter()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(b)
+	return err
+}
+
+// sortedKeys returns the keys of a map of settings in order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// redactedSetting replaces access tokens in the output of bl config view.
+const redactedSetting = "REDACTED"
+
+// isDefaultSetting reports whether a value is the default of its setting.
+func isDefaultSetting(s configSetting, v interface{}) bool {
+	text := settingValueString(v)
+	return text == s.def || text == "" && s.def == "[]"
+}
+
+// mapSettingRows lists the entries of a map of auth contexts. The defaults
+// of a context are listed one flag at a time.
+func mapSettingRows(s configSetting, v interface{}) []displayers.ConfigSetting {
+	m, _ := stringMap(v)
+
+	var rows []displayers.ConfigSetting
+	for context, val := range m {
+		switch s.key {
+		case "required":
+			return nil
+		case contextDefaultsKey:
+			defaults := map[string]interface{}{}
+			flattenDefaults(defaults, "", val)
+			for key, d := range defaults {
+				rows = append(rows, displayers.ConfigSetting{
+					Key: s.key + "." + context + "." + key, Value: settingValueString(d), Source: "file",
+				})
+			}
+		default:
+			text := settingValueString(val)
+			if s.secret && text != "" {
+				text = redactedSetting
+			}
+			rows = append(rows, displayers.ConfigSetting{Key: s.key + "." + context, Value: text, Source: "file"})
+		}
+	}
+	return rows
+}
+
+// checkMapSetting checks the entries of a map of auth contexts.
+func checkMapSetting(s configSetting, v interface{}) []string {
+	m, ok := stringMap(v)
+	if !ok || s.key == "required" {
+		return nil
+	}
+
+	var problems []string
+	for _, context := range sortedKeys(m) {
+		val := m[context]
+		key := s.key + "." + context
+
+		switch s.key {
+		case contextDefaultsKey:
+			defaults := map[string]interface{}{}
+			flattenDefaults(defaults, "", val)
+			for _, name := range sortedKeys(defaults) {
+				_, f, err := findDefaultFlag(DoitCmd.Command, name)
+				if err == nil {
+					_, err = parseDefaultValue(f, defaultValueString(defaults[name]))
+				}
+				if err != nil {
+					problems = append(problems, fmt.Sprintf("%s.%s: %v", key, name, err))
+				}
+			}
+		default:
+			if _, ok := val.(map[string]interface{}); ok {
+				problems = append(problems, fmt.Sprintf("%s: expected a string, not a map", key))
+				continue
+			}
+			if s.key == credentialStoresKey {
+				if _, err := newCredentialStore(fmt.Sprint(val)); err != nil {
+					problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// sortedSettingKeys returns the keys of the settings in order.
+func sortedSettingKeys(settings map[string]configSetting) []string {
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortConfigSettings sorts settings by their keys.
+func sortConfigSettings(rows []displayers.ConfigSetting) {
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Key < rows[j].Key
+	})
+}
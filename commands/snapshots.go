@@ -188,7 +188,7 @@ func RunSnapshotDelete(c *CmdConfig) error {
 			}
 		}
 	} else {
-		return fmt.Errorf("Operation aborted.")
+		return blcli.ErrOperationAborted
 	}
 	return nil
 }
@@ -43,7 +43,8 @@ func SSH(parent *Command) *Command {
 You may specify the user to login with by passing the `+"`"+`--%s`+"`"+` flag. To access the Server on a non-default port, use the `+"`"+`--%s`+"`"+` flag. By default, the connection will be made to the Server's public IP address. In order access it using its private IP address, use the `+"`"+`--%s`+"`"+` flag.
 `, blcli.ArgSSHUser, blcli.ArgsSSHPort, blcli.ArgsSSHPrivateIP)
 
-	cmdSSH := CmdBuilder(parent, RunSSH, "ssh <server-id|name>", "Access a Server using SSH", sshDesc, Writer)
+	cmdSSH := CmdBuilder(parent, RunSSH, "ssh <server-id|name>", "Access a Server using SSH", sshDesc, Writer,
+		completeArgsOpt(completeServers))
 	AddStringFlag(cmdSSH, blcli.ArgSSHUser, "", "root", "SSH user for connection")
 	AddStringFlag(cmdSSH, blcli.ArgsSSHKeyPath, "", path, "Path to SSH private key")
 	AddIntFlag(cmdSSH, blcli.ArgsSSHPort, "", 22, "The remote port sshd is running on")
@@ -14,7 +14,6 @@ limitations under the License.
 package commands
 
 import (
-	"fmt"
 	"io/ioutil"
 
 	"github.com/binarylane/bl-cli"
@@ -42,7 +41,7 @@ BinaryLane allows you to add SSH public keys to the interface so that you can em
 		aliasOpt("ls"), displayerType(&displayers.Key{}))
 
 	CmdBuilder(cmd, RunKeyGet, "get <key-id|key-fingerprint>", "Retrieve information about an SSH key on your account", `Use this command to get the id, fingerprint, public_key, and name of a specific SSH key on your account.`, Writer,
-		aliasOpt("g"), displayerType(&displayers.Key{}))
+		aliasOpt("g"), displayerType(&displayers.Key{}), completeArgsOpt(completeSSHKeys))
 
 	cmdSSHKeysCreate := CmdBuilder(cmd, RunKeyCreate, "create <key-name>", "Create a new SSH key on your account", `Use this command to add a new SSH key to your account.
 
@@ -61,11 +60,11 @@ Note that importing a key to your account will not add it to any Servers`, Write
 	cmdRunKeyDelete := CmdBuilder(cmd, RunKeyDelete, "delete <key-id|key-fingerprint>", "Permanently delete an SSH key from your account", `Use this command to permanently delete an SSH key from your account.
 
 Note that this does not delete an SSH key from any Servers.`, Writer,
-		aliasOpt("d"))
+		aliasOpt("d"), completeArgsOpt(completeSSHKeys))
 	AddBoolFlag(cmdRunKeyDelete, blcli.ArgForce, blcli.ArgShortForce, false, "Delete the key without a confirmation prompt")
 
 	cmdSSHKeysUpdate := CmdBuilder(cmd, RunKeyUpdate, "update <key-id|key-fingerprint>", "Update an SSH key's name", `Use this command to update the name of an SSH key.`, Writer,
-		aliasOpt("u"), displayerType(&displayers.Key{}))
+		aliasOpt("u"), displayerType(&displayers.Key{}), completeArgsOpt(completeSSHKeys))
 	AddStringFlag(cmdSSHKeysUpdate, blcli.ArgKeyName, "", "", "Key name", requiredOpt())
 
 	return cmd
@@ -197,7 +196,7 @@ func RunKeyDelete(c *CmdConfig) error {
 		return ks.Delete(rawKey)
 	}
 
-	return fmt.Errorf("Operation aborted.")
+	return blcli.ErrOperationAborted
 }
 
 // RunKeyUpdate updates a key.
@@ -0,0 +1,2058 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/bl-cli/commands/displayers"
+	"github.com/binarylane/go-binarylane"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// stackSpec is the declarative description of resources read by
+// `bl apply`, `bl plan` and `bl destroy`. Resources are identified by name,
+// and references between resources (a server's VPC, the servers behind a
+// load balancer) may use either the name of the referenced resource or its ID.
+type stackSpec struct {
+	SSHKeys       []stackSSHKey       `yaml:"ssh_keys,omitempty" json:"ssh_keys,omitempty"`
+	Tags          []string            `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Projects      []stackProject      `yaml:"projects,omitempty" json:"projects,omitempty"`
+	VPCs          []stackVPC          `yaml:"vpcs,omitempty" json:"vpcs,omitempty"`
+	Servers       []stackServer       `yaml:"servers,omitempty" json:"servers,omitempty"`
+	LoadBalancers []stackLoadBalancer `yaml:"load_balancers,omitempty" json:"load_balancers,omitempty"`
+	Firewalls     []stackFirewall     `yaml:"firewalls,omitempty" json:"firewalls,omitempty"`
+	FloatingIPs   []stackFloatingIP   `yaml:"floating_ips,omitempty" json:"floating_ips,omitempty"`
+	Domains       []stackDomain       `yaml:"domains,omitempty" json:"domains,omitempty"`
+}
+
+type stackSSHKey struct {
+	Name      string `yaml:"name" json:"name"`
+	PublicKey string `yaml:"public_key" json:"public_key"`
+}
+
+type stackProject struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Purpose     string `yaml:"purpose,omitempty" json:"purpose,omitempty"`
+	Environment string `yaml:"environment,omitempty" json:"environment,omitempty"`
+	IsDefault   bool   `yaml:"is_default,omitempty" json:"is_default,omitempty"`
+}
+
+// stackFloatingIP is matched to a live floating IP by its address. Entries
+// without an address are matched to a floating IP in their region that is
+// assigned to their server, or else unassigned. Entries that match no live
+// floating IP allocate a new one.
+type stackFloatingIP struct {
+	IP     string `yaml:"ip,omitempty" json:"ip,omitempty"`
+	Region string `yaml:"region" json:"region"`
+	Server string `yaml:"server,omitempty" json:"server,omitempty"`
+}
+
+type stackVPC struct {
+	Name        string `yaml:"name" json:"name"`
+	Region      string `yaml:"region" json:"region"`
+	IPRange     string `yaml:"ip_range,omitempty" json:"ip_range,omitempty"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+type stackServer struct {
+	Name              string   `yaml:"name" json:"name"`
+	Region            string   `yaml:"region" json:"region"`
+	Size              string   `yaml:"size" json:"size"`
+	Image             string   `yaml:"image" json:"image"`
+	VPC               string   `yaml:"vpc,omitempty" json:"vpc,omitempty"`
+	SSHKeys           []string `yaml:"ssh_keys,omitempty" json:"ssh_keys,omitempty"`
+	Tags              []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	UserData          string   `yaml:"user_data,omitempty" json:"user_data,omitempty"`
+	Backups           bool     `yaml:"backups,omitempty" json:"backups,omitempty"`
+	IPv6              bool     `yaml:"ipv6,omitempty" json:"ipv6,omitempty"`
+	PrivateNetworking bool     `yaml:"private_networking,omitempty" json:"private_networking,omitempty"`
+	Monitoring        bool     `yaml:"monitoring,omitempty" json:"monitoring,omitempty"`
+}
+
+type stackLoadBalancer struct {
+	Name                   string                `yaml:"name" json:"name"`
+	Region                 string                `yaml:"region" json:"region"`
+	Size                   string                `yaml:"size,omitempty" json:"size,omitempty"`
+	Algorithm              string                `yaml:"algorithm,omitempty" json:"algorithm,omitempty"`
+	VPC                    string                `yaml:"vpc,omitempty" json:"vpc,omitempty"`
+	Servers                []string              `yaml:"servers,omitempty" json:"servers,omitempty"`
+	Tag                    string                `yaml:"tag,omitempty" json:"tag,omitempty"`
+	ForwardingRules        []stackForwardingRule `yaml:"forwarding_rules,omitempty" json:"forwarding_rules,omitempty"`
+	HealthCheck            *stackHealthCheck     `yaml:"health_check,omitempty" json:"health_check,omitempty"`
+	StickySessions         *stackStickySessions  `yaml:"sticky_sessions,omitempty" json:"sticky_sessions,omitempty"`
+	RedirectHTTPToHTTPS    bool                  `yaml:"redirect_http_to_https,omitempty" json:"redirect_http_to_https,omitempty"`
+	EnableProxyProtocol    bool                  `yaml:"enable_proxy_protocol,omitempty" json:"enable_proxy_protocol,omitempty"`
+	EnableBackendKeepalive bool                  `yaml:"enable_backend_keepalive,omitempty" json:"enable_backend_keepalive,omitempty"`
+}
+
+type stackForwardingRule struct {
+	EntryProtocol  string `yaml:"entry_protocol" json:"entry_protocol"`
+	EntryPort      int    `yaml:"entry_port" json:"entry_port"`
+	TargetProtocol string `yaml:"target_protocol" json:"target_protocol"`
+	TargetPort     int    `yaml:"target_port" json:"target_port"`
+	CertificateID  string `yaml:"certificate_id,omitempty" json:"certificate_id,omitempty"`
+	TLSPassthrough bool   `yaml:"tls_passthrough,omitempty" json:"tls_passthrough,omitempty"`
+}
+
+type stackHealthCheck struct {
+	Protocol               string `yaml:"protocol,omitempty" json:"protocol,omitempty"`
+	Port                   int    `yaml:"port,omitempty" json:"port,omitempty"`
+	Path                   string `yaml:"path,omitempty" json:"path,omitempty"`
+	CheckIntervalSeconds   int    `yaml:"check_interval_seconds,omitempty" json:"check_interval_seconds,omitempty"`
+	ResponseTimeoutSeconds int    `yaml:"response_timeout_seconds,omitempty" json:"response_timeout_seconds,omitempty"`
+	HealthyThreshold       int    `yaml:"healthy_threshold,omitempty" json:"healthy_threshold,omitempty"`
+	UnhealthyThreshold     int    `yaml:"unhealthy_threshold,omitempty" json:"unhealthy_threshold,omitempty"`
+}
+
+type stackStickySessions struct {
+	Type             string `yaml:"type,omitempty" json:"type,omitempty"`
+	CookieName       string `yaml:"cookie_name,omitempty" json:"cookie_name,omitempty"`
+	CookieTTLSeconds int    `yaml:"cookie_ttl_seconds,omitempty" json:"cookie_ttl_seconds,omitempty"`
+}
+
+type stackFirewall struct {
+	Name          string              `yaml:"name" json:"name"`
+	InboundRules  []stackFirewallRule `yaml:"inbound_rules,omitempty" json:"inbound_rules,omitempty"`
+	OutboundRules []stackFirewallRule `yaml:"outbound_rules,omitempty" json:"outbound_rules,omitempty"`
+	Servers       []string            `yaml:"servers,omitempty" json:"servers,omitempty"`
+	Tags          []string            `yaml:"tags,omitempty" json:"tags,omitempty"`
+}
+
+// stackFirewallRule describes both inbound and outbound rules. Inbound rules
+// list the permitted sources of traffic, outbound rules its destinations.
+type stackFirewallRule struct {
+	Protocol     string                `yaml:"protocol" json:"protocol"`
+	Ports        string                `yaml:"ports,omitempty" json:"ports,omitempty"`
+	Sources      *stackFirewallTargets `yaml:"sources,omitempty" json:"sources,omitempty"`
+	Destinations *stackFirewallTargets `yaml:"destinations,omitempty" json:"destinations,omitempty"`
+}
+
+type stackFirewallTargets struct {
+	Addresses     []string `yaml:"addresses,omitempty" json:"addresses,omitempty"`
+	Tags          []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Servers       []string `yaml:"servers,omitempty" json:"servers,omitempty"`
+	LoadBalancers []string `yaml:"load_balancers,omitempty" json:"load_balancers,omitempty"`
+}
+
+type stackDomain struct {
+	Name      string              `yaml:"name" json:"name"`
+	IPAddress string              `yaml:"ip_address,omitempty" json:"ip_address,omitempty"`
+	Records   []stackDomainRecord `yaml:"records,omitempty" json:"records,omitempty"`
+}
+
+type stackDomainRecord struct {
+	Type     string `yaml:"type" json:"type"`
+	Name     string `yaml:"name" json:"name"`
+	Data     string `yaml:"data" json:"data"`
+	Priority int    `yaml:"priority,omitempty" json:"priority,omitempty"`
+	Port     int    `yaml:"port,omitempty" json:"port,omitempty"`
+	TTL      int    `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+	Weight   int    `yaml:"weight,omitempty" json:"weight,omitempty"`
+	Flags    int    `yaml:"flags,omitempty" json:"flags,omitempty"`
+	Tag      string `yaml:"tag,omitempty" json:"tag,omitempty"`
+}
+
+// readStackSpec reads a stack spec from a YAML or JSON file. A filename of
+// "-" reads the spec from standard input.
+func readStackSpec(filename string) (*stackSpec, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if filename == "-" {
+		b, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		b, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parseStackSpec(b)
+}
+
+// parseStackSpec decodes and validates a stack spec. JSON is a subset of
+// YAML, so both formats are handled by the YAML decoder.
+func parseStackSpec(b []byte) (*stackSpec, error) {
+	var spec stackSpec
+	if err := yaml.UnmarshalStrict(b, &spec); err != nil {
+		return nil, fmt.Errorf("Unable to parse stack spec: %v", err)
+	}
+
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+
+	return &spec, nil
+}
+
+func (s *stackSpec) validate() error {
+	seen := map[string]bool{}
+	check := func(kind, name string, required map[string]string) error {
+		if name == "" {
+			return fmt.Errorf("Invalid stack spec: every %s requires a name", kind)
+		}
+		key := kind + "/" + name
+		if seen[key] {
+			return fmt.Errorf("Invalid stack spec: %s %q is declared more than once", kind, name)
+		}
+		seen[key] = true
+
+		fields := make([]string, 0, len(required))
+		for field := range required {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			if required[field] == "" {
+				return fmt.Errorf("Invalid stack spec: %s %q is missing %q", kind, name, field)
+			}
+		}
+		return nil
+	}
+
+	for _, k := range s.SSHKeys {
+		if err := check(stackKindSSHKey, k.Name, map[string]string{"public_key": k.PublicKey}); err != nil {
+			return err
+		}
+	}
+	for _, t := range s.Tags {
+		if err := check(stackKindTag, t, nil); err != nil {
+			return err
+		}
+	}
+	for _, p := range s.Projects {
+		if err := check(stackKindProject, p.Name, nil); err != nil {
+			return err
+		}
+	}
+	for _, v := range s.VPCs {
+		if err := check(stackKindVPC, v.Name, map[string]string{"region": v.Region}); err != nil {
+			return err
+		}
+	}
+	for _, sv := range s.Servers {
+		required := map[string]string{"region": sv.Region, "size": sv.Size, "image": sv.Image}
+		if err := check(stackKindServer, sv.Name, required); err != nil {
+			return err
+		}
+	}
+	for _, lb := range s.LoadBalancers {
+		if err := check(stackKindLoadBalancer, lb.Name, map[string]string{"region": lb.Region}); err != nil {
+			return err
+		}
+	}
+	for _, fw := range s.Firewalls {
+		if err := check(stackKindFirewall, fw.Name, nil); err != nil {
+			return err
+		}
+	}
+	for _, f := range s.FloatingIPs {
+		if f.Region == "" {
+			return fmt.Errorf("Invalid stack spec: every %s requires a region", stackKindFloatingIP)
+		}
+		if f.IP == "" {
+			continue
+		}
+		if err := check(stackKindFloatingIP, f.IP, nil); err != nil {
+			return err
+		}
+	}
+	for _, d := range s.Domains {
+		if err := check(stackKindDomain, d.Name, nil); err != nil {
+			return err
+		}
+		for _, r := range d.Records {
+			if r.Type == "" || r.Name == "" {
+				return fmt.Errorf("Invalid stack spec: records of domain %q require a type and name", d.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+const (
+	stackKindSSHKey       = "ssh_key"
+	stackKindTag          = "tag"
+	stackKindProject      = "project"
+	stackKindVPC          = "vpc"
+	stackKindServer       = "server"
+	stackKindFloatingIP   = "floating_ip"
+	stackKindLoadBalancer = "load_balancer"
+	stackKindFirewall     = "firewall"
+	stackKindDomain       = "domain"
+	stackKindRecord       = "domain_record"
+
+	stackActionCreate = "create"
+	stackActionUpdate = "update"
+	stackActionDelete = "delete"
+)
+
+var stackActionProgress = map[string]string{
+	stackActionCreate: "Creating",
+	stackActionUpdate: "Updating",
+	stackActionDelete: "Deleting",
+}
+
+// stackChange is a single step of a stack plan.
+type stackChange struct {
+	Action string
+	Kind   string
+	Name   string
+	Detail string
+
+	phase int
+	run   func(*stackState) error
+}
+
+// Changes are executed in dependency order: resources are created before
+// anything that refers to them, and deleted after.
+const (
+	phaseSSHKey = iota
+	phaseTag
+	phaseProject
+	phaseVPC
+	phaseServer
+	phaseFloatingIP
+	phaseLoadBalancer
+	phaseFirewall
+	phaseDomain
+	phaseRecord
+	phaseDeleteRecord
+	phaseDeleteFirewall
+	phaseDeleteLoadBalancer
+	phaseDeleteFloatingIP
+	phaseDeleteServer
+	phaseDeleteVPC
+	phaseDeleteDomain
+	phaseDeleteProject
+	phaseDeleteTag
+	phaseDeleteSSHKey
+)
+
+type stackPlan []stackChange
+
+func (p stackPlan) displayable() *displayers.StackPlan {
+	changes := make([]displayers.StackChange, 0, len(p))
+	for _, ch := range p {
+		changes = append(changes, displayers.StackChange{
+			Action: ch.Action,
+			Kind:   ch.Kind,
+			Name:   ch.Name,
+			Detail: ch.Detail,
+		})
+	}
+	return &displayers.StackPlan{Changes: changes}
+}
+
+// stackState holds the live resources relevant to a stack spec. The ID maps
+// are updated as changes are applied so that later changes can refer to
+// resources created earlier in the same run.
+type stackState struct {
+	c    *CmdConfig
+	spec *stackSpec
+	wait bool
+
+	sshKeys       map[string]bl.SSHKey
+	tags          map[string]bool
+	projects      map[string]bl.Project
+	vpcs          map[string]bl.VPC
+	servers       map[string]bl.Server
+	floatingIPs   map[string]bl.FloatingIP
+	loadBalancers map[string]bl.LoadBalancer
+	firewalls     map[string]bl.Firewall
+	domains       map[string]bl.Domain
+	records       map[string]bl.DomainRecords
+
+	// The live lists are kept in API order so that pruning is deterministic
+	// and covers resources that share a name.
+	sshKeyList       bl.SSHKeys
+	tagList          bl.Tags
+	projectList      bl.Projects
+	vpcList          bl.VPCs
+	serverList       bl.Servers
+	floatingIPList   bl.FloatingIPs
+	loadBalancerList bl.LoadBalancers
+	firewallList     bl.Firewalls
+	domainList       bl.Domains
+
+	vpcIDs          map[string]int
+	serverIDs       map[string]int
+	loadBalancerIDs map[string]int
+
+	duplicateServers map[string]bool
+
+	// deletedServers are the servers deleted by the plan, which are waited
+	// for before the VPCs they were in are deleted.
+	deletedServers []int
+}
+
+// loadStackState fetches the live resources for every kind declared in the
+// spec, plus the servers and VPCs that declared resources may refer to.
+func loadStackState(c *CmdConfig, spec *stackSpec) (*stackState, error) {
+	st := &stackState{
+		c:                c,
+		spec:             spec,
+		sshKeys:          map[string]bl.SSHKey{},
+		tags:             map[string]bool{},
+		projects:         map[string]bl.Project{},
+		vpcs:             map[string]bl.VPC{},
+		servers:          map[string]bl.Server{},
+		floatingIPs:      map[string]bl.FloatingIP{},
+		loadBalancers:    map[string]bl.LoadBalancer{},
+		firewalls:        map[string]bl.Firewall{},
+		domains:          map[string]bl.Domain{},
+		records:          map[string]bl.DomainRecords{},
+		vpcIDs:           map[string]int{},
+		serverIDs:        map[string]int{},
+		loadBalancerIDs:  map[string]int{},
+		duplicateServers: map[string]bool{},
+	}
+
+	if len(spec.SSHKeys) > 0 {
+		keys, err := c.Keys().List()
+		if err != nil {
+			return nil, err
+		}
+		st.sshKeyList = keys
+		for _, k := range keys {
+			st.sshKeys[k.Name] = k
+		}
+	}
+
+	if len(spec.Tags) > 0 {
+		tags, err := c.Tags().List()
+		if err != nil {
+			return nil, err
+		}
+		st.tagList = tags
+		for _, t := range tags {
+			st.tags[t.Name] = true
+		}
+	}
+
+	if len(spec.Projects) > 0 {
+		projects, err := c.Projects().List()
+		if err != nil {
+			return nil, err
+		}
+		st.projectList = projects
+		for _, p := range projects {
+			st.projects[p.Name] = p
+		}
+	}
+
+	if len(spec.VPCs) > 0 || len(spec.Servers) > 0 || len(spec.LoadBalancers) > 0 {
+		vpcs, err := c.VPCs().List()
+		if err != nil {
+			return nil, err
+		}
+		st.vpcList = vpcs
+		for _, v := range vpcs {
+			st.vpcs[v.Name] = v
+			st.vpcIDs[v.Name] = v.ID
+		}
+	}
+
+	if len(spec.Servers) > 0 || len(spec.FloatingIPs) > 0 || len(spec.LoadBalancers) > 0 || len(spec.Firewalls) > 0 {
+		servers, err := c.Servers().List()
+		if err != nil {
+			return nil, err
+		}
+		st.serverList = servers
+		for _, s := range servers {
+			if _, ok := st.servers[s.Name]; ok {
+				st.duplicateServers[s.Name] = true
+			}
+			st.servers[s.Name] = s
+			st.serverIDs[s.Name] = s.ID
+		}
+	}
+
+	if len(spec.FloatingIPs) > 0 {
+		fips, err := c.FloatingIPs().List()
+		if err != nil {
+			return nil, err
+		}
+		st.floatingIPList = fips
+		for _, f := range fips {
+			st.floatingIPs[f.IP] = f
+		}
+	}
+
+	if len(spec.LoadBalancers) > 0 || len(spec.Firewalls) > 0 {
+		lbs, err := c.LoadBalancers().List()
+		if err != nil {
+			return nil, err
+		}
+		st.loadBalancerList = lbs
+		for _, lb := range lbs {
+			st.loadBalancers[lb.Name] = lb
+			st.loadBalancerIDs[lb.Name] = lb.ID
+		}
+	}
+
+	if len(spec.Firewalls) > 0 {
+		fws, err := c.Firewalls().List()
+		if err != nil {
+			return nil, err
+		}
+		st.firewallList = fws
+		for _, fw := range fws {
+			st.firewalls[fw.Name] = fw
+		}
+	}
+
+	if len(spec.Domains) > 0 {
+		domains, err := c.Domains().List()
+		if err != nil {
+			return nil, err
+		}
+		st.domainList = domains
+		for _, d := range domains {
+			st.domains[d.Name] = d
+		}
+		for _, d := range spec.Domains {
+			if _, ok := st.domains[d.Name]; !ok {
+				continue
+			}
+			records, err := c.Domains().Records(d.Name)
+			if err != nil {
+				return nil, err
+			}
+			st.records[d.Name] = records
+		}
+	}
+
+	return st, nil
+}
+
+// resolveID resolves a reference to a resource of the given kind. References
+// may be numeric IDs or names. The second return value is false when the
+// reference names a resource that is declared in the spec but does not exist
+// yet.
+func (st *stackState) resolveID(kind, ref string) (int, bool, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return id, true, nil
+	}
+
+	var (
+		ids      map[string]int
+		declared bool
+	)
+	switch kind {
+	case stackKindVPC:
+		ids = st.vpcIDs
+		for _, v := range st.spec.VPCs {
+			declared = declared || v.Name == ref
+		}
+	case stackKindServer:
+		if st.duplicateServers[ref] {
+			return 0, false, fmt.Errorf("There are multiple Servers with the name %q; please refer to it by ID.", ref)
+		}
+		ids = st.serverIDs
+		for _, s := range st.spec.Servers {
+			declared = declared || s.Name == ref
+		}
+	case stackKindLoadBalancer:
+		ids = st.loadBalancerIDs
+		for _, lb := range st.spec.LoadBalancers {
+			declared = declared || lb.Name == ref
+		}
+	}
+
+	if id, ok := ids[ref]; ok {
+		return id, true, nil
+	}
+	if declared {
+		return 0, false, nil
+	}
+
+	return 0, false, fmt.Errorf("%s %q could not be found", kind, ref)
+}
+
+// resolveIDs resolves a list of references, skipping those that name
+// resources which do not exist yet.
+func (st *stackState) resolveIDs(kind string, refs []string) ([]int, error) {
+	ids := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		id, ok, err := st.resolveID(kind, ref)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// buildStackPlan computes the changes required to bring the live resources in
+// line with the spec. When prune is set, live resources of the kinds declared
+// in the spec that the spec does not mention are deleted.
+func buildStackPlan(st *stackState, prune bool) (stackPlan, error) {
+	var plan stackPlan
+
+	builders := []func(*stackState, bool) (stackPlan, error){
+		planSSHKeys,
+		planTags,
+		planProjects,
+		planVPCs,
+		planServers,
+		planFloatingIPs,
+		planLoadBalancers,
+		planFirewalls,
+		planDomains,
+	}
+	for _, build := range builders {
+		changes, err := build(st, prune)
+		if err != nil {
+			return nil, err
+		}
+		plan = append(plan, changes...)
+	}
+
+	sort.SliceStable(plan, func(i, j int) bool {
+		return plan[i].phase < plan[j].phase
+	})
+
+	return plan, nil
+}
+
+// buildStackDestroyPlan computes the changes required to delete every live
+// resource declared in the spec.
+func buildStackDestroyPlan(st *stackState) (stackPlan, error) {
+	var plan stackPlan
+
+	for _, k := range st.spec.SSHKeys {
+		if live, ok := st.sshKeys[k.Name]; ok {
+			plan = append(plan, deleteSSHKeyChange(live))
+		}
+	}
+	for _, t := range st.spec.Tags {
+		if st.tags[t] {
+			plan = append(plan, deleteTagChange(t))
+		}
+	}
+	for _, p := range st.spec.Projects {
+		if live, ok := st.projects[p.Name]; ok && !live.IsDefault {
+			plan = append(plan, deleteProjectChange(live))
+		}
+	}
+	for _, v := range st.spec.VPCs {
+		if live, ok := st.vpcs[v.Name]; ok {
+			plan = append(plan, deleteVPCChange(live))
+		}
+	}
+	for _, s := range st.spec.Servers {
+		if st.duplicateServers[s.Name] {
+			return nil, fmt.Errorf("There are multiple Servers with the name %q; server names must be unique to be managed by a stack", s.Name)
+		}
+		if live, ok := st.servers[s.Name]; ok {
+			plan = append(plan, deleteServerChange(live))
+		}
+	}
+	claimed := map[string]bool{}
+	for _, f := range st.spec.FloatingIPs {
+		if _, ok := st.floatingIPs[f.IP]; ok && f.IP != "" {
+			claimed[f.IP] = true
+			plan = append(plan, deleteFloatingIPChange(f.IP))
+		}
+	}
+	// Only the floating IPs that the spec identifies for certain are
+	// deleted. Without an address, that is the one attached to the entry's
+	// Server.
+	for _, f := range st.spec.FloatingIPs {
+		if f.IP != "" {
+			continue
+		}
+		live, found, err := st.attachedFloatingIP(f, claimed)
+		if err != nil {
+			return nil, fmt.Errorf("Floating IP %s: %w", f.label(), err)
+		}
+		if !found {
+			if f.Server == "" {
+				warn("Not deleting floating IP %s, as the entry has neither an ip nor a server to identify it", f.label())
+			} else {
+				warn("Not deleting floating IP %s, as no floating IP is attached to the Server", f.label())
+			}
+			continue
+		}
+		claimed[live.IP] = true
+		plan = append(plan, deleteFloatingIPChange(live.IP))
+	}
+	for _, lb := range st.spec.LoadBalancers {
+		if live, ok := st.loadBalancers[lb.Name]; ok {
+			plan = append(plan, deleteLoadBalancerChange(live))
+		}
+	}
+	for _, fw := range st.spec.Firewalls {
+		if live, ok := st.firewalls[fw.Name]; ok {
+			plan = append(plan, deleteFirewallChange(live))
+		}
+	}
+	for _, d := range st.spec.Domains {
+		if _, ok := st.domains[d.Name]; ok {
+			plan = append(plan, deleteDomainChange(d.Name))
+		}
+	}
+
+	sort.SliceStable(plan, func(i, j int) bool {
+		return plan[i].phase < plan[j].phase
+	})
+
+	return plan, nil
+}
+
+// applyStackPlan executes the plan in order, stopping at the first failure.
+func applyStackPlan(st *stackState, plan stackPlan) error {
+	for _, ch := range plan {
+		if ch.phase > phaseDeleteServer && len(st.deletedServers) > 0 {
+			if err := st.waitForServerDeletes(); err != nil {
+				return err
+			}
+		}
+
+		kind := strings.Replace(ch.Kind, "_", " ", -1)
+		notice("%s %s %q", stackActionProgress[ch.Action], kind, ch.Name)
+		if err := ch.run(st); err != nil {
+			return fmt.Errorf("Unable to %s %s %q: %w", ch.Action, kind, ch.Name, err)
+		}
+	}
+	return nil
+}
+
+func planSSHKeys(st *stackState, prune bool) (stackPlan, error) {
+	var plan stackPlan
+
+	declared := map[string]bool{}
+	for _, k := range st.spec.SSHKeys {
+		k := k
+		declared[k.Name] = true
+
+		live, ok := st.sshKeys[k.Name]
+		if !ok {
+			plan = append(plan, stackChange{
+				Action: stackActionCreate, Kind: stackKindSSHKey, Name: k.Name, phase: phaseSSHKey,
+				run: func(st *stackState) error {
+					_, err := st.c.Keys().Create(&binarylane.KeyCreateRequest{
+						Name:      k.Name,
+						PublicKey: k.PublicKey,
+					})
+					return err
+				},
+			})
+			continue
+		}
+
+		if live.PublicKey != "" && strings.TrimSpace(live.PublicKey) != strings.TrimSpace(k.PublicKey) {
+			return nil, fmt.Errorf("SSH key %q has a different public key; the public key of an existing SSH key cannot be changed", k.Name)
+		}
+	}
+
+	if prune && len(st.spec.SSHKeys) > 0 {
+		for _, live := range st.sshKeyList {
+			if !declared[live.Name] {
+				plan = append(plan, deleteSSHKeyChange(live))
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+func deleteSSHKeyChange(k bl.SSHKey) stackChange {
+	id := strconv.Itoa(k.ID)
+	return stackChange{
+		Action: stackActionDelete, Kind: stackKindSSHKey, Name: k.Name, Detail: id, phase: phaseDeleteSSHKey,
+		run: func(st *stackState) error {
+			return st.c.Keys().Delete(id)
+		},
+	}
+}
+
+func planTags(st *stackState, prune bool) (stackPlan, error) {
+	var plan stackPlan
+
+	declared := map[string]bool{}
+	for _, t := range st.spec.Tags {
+		t := t
+		declared[t] = true
+
+		if st.tags[t] {
+			continue
+		}
+		plan = append(plan, stackChange{
+			Action: stackActionCreate, Kind: stackKindTag, Name: t, phase: phaseTag,
+			run: func(st *stackState) error {
+				_, err := st.c.Tags().Create(&binarylane.TagCreateRequest{Name: t})
+				return err
+			},
+		})
+	}
+
+	if prune && len(st.spec.Tags) > 0 {
+		for _, live := range st.tagList {
+			if !declared[live.Name] {
+				plan = append(plan, deleteTagChange(live.Name))
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+func deleteTagChange(name string) stackChange {
+	return stackChange{
+		Action: stackActionDelete, Kind: stackKindTag, Name: name, phase: phaseDeleteTag,
+		run: func(st *stackState) error {
+			return st.c.Tags().Delete(name)
+		},
+	}
+}
+
+func planProjects(st *stackState, prune bool) (stackPlan, error) {
+	var plan stackPlan
+
+	declared := map[string]bool{}
+	for _, p := range st.spec.Projects {
+		p := p
+		declared[p.Name] = true
+
+		live, ok := st.projects[p.Name]
+		if !ok {
+			plan = append(plan, stackChange{
+				Action: stackActionCreate, Kind: stackKindProject, Name: p.Name, Detail: p.Purpose, phase: phaseProject,
+				run: func(st *stackState) error {
+					created, err := st.c.Projects().Create(&binarylane.CreateProjectRequest{
+						Name:        p.Name,
+						Description: p.Description,
+						Purpose:     p.Purpose,
+						Environment: p.Environment,
+					})
+					if err != nil || !p.IsDefault {
+						return err
+					}
+					_, err = st.c.Projects().Update(created.ID, &binarylane.UpdateProjectRequest{IsDefault: true})
+					return err
+				},
+			})
+			continue
+		}
+
+		var fields []string
+		r := &binarylane.UpdateProjectRequest{}
+		if live.Description != p.Description {
+			fields = append(fields, "description")
+			r.Description = p.Description
+		}
+		if p.Purpose != "" && live.Purpose != p.Purpose {
+			fields = append(fields, "purpose")
+			r.Purpose = p.Purpose
+		}
+		if p.Environment != "" && live.Environment != p.Environment {
+			fields = append(fields, "environment")
+			r.Environment = p.Environment
+		}
+		if p.IsDefault && !live.IsDefault {
+			fields = append(fields, "is_default")
+			r.IsDefault = true
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		id := live.ID
+		plan = append(plan, stackChange{
+			Action: stackActionUpdate, Kind: stackKindProject, Name: p.Name, Detail: strings.Join(fields, ", "), phase: phaseProject,
+			run: func(st *stackState) error {
+				_, err := st.c.Projects().Update(id, r)
+				return err
+			},
+		})
+	}
+
+	if prune && len(st.spec.Projects) > 0 {
+		for _, live := range st.projectList {
+			// The default project cannot be deleted.
+			if !declared[live.Name] && !live.IsDefault {
+				plan = append(plan, deleteProjectChange(live))
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+func deleteProjectChange(p bl.Project) stackChange {
+	id := p.ID
+	return stackChange{
+		Action: stackActionDelete, Kind: stackKindProject, Name: p.Name, Detail: id, phase: phaseDeleteProject,
+		run: func(st *stackState) error {
+			return st.c.Projects().Delete(id)
+		},
+	}
+}
+
+func planVPCs(st *stackState, prune bool) (stackPlan, error) {
+	var plan stackPlan
+
+	declared := map[string]bool{}
+	for _, v := range st.spec.VPCs {
+		v := v
+		declared[v.Name] = true
+
+		live, ok := st.vpcs[v.Name]
+		if !ok {
+			plan = append(plan, stackChange{
+				Action: stackActionCreate, Kind: stackKindVPC, Name: v.Name, Detail: v.Region, phase: phaseVPC,
+				run: func(st *stackState) error {
+					vpc, err := st.c.VPCs().Create(&binarylane.VPCCreateRequest{
+						Name:        v.Name,
+						RegionSlug:  v.Region,
+						Description: v.Description,
+						IPRange:     v.IPRange,
+					})
+					if err != nil {
+						return err
+					}
+					st.vpcIDs[v.Name] = vpc.ID
+					return nil
+				},
+			})
+			continue
+		}
+
+		if live.RegionSlug != v.Region {
+			return nil, fmt.Errorf("VPC %q is in region %q; the region of an existing VPC cannot be changed", v.Name, live.RegionSlug)
+		}
+		if v.IPRange != "" && live.IPRange != v.IPRange {
+			return nil, fmt.Errorf("VPC %q has IP range %q; the IP range of an existing VPC cannot be changed", v.Name, live.IPRange)
+		}
+
+		if v.Description != "" && live.Description != v.Description {
+			id := live.ID
+			plan = append(plan, stackChange{
+				Action: stackActionUpdate, Kind: stackKindVPC, Name: v.Name, Detail: "description", phase: phaseVPC,
+				run: func(st *stackState) error {
+					_, err := st.c.VPCs().Update(id, &binarylane.VPCUpdateRequest{
+						Name:        v.Name,
+						Description: v.Description,
+					})
+					return err
+				},
+			})
+		}
+	}
+
+	if prune && len(st.spec.VPCs) > 0 {
+		for _, live := range st.vpcList {
+			if !declared[live.Name] && !live.Default {
+				plan = append(plan, deleteVPCChange(live))
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+func deleteVPCChange(v bl.VPC) stackChange {
+	id := v.ID
+	return stackChange{
+		Action: stackActionDelete, Kind: stackKindVPC, Name: v.Name, Detail: strconv.Itoa(id), phase: phaseDeleteVPC,
+		run: func(st *stackState) error {
+			return st.c.VPCs().Delete(id)
+		},
+	}
+}
+
+func planServers(st *stackState, prune bool) (stackPlan, error) {
+	var plan stackPlan
+
+	declared := map[string]bool{}
+	for _, s := range st.spec.Servers {
+		s := s
+		declared[s.Name] = true
+
+		if s.VPC != "" {
+			if _, _, err := st.resolveID(stackKindVPC, s.VPC); err != nil {
+				return nil, fmt.Errorf("Server %q: %w", s.Name, err)
+			}
+		}
+
+		if st.duplicateServers[s.Name] {
+			return nil, fmt.Errorf("There are multiple Servers with the name %q; server names must be unique to be managed by a stack", s.Name)
+		}
+
+		live, ok := st.servers[s.Name]
+		if !ok {
+			plan = append(plan, stackChange{
+				Action: stackActionCreate, Kind: stackKindServer, Name: s.Name,
+				Detail: fmt.Sprintf("%s %s %s", s.Region, s.Size, s.Image), phase: phaseServer,
+				run: func(st *stackState) error {
+					r, err := s.createRequest(st)
+					if err != nil {
+						return err
+					}
+					server, err := st.c.Servers().Create(r, st.wait)
+					if err != nil {
+						return err
+					}
+					st.serverIDs[s.Name] = server.ID
+					return nil
+				},
+			})
+			continue
+		}
+
+		add, remove := diffStrings(live.Tags, s.Tags)
+		if len(add) == 0 && len(remove) == 0 {
+			continue
+		}
+
+		id := live.ID
+		plan = append(plan, stackChange{
+			Action: stackActionUpdate, Kind: stackKindServer, Name: s.Name, Detail: tagsDetail(add, remove), phase: phaseServer,
+			run: func(st *stackState) error {
+				resources := []binarylane.Resource{{ID: strconv.Itoa(id), Type: binarylane.ServerResourceType}}
+				for _, tag := range add {
+					if err := st.c.Tags().TagResources(tag, &binarylane.TagResourcesRequest{Resources: resources}); err != nil {
+						return err
+					}
+				}
+				for _, tag := range remove {
+					if err := st.c.Tags().UntagResources(tag, &binarylane.UntagResourcesRequest{Resources: resources}); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		})
+	}
+
+	if prune && len(st.spec.Servers) > 0 {
+		for _, live := range st.serverList {
+			if !declared[live.Name] {
+				plan = append(plan, deleteServerChange(live))
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+func (s *stackServer) createRequest(st *stackState) (*binarylane.ServerCreateRequest, error) {
+	image := binarylane.ServerCreateImage{Slug: s.Image}
+	if i, err := strconv.Atoi(s.Image); err == nil {
+		image = binarylane.ServerCreateImage{ID: i}
+	}
+
+	r := &binarylane.ServerCreateRequest{
+		Name:              s.Name,
+		Region:            s.Region,
+		Size:              s.Size,
+		Image:             image,
+		SSHKeys:           extractSSHKeys(s.SSHKeys),
+		Backups:           s.Backups,
+		IPv6:              s.IPv6,
+		PrivateNetworking: s.PrivateNetworking,
+		Monitoring:        s.Monitoring,
+		UserData:          s.UserData,
+		Tags:              s.Tags,
+	}
+
+	if s.VPC != "" {
+		id, ok, err := st.resolveID(stackKindVPC, s.VPC)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("VPC %q has not been created", s.VPC)
+		}
+		r.VPCID = id
+	}
+
+	return r, nil
+}
+
+func deleteServerChange(s bl.Server) stackChange {
+	id := s.ID
+	return stackChange{
+		Action: stackActionDelete, Kind: stackKindServer, Name: s.Name, Detail: strconv.Itoa(id), phase: phaseDeleteServer,
+		run: func(st *stackState) error {
+			if err := st.c.Servers().Delete(id); err != nil {
+				return err
+			}
+			st.deletedServers = append(st.deletedServers, id)
+			return nil
+		},
+	}
+}
+
+// stackDeletePollInterval is how often deleted servers are checked for.
+var stackDeletePollInterval = 5 * time.Second
+
+// waitForServerDeletes waits until the servers deleted by the plan are gone.
+// Servers are deleted asynchronously, and a VPC can't be deleted while
+// servers are still in it.
+func (st *stackState) waitForServerDeletes() error {
+	ids := st.deletedServers
+	st.deletedServers = nil
+
+	// The deletes of a dry run are never sent.
+	if st.c.dryRunIntercepted() {
+		return nil
+	}
+
+	for _, id := range ids {
+		notice("Waiting for server %d to be deleted", id)
+		for {
+			_, err := st.c.Servers().Get(id)
+			var apiErr *binarylane.ErrorResponse
+			if errors.As(err, &apiErr) && apiErr.Response != nil && apiErr.Response.StatusCode == http.StatusNotFound {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			time.Sleep(stackDeletePollInterval)
+		}
+	}
+	return nil
+}
+
+func planFloatingIPs(st *stackState, prune bool) (stackPlan, error) {
+	var plan stackPlan
+
+	// Entries without an address are matched to the live floating IPs that
+	// the other entries don't claim, so that applying the spec again doesn't
+	// allocate another floating IP.
+	declared := map[string]bool{}
+	for _, f := range st.spec.FloatingIPs {
+		if _, ok := st.floatingIPs[f.IP]; ok && f.IP != "" {
+			declared[f.IP] = true
+		}
+	}
+
+	for _, f := range st.spec.FloatingIPs {
+		f := f
+
+		serverID, serverExists := 0, true
+		if f.Server != "" {
+			id, ok, err := st.resolveID(stackKindServer, f.Server)
+			if err != nil {
+				return nil, fmt.Errorf("Floating IP %s: %w", f.label(), err)
+			}
+			serverID, serverExists = id, ok
+		}
+
+		live, ok := st.floatingIPs[f.IP]
+		if f.IP == "" {
+			live, ok = st.matchFloatingIP(f.Region, serverID, serverExists && f.Server != "", declared)
+			if ok {
+				declared[live.IP] = true
+				f.IP = live.IP
+			}
+		}
+		if !ok {
+			name := f.IP
+			if name == "" {
+				name = f.Region
+			}
+			plan = append(plan, stackChange{
+				Action: stackActionCreate, Kind: stackKindFloatingIP, Name: name, Detail: f.Server, phase: phaseFloatingIP,
+				run: func(st *stackState) error {
+					r := &binarylane.FloatingIPCreateRequest{Region: f.Region}
+					if f.Server != "" {
+						id, ok, err := st.resolveID(stackKindServer, f.Server)
+						if err != nil {
+							return err
+						}
+						if !ok {
+							return fmt.Errorf("Server %q has not been created", f.Server)
+						}
+						r.ServerID = id
+					}
+					_, err := st.c.FloatingIPs().Create(r)
+					return err
+				},
+			})
+			continue
+		}
+
+		liveServerID := 0
+		if live.Server != nil {
+			liveServerID = live.Server.ID
+		}
+		if serverExists && serverID == liveServerID {
+			continue
+		}
+
+		detail := f.Server
+		if f.Server == "" {
+			detail = "unassign"
+		}
+		plan = append(plan, stackChange{
+			Action: stackActionUpdate, Kind: stackKindFloatingIP, Name: f.IP, Detail: detail, phase: phaseFloatingIP,
+			run: func(st *stackState) error {
+				if f.Server == "" {
+					_, err := st.c.FloatingIPActions().Unassign(f.IP)
+					return err
+				}
+				id, ok, err := st.resolveID(stackKindServer, f.Server)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("Server %q has not been created", f.Server)
+				}
+				_, err = st.c.FloatingIPActions().Assign(f.IP, id)
+				return err
+			},
+		})
+	}
+
+	if prune && len(st.spec.FloatingIPs) > 0 {
+		for _, live := range st.floatingIPList {
+			if !declared[live.IP] {
+				plan = append(plan, deleteFloatingIPChange(live.IP))
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// matchFloatingIP finds an unclaimed live floating IP in the region for an
+// entry without an address: one assigned to the entry's server if it has
+// one, or else an unassigned one.
+func (st *stackState) matchFloatingIP(region string, serverID int, hasServer bool, claimed map[string]bool) (bl.FloatingIP, bool) {
+	find := func(match func(f bl.FloatingIP) bool) (bl.FloatingIP, bool) {
+		for _, f := range st.floatingIPList {
+			if !claimed[f.IP] && f.Region != nil && f.Region.Slug == region && match(f) {
+				return f, true
+			}
+		}
+		return bl.FloatingIP{}, false
+	}
+
+	if hasServer {
+		if f, ok := find(func(f bl.FloatingIP) bool { return f.Server != nil && f.Server.ID == serverID }); ok {
+			return f, true
+		}
+	}
+	return find(func(f bl.FloatingIP) bool { return f.Server == nil })
+}
+
+// attachedFloatingIP finds the unclaimed live floating IP in the region of
+// an entry without an address that is attached to the entry's Server.
+func (st *stackState) attachedFloatingIP(f stackFloatingIP, claimed map[string]bool) (bl.FloatingIP, bool, error) {
+	if f.Server == "" {
+		return bl.FloatingIP{}, false, nil
+	}
+
+	serverID, ok, err := st.resolveID(stackKindServer, f.Server)
+	if err != nil || !ok {
+		return bl.FloatingIP{}, false, err
+	}
+
+	for _, live := range st.floatingIPList {
+		if !claimed[live.IP] && live.Region != nil && live.Region.Slug == f.Region && live.Server != nil && live.Server.ID == serverID {
+			return live, true, nil
+		}
+	}
+	return bl.FloatingIP{}, false, nil
+}
+
+// label names a floating IP entry in messages, by its address or, when it
+// has none, by its region and Server.
+func (f stackFloatingIP) label() string {
+	switch {
+	case f.IP != "":
+		return strconv.Quote(f.IP)
+	case f.Server != "":
+		return fmt.Sprintf("in %s for Server %q", f.Region, f.Server)
+	default:
+		return "in " + f.Region
+	}
+}
+
+func deleteFloatingIPChange(ip string) stackChange {
+	return stackChange{
+		Action: stackActionDelete, Kind: stackKindFloatingIP, Name: ip, phase: phaseDeleteFloatingIP,
+		run: func(st *stackState) error {
+			return st.c.FloatingIPs().Delete(ip)
+		},
+	}
+}
+
+func planLoadBalancers(st *stackState, prune bool) (stackPlan, error) {
+	var plan stackPlan
+
+	declared := map[string]bool{}
+	for _, lb := range st.spec.LoadBalancers {
+		lb := lb
+		declared[lb.Name] = true
+
+		if _, err := lb.request(st); err != nil {
+			return nil, fmt.Errorf("Load balancer %q: %w", lb.Name, err)
+		}
+
+		live, ok := st.loadBalancers[lb.Name]
+		if !ok {
+			plan = append(plan, stackChange{
+				Action: stackActionCreate, Kind: stackKindLoadBalancer, Name: lb.Name, Detail: lb.Region, phase: phaseLoadBalancer,
+				run: func(st *stackState) error {
+					r, err := lb.request(st)
+					if err != nil {
+						return err
+					}
+					created, err := st.c.LoadBalancers().Create(r)
+					if err != nil {
+						return err
+					}
+					st.loadBalancerIDs[lb.Name] = created.ID
+					return nil
+				},
+			})
+			continue
+		}
+
+		diffs, err := diffLoadBalancer(st, &lb, live)
+		if err != nil {
+			return nil, err
+		}
+		fields := diffFieldNames(diffs)
+		if len(fields) == 0 {
+			continue
+		}
+
+		id := live.ID
+		plan = append(plan, stackChange{
+			Action: stackActionUpdate, Kind: stackKindLoadBalancer, Name: lb.Name, Detail: strings.Join(fields, ", "), phase: phaseLoadBalancer,
+			run: func(st *stackState) error {
+				r, err := lb.request(st)
+				if err != nil {
+					return err
+				}
+				_, err = st.c.LoadBalancers().Update(id, r)
+				return err
+			},
+		})
+	}
+
+	if prune && len(st.spec.LoadBalancers) > 0 {
+		for _, live := range st.loadBalancerList {
+			if !declared[live.Name] {
+				plan = append(plan, deleteLoadBalancerChange(live))
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// request builds the load balancer request for the spec.
+func (lb *stackLoadBalancer) request(st *stackState) (*binarylane.LoadBalancerRequest, error) {
+	serverIDs, err := st.resolveIDs(stackKindServer, lb.Servers)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &binarylane.LoadBalancerRequest{
+		Name:                   lb.Name,
+		Algorithm:              lb.Algorithm,
+		Region:                 lb.Region,
+		SizeSlug:               lb.Size,
+		ServerIDs:              serverIDs,
+		Tag:                    lb.Tag,
+		RedirectHttpToHttps:    lb.RedirectHTTPToHTTPS,
+		EnableProxyProtocol:    lb.EnableProxyProtocol,
+		EnableBackendKeepalive: lb.EnableBackendKeepalive,
+	}
+
+	for _, fr := range lb.ForwardingRules {
+		r.ForwardingRules = append(r.ForwardingRules, binarylane.ForwardingRule{
+			EntryProtocol:  fr.EntryProtocol,
+			EntryPort:      fr.EntryPort,
+			TargetProtocol: fr.TargetProtocol,
+			TargetPort:     fr.TargetPort,
+			CertificateID:  fr.CertificateID,
+			TlsPassthrough: fr.TLSPassthrough,
+		})
+	}
+
+	if hc := lb.HealthCheck; hc != nil {
+		r.HealthCheck = &binarylane.HealthCheck{
+			Protocol:               hc.Protocol,
+			Port:                   hc.Port,
+			Path:                   hc.Path,
+			CheckIntervalSeconds:   hc.CheckIntervalSeconds,
+			ResponseTimeoutSeconds: hc.ResponseTimeoutSeconds,
+			HealthyThreshold:       hc.HealthyThreshold,
+			UnhealthyThreshold:     hc.UnhealthyThreshold,
+		}
+	}
+
+	if ss := lb.StickySessions; ss != nil {
+		r.StickySessions = &binarylane.StickySessions{
+			Type:             ss.Type,
+			CookieName:       ss.CookieName,
+			CookieTtlSeconds: ss.CookieTTLSeconds,
+		}
+	}
+
+	if lb.VPC != "" {
+		id, _, err := st.resolveID(stackKindVPC, lb.VPC)
+		if err != nil {
+			return nil, err
+		}
+		r.VPCID = id
+	}
+
+	return r, nil
+}
+
+// diffLoadBalancer lists the fields of the live load balancer that differ
+// from the spec. Fields left unset in the spec are not compared.
+func diffLoadBalancer(st *stackState, lb *stackLoadBalancer, live bl.LoadBalancer) ([]stackFieldDiff, error) {
+	r, err := lb.request(st)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []stackFieldDiff
+
+	if lb.Algorithm != "" {
+		diffs = append(diffs, diffValue("algorithm", lb.Algorithm, live.Algorithm)...)
+	}
+
+	var want, have []string
+	for _, fr := range r.ForwardingRules {
+		want = append(want, forwardingRuleLabel(fr))
+	}
+	for _, fr := range live.ForwardingRules {
+		have = append(have, forwardingRuleLabel(fr))
+	}
+	diffs = append(diffs, diffSets("forwarding_rules", want, have)...)
+
+	// The API fills in defaults for the fields of the health check and
+	// sticky sessions that aren't set, so only those the spec sets are
+	// compared.
+	if hc := lb.HealthCheck; hc != nil {
+		var have binarylane.HealthCheck
+		if live.HealthCheck != nil {
+			have = *live.HealthCheck
+		}
+		diffs = append(diffs, diffSetString("health_check.protocol", hc.Protocol, have.Protocol)...)
+		diffs = append(diffs, diffSetInt("health_check.port", hc.Port, have.Port)...)
+		diffs = append(diffs, diffSetString("health_check.path", hc.Path, have.Path)...)
+		diffs = append(diffs, diffSetInt("health_check.check_interval_seconds", hc.CheckIntervalSeconds, have.CheckIntervalSeconds)...)
+		diffs = append(diffs, diffSetInt("health_check.response_timeout_seconds", hc.ResponseTimeoutSeconds, have.ResponseTimeoutSeconds)...)
+		diffs = append(diffs, diffSetInt("health_check.healthy_threshold", hc.HealthyThreshold, have.HealthyThreshold)...)
+		diffs = append(diffs, diffSetInt("health_check.unhealthy_threshold", hc.UnhealthyThreshold, have.UnhealthyThreshold)...)
+	}
+	if ss := lb.StickySessions; ss != nil {
+		var have binarylane.StickySessions
+		if live.StickySessions != nil {
+			have = *live.StickySessions
+		}
+		diffs = append(diffs, diffSetString("sticky_sessions.type", ss.Type, have.Type)...)
+		diffs = append(diffs, diffSetString("sticky_sessions.cookie_name", ss.CookieName, have.CookieName)...)
+		diffs = append(diffs, diffSetInt("sticky_sessions.cookie_ttl_seconds", ss.CookieTTLSeconds, have.CookieTtlSeconds)...)
+	}
+
+	diffs = append(diffs, diffValue("servers",
+		strings.Join(st.refLabels(stackKindServer, lb.Servers), ", "),
+		strings.Join(st.idLabels(stackKindServer, live.ServerIDs), ", "))...)
+	diffs = append(diffs, diffValue("tag", lb.Tag, live.Tag)...)
+	diffs = append(diffs, diffValue("redirect_http_to_https", strconv.FormatBool(lb.RedirectHTTPToHTTPS), strconv.FormatBool(live.RedirectHttpToHttps))...)
+	diffs = append(diffs, diffValue("enable_proxy_protocol", strconv.FormatBool(lb.EnableProxyProtocol), strconv.FormatBool(live.EnableProxyProtocol))...)
+	diffs = append(diffs, diffValue("enable_backend_keepalive", strconv.FormatBool(lb.EnableBackendKeepalive), strconv.FormatBool(live.EnableBackendKeepalive))...)
+
+	return diffs, nil
+}
+
+func forwardingRuleLabel(fr binarylane.ForwardingRule) string {
+	label := fmt.Sprintf("%s:%d -> %s:%d", fr.EntryProtocol, fr.EntryPort, fr.TargetProtocol, fr.TargetPort)
+	if fr.CertificateID != "" {
+		label += " certificate_id=" + fr.CertificateID
+	}
+	if fr.TlsPassthrough {
+		label += " tls_passthrough"
+	}
+	return label
+}
+
+func deleteLoadBalancerChange(lb bl.LoadBalancer) stackChange {
+	id := lb.ID
+	return stackChange{
+		Action: stackActionDelete, Kind: stackKindLoadBalancer, Name: lb.Name, Detail: strconv.Itoa(id), phase: phaseDeleteLoadBalancer,
+		run: func(st *stackState) error {
+			return st.c.LoadBalancers().Delete(id)
+		},
+	}
+}
+
+func planFirewalls(st *stackState, prune bool) (stackPlan, error) {
+	var plan stackPlan
+
+	declared := map[string]bool{}
+	for _, fw := range st.spec.Firewalls {
+		fw := fw
+		declared[fw.Name] = true
+
+		if _, err := fw.request(st); err != nil {
+			return nil, fmt.Errorf("Firewall %q: %w", fw.Name, err)
+		}
+
+		live, ok := st.firewalls[fw.Name]
+		if !ok {
+			plan = append(plan, stackChange{
+				Action: stackActionCreate, Kind: stackKindFirewall, Name: fw.Name, phase: phaseFirewall,
+				run: func(st *stackState) error {
+					r, err := fw.request(st)
+					if err != nil {
+						return err
+					}
+					_, err = st.c.Firewalls().Create(r)
+					return err
+				},
+			})
+			continue
+		}
+
+		fields := diffFieldNames(diffFirewall(st, &fw, live))
+		if len(fields) == 0 {
+			continue
+		}
+
+		id := live.ID
+		plan = append(plan, stackChange{
+			Action: stackActionUpdate, Kind: stackKindFirewall, Name: fw.Name, Detail: strings.Join(fields, ", "), phase: phaseFirewall,
+			run: func(st *stackState) error {
+				r, err := fw.request(st)
+				if err != nil {
+					return err
+				}
+				_, err = st.c.Firewalls().Update(id, r)
+				return err
+			},
+		})
+	}
+
+	if prune && len(st.spec.Firewalls) > 0 {
+		for _, live := range st.firewallList {
+			if !declared[live.Name] {
+				plan = append(plan, deleteFirewallChange(live))
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// request builds the firewall request for the spec.
+func (fw *stackFirewall) request(st *stackState) (*binarylane.FirewallRequest, error) {
+	serverIDs, err := st.resolveIDs(stackKindServer, fw.Servers)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &binarylane.FirewallRequest{
+		Name:      fw.Name,
+		ServerIDs: serverIDs,
+		Tags:      fw.Tags,
+	}
+
+	for _, rule := range fw.InboundRules {
+		sources, err := rule.Sources.resolve(st)
+		if err != nil {
+			return nil, err
+		}
+		r.InboundRules = append(r.InboundRules, binarylane.InboundRule{
+			Protocol:  rule.Protocol,
+			PortRange: rule.Ports,
+			Sources:   (*binarylane.Sources)(sources),
+		})
+	}
+
+	for _, rule := range fw.OutboundRules {
+		destinations, err := rule.Destinations.resolve(st)
+		if err != nil {
+			return nil, err
+		}
+		r.OutboundRules = append(r.OutboundRules, binarylane.OutboundRule{
+			Protocol:     rule.Protocol,
+			PortRange:    rule.Ports,
+			Destinations: destinations,
+		})
+	}
+
+	return r, nil
+}
+
+func (t *stackFirewallTargets) resolve(st *stackState) (*binarylane.Destinations, error) {
+	if t == nil {
+		return &binarylane.Destinations{}, nil
+	}
+
+	serverIDs, err := st.resolveIDs(stackKindServer, t.Servers)
+	if err != nil {
+		return nil, err
+	}
+	lbIDs, err := st.resolveIDs(stackKindLoadBalancer, t.LoadBalancers)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &binarylane.Destinations{
+		Addresses: t.Addresses,
+		Tags:      t.Tags,
+		ServerIDs: serverIDs,
+	}
+	for _, id := range lbIDs {
+		d.LoadBalancerUIDs = append(d.LoadBalancerUIDs, strconv.Itoa(id))
+	}
+
+	return d, nil
+}
+
+// diffFirewall lists the fields of the live firewall that differ from the
+// spec.
+func diffFirewall(st *stackState, fw *stackFirewall, live bl.Firewall) []stackFieldDiff {
+	var diffs []stackFieldDiff
+
+	var want, have []string
+	for _, r := range fw.InboundRules {
+		want = append(want, st.specRuleLabel(r.Protocol, r.Ports, r.Sources))
+	}
+	for _, r := range live.InboundRules {
+		have = append(have, st.liveRuleLabel(r.Protocol, r.PortRange, (*binarylane.Destinations)(r.Sources)))
+	}
+	diffs = append(diffs, diffSets("inbound_rules", want, have)...)
+
+	want, have = nil, nil
+	for _, r := range fw.OutboundRules {
+		want = append(want, st.specRuleLabel(r.Protocol, r.Ports, r.Destinations))
+	}
+	for _, r := range live.OutboundRules {
+		have = append(have, st.liveRuleLabel(r.Protocol, r.PortRange, r.Destinations))
+	}
+	diffs = append(diffs, diffSets("outbound_rules", want, have)...)
+
+	diffs = append(diffs, diffValue("servers",
+		strings.Join(st.refLabels(stackKindServer, fw.Servers), ", "),
+		strings.Join(st.idLabels(stackKindServer, live.ServerIDs), ", "))...)
+	diffs = append(diffs, diffValue("tags", strings.Join(sortedStrings(fw.Tags), ", "), strings.Join(sortedStrings(live.Tags), ", "))...)
+
+	return diffs
+}
+
+func (st *stackState) specRuleLabel(protocol, ports string, t *stackFirewallTargets) string {
+	if t == nil {
+		t = &stackFirewallTargets{}
+	}
+	return ruleLabel(protocol, ports, t.Addresses, t.Tags,
+		st.refLabels(stackKindServer, t.Servers),
+		st.refLabels(stackKindLoadBalancer, t.LoadBalancers))
+}
+
+func (st *stackState) liveRuleLabel(protocol, ports string, d *binarylane.Destinations) string {
+	if d == nil {
+		d = &binarylane.Destinations{}
+	}
+	return ruleLabel(protocol, ports, d.Addresses, d.Tags,
+		st.idLabels(stackKindServer, d.ServerIDs),
+		st.refLabels(stackKindLoadBalancer, d.LoadBalancerUIDs))
+}
+
+func ruleLabel(protocol, ports string, addresses, tags, servers, loadBalancers []string) string {
+	label := protocol
+	if ports != "" {
+		label += ":" + ports
+	}
+	for _, t := range []struct {
+		name   string
+		values []string
+	}{
+		{"addresses", addresses},
+		{"tags", tags},
+		{"servers", servers},
+		{"load_balancers", loadBalancers},
+	} {
+		if len(t.values) > 0 {
+			label += fmt.Sprintf(" %s=%s", t.name, strings.Join(sortedStrings(t.values), ","))
+		}
+	}
+	return label
+}
+
+func deleteFirewallChange(fw bl.Firewall) stackChange {
+	id := fw.ID
+	return stackChange{
+		Action: stackActionDelete, Kind: stackKindFirewall, Name: fw.Name, Detail: id, phase: phaseDeleteFirewall,
+		run: func(st *stackState) error {
+			return st.c.Firewalls().Delete(id)
+		},
+	}
+}
+
+func planDomains(st *stackState, prune bool) (stackPlan, error) {
+	var plan stackPlan
+
+	declared := map[string]bool{}
+	for _, d := range st.spec.Domains {
+		d := d
+		declared[d.Name] = true
+
+		if _, ok := st.domains[d.Name]; !ok {
+			plan = append(plan, stackChange{
+				Action: stackActionCreate, Kind: stackKindDomain, Name: d.Name, Detail: d.IPAddress, phase: phaseDomain,
+				run: func(st *stackState) error {
+					_, err := st.c.Domains().Create(&binarylane.DomainCreateRequest{
+						Name:      d.Name,
+						IPAddress: d.IPAddress,
+					})
+					return err
+				},
+			})
+		}
+
+		plan = append(plan, planDomainRecords(d, st.records[d.Name], prune)...)
+	}
+
+	if prune && len(st.spec.Domains) > 0 {
+		for _, live := range st.domainList {
+			if !declared[live.Name] {
+				plan = append(plan, deleteDomainChange(live.Name))
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// matchDomainRecords pairs each record in the spec with a live record, or
+// nil when there is none. Records of the same type, name and data are paired
+// first, then the remaining records of the same type and name, which are
+// updated to the spec's data. MX and SRV records must also have the same
+// priority and port, as there is usually more than one of them for a name.
+// The live records left unmatched are returned separately, apart from the NS
+// and SOA records managed by BinaryLane.
+func matchDomainRecords(d stackDomain, live bl.DomainRecords) ([]*bl.DomainRecord, bl.DomainRecords) {
+	matches := make([]*bl.DomainRecord, len(d.Records))
+
+	matched := map[int]bool{}
+	match := func(same func(r stackDomainRecord, lr bl.DomainRecord) bool) {
+		for i, r := range d.Records {
+			if matches[i] != nil {
+				continue
+			}
+			for j := range live {
+				lr := live[j]
+				if !matched[lr.ID] && lr.Type == r.Type && lr.Name == r.Name && same(r, lr) {
+					matched[lr.ID] = true
+					matches[i] = &lr
+					break
+				}
+			}
+		}
+	}
+
+	match(func(r stackDomainRecord, lr bl.DomainRecord) bool {
+		return lr.Data == r.Data
+	})
+	match(func(r stackDomainRecord, lr bl.DomainRecord) bool {
+		switch r.Type {
+		case "MX":
+			return lr.Priority == r.Priority
+		case "SRV":
+			return lr.Priority == r.Priority && lr.Port == r.Port
+		}
+		return true
+	})
+
+	var unmatched bl.DomainRecords
+	for _, lr := range live {
+		if !matched[lr.ID] && lr.Type != "NS" && lr.Type != "SOA" {
+			unmatched = append(unmatched, lr)
+		}
+	}
+
+	return matches, unmatched
+}
+
+func planDomainRecords(d stackDomain, live bl.DomainRecords, prune bool) stackPlan {
+	var plan stackPlan
+
+	matches, unmatched := matchDomainRecords(d, live)
+	for i, r := range d.Records {
+		r := r
+		name := recordName(d.Name, r.Type, r.Name)
+		req := r.editRequest()
+
+		existing := matches[i]
+		if existing == nil {
+			plan = append(plan, stackChange{
+				Action: stackActionCreate, Kind: stackKindRecord, Name: name, Detail: r.Data, phase: phaseRecord,
+				run: func(st *stackState) error {
+					_, err := st.c.Domains().CreateRecord(d.Name, req)
+					return err
+				},
+			})
+			continue
+		}
+
+		if len(diffRecord(existing, r)) == 0 {
+			continue
+		}
+
+		id := existing.ID
+		plan = append(plan, stackChange{
+			Action: stackActionUpdate, Kind: stackKindRecord, Name: name, Detail: r.Data, phase: phaseRecord,
+			run: func(st *stackState) error {
+				_, err := st.c.Domains().EditRecord(d.Name, id, req)
+				return err
+			},
+		})
+	}
+
+	if prune {
+		for _, lr := range unmatched {
+			id := lr.ID
+			plan = append(plan, stackChange{
+				Action: stackActionDelete, Kind: stackKindRecord, Name: recordName(d.Name, lr.Type, lr.Name), Detail: lr.Data, phase: phaseDeleteRecord,
+				run: func(st *stackState) error {
+					return st.c.Domains().DeleteRecord(d.Name, id)
+				},
+			})
+		}
+	}
+
+	return plan
+}
+
+func (r *stackDomainRecord) editRequest() *bl.DomainRecordEditRequest {
+	port := r.Port
+	return &bl.DomainRecordEditRequest{
+		Type:     r.Type,
+		Name:     r.Name,
+		Data:     r.Data,
+		Priority: r.Priority,
+		Port:     &port,
+		TTL:      r.TTL,
+		Weight:   r.Weight,
+		Flags:    r.Flags,
+		Tag:      r.Tag,
+	}
+}
+
+// diffRecord lists the fields of the live record that differ from the spec.
+// A TTL left unset in the spec is not compared.
+func diffRecord(live *bl.DomainRecord, r stackDomainRecord) []stackFieldDiff {
+	var diffs []stackFieldDiff
+
+	diffs = append(diffs, diffValue("data", r.Data, live.Data)...)
+	if r.TTL != 0 {
+		diffs = append(diffs, diffValue("ttl", strconv.Itoa(r.TTL), strconv.Itoa(live.TTL))...)
+	}
+	diffs = append(diffs, diffValue("priority", strconv.Itoa(r.Priority), strconv.Itoa(live.Priority))...)
+	diffs = append(diffs, diffValue("port", strconv.Itoa(r.Port), strconv.Itoa(live.Port))...)
+	diffs = append(diffs, diffValue("weight", strconv.Itoa(r.Weight), strconv.Itoa(live.Weight))...)
+	diffs = append(diffs, diffValue("flags", strconv.Itoa(r.Flags), strconv.Itoa(live.Flags))...)
+	diffs = append(diffs, diffValue("tag", r.Tag, live.Tag)...)
+
+	return diffs
+}
+
+func recordName(domain, rType, name string) string {
+	return fmt.Sprintf("%s %s.%s", rType, name, domain)
+}
+
+func deleteDomainChange(name string) stackChange {
+	return stackChange{
+		Action: stackActionDelete, Kind: stackKindDomain, Name: name, phase: phaseDeleteDomain,
+		run: func(st *stackState) error {
+			return st.c.Domains().Delete(name)
+		},
+	}
+}
+
+// diffStrings returns the values of want missing from have, and the values of
+// have missing from want.
+func diffStrings(have, want []string) (add, remove []string) {
+	haveSet := map[string]bool{}
+	for _, s := range have {
+		haveSet[s] = true
+	}
+	wantSet := map[string]bool{}
+	for _, s := range want {
+		wantSet[s] = true
+		if !haveSet[s] {
+			add = append(add, s)
+		}
+	}
+	for _, s := range have {
+		if !wantSet[s] {
+			remove = append(remove, s)
+		}
+	}
+	sort.Strings(add)
+	sort.Strings(remove)
+	return add, remove
+}
+
+func tagsDetail(add, remove []string) string {
+	var parts []string
+	for _, t := range add {
+		parts = append(parts, "+tag:"+t)
+	}
+	for _, t := range remove {
+		parts = append(parts, "-tag:"+t)
+	}
+	return strings.Join(parts, " ")
+}
+
+// stackFieldDiff is a field of a live resource that differs from the spec.
+// List fields such as firewall rules produce one diff for each entry that is
+// missing from, or unexpected in, the live resource.
+type stackFieldDiff struct {
+	Field    string
+	Expected string
+	Actual   string
+}
+
+func diffValue(field, expected, actual string) []stackFieldDiff {
+	if expected == actual {
+		return nil
+	}
+	return []stackFieldDiff{{Field: field, Expected: expected, Actual: actual}}
+}
+
+// diffSetString compares a field only when the spec sets it.
+func diffSetString(field, expected, actual string) []stackFieldDiff {
+	if expected == "" {
+		return nil
+	}
+	return diffValue(field, expected, actual)
+}
+
+// diffSetInt compares a number only when the spec sets it.
+func diffSetInt(field string, expected, actual int) []stackFieldDiff {
+	if expected == 0 {
+		return nil
+	}
+	return diffValue(field, strconv.Itoa(expected), strconv.Itoa(actual))
+}
+
+// diffSets compares two lists as unordered multisets.
+func diffSets(field string, expected, actual []string) []stackFieldDiff {
+	var diffs []stackFieldDiff
+
+	remaining := map[string]int{}
+	for _, a := range actual {
+		remaining[a]++
+	}
+	for _, e := range sortedStrings(expected) {
+		if remaining[e] > 0 {
+			remaining[e]--
+			continue
+		}
+		diffs = append(diffs, stackFieldDiff{Field: field, Expected: e})
+	}
+	for _, a := range sortedStrings(actual) {
+		if remaining[a] > 0 {
+			remaining[a]--
+			diffs = append(diffs, stackFieldDiff{Field: field, Actual: a})
+		}
+	}
+
+	return diffs
+}
+
+// diffFieldNames returns the distinct fields of the diffs in order.
+func diffFieldNames(diffs []stackFieldDiff) []string {
+	var fields []string
+	seen := map[string]bool{}
+	for _, d := range diffs {
+		if !seen[d.Field] {
+			seen[d.Field] = true
+			fields = append(fields, d.Field)
+		}
+	}
+	return fields
+}
+
+// label returns the name of a live resource for display, or its ID when the
+// resource is unknown or shares its name with another.
+func (st *stackState) label(kind string, id int) string {
+	switch kind {
+	case stackKindServer:
+		for _, s := range st.serverList {
+			if s.ID == id && !st.duplicateServers[s.Name] {
+				return s.Name
+			}
+		}
+	case stackKindLoadBalancer:
+		for _, lb := range st.loadBalancerList {
+			if lb.ID == id && st.loadBalancers[lb.Name].ID == id {
+				return lb.Name
+			}
+		}
+	}
+	return strconv.Itoa(id)
+}
+
+// refLabels returns the display names of references from the spec, so that
+// they can be compared with the idLabels of a live resource.
+func (st *stackState) refLabels(kind string, refs []string) []string {
+	labels := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if id, err := strconv.Atoi(ref); err == nil {
+			ref = st.label(kind, id)
+		}
+		labels = append(labels, ref)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+func (st *stackState) idLabels(kind string, ids []int) []string {
+	labels := make([]string, 0, len(ids))
+	for _, id := range ids {
+		labels = append(labels, st.label(kind, id))
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+func sortedStrings(s []string) []string {
+	out := append([]string(nil), s...)
+	sort.Strings(out)
+	return out
+}
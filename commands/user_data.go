@@ -0,0 +1,419 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/go-binarylane"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// userDataTypes are the headers cloud-init recognizes user-data by, and the
+// MIME type of each. Longer headers come before those they start with.
+var userDataTypes = []struct{ header, mimeType string }{
+	{"#cloud-config-archive", "text/cloud-config-archive"},
+	{"#cloud-config", "text/cloud-config"},
+	{"#cloud-boothook", "text/cloud-boothook"},
+	{"#part-handler", "text/part-handler"},
+	{"#upstart-job", "text/upstart-job"},
+	{"#include", "text/x-include-url"},
+	{"## template: jinja", "text/jinja2"},
+	{"#!", "text/x-shellscript"},
+}
+
+// userDataPart is a part of the user-data of Servers, from --user-data or
+// a file.
+type userDataPart struct {
+	name    string
+	content string
+}
+
+// userData is the user-data of the Servers that server create creates,
+// before it's rendered for each of them.
+type userData struct {
+	parts []userDataPart
+
+	// template renders each part as a Go template, with values for the
+	// templates to use.
+	template bool
+	values   map[string]interface{}
+
+	// gzip compresses the parts, in a multipart MIME document.
+	gzip bool
+
+	// sshKeys looks up the public key of an SSH key by its ID or
+	// fingerprint, for templates that use them.
+	sshKeys func(id string) (string, error)
+}
+
+// userDataFlags reads the user-data of server create from its flags.
+func userDataFlags(c *CmdConfig) (*userData, error) {
+	content, err := c.Doit.GetString(c.NS, blcli.ArgUserData)
+	if err != nil {
+		return nil, err
+	}
+
+	filenames, err := c.Doit.GetStringSlice(c.NS, blcli.ArgUserDataFile)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, err := readUserDataParts(content, filenames, "")
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := c.Doit.GetBool(c.NS, blcli.ArgUserDataTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	valuesFile, err := c.Doit.GetString(c.NS, blcli.ArgUserDataValues)
+	if err != nil {
+		return nil, err
+	}
+
+	vars, err := c.Doit.GetStringSlice(c.NS, blcli.ArgUserDataVar)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := userDataValues(valuesFile, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	gz, err := c.Doit.GetBool(c.NS, blcli.ArgUserDataGzip)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]string{}
+	return &userData{
+		parts:    parts,
+		template: tmpl || valuesFile != "" || len(vars) > 0,
+		values:   values,
+		gzip:     gz,
+		sshKeys: func(id string) (string, error) {
+			if key, ok := keys[id]; ok {
+				return key, nil
+			}
+			k, err := c.Keys().Get(id)
+			if err != nil {
+				return "", err
+			}
+			keys[id] = k.PublicKey
+			return k.PublicKey, nil
+		},
+	}, nil
+}
+
+// readUserDataParts reads the parts of user-data, which are the content of
+// --user-data, if any, followed by each of the files. The files are
+// relative to dir.
+func readUserDataParts(content string, filenames []string, dir string) ([]userDataPart, error) {
+	var parts []userDataPart
+	if content != "" {
+		parts = append(parts, userDataPart{name: blcli.ArgUserData, content: content})
+	}
+
+	for _, filename := range filenames {
+		if filename == "" {
+			continue
+		}
+
+		path := filename
+		if dir != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, userDataPart{name: filepath.Base(filename), content: string(data)})
+	}
+
+	return parts, nil
+}
+
+// userDataValues reads the values of user-data templates from a YAML or JSON
+// file, if there is one, and from key=value pairs, which take precedence.
+func userDataValues(filename string, vars []string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	if filename != "" {
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(b, &values); err != nil {
+			return nil, fmt.Errorf("Unable to parse user-data values file: %v", err)
+		}
+	}
+
+	for _, v := range vars {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, usageErr(fmt.Errorf("Invalid --%s %q. Use the format key=value", blcli.ArgUserDataVar, v))
+		}
+		values[kv[0]] = kv[1]
+	}
+
+	return values, nil
+}
+
+// withParts returns a copy of u with other parts.
+func (u *userData) withParts(parts []userDataPart) *userData {
+	out := *u
+	out.parts = parts
+	return &out
+}
+
+// userDataVars are the variables of a user-data template.
+type userDataVars struct {
+	// Name is the name of the Server.
+	Name string
+	// Index is the position of the Server among those being created,
+	// starting at 0.
+	Index  int
+	Region string
+	Size   string
+	Image  string
+	Tags   []string
+	// Values are the values of --user-data-values and --user-data-var.
+	Values map[string]interface{}
+	// Env is the environment of bl.
+	Env map[string]string
+
+	keys    []binarylane.ServerCreateSSHKey
+	sshKeys func(id string) (string, error)
+}
+
+// SSHKeys returns the public keys of the SSH keys of the Server. They are
+// only looked up when a template uses them.
+func (v userDataVars) SSHKeys() ([]string, error) {
+	var keys []string
+	for _, k := range v.keys {
+		id := k.Fingerprint
+		if id == "" {
+			id = strconv.Itoa(k.ID)
+		}
+
+		key, err := v.sshKeys(id)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// render returns the user-data of a Server, which is the index-th of those
+// being created. Each part is rendered as a template if templating is on,
+// has the whitespace before its header removed, and must be valid YAML if
+// it is cloud-config. A single part is returned as is, while several parts,
+// or compressed parts, are assembled into a multipart MIME document for
+// cloud-init.
+func (u *userData) render(dcr *binarylane.ServerCreateRequest, index int) (string, error) {
+	if u == nil || len(u.parts) == 0 {
+		return "", nil
+	}
+
+	vars := userDataVars{
+		Name:    dcr.Name,
+		Index:   index,
+		Region:  dcr.Region,
+		Size:    dcr.Size,
+		Image:   dcr.Image.Slug,
+		Tags:    dcr.Tags,
+		Values:  u.values,
+		Env:     environMap(),
+		keys:    dcr.SSHKeys,
+		sshKeys: u.sshKeys,
+	}
+	if vars.Image == "" && dcr.Image.ID != 0 {
+		vars.Image = strconv.Itoa(dcr.Image.ID)
+	}
+
+	parts := make([]userDataPart, len(u.parts))
+	for i, p := range u.parts {
+		content := p.content
+		if u.template {
+			var err error
+			content, err = renderUserDataTemplate(p, vars)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		content = trimUserDataHeader(content)
+		if err := validateUserData(p.name, content); err != nil {
+			return "", err
+		}
+		parts[i] = userDataPart{name: p.name, content: content}
+	}
+
+	if len(parts) == 1 && !u.gzip {
+		return parts[0].content, nil
+	}
+	return multipartUserData(parts, u.gzip)
+}
+
+func renderUserDataTemplate(p userDataPart, vars userDataVars) (string, error) {
+	tmpl, err := template.New(p.name).Option("missingkey=error").Parse(p.content)
+	if err != nil {
+		return "", fmt.Errorf("Unable to parse user-data template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("Unable to render user-data for Server %q: %v", vars.Name, err)
+	}
+	return buf.String(), nil
+}
+
+// validateUserData checks that a cloud-config part of user-data is a valid
+// YAML document, so that a mistake fails before any Server is created
+// rather than when cloud-init runs.
+func validateUserData(name, content string) error {
+	if userDataType(content) != "text/cloud-config" {
+		return nil
+	}
+
+	var doc interface{}
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return fmt.Errorf("Invalid user-data %s: cloud-config is not valid YAML: %v", name, err)
+	}
+	if _, ok := doc.(map[interface{}]interface{}); doc != nil && !ok {
+		return fmt.Errorf("Invalid user-data %s: cloud-config must be a mapping of keys to values", name)
+	}
+	return nil
+}
+
+// trimUserDataHeader removes the whitespace before the header of a part of
+// user-data, as cloud-init only recognizes a header at its very start.
+// Content without a header is returned as is.
+func trimUserDataHeader(content string) string {
+	if trimmed := strings.TrimLeft(content, " \t\r\n"); userDataType(trimmed) != "" {
+		return trimmed
+	}
+	return content
+}
+
+// userDataType returns the MIME type of a part of user-data by its header,
+// or "" if cloud-init wouldn't recognize it.
+func userDataType(content string) string {
+	for _, t := range userDataTypes {
+		if strings.HasPrefix(content, t.header) {
+			return t.mimeType
+		}
+	}
+	return ""
+}
+
+// multipartUserData assembles parts of user-data into a multipart MIME
+// document. Compressed parts are gzipped and base64 encoded, and cloud-init
+// finds their type once it has decompressed them.
+func multipartUserData(parts []userDataPart, gz bool) (string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	for _, p := range parts {
+		mimeType := userDataType(p.content)
+		if mimeType == "" {
+			return "", fmt.Errorf("Invalid user-data %s: a part of multipart user-data must start with a header such as #cloud-config or #!", p.name)
+		}
+
+		h := textproto.MIMEHeader{}
+		h.Set("MIME-Version", "1.0")
+		h.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", p.name))
+
+		content := []byte(p.content)
+		if gz {
+			var err error
+			if content, err = gzipBase64(content); err != nil {
+				return "", err
+			}
+			h.Set("Content-Type", "application/x-gzip")
+			h.Set("Content-Transfer-Encoding", "base64")
+		} else {
+			h.Set("Content-Type", mimeType+`; charset="utf-8"`)
+		}
+
+		pw, err := w.CreatePart(h)
+		if err != nil {
+			return "", err
+		}
+		if _, err := pw.Write(content); err != nil {
+			return "", err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "Content-Type: multipart/mixed; boundary=%q\r\n", w.Boundary())
+	fmt.Fprint(&out, "MIME-Version: 1.0\r\n\r\n")
+	out.Write(body.Bytes())
+	return out.String(), nil
+}
+
+// gzipBase64 compresses b and encodes it as base64, in lines of 76
+// characters as MIME requires.
+func gzipBase64(b []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(compressed.Bytes())
+
+	var out bytes.Buffer
+	for len(encoded) > 76 {
+		out.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	out.WriteString(encoded + "\r\n")
+	return out.Bytes(), nil
+}
+
+func environMap() map[string]string {
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		if i := strings.Index(kv, "="); i > 0 {
+			env[kv[:i]] = kv[i+1:]
+		}
+	}
+	return env
+}
@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/binarylane/bl-cli"
+	"github.com/binarylane/bl-cli/bl"
+	"github.com/binarylane/go-binarylane"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readMultipartUserData parses multipart user-data into the content types
+// and the decoded, decompressed content of its parts.
+func readMultipartUserData(t *testing.T, userData string) (types []string, contents []string) {
+	msg, err := mail.ReadMessage(strings.NewReader(userData))
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := r.NextPart()
+		if err != nil {
+			break
+		}
+
+		b, err := ioutil.ReadAll(p)
+		require.NoError(t, err)
+
+		if p.Header.Get("Content-Transfer-Encoding") == "base64" {
+			b, err = base64.StdEncoding.DecodeString(strings.Replace(string(b), "\r\n", "", -1))
+			require.NoError(t, err)
+
+			zr, err := gzip.NewReader(bytes.NewReader(b))
+			require.NoError(t, err)
+			b, err = ioutil.ReadAll(zr)
+			require.NoError(t, err)
+		}
+
+		types = append(types, p.Header.Get("Content-Type"))
+		contents = append(contents, string(b))
+	}
+	return types, contents
+}
+
+func TestUserDataRender(t *testing.T) {
+	os.Setenv("BL_TEST_USER_DATA", "from-env")
+	defer os.Unsetenv("BL_TEST_USER_DATA")
+
+	ud := &userData{
+		parts: []userDataPart{{
+			name:    "cloud.yaml",
+			content: "#cloud-config\nhostname: {{.Name}}\nfqdn: {{.Name}}.{{.Values.domain}}\nindex: {{.Index}}\nregion: {{.Region}}\nimage: {{.Image}}\ntags: [{{range $i, $t := .Tags}}{{if $i}},{{end}}{{$t}}{{end}}]\nenv: {{.Env.BL_TEST_USER_DATA}}\nssh_authorized_keys:\n{{- range .SSHKeys}}\n  - {{.}}\n{{- end}}\n",
+		}},
+		template: true,
+		values:   map[string]interface{}{"domain": "example.com"},
+		sshKeys: func(id string) (string, error) {
+			return "ssh-ed25519 key-" + id, nil
+		},
+	}
+
+	dcr := &binarylane.ServerCreateRequest{
+		Name:    "web-2",
+		Region:  "syd",
+		Image:   binarylane.ServerCreateImage{ID: 42},
+		Tags:    []string{"web", "prod"},
+		SSHKeys: []binarylane.ServerCreateSSHKey{{ID: 7}, {Fingerprint: "aa:bb"}},
+	}
+
+	out, err := ud.render(dcr, 1)
+	require.NoError(t, err)
+	assert.Equal(t, `#cloud-config
+hostname: web-2
+fqdn: web-2.example.com
+index: 1
+region: syd
+image: 42
+tags: [web,prod]
+env: from-env
+ssh_authorized_keys:
+  - ssh-ed25519 key-7
+  - ssh-ed25519 key-aa:bb
+`, out)
+}
+
+func TestUserDataRenderErrors(t *testing.T) {
+	ud := &userData{
+		parts:    []userDataPart{{name: "cloud.yaml", content: "#cloud-config\nfqdn: {{.Values.domain}}\n"}},
+		template: true,
+		values:   map[string]interface{}{},
+	}
+
+	_, err := ud.render(&binarylane.ServerCreateRequest{Name: "web"}, 0)
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), `Unable to render user-data for Server "web": template: cloud.yaml:2:`), err.Error())
+	assert.Contains(t, err.Error(), `map has no entry for key "domain"`)
+
+	ud.parts[0].content = "#cloud-config\nfqdn: {{.Name | nope}}\n"
+	_, err = ud.render(&binarylane.ServerCreateRequest{Name: "web"}, 0)
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "Unable to parse user-data template: template: cloud.yaml:2"), err.Error())
+	assert.Contains(t, err.Error(), `function "nope" not defined`)
+}
+
+func TestUserDataRenderWithoutTemplate(t *testing.T) {
+	ud := &userData{parts: []userDataPart{{name: "user-data", content: "#!/bin/sh\necho {{.Name}}\n"}}}
+
+	out, err := ud.render(&binarylane.ServerCreateRequest{Name: "web"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\necho {{.Name}}\n", out)
+}
+
+func TestValidateUserData(t *testing.T) {
+	tests := []struct {
+		content string
+		err     string
+	}{
+		{content: "#cloud-config\npackages: [nginx]\n"},
+		{content: "#cloud-config\n"},
+		{content: "\n#cloud-config\npackages: [nginx\n"},
+		{content: "#!/bin/sh\n: [\n"},
+		{content: "#cloud-config-archive\n- type: text/x-shellscript\n"},
+		{content: "#cloud-config\npackages: [nginx\n", err: "Invalid user-data cloud.yaml: cloud-config is not valid YAML: yaml: line 2: did not find expected ',' or ']'"},
+		{content: "#cloud-config\n- nginx\n", err: "Invalid user-data cloud.yaml: cloud-config must be a mapping of keys to values"},
+	}
+
+	for _, tt := range tests {
+		err := validateUserData("cloud.yaml", tt.content)
+		if tt.err == "" {
+			assert.NoError(t, err, tt.content)
+		} else {
+			assert.EqualError(t, err, tt.err, tt.content)
+		}
+	}
+}
+
+func TestUserDataRenderTrimsHeader(t *testing.T) {
+	ud := &userData{parts: []userDataPart{{name: "cloud.yaml", content: "\n  #cloud-config\nruncmd:\n  - [ls, -l]\n"}}}
+
+	out, err := ud.render(&binarylane.ServerCreateRequest{Name: "web"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "#cloud-config\nruncmd:\n  - [ls, -l]\n", out)
+
+	ud.parts[0].content = "\n#cloud-config\npackages: [nginx\n"
+	_, err = ud.render(&binarylane.ServerCreateRequest{Name: "web"}, 0)
+	assert.EqualError(t, err, "Invalid user-data cloud.yaml: cloud-config is not valid YAML: yaml: line 2: did not find expected ',' or ']'")
+
+	// Content without a header isn't changed.
+	ud.parts[0].content = "\ncoreos:\n  units: []\n"
+	out, err = ud.render(&binarylane.ServerCreateRequest{Name: "web"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "\ncoreos:\n  units: []\n", out)
+}
+
+func TestUserDataRenderMultipart(t *testing.T) {
+	parts := []userDataPart{
+		{name: "cloud.yaml", content: "#cloud-config\npackages: [nginx]\n"},
+		{name: "setup.sh", content: "#!/bin/sh\necho {{.Name}}\n"},
+	}
+
+	for _, gz := range []bool{false, true} {
+		ud := &userData{parts: parts, template: true, gzip: gz}
+
+		out, err := ud.render(&binarylane.ServerCreateRequest{Name: "web"}, 0)
+		require.NoError(t, err)
+
+		types, contents := readMultipartUserData(t, out)
+		if gz {
+			assert.Equal(t, []string{"application/x-gzip", "application/x-gzip"}, types)
+		} else {
+			assert.Equal(t, []string{`text/cloud-config; charset="utf-8"`, `text/x-shellscript; charset="utf-8"`}, types)
+		}
+		assert.Equal(t, []string{"#cloud-config\npackages: [nginx]\n", "#!/bin/sh\necho web\n"}, contents)
+	}
+}
+
+func TestUserDataRenderGzipSinglePart(t *testing.T) {
+	content := "#cloud-config\nwrite_files:\n  - path: /etc/motd\n    content: " + strings.Repeat("hello ", 100) + "\n"
+	ud := &userData{parts: []userDataPart{{name: "cloud.yaml", content: content}}, gzip: true}
+
+	out, err := ud.render(&binarylane.ServerCreateRequest{Name: "web"}, 0)
+	require.NoError(t, err)
+	assert.True(t, len(out) < len(content))
+
+	types, contents := readMultipartUserData(t, out)
+	assert.Equal(t, []string{"application/x-gzip"}, types)
+	assert.Equal(t, []string{content}, contents)
+}
+
+func TestUserDataRenderMultipartUnknownType(t *testing.T) {
+	ud := &userData{parts: []userDataPart{
+		{name: "cloud.yaml", content: "#cloud-config\n"},
+		{name: "notes.txt", content: "hello\n"},
+	}}
+
+	_, err := ud.render(&binarylane.ServerCreateRequest{Name: "web"}, 0)
+	assert.EqualError(t, err, "Invalid user-data notes.txt: a part of multipart user-data must start with a header such as #cloud-config or #!")
+}
+
+func TestUserDataValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bl-user-data")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "values.yaml")
+	require.NoError(t, ioutil.WriteFile(filename, []byte("domain: example.com\nreplicas: 3\ndb:\n  host: db.internal\n"), 0600))
+
+	values, err := userDataValues(filename, []string{"domain=example.org", "token=a=b"})
+	require.NoError(t, err)
+	assert.Equal(t, "example.org", values["domain"])
+	assert.Equal(t, 3, values["replicas"])
+	assert.Equal(t, "a=b", values["token"])
+	assert.Equal(t, map[interface{}]interface{}{"host": "db.internal"}, values["db"])
+
+	_, err = userDataValues("", []string{"domain"})
+	assert.EqualError(t, err, `Invalid --user-data-var "domain". Use the format key=value`)
+	assert.Equal(t, ExitUsage, describeErr(err).ExitCode)
+}
+
+func TestServerCreateUserDataTemplate(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.keys.EXPECT().Get("7").Return(&bl.SSHKey{Key: &binarylane.Key{ID: 7, PublicKey: "ssh-ed25519 AAAA"}}, nil)
+
+		for i, name := range []string{"web-1", "web-2"} {
+			tm.servers.EXPECT().Create(&binarylane.ServerCreateRequest{
+				Name:     name,
+				Region:   "syd",
+				Size:     "std-min",
+				Image:    binarylane.ServerCreateImage{Slug: "ubuntu"},
+				SSHKeys:  []binarylane.ServerCreateSSHKey{{ID: 7}},
+				UserData: "#cloud-config\nhostname: " + name + ".example.com\nindex: " + string(rune('0'+i)) + "\nssh_authorized_keys: [ssh-ed25519 AAAA]\n",
+			}, false).Return(&testServer, nil)
+		}
+
+		config.Args = []string{"web-1", "web-2"}
+		config.Doit.Set(config.NS, blcli.ArgRegionSlug, "syd")
+		config.Doit.Set(config.NS, blcli.ArgSizeSlug, "std-min")
+		config.Doit.Set(config.NS, blcli.ArgImage, "ubuntu")
+		config.Doit.Set(config.NS, blcli.ArgSSHKeys, []string{"7"})
+		config.Doit.Set(config.NS, blcli.ArgUserData, "#cloud-config\nhostname: {{.Name}}.{{.Values.domain}}\nindex: {{.Index}}\nssh_authorized_keys: {{.SSHKeys}}\n")
+		config.Doit.Set(config.NS, blcli.ArgUserDataVar, []string{"domain=example.com"})
+
+		err := RunServerCreate(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestServerCreateUserDataInvalid(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = []string{"web"}
+		config.Doit.Set(config.NS, blcli.ArgRegionSlug, "syd")
+		config.Doit.Set(config.NS, blcli.ArgSizeSlug, "std-min")
+		config.Doit.Set(config.NS, blcli.ArgImage, "ubuntu")
+		config.Doit.Set(config.NS, blcli.ArgUserData, "#cloud-config\npackages: [nginx\n")
+
+		err := RunServerCreate(config)
+		assert.EqualError(t, err, "Invalid user-data user-data: cloud-config is not valid YAML: yaml: line 2: did not find expected ',' or ']'")
+	})
+}
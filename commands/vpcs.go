@@ -14,7 +14,6 @@ limitations under the License.
 package commands
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/binarylane/bl-cli"
@@ -59,7 +58,7 @@ With the vpcs command, you can list, create, or delete VPCs, and manage their co
 	AddStringFlag(cmdRecordCreate, blcli.ArgVPCDescription, "", "", "The VPC's name")
 	AddStringFlag(cmdRecordCreate, blcli.ArgVPCIPRange, "", "",
 		"The range of IP addresses in the VPC in CIDR notation, e.g.: `10.240.0.0/16`")
-	AddStringFlag(cmdRecordCreate, blcli.ArgRegionSlug, "", "", "The VPC's region slug, e.g.: `syd`", requiredOpt())
+	AddStringFlag(cmdRecordCreate, blcli.ArgRegionSlug, "", "", "The VPC's region slug, e.g.: `syd`", requiredOpt(), completeFlagOpt(completeRegions))
 
 	cmdRecordUpdate := CmdBuilder(cmd, RunVPCUpdate, "update <id>",
 		"Update a VPC's configuration", `Use this command to update the configuration of a specified VPC.`, Writer, aliasOpt("u"))
@@ -216,7 +215,7 @@ func RunVPCDelete(c *CmdConfig) error {
 			return err
 		}
 	} else {
-		return fmt.Errorf("operation aborted")
+		return blcli.ErrOperationAborted
 	}
 
 	return nil
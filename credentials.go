@@ -0,0 +1,173 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blcli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const (
+	// CredentialStoreConfig keeps access tokens in the config file.
+	CredentialStoreConfig = "config"
+	// CredentialStoreKeyring keeps access tokens in the OS secret service:
+	// the Keychain on macOS, the Credential Manager on Windows and the Secret
+	// Service (through secret-tool) elsewhere.
+	CredentialStoreKeyring = "keyring"
+	// CredentialStoreFile keeps access tokens in a file encrypted with a
+	// passphrase, for systems without a secret service.
+	CredentialStoreFile = "file"
+
+	// CredentialsPassphraseEnv is the environment variable the passphrase of
+	// the encrypted credentials file is read from.
+	CredentialsPassphraseEnv = "BINARYLANE_CREDENTIALS_PASSPHRASE"
+
+	// keyringService is the name access tokens are stored under in the OS
+	// secret service.
+	keyringService = "bl-cli"
+)
+
+var (
+	// ErrCredentialNotFound is returned when a credential store has no
+	// access token for an auth context.
+	ErrCredentialNotFound = errors.New("no access token is stored for this context")
+	// ErrKeyringUnavailable is returned when there is no OS secret service
+	// to store access tokens in.
+	ErrKeyringUnavailable = fmt.Errorf("the OS keyring is not available on this system; use the %q credential store instead", CredentialStoreFile)
+)
+
+// CredentialStore stores the access tokens of auth contexts outside of the
+// config file.
+type CredentialStore interface {
+	// Get returns the access token of a context, or ErrCredentialNotFound.
+	Get(context string) (string, error)
+	// Set stores the access token of a context.
+	Set(context, token string) error
+	// Delete removes the access token of a context. Deleting a token that
+	// isn't stored is not an error.
+	Delete(context string) error
+}
+
+// NewKeyringStore returns a store for the OS secret service.
+func NewKeyringStore() CredentialStore {
+	return &keyringStore{service: keyringService}
+}
+
+// keyringStore stores access tokens in the OS secret service, with an entry
+// per context. Its methods are implemented for each OS.
+type keyringStore struct {
+	service string
+}
+
+// CredentialHelper is an external command that stores and retrieves access
+// tokens, like a git credential helper.
+//
+// The helper is run with the shell, with one of the arguments get, store or
+// erase appended. Its standard input is a list of key=value lines, naming
+// the context and, for store, the token. For get it writes a token=<token>
+// line to its standard output; a lone line without a key is taken as the
+// token too, so commands such as "pass show binarylane" can be used as they
+// are. Helpers that only support get can ignore store and erase.
+type CredentialHelper struct {
+	Command string
+}
+
+var _ CredentialStore = &CredentialHelper{}
+
+// Get runs the helper to look up the access token of a context.
+func (h *CredentialHelper) Get(context string) (string, error) {
+	out, err := h.run("get", map[string]string{"context": context})
+	if err != nil {
+		return "", err
+	}
+
+	var lone string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) == 2 && kv[0] == "token" {
+			return strings.TrimSpace(kv[1]), nil
+		}
+		if lone == "" && len(kv) == 1 {
+			lone = line
+		}
+	}
+
+	if lone == "" {
+		return "", ErrCredentialNotFound
+	}
+	return lone, nil
+}
+
+// Set runs the helper to store the access token of a context.
+func (h *CredentialHelper) Set(context, token string) error {
+	_, err := h.run("store", map[string]string{"context": context, "token": token})
+	return err
+}
+
+// Delete runs the helper to erase the access token of a context.
+func (h *CredentialHelper) Delete(context string) error {
+	_, err := h.run("erase", map[string]string{"context": context})
+	return err
+}
+
+func (h *CredentialHelper) run(action string, input map[string]string) ([]byte, error) {
+	var stdin bytes.Buffer
+	for _, k := range []string{"context", "token"} {
+		if v, ok := input[k]; ok {
+			fmt.Fprintf(&stdin, "%s=%s\n", k, v)
+		}
+	}
+	stdin.WriteString("\n")
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", h.Command+" "+action)
+	} else {
+		cmd = exec.Command("/bin/sh", "-c", h.Command+" "+action)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stdin = &stdin
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("credential helper %q failed: %v: %s", h.Command, err, msg)
+		}
+		return nil, fmt.Errorf("credential helper %q failed: %v", h.Command, err)
+	}
+	return out, nil
+}
+
+// PassphraseFromEnv returns the passphrase of the encrypted credentials file
+// from the environment, or an error if it isn't set. It is the passphrase
+// function to use when there is no terminal to prompt on.
+func PassphraseFromEnv() (string, error) {
+	if p := os.Getenv(CredentialsPassphraseEnv); p != "" {
+		return p, nil
+	}
+	return "", fmt.Errorf("set %s to the passphrase of the encrypted credentials file", CredentialsPassphraseEnv)
+}
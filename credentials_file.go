@@ -0,0 +1,209 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blcli
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// DefaultKeyIterations is the number of PBKDF2 iterations used to derive the
+// key of the encrypted credentials file from its passphrase.
+const DefaultKeyIterations = 210000
+
+// MaxKeyIterations is the most PBKDF2 iterations a credentials file may ask
+// for, so that a damaged or malicious file can't make bl hang while it
+// derives the key.
+const MaxKeyIterations = 10000000
+
+// ErrWrongPassphrase is returned when the encrypted credentials file can't be
+// decrypted.
+var ErrWrongPassphrase = errors.New("unable to decrypt the credentials file: the passphrase is wrong or the file is damaged")
+
+// FileStore stores access tokens in a file encrypted with AES-256-GCM, using
+// a key derived from a passphrase with PBKDF2-HMAC-SHA256. It is for systems
+// without an OS secret service, such as headless Linux servers.
+type FileStore struct {
+	Path string
+	// Passphrase returns the passphrase of the file. It is called once, when
+	// the file is first read or written.
+	Passphrase func() (string, error)
+	// Iterations is the number of PBKDF2 iterations used for new files.
+	Iterations int
+
+	passphrase string
+}
+
+var _ CredentialStore = &FileStore{}
+
+// NewFileStore returns a store for the encrypted file at path.
+func NewFileStore(path string, passphrase func() (string, error)) *FileStore {
+	return &FileStore{Path: path, Passphrase: passphrase, Iterations: DefaultKeyIterations}
+}
+
+// encryptedFile is the format of the encrypted credentials file. The
+// plaintext of Data is a JSON object of context names to tokens.
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// Get returns the access token of a context.
+func (s *FileStore) Get(context string) (string, error) {
+	tokens, err := s.read()
+	if err != nil {
+		return "", err
+	}
+
+	token, ok := tokens[context]
+	if !ok {
+		return "", ErrCredentialNotFound
+	}
+	return token, nil
+}
+
+// Set stores the access token of a context, creating the file if needed.
+func (s *FileStore) Set(context, token string) error {
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	tokens[context] = token
+	return s.write(tokens)
+}
+
+// Delete removes the access token of a context.
+func (s *FileStore) Delete(context string) error {
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := tokens[context]; !ok {
+		return nil
+	}
+	delete(tokens, context)
+	return s.write(tokens)
+}
+
+func (s *FileStore) read() (map[string]string, error) {
+	b, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f encryptedFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("unable to read the credentials file %s: %v", s.Path, err)
+	}
+	if f.Version != 1 || f.Iterations <= 0 {
+		return nil, fmt.Errorf("unable to read the credentials file %s: unsupported version %d", s.Path, f.Version)
+	}
+	if f.Iterations > MaxKeyIterations {
+		return nil, fmt.Errorf("unable to read the credentials file %s: %d key iterations is more than the maximum of %d", s.Path, f.Iterations, MaxKeyIterations)
+	}
+
+	aead, err := s.cipher(f.Salt, f.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+
+	plaintext, err := aead.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	tokens := map[string]string{}
+	if err := json.Unmarshal(plaintext, &tokens); err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return tokens, nil
+}
+
+// write encrypts the tokens with a new salt and nonce and replaces the file.
+func (s *FileStore) write(tokens map[string]string) error {
+	plaintext, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	f := encryptedFile{Version: 1, Iterations: s.Iterations, Salt: make([]byte, 16)}
+	if f.Iterations <= 0 {
+		f.Iterations = DefaultKeyIterations
+	}
+	if _, err := rand.Read(f.Salt); err != nil {
+		return err
+	}
+
+	aead, err := s.cipher(f.Salt, f.Iterations)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	f.Data = aead.Seal(nil, f.Nonce, plaintext, nil)
+
+	b, err := json.MarshalIndent(&f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that the file isn't lost if the
+	// write fails part way.
+	tmp := s.Path + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(b, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+func (s *FileStore) cipher(salt []byte, iterations int) (cipher.AEAD, error) {
+	if s.passphrase == "" {
+		passphrase, err := s.Passphrase()
+		if err != nil {
+			return nil, err
+		}
+		if passphrase == "" {
+			return nil, errors.New("the passphrase of the credentials file can't be empty")
+		}
+		s.passphrase = passphrase
+	}
+
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(s.passphrase), salt, iterations, 32, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blcli
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "credentials.enc")
+	newStore := func(passphrase string) *FileStore {
+		s := NewFileStore(path, func() (string, error) { return passphrase, nil })
+		s.Iterations = 1000
+		return s
+	}
+
+	s := newStore("correct horse")
+	_, err = s.Get("default")
+	assert.Equal(t, ErrCredentialNotFound, err)
+
+	require.NoError(t, s.Set("default", "first-token"))
+	require.NoError(t, s.Set("next", "second-token"))
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "first-token")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	s = newStore("correct horse")
+	token, err := s.Get("next")
+	require.NoError(t, err)
+	assert.Equal(t, "second-token", token)
+
+	_, err = newStore("battery staple").Get("next")
+	assert.Equal(t, ErrWrongPassphrase, err)
+
+	require.NoError(t, s.Delete("next"))
+	_, err = s.Get("next")
+	assert.Equal(t, ErrCredentialNotFound, err)
+
+	token, err = s.Get("default")
+	require.NoError(t, err)
+	assert.Equal(t, "first-token", token)
+
+	// A file asking for too many key iterations is refused before the key is
+	// derived.
+	var f map[string]interface{}
+	b, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, &f))
+	f["iterations"] = MaxKeyIterations + 1
+	b, err = json.Marshal(f)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path, b, 0600))
+
+	_, err = newStore("correct horse").Get("default")
+	assert.Contains(t, err.Error(), "more than the maximum")
+}
+
+func TestCredentialHelper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test helper is a shell script")
+	}
+
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// The helper stores its input for each action, and answers get with the
+	// last token it was given.
+	helper := filepath.Join(dir, "helper")
+	script := `#!/bin/sh
+cat > "$0.$1"
+if [ "$1" = get ] && [ -f "$0.store" ]; then
+  grep '^token=' "$0.store"
+fi
+exit 0
+`
+	require.NoError(t, ioutil.WriteFile(helper, []byte(script), 0700))
+
+	h := &CredentialHelper{Command: helper}
+
+	_, err = h.Get("next")
+	assert.Equal(t, ErrCredentialNotFound, err)
+
+	require.NoError(t, h.Set("next", "second-token"))
+	token, err := h.Get("next")
+	require.NoError(t, err)
+	assert.Equal(t, "second-token", token)
+
+	input, err := ioutil.ReadFile(helper + ".get")
+	require.NoError(t, err)
+	assert.Equal(t, "context=next\n\n", string(input))
+
+	require.NoError(t, h.Delete("next"))
+	input, err = ioutil.ReadFile(helper + ".erase")
+	require.NoError(t, err)
+	assert.Equal(t, "context=next\n\n", string(input))
+
+	token, err = (&CredentialHelper{Command: "echo lone-token; true"}).Get("next")
+	require.NoError(t, err)
+	assert.Equal(t, "lone-token", token)
+
+	_, err = (&CredentialHelper{Command: "echo locked >&2; false"}).Get("next")
+	assert.EqualError(t, err, `credential helper "echo locked >&2; false" failed: exit status 1: locked`)
+}
@@ -15,7 +15,6 @@ package blcli
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -165,7 +164,7 @@ func (glv *GithubLatestVersioner) LatestVersion() (string, error) {
 
 // Config is an interface that represent doit's config.
 type Config interface {
-	GetClient(trace bool, accessToken string) (*binarylane.Client, error)
+	GetClient(trace bool, accessToken string, dryRun *DryRun) (*binarylane.Client, error)
 	SSH(user, host, keyPath string, port int, opts ssh.Options) runner.Runner
 	Set(ns, key string, val interface{})
 	IsSet(key string) bool
@@ -176,41 +175,76 @@ type Config interface {
 	GetIntPtr(ns, key string) (*int, error)
 	GetStringSlice(ns, key string) ([]string, error)
 	GetStringMapString(ns, key string) (map[string]string, error)
+	Close() error
 }
 
 // LiveConfig is an implementation of Config for live values.
 type LiveConfig struct {
 	cliArgs map[string]bool
+
+	// cassette records the API requests of the clients for --record.
+	cassette *cassetteRecorder
 }
 
 var _ Config = &LiveConfig{}
 
-// GetClient returns a binarylane.Client.
-func (c *LiveConfig) GetClient(trace bool, accessToken string) (*binarylane.Client, error) {
-	if accessToken == "" {
+// GetClient returns a binarylane.Client. When dryRun is given, requests that
+// would change resources are printed to it instead of being sent.
+func (c *LiveConfig) GetClient(trace bool, accessToken string, dryRun *DryRun) (*binarylane.Client, error) {
+	recordPath, replayPath := viper.GetString(ArgRecord), viper.GetString(ArgReplay)
+	if recordPath != "" && replayPath != "" {
+		return nil, fmt.Errorf("the --%s and --%s flags cannot be used together", ArgRecord, ArgReplay)
+	}
+
+	// A replayed cassette needs no access token, as no requests are sent.
+	if accessToken == "" && replayPath == "" {
 		return nil, fmt.Errorf("access token is required. (hint: run 'bl auth init')")
 	}
 
-	tokenSource := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken})
-	oauthClient := oauth2.NewClient(context.Background(), tokenSource)
+	oauthClient := &http.Client{
+		Transport: &oauth2.Transport{
+			Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken}),
+			Base:   &accessTokenTransport{wrap: http.DefaultTransport},
+		},
+	}
 
-	if trace {
-		r := newRecorder(oauthClient.Transport)
-
-		go func() {
-			for {
-				select {
-				case msg := <-r.req:
-					log.Println("->", strconv.Quote(msg))
-				case msg := <-r.resp:
-					log.Println("<-", strconv.Quote(msg))
-				}
+	switch {
+	case replayPath != "":
+		player, err := newCassettePlayer(replayPath)
+		if err != nil {
+			return nil, err
+		}
+		oauthClient.Transport = player
+	case recordPath != "":
+		if c.cassette == nil {
+			cr, err := newCassetteRecorder(recordPath, accessToken)
+			if err != nil {
+				return nil, err
 			}
-		}()
+			c.cassette = cr
+		}
+		oauthClient.Transport = newRecorder(oauthClient.Transport, nil, c.cassette.record)
+	}
 
-		oauthClient.Transport = r
+	if dryRun != nil {
+		oauthClient.Transport = newDryRunTransport(oauthClient.Transport, dryRun)
 	}
 
+	if trace {
+		oauthClient.Transport = newRecorder(oauthClient.Transport,
+			func(req []byte) {
+				log.Println("->", strconv.Quote(string(req)))
+			},
+			func(_ *http.Request, _, resp []byte) {
+				log.Println("<-", strconv.Quote(string(resp)))
+			},
+		)
+	}
+
+	// The retrying transport wraps the recorder so that every attempt is
+	// traced.
+	oauthClient.Transport = newRetryTransport(oauthClient.Transport, viper.GetInt(ArgMaxRetries))
+
 	args := []binarylane.ClientOpt{binarylane.SetUserAgent(userAgent())}
 
 	apiURL := viper.GetString("api-url")
@@ -221,6 +255,15 @@ func (c *LiveConfig) GetClient(trace bool, accessToken string) (*binarylane.Clie
 	return binarylane.New(oauthClient, args...)
 }
 
+// Close writes the cassette of the API requests sent by the clients, when
+// they are recorded with --record.
+func (c *LiveConfig) Close() error {
+	if c.cassette == nil {
+		return nil
+	}
+	return c.cassette.save()
+}
+
 func userAgent() string {
 	return fmt.Sprintf("bl/%s (%s %s)", DoitVersion.String(), runtime.GOOS, runtime.GOARCH)
 }
@@ -392,10 +435,15 @@ func NewTestConfig() *TestConfig {
 
 // GetClient mocks a GetClient call. The returned binarylane client will
 // be nil.
-func (c *TestConfig) GetClient(trace bool, accessToken string) (*binarylane.Client, error) {
+func (c *TestConfig) GetClient(trace bool, accessToken string, dryRun *DryRun) (*binarylane.Client, error) {
 	return &binarylane.Client{}, nil
 }
 
+// Close does nothing, as the test config records no requests.
+func (c *TestConfig) Close() error {
+	return nil
+}
+
 // SSH returns a mock SSH runner.
 func (c *TestConfig) SSH(user, host, keyPath string, port int, opts ssh.Options) runner.Runner {
 	return c.SSHFn(user, host, keyPath, port, opts)
@@ -0,0 +1,213 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blcli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DryRun is the state of a command run with --dry-run: where the requests
+// it would send are printed, and whether any were intercepted.
+type DryRun struct {
+	out  io.Writer
+	json bool
+
+	mu          sync.Mutex
+	intercepted bool
+}
+
+// NewDryRun creates the dry run state of a command. The requests are
+// printed to out, as JSON objects if jsonOutput is set.
+func NewDryRun(out io.Writer, jsonOutput bool) *DryRun {
+	return &DryRun{out: out, json: jsonOutput}
+}
+
+// Intercepted reports whether a request has been intercepted, in which case
+// the API responses seen by the command are synthetic.
+func (d *DryRun) Intercepted() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.intercepted
+}
+
+// dryRunTransport sends GET, HEAD and OPTIONS requests but prints every
+// other request instead of sending it, and answers it with a synthetic
+// success. Synthetic resources have the ID 0, so requests that refer to
+// them, such as waiting for a synthetic action, are answered synthetically
+// too.
+type dryRunTransport struct {
+	wrap   http.RoundTripper
+	dryRun *DryRun
+}
+
+func newDryRunTransport(transport http.RoundTripper, dryRun *DryRun) *dryRunTransport {
+	return &dryRunTransport{
+		wrap:   transport,
+		dryRun: dryRun,
+	}
+}
+
+// dryRunRequest is the JSON representation of an intercepted request.
+type dryRunRequest struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+func (dt *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch {
+	case refersToSynthetic(req.URL.Path):
+		return syntheticResponse(req), nil
+	case req.Method == http.MethodGet, req.Method == http.MethodHead, req.Method == http.MethodOptions:
+		return dt.wrap.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := dt.dryRun.print(req, bytes.TrimSpace(body)); err != nil {
+		return nil, err
+	}
+
+	return syntheticResponse(req), nil
+}
+
+// print prints an intercepted request and records that it was intercepted.
+func (d *DryRun) print(req *http.Request, body []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.intercepted = true
+
+	if d.json {
+		r := dryRunRequest{Method: req.Method, URL: req.URL.String()}
+		if json.Valid(body) {
+			r.Body = body
+		} else if len(body) > 0 {
+			r.Body, _ = json.Marshal(string(body))
+		}
+
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(d.out, "%s\n", b)
+		return err
+	}
+
+	if _, err := fmt.Fprintf(d.out, "%s %s\n", req.Method, req.URL); err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		return nil
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		indented.Reset()
+		indented.Write(body)
+	}
+	_, err := fmt.Fprintf(d.out, "%s\n", indented.Bytes())
+	return err
+}
+
+var (
+	collectionSegment = regexp.MustCompile(`^[a-z_]+$`)
+
+	// syntheticKeys maps the collections whose responses do not wrap the
+	// resource in the singular of the collection name.
+	syntheticKeys = map[string]string{
+		"keys":    "ssh_key",
+		"records": "domain_record",
+	}
+)
+
+// refersToSynthetic reports whether the path refers to a resource with the
+// ID 0, which only exists as the result of an intercepted request.
+func refersToSynthetic(path string) bool {
+	for _, s := range strings.Split(path, "/") {
+		if s == "0" {
+			return true
+		}
+	}
+	return false
+}
+
+// syntheticResponse is a successful response to the request. Deletes have
+// no content. Other responses contain an empty resource, keyed by the
+// singular of the last collection in the path, and no links, so that
+// commands don't wait for actions that will never run.
+func syntheticResponse(req *http.Request) *http.Response {
+	resp := &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Request:    req,
+	}
+
+	if req.Method == http.MethodDelete {
+		resp.Status = "204 No Content"
+		resp.StatusCode = http.StatusNoContent
+		resp.Body = ioutil.NopCloser(bytes.NewReader(nil))
+		return resp
+	}
+
+	var key string
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for i := len(segments) - 1; i > 0; i-- {
+		if collectionSegment.MatchString(segments[i]) {
+			key = segments[i]
+			break
+		}
+	}
+	if k, ok := syntheticKeys[key]; ok {
+		key = k
+	} else {
+		key = strings.TrimSuffix(key, "s")
+	}
+
+	// The resource has no ID field, as some resources have numeric IDs and
+	// others string IDs, so numeric IDs decode as 0.
+	resource := map[string]interface{}{}
+	if key == "action" {
+		resource["status"] = "completed"
+	}
+
+	b, _ := json.Marshal(map[string]interface{}{
+		key:     resource,
+		"links": map[string]interface{}{},
+	})
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+	resp.ContentLength = int64(len(b))
+
+	return resp
+}
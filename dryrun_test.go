@@ -0,0 +1,120 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blcli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunTransport(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		sent = append(sent, req.Method+" "+req.URL.Path)
+		w.Write([]byte(`{"servers":[]}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		json   bool
+		status int
+		resp   string
+		out    string
+		sent   []string
+	}{
+		{
+			name:   "sends reads",
+			method: http.MethodGet,
+			path:   "/v2/servers",
+			status: http.StatusOK,
+			resp:   `{"servers":[]}`,
+			sent:   []string{"GET /v2/servers"},
+		},
+		{
+			name:   "intercepts creates",
+			method: http.MethodPost,
+			path:   "/v2/servers/12/actions",
+			body:   `{"type":"reboot"}`,
+			status: http.StatusOK,
+			resp:   `{"action":{"status":"completed"},"links":{}}`,
+			out:    "POST " + server.URL + "/v2/servers/12/actions\n{\n  \"type\": \"reboot\"\n}\n",
+		},
+		{
+			name:   "intercepts updates in json",
+			method: http.MethodPut,
+			path:   "/v2/domains/example.com/records/3",
+			body:   `{"data":"192.0.2.1"}`,
+			json:   true,
+			status: http.StatusOK,
+			resp:   `{"domain_record":{},"links":{}}`,
+			out:    `{"method":"PUT","url":"` + server.URL + `/v2/domains/example.com/records/3","body":{"data":"192.0.2.1"}}` + "\n",
+		},
+		{
+			name:   "intercepts deletes",
+			method: http.MethodDelete,
+			path:   "/v2/servers",
+			body:   "",
+			status: http.StatusNoContent,
+			out:    "DELETE " + server.URL + "/v2/servers?tag_name=web\n",
+		},
+		{
+			name:   "answers reads of synthetic resources",
+			method: http.MethodGet,
+			path:   "/v2/actions/0",
+			status: http.StatusOK,
+			resp:   `{"action":{"status":"completed"},"links":{}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			sent = nil
+			var out bytes.Buffer
+			dryRun := NewDryRun(&out, tt.json)
+			client := &http.Client{Transport: newDryRunTransport(http.DefaultTransport, dryRun)}
+
+			url := server.URL + tt.path
+			if tt.method == http.MethodDelete {
+				url += "?tag_name=web"
+			}
+			req, err := http.NewRequest(tt.method, url, strings.NewReader(tt.body))
+			require.NoError(t, err)
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.resp, string(body))
+			assert.Equal(t, tt.out, out.String())
+			assert.Equal(t, tt.sent, sent)
+			assert.Equal(t, tt.out != "", dryRun.Intercepted())
+		})
+	}
+}
@@ -13,7 +13,13 @@ limitations under the License.
 
 package blcli
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrOperationAborted is returned when the user doesn't confirm an operation.
+var ErrOperationAborted = errors.New("Operation aborted.")
 
 // MissingArgsErr is returned when there are too few arguments for a command.
 type MissingArgsErr struct {
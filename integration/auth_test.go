@@ -133,6 +133,112 @@ var _ = suite("auth/init", func(t *testing.T, when spec.G, it spec.S) {
 		})
 	})
 
+	when("the token is saved to the encrypted credentials file", func() {
+		it("keeps the token out of the config file", func() {
+			tmpDir, err := ioutil.TempDir("", "")
+			expect.NoError(err)
+			defer os.RemoveAll(tmpDir)
+
+			testConfig := filepath.Join(tmpDir, "test-config.yml")
+
+			cmd := exec.Command(builtBinaryPath,
+				"-u", server.URL,
+				"--config", testConfig,
+				"--context", "next",
+				"auth",
+				"init",
+				"--credential-store", "file",
+			)
+			cmd.Env = append(os.Environ(), "BINARYLANE_CREDENTIALS_PASSPHRASE=correct horse")
+
+			ptmx, err := pty.Start(cmd)
+			expect.NoError(err)
+
+			go func() {
+				ptmx.Write([]byte("second-token\n"))
+			}()
+
+			buf := bytes.NewBuffer([]byte{})
+
+			count, _ := io.Copy(buf, ptmx) // yes, ignore error intentionally
+			expect.NotZero(count)
+			ptmx.Close()
+
+			expect.Contains(buf.String(), "Validating token... OK")
+
+			fileBytes, err := ioutil.ReadFile(testConfig)
+			expect.NoError(err)
+			expect.NotContains(string(fileBytes), "second-token")
+			expect.Contains(string(fileBytes), "credential-stores:\n  next: file\n")
+
+			fileBytes, err = ioutil.ReadFile(filepath.Join(tmpDir, "credentials.enc"))
+			expect.NoError(err)
+			expect.NotContains(string(fileBytes), "second-token")
+
+			cmd = exec.Command(builtBinaryPath,
+				"-u", server.URL,
+				"--config", testConfig,
+				"--context", "next",
+				"compute",
+				"server",
+				"delete",
+				"1",
+				"-f",
+			)
+			cmd.Env = append(os.Environ(), "BINARYLANE_CREDENTIALS_PASSPHRASE=correct horse")
+
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, string(output))
+
+			cmd = exec.Command(builtBinaryPath,
+				"-u", server.URL,
+				"--config", testConfig,
+				"--context", "next",
+				"compute",
+				"server",
+				"delete",
+				"1",
+				"-f",
+			)
+			cmd.Env = append(os.Environ(), "BINARYLANE_CREDENTIALS_PASSPHRASE=battery staple")
+
+			output, err = cmd.CombinedOutput()
+			expect.Error(err)
+			expect.Contains(string(output), "the passphrase is wrong")
+		})
+	})
+
+	when("the context has a credential helper", func() {
+		it("fetches the token from the helper", func() {
+			var testConfigBytes = []byte(`access-token: first-token
+auth-contexts:
+  next: ""
+credential-helpers:
+  next: echo token=second-token; true
+context: next
+`)
+
+			tmpDir, err := ioutil.TempDir("", "")
+			expect.NoError(err)
+			defer os.RemoveAll(tmpDir)
+			testConfig := filepath.Join(tmpDir, "test-config.yml")
+			expect.NoError(ioutil.WriteFile(testConfig, testConfigBytes, 0644))
+
+			cmd := exec.Command(builtBinaryPath,
+				"-u", server.URL,
+				"--config", testConfig,
+				"compute",
+				"server",
+				"delete",
+				"1",
+				"-f",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, string(output))
+		})
+	})
+
 	when("a token cannot be validated", func() {
 		it("exits non-zero with an error", func() {
 			tmpDir, err := ioutil.TempDir("", "")
@@ -238,7 +344,134 @@ context: default
 			output, err := cmd.CombinedOutput()
 			expect.NoError(err, string(output))
 
-			expect.Contains(string(output), "next (current)")
+			expect.Regexp(`(?m)^next\s+true\s+config\s+valid`, string(output))
+		})
+	})
+
+	when("auth contexts are listed as json", func() {
+		it("shows whether their tokens are valid", func() {
+			var testConfigBytes = []byte(`access-token: first-token
+auth-contexts:
+  next: second-token
+  stale: some-bad-token
+context: default
+`)
+
+			tmpDir, err := ioutil.TempDir("", "")
+			expect.NoError(err)
+			defer os.RemoveAll(tmpDir)
+			testConfig := filepath.Join(tmpDir, "test-config.yml")
+			expect.NoError(ioutil.WriteFile(testConfig, testConfigBytes, 0644))
+
+			cmd := exec.Command(builtBinaryPath,
+				"-u", server.URL,
+				"--config", testConfig,
+				"-o", "json",
+				"auth",
+				"list",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, string(output))
+			expect.JSONEq(authListJSONOutput, string(output))
+		})
+	})
+})
+
+var _ = suite("auth/rename", func(t *testing.T, when spec.G, it spec.S) {
+	var expect *require.Assertions
+
+	it.Before(func() {
+		expect = require.New(t)
+	})
+
+	when("auth rename", func() {
+		it("renames it in the config file", func() {
+			tmpDir, err := ioutil.TempDir("", "")
+			expect.NoError(err)
+			defer os.RemoveAll(tmpDir)
+			testConfig := filepath.Join(tmpDir, "test-config.yml")
+			expect.NoError(ioutil.WriteFile(testConfig, authContextsConfig, 0644))
+
+			cmd := exec.Command(builtBinaryPath,
+				"--config", testConfig,
+				"auth",
+				"rename",
+				"next",
+				"prod",
+			)
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, string(output))
+			expect.Equal("Renamed context [next] to [prod]\n", string(output))
+
+			fileBytes, err := ioutil.ReadFile(testConfig)
+			expect.NoError(err)
+			expect.Contains(string(fileBytes), "  prod: second-token\n")
+			expect.NotContains(string(fileBytes), "next: second-token")
+			expect.Contains(string(fileBytes), "\ncontext: prod\n")
+		})
+	})
+})
+
+var _ = suite("auth/remove", func(t *testing.T, when spec.G, it spec.S) {
+	var expect *require.Assertions
+
+	it.Before(func() {
+		expect = require.New(t)
+	})
+
+	when("auth remove", func() {
+		it("removes it from the config file", func() {
+			tmpDir, err := ioutil.TempDir("", "")
+			expect.NoError(err)
+			defer os.RemoveAll(tmpDir)
+			testConfig := filepath.Join(tmpDir, "test-config.yml")
+			expect.NoError(ioutil.WriteFile(testConfig, authContextsConfig, 0644))
+
+			cmd := exec.Command(builtBinaryPath,
+				"--config", testConfig,
+				"auth",
+				"remove",
+				"stale",
+				"--force",
+			)
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, string(output))
+
+			fileBytes, err := ioutil.ReadFile(testConfig)
+			expect.NoError(err)
+			expect.Contains(string(fileBytes), "auth-contexts:\n  next: second-token\n")
+			expect.NotContains(string(fileBytes), "stale")
 		})
 	})
 })
+
+var authContextsConfig = []byte(`access-token: first-token
+auth-contexts:
+  next: second-token
+  stale: some-bad-token
+context: next
+`)
+
+const authListJSONOutput = `
+[
+  {
+    "name": "default",
+    "current": true,
+    "source": "config",
+    "status": "valid"
+  },
+  {
+    "name": "next",
+    "current": false,
+    "source": "config",
+    "status": "valid"
+  },
+  {
+    "name": "stale",
+    "current": false,
+    "source": "config",
+    "status": "invalid"
+  }
+]
+`
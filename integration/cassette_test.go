@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+)
+
+var _ = suite("cassette", func(t *testing.T, when spec.G, it spec.S) {
+	var (
+		expect *require.Assertions
+		server *httptest.Server
+		dir    string
+	)
+
+	it.Before(func() {
+		expect = require.New(t)
+
+		var err error
+		dir, err = ioutil.TempDir("", "bl-cassette")
+		expect.NoError(err)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("Authorization") != "Bearer some-magic-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			w.Write([]byte(regionListResponse))
+		}))
+	})
+
+	it.After(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	when("a cassette is recorded and replayed", func() {
+		it("replays the responses without the API", func() {
+			path := filepath.Join(dir, "regions.json")
+
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"--record", path,
+				"compute",
+				"region",
+				"list",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+			expect.Equal(strings.TrimSpace(regionListOutput), strings.TrimSpace(string(output)))
+
+			cassette, err := ioutil.ReadFile(path)
+			expect.NoError(err)
+			expect.NotContains(string(cassette), "some-magic-token")
+
+			server.Close()
+
+			cmd = exec.Command(builtBinaryPath,
+				"-u", server.URL,
+				"--replay", path,
+				"compute",
+				"region",
+				"list",
+			)
+
+			output, err = cmd.CombinedOutput()
+			expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+			expect.Equal(strings.TrimSpace(regionListOutput), strings.TrimSpace(string(output)))
+		})
+	})
+
+	when("the cassette has no response for a request", func() {
+		it("returns an error", func() {
+			path := filepath.Join(dir, "empty.json")
+			expect.NoError(ioutil.WriteFile(path, []byte(`{"interactions":[]}`), 0600))
+
+			cmd := exec.Command(builtBinaryPath,
+				"--replay", path,
+				"compute",
+				"region",
+				"list",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.Error(err)
+			expect.Contains(string(output), "the cassette has no recorded response for GET /v2/regions?page=1&per_page=200")
+		})
+	})
+})
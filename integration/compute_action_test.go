@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -70,6 +71,10 @@ var _ = suite("compute/action", func(t *testing.T, when spec.G, it spec.S) {
 				}
 
 				w.Write([]byte(computeActionGetResponse))
+			case "/v2/actions/30303":
+				w.Write([]byte(`{"action": {"id": 30303, "status": "errored", "type": "reboot", "resource_id": 3333, "resource_type": "server"}}`))
+			case "/v2/actions/40404":
+				w.Write([]byte(`{"action": {"id": 40404, "status": "in-progress", "type": "reboot", "resource_id": 4444, "resource_type": "server"}}`))
 			default:
 				dump, err := httputil.DumpRequest(req, true)
 				if err != nil {
@@ -130,10 +135,55 @@ var _ = suite("compute/action", func(t *testing.T, when spec.G, it spec.S) {
 			expect.NoError(err, fmt.Sprintf("received error output: %s", output))
 			expect.Equal(strings.TrimSpace(computeActionWaitOutput), strings.TrimSpace(string(output)))
 		})
+
+		it("waits for several actions and fails when one errored", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"compute",
+				"action",
+				"wait",
+				"20202",
+				"30303",
+				"--format", "ID,Status,ResourceID",
+			)
+			var stderr bytes.Buffer
+			cmd.Stderr = &stderr
+
+			output, err := cmd.Output()
+			expect.Error(err)
+			expect.Equal(1, cmd.ProcessState.ExitCode())
+			expect.Equal(strings.TrimSpace(computeActionWaitManyOutput), strings.TrimSpace(string(output)))
+			expect.Contains(stderr.String(), "action 30303 (reboot on server 3333): errored\n")
+			expect.Contains(stderr.String(), "Error: 1 of 2 actions errored")
+		})
+
+		it("stops at the wait timeout", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"compute",
+				"action",
+				"wait",
+				"40404",
+				"--poll-timeout", "1",
+				"--wait-timeout", "1500ms",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.Error(err)
+			expect.Equal(9, cmd.ProcessState.ExitCode())
+			expect.Contains(string(output), "Error: timed out after 1.5s waiting for 1 of 1 actions")
+		})
 	})
 })
 
 const (
+	computeActionWaitManyOutput = `
+ID       Status       Resource ID
+20202    completed    3164444
+30303    errored      3333
+`
 	computeActionGetOutput = `
 ID       Status       Type      Started At                       Completed At                     Resource ID    Resource Type    Region
 20202    completed    create    2014-11-14 16:29:21 +0000 UTC    2014-11-14 16:30:06 +0000 UTC    3164444        server           syd
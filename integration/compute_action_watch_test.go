@@ -0,0 +1,93 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+)
+
+var _ = suite("compute/action/watch", func(t *testing.T, when spec.G, it spec.S) {
+	var (
+		expect *require.Assertions
+		server *httptest.Server
+	)
+
+	it.Before(func() {
+		expect = require.New(t)
+
+		var (
+			mu    sync.Mutex
+			polls int
+		)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("Authorization") != "Bearer some-magic-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if req.URL.Path != "/v2/actions" || req.Method != http.MethodGet {
+				t.Fatalf("received unknown request: %s %s", req.Method, req.URL)
+			}
+
+			mu.Lock()
+			polls++
+			status := "in-progress"
+			if polls > 1 {
+				status = "completed"
+			}
+			mu.Unlock()
+
+			fmt.Fprintf(w, computeActionWatchResponse, status)
+		}))
+	})
+
+	it.After(func() {
+		server.Close()
+	})
+
+	it("writes an event each time an action starts or completes", func() {
+		cmd := exec.Command(builtBinaryPath,
+			"-t", "some-magic-token",
+			"-u", server.URL,
+			"--timeout", "1500ms",
+			"-o", "ndjson",
+			"compute",
+			"action",
+			"watch",
+			"--poll-timeout", "1",
+			"--resource-type", "server",
+		)
+
+		output, err := cmd.CombinedOutput()
+		expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+
+		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+		expect.Len(lines, 2)
+		expect.Contains(lines[0], `"event":"start"`)
+		expect.Contains(lines[0], `"id":101,"status":"in-progress","type":"reboot"`)
+		expect.Contains(lines[1], `"event":"complete"`)
+		expect.Contains(lines[1], `"id":101,"status":"completed","type":"reboot"`)
+	})
+})
+
+const computeActionWatchResponse = `
+{
+  "actions": [
+    {"id": 101, "status": "%s", "type": "reboot", "resource_id": 3164444, "resource_type": "server"},
+    {"id": 102, "status": "in-progress", "type": "create", "resource_id": 55, "resource_type": "load_balancer"},
+    {"id": 100, "status": "completed", "type": "create", "resource_id": 3164444, "resource_type": "server"}
+  ],
+  "links": {
+    "pages": {}
+  },
+  "meta": {
+    "total": 3
+  }
+}
+`
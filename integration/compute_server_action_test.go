@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -131,6 +132,126 @@ var _ = suite("compute/server-action", func(t *testing.T, when spec.G, it spec.S
 	}
 })
 
+var _ = suite("compute/server-action/by-tag", func(t *testing.T, when spec.G, it spec.S) {
+	var (
+		expect *require.Assertions
+		server *httptest.Server
+	)
+
+	it.Before(func() {
+		expect = require.New(t)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("Authorization") != "Bearer some-magic-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			switch req.URL.Path {
+			case "/v2/servers/actions":
+				expect.Equal(http.MethodPost, req.Method)
+				expect.Equal("web", req.URL.Query().Get("tag_name"))
+
+				reqBody, err := ioutil.ReadAll(req.Body)
+				expect.NoError(err)
+				expect.JSONEq(`{"type":"power_off"}`, string(reqBody))
+
+				w.Write([]byte(serverActionsByTagResponse))
+			case "/v2/actions/101", "/v2/actions/102":
+				expect.Equal(http.MethodGet, req.Method)
+
+				id := strings.TrimPrefix(req.URL.Path, "/v2/actions/")
+				w.Write([]byte(`{"action": {"id": ` + id + `, "status": "completed", "type": "power_off", "resource_id": ` + id + `, "resource_type": "server"}}`))
+			default:
+				dump, err := httputil.DumpRequest(req, true)
+				if err != nil {
+					t.Fatal("failed to dump request")
+				}
+
+				t.Fatalf("received unknown request: %s", dump)
+			}
+		}))
+	})
+
+	it.After(func() {
+		server.Close()
+	})
+
+	it("performs the action on every tagged server", func() {
+		cmd := exec.Command(builtBinaryPath,
+			"-t", "some-magic-token",
+			"-u", server.URL,
+			"compute",
+			"server-action",
+			"power-off",
+			"--tag-name", "web",
+			"--format", "ID,Status,ResourceID",
+		)
+
+		output, err := cmd.CombinedOutput()
+		expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+		expect.Equal(strings.TrimSpace(serverActionsByTagOutput), strings.TrimSpace(string(output)))
+	})
+
+	it("waits for all of the actions", func() {
+		cmd := exec.Command(builtBinaryPath,
+			"-t", "some-magic-token",
+			"-u", server.URL,
+			"compute",
+			"server-action",
+			"power-off",
+			"--tag-name", "web",
+			"--wait",
+			"--format", "ID,Status,ResourceID",
+		)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+
+		output, err := cmd.Output()
+		expect.NoError(err, fmt.Sprintf("received error output: %s", stderr.String()))
+		expect.Equal(strings.TrimSpace(serverActionsByTagWaitOutput), strings.TrimSpace(string(output)))
+		expect.Contains(stderr.String(), "action 101 (power_off on server 101): in-progress\n")
+		expect.Contains(stderr.String(), "action 102 (power_off on server 102): completed\n")
+	})
+
+	it("rejects a server ID with a tag", func() {
+		cmd := exec.Command(builtBinaryPath,
+			"-t", "some-magic-token",
+			"-u", server.URL,
+			"compute",
+			"server-action",
+			"power-off",
+			"34",
+			"--tag-name", "web",
+		)
+
+		output, err := cmd.CombinedOutput()
+		expect.Error(err)
+		expect.Contains(string(output), "Please specify Server identifier or a tag name.")
+	})
+})
+
+const (
+	serverActionsByTagOutput = `
+ID     Status         Resource ID
+101    in-progress    101
+102    in-progress    102
+`
+	serverActionsByTagWaitOutput = `
+ID     Status       Resource ID
+101    completed    101
+102    completed    102
+`
+	serverActionsByTagResponse = `
+{
+  "actions": [
+    {"id": 101, "status": "in-progress", "type": "power_off", "resource_id": 101, "resource_type": "server"},
+    {"id": 102, "status": "in-progress", "type": "power_off", "resource_id": 102, "resource_type": "server"}
+  ]
+}
+`
+)
+
 const (
 	serverActionOutput = `
 ID          Status         Type              Started At                       Completed At    Resource ID    Resource Type    Region
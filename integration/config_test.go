@@ -0,0 +1,386 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+)
+
+var _ = suite("config/set-default", func(t *testing.T, when spec.G, it spec.S) {
+	var (
+		expect     *require.Assertions
+		server     *httptest.Server
+		reqBody    []byte
+		tmpDir     string
+		testConfig string
+	)
+
+	it.Before(func() {
+		expect = require.New(t)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/v2/servers":
+				if req.Header.Get("Authorization") != "Bearer team-token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				var err error
+				reqBody, err = ioutil.ReadAll(req.Body)
+				expect.NoError(err)
+
+				w.Write([]byte(serverCreateResponse))
+			default:
+				dump, err := httputil.DumpRequest(req, true)
+				if err != nil {
+					t.Fatal("failed to dump request")
+				}
+
+				t.Fatalf("received unknown request: %s", dump)
+			}
+		}))
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "")
+		expect.NoError(err)
+
+		testConfig = filepath.Join(tmpDir, "config.yaml")
+		err = ioutil.WriteFile(testConfig, []byte("auth-contexts:\n  team: team-token\n"), 0600)
+		expect.NoError(err)
+	})
+
+	it.After(func() {
+		server.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	it("uses the context's defaults for the flags that aren't given", func() {
+		for _, args := range [][]string{
+			{"compute.server.create.region", "syd"},
+			{"compute.server.create.size", "std-min"},
+			{"server.create.ssh-keys", "123,456"},
+		} {
+			cmd := exec.Command(builtBinaryPath, append([]string{
+				"--config", testConfig,
+				"--context", "team",
+				"config",
+				"set-default",
+			}, args...)...)
+
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+		}
+
+		cmd := exec.Command(builtBinaryPath,
+			"--config", testConfig,
+			"--context", "team",
+			"config",
+			"unset-default",
+			"compute.server.create.size",
+		)
+		output, err := cmd.CombinedOutput()
+		expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+
+		cmd = exec.Command(builtBinaryPath,
+			"--config", testConfig,
+			"--context", "team",
+			"-u", server.URL,
+			"compute",
+			"server",
+			"create",
+			"some-server-name",
+			"--image", "a-test-image",
+			"--size", "a-test-size",
+		)
+		output, err = cmd.CombinedOutput()
+		expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+
+		request := &struct {
+			Region  string
+			Size    string
+			SSHKeys []interface{} `json:"ssh_keys"`
+		}{}
+		err = json.Unmarshal(reqBody, request)
+		expect.NoError(err)
+
+		expect.Equal("syd", request.Region)
+		expect.Equal("a-test-size", request.Size)
+		expect.Equal([]interface{}{float64(123), float64(456)}, request.SSHKeys)
+	})
+
+	it("leaves other contexts alone", func() {
+		cmd := exec.Command(builtBinaryPath,
+			"--config", testConfig,
+			"--context", "team",
+			"config",
+			"set-default",
+			"compute.server.create.region",
+			"syd",
+		)
+		output, err := cmd.CombinedOutput()
+		expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+
+		cmd = exec.Command(builtBinaryPath,
+			"--config", testConfig,
+			"-t", "some-magic-token",
+			"-u", server.URL,
+			"compute",
+			"server",
+			"create",
+			"some-server-name",
+			"--image", "a-test-image",
+			"--size", "a-test-size",
+		)
+		output, err = cmd.CombinedOutput()
+		expect.Error(err)
+		expect.Contains(string(output), "Error: (server.create.region) command is missing required arguments")
+	})
+
+	it("rejects flags that don't exist", func() {
+		cmd := exec.Command(builtBinaryPath,
+			"--config", testConfig,
+			"config",
+			"set-default",
+			"compute.server.create.colour",
+			"blue",
+		)
+		output, err := cmd.CombinedOutput()
+		expect.Error(err)
+		expect.Contains(string(output), `"compute.server.create.colour" isn't a command flag`)
+	})
+})
+
+var _ = suite("config/view", func(t *testing.T, when spec.G, it spec.S) {
+	var (
+		expect     *require.Assertions
+		tmpDir     string
+		testConfig string
+	)
+
+	it.Before(func() {
+		expect = require.New(t)
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "")
+		expect.NoError(err)
+
+		testConfig = filepath.Join(tmpDir, "config.yaml")
+		err = ioutil.WriteFile(testConfig, []byte("auth-contexts:\n  team: team-token\nmax-retries: 3\n"), 0600)
+		expect.NoError(err)
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	it("redacts access tokens and shows where values come from", func() {
+		cmd := exec.Command(builtBinaryPath,
+			"--config", testConfig,
+			"config",
+			"view",
+		)
+		cmd.Env = append(os.Environ(), "BINARYLANE_OUTPUT=text")
+		output, err := cmd.CombinedOutput()
+		expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+
+		expect.Contains(string(output), "auth-contexts.team    REDACTED")
+		expect.NotContains(string(output), "team-token")
+		expect.Regexp(`max-retries +3 +file`, string(output))
+		expect.Regexp(`output +text +env`, string(output))
+	})
+
+	it("gets, sets and unsets a setting", func() {
+		cmd := exec.Command(builtBinaryPath,
+			"--config", testConfig,
+			"config",
+			"set",
+			"compute.ssh.ssh-user",
+			"admin",
+		)
+		output, err := cmd.CombinedOutput()
+		expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+
+		cmd = exec.Command(builtBinaryPath,
+			"--config", testConfig,
+			"config",
+			"get",
+			"compute.ssh.ssh-user",
+			"--format", "Value",
+			"--no-header",
+		)
+		output, err = cmd.CombinedOutput()
+		expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+		expect.Equal("admin\n", string(output))
+
+		cmd = exec.Command(builtBinaryPath,
+			"--config", testConfig,
+			"config",
+			"unset",
+			"max-retries",
+		)
+		output, err = cmd.CombinedOutput()
+		expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+
+		b, err := ioutil.ReadFile(testConfig)
+		expect.NoError(err)
+		expect.Equal("auth-contexts:\n  team: team-token\ncompute:\n  ssh:\n    ssh-user: admin\n", string(b))
+	})
+
+	it("reports a config file that can't be read", func() {
+		err := ioutil.WriteFile(testConfig, []byte("max-retries: [\n"), 0600)
+		expect.NoError(err)
+
+		cmd := exec.Command(builtBinaryPath,
+			"--config", testConfig,
+			"account",
+			"get",
+		)
+		output, err := cmd.CombinedOutput()
+		expect.Error(err)
+		expect.Contains(string(output), "Unable to read the config file "+testConfig)
+		expect.Contains(string(output), "Run bl config validate to check it")
+
+		cmd = exec.Command(builtBinaryPath,
+			"--config", testConfig,
+			"config",
+			"path",
+		)
+		output, err = cmd.CombinedOutput()
+		expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+		expect.Equal(testConfig+"\n", string(output))
+	})
+})
+
+var _ = suite("config/project", func(t *testing.T, when spec.G, it spec.S) {
+	var (
+		expect     *require.Assertions
+		server     *httptest.Server
+		reqBody    []byte
+		tmpDir     string
+		testConfig string
+		repoDir    string
+	)
+
+	it.Before(func() {
+		expect = require.New(t)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/v2/servers":
+				if req.Header.Get("Authorization") != "Bearer team-token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				var err error
+				reqBody, err = ioutil.ReadAll(req.Body)
+				expect.NoError(err)
+
+				w.Write([]byte(serverCreateResponse))
+			default:
+				dump, err := httputil.DumpRequest(req, true)
+				if err != nil {
+					t.Fatal("failed to dump request")
+				}
+
+				t.Fatalf("received unknown request: %s", dump)
+			}
+		}))
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "")
+		expect.NoError(err)
+
+		testConfig = filepath.Join(tmpDir, "config.yaml")
+		err = ioutil.WriteFile(testConfig, []byte("auth-contexts:\n  default: default-token\n  team: team-token\ndefaults:\n  team:\n    server.create.region: syd\n"), 0600)
+		expect.NoError(err)
+
+		repoDir = filepath.Join(tmpDir, "repo", "deploy")
+		expect.NoError(os.MkdirAll(repoDir, 0755))
+		err = ioutil.WriteFile(filepath.Join(tmpDir, "repo", ".bl.yaml"), []byte("context: team\nregion: bne\noutput: json\n"), 0600)
+		expect.NoError(err)
+	})
+
+	it.After(func() {
+		server.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	it("uses the context, region and output format of the project", func() {
+		cmd := exec.Command(builtBinaryPath,
+			"--config", testConfig,
+			"-u", server.URL,
+			"compute",
+			"server",
+			"create",
+			"some-server-name",
+			"--image", "a-test-image",
+			"--size", "a-test-size",
+		)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+		expect.True(json.Valid(output), fmt.Sprintf("expected JSON output: %s", output))
+
+		request := &struct {
+			Region string
+		}{}
+		err = json.Unmarshal(reqBody, request)
+		expect.NoError(err)
+		expect.Equal("bne", request.Region)
+	})
+
+	it("lets flags override the project", func() {
+		cmd := exec.Command(builtBinaryPath,
+			"--config", testConfig,
+			"-u", server.URL,
+			"--output", "text",
+			"compute",
+			"server",
+			"create",
+			"some-server-name",
+			"--image", "a-test-image",
+			"--size", "a-test-size",
+			"--region", "per",
+		)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+		expect.False(json.Valid(output), fmt.Sprintf("expected text output: %s", output))
+
+		request := &struct {
+			Region string
+		}{}
+		err = json.Unmarshal(reqBody, request)
+		expect.NoError(err)
+		expect.Equal("per", request.Region)
+	})
+
+	it("rejects settings a project can't pin", func() {
+		err := ioutil.WriteFile(filepath.Join(tmpDir, "repo", ".bl.yaml"), []byte("api-url: https://example.com\n"), 0600)
+		expect.NoError(err)
+
+		cmd := exec.Command(builtBinaryPath,
+			"--config", testConfig,
+			"-u", server.URL,
+			"compute",
+			"server",
+			"list",
+		)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		expect.Error(err)
+		expect.Contains(string(output), `unknown key "api-url"`)
+	})
+})
@@ -0,0 +1,105 @@
+// +build !windows
+
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/binarylane/bl-cli/pkg/fakeapi"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+)
+
+var _ = suite("dev/fake-api", func(t *testing.T, when spec.G, it spec.S) {
+	var expect *require.Assertions
+
+	it.Before(func() {
+		expect = require.New(t)
+	})
+
+	when("commands are run against the fake API package", func() {
+		var server *httptest.Server
+
+		it.Before(func() {
+			api := fakeapi.New()
+			api.ActionDuration = 0
+			server = httptest.NewServer(api)
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		it("keeps the state between commands", func() {
+			bl := func(args ...string) string {
+				cmd := exec.Command(builtBinaryPath, append([]string{"-t", "some-magic-token", "-u", server.URL}, args...)...)
+				output, err := cmd.CombinedOutput()
+				expect.NoError(err, string(output))
+				return strings.TrimSpace(string(output))
+			}
+
+			for _, name := range []string{"web-1", "web-2"} {
+				bl("compute", "server", "create", name,
+					"--region", "syd", "--size", "std-min", "--image", "ubuntu-22.04", "--tag-name", "web", "--wait")
+			}
+			bl("compute", "server-action", "power-off", "1001", "--wait")
+
+			output := bl("compute", "server", "list", "--format", "ID,Name,Status,Tags")
+			expect.Equal(strings.TrimSpace(fakeAPIServerListOutput), output)
+
+			bl("compute", "server", "delete", "--tag-name", "web", "--force")
+
+			output = bl("compute", "server", "list", "--format", "ID,Name", "--no-header")
+			expect.Empty(output)
+		})
+	})
+
+	when("bl dev fake-api is run", func() {
+		it("serves the fake API until it is interrupted", func() {
+			cmd := exec.Command(builtBinaryPath, "dev", "fake-api", "--listen", "127.0.0.1:0")
+			stdout, err := cmd.StdoutPipe()
+			expect.NoError(err)
+			var stderr bytes.Buffer
+			cmd.Stderr = &stderr
+			expect.NoError(cmd.Start())
+
+			lines := make(chan string, 1)
+			go func() {
+				line, _ := bufio.NewReader(stdout).ReadString('\n')
+				lines <- line
+			}()
+
+			var line string
+			select {
+			case line = <-lines:
+			case <-time.After(10 * time.Second):
+				cmd.Process.Kill()
+				t.Fatal("the fake API did not start")
+			}
+			expect.Contains(line, "Serving a fake BinaryLane API at http://127.0.0.1:")
+			url := strings.TrimSpace(line[strings.Index(line, "http://"):])
+
+			region := exec.Command(builtBinaryPath, "-t", "some-magic-token", "-u", url,
+				"compute", "region", "list", "--format", "Slug,Name", "--no-header")
+			output, err := region.CombinedOutput()
+			expect.NoError(err, string(output))
+			expect.Contains(string(output), "syd    Sydney")
+
+			expect.NoError(cmd.Process.Signal(os.Interrupt))
+			expect.NoError(cmd.Wait(), stderr.String())
+		})
+	})
+})
+
+const fakeAPIServerListOutput = `
+ID      Name     Status    Tags
+1001    web-1    off       web
+1003    web-2    active    web
+`
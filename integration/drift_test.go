@@ -0,0 +1,113 @@
+package integration
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+)
+
+var _ = suite("drift", func(t *testing.T, when spec.G, it spec.S) {
+	var (
+		expect   *require.Assertions
+		server   *httptest.Server
+		specPath string
+	)
+
+	it.Before(func() {
+		expect = require.New(t)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			auth := req.Header.Get("Authorization")
+			if auth != "Bearer some-magic-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			if req.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			switch req.URL.Path {
+			case "/v2/domains":
+				w.Write([]byte(driftDomainsResponse))
+			case "/v2/domains/example.com/records":
+				w.Write([]byte(driftRecordsResponse))
+			default:
+				dump, err := httputil.DumpRequest(req, true)
+				if err != nil {
+					t.Fatal("failed to dump request")
+				}
+
+				t.Fatalf("received unknown request: %s", dump)
+			}
+		}))
+
+		f, err := ioutil.TempFile("", "bl-drift-*.yaml")
+		expect.NoError(err)
+		_, err = f.WriteString(driftSpec)
+		expect.NoError(err)
+		expect.NoError(f.Close())
+		specPath = f.Name()
+	})
+
+	it.After(func() {
+		os.Remove(specPath)
+	})
+
+	when("the live resources differ from the stack spec", func() {
+		it("lists the differences and exits with an error", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"drift",
+				"--spec", specPath,
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.Error(err, fmt.Sprintf("received output: %s", output))
+			expect.Equal(10, cmd.ProcessState.ExitCode())
+			expect.Equal(strings.TrimSpace(driftOutput), strings.TrimSpace(string(output)))
+		})
+	})
+})
+
+const (
+	driftSpec = `
+domains:
+  - name: example.com
+    records:
+      - {type: A, name: www, data: 192.0.2.10, ttl: 300}
+`
+	driftOutput = `
+Kind             Name                 Field    Expected    Actual
+domain_record    A www.example.com    ttl      300         3600
+Error: Found 1 difference between the stack spec and live resources
+`
+	driftDomainsResponse = `
+{
+  "domains": [
+    {"name": "example.com", "ttl": 1800}
+  ],
+  "links": {},
+  "meta": {"total": 1}
+}`
+	driftRecordsResponse = `
+{
+  "domain_records": [
+    {"id": 1, "type": "NS", "name": "@", "data": "ns1.binarylane.com.au"},
+    {"id": 2, "type": "A", "name": "www", "data": "192.0.2.10", "ttl": 3600}
+  ],
+  "links": {},
+  "meta": {"total": 2}
+}`
+)
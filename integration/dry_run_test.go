@@ -0,0 +1,125 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+)
+
+var _ = suite("dry-run", func(t *testing.T, when spec.G, it spec.S) {
+	var (
+		expect *require.Assertions
+		server *httptest.Server
+	)
+
+	it.Before(func() {
+		expect = require.New(t)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			dump, err := httputil.DumpRequest(req, true)
+			if err != nil {
+				t.Fatal("failed to dump request")
+			}
+
+			t.Fatalf("received unexpected request: %s", dump)
+		}))
+	})
+
+	when("deleting a server", func() {
+		it("prints the request instead of sending it", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"--dry-run",
+				"compute",
+				"server",
+				"delete",
+				"1111",
+				"--force",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+			expect.Equal("DELETE "+server.URL+"/v2/servers/1111", strings.TrimSpace(string(output)))
+		})
+	})
+
+	when("performing an action and waiting for it", func() {
+		it("prints the request and does not wait", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"--dry-run",
+				"compute",
+				"server-action",
+				"power-off",
+				"1111",
+				"--wait",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+			expect.Equal(strings.TrimSpace(fmt.Sprintf(dryRunActionOutput, server.URL)), strings.TrimSpace(string(output)))
+		})
+	})
+
+	when("the output is json", func() {
+		it("prints the requests as json", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"--dry-run",
+				"-o", "json",
+				"compute",
+				"firewall",
+				"update",
+				"fw-id",
+				"--name", "web",
+				"--inbound-rules", "protocol:tcp,ports:22,address:0.0.0.0/0",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+			expect.Equal(strings.TrimSpace(fmt.Sprintf(dryRunJSONOutput, server.URL)), strings.TrimSpace(string(output)))
+		})
+	})
+
+	when("the output is ndjson", func() {
+		it("prints the requests as json", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"--dry-run",
+				"-o", "ndjson",
+				"compute",
+				"server",
+				"delete",
+				"1111",
+				"--force",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+			expect.Equal(`{"method":"DELETE","url":"`+server.URL+`/v2/servers/1111"}`, strings.TrimSpace(string(output)))
+		})
+	})
+})
+
+const (
+	dryRunActionOutput = `
+POST %s/v2/servers/1111/actions
+{
+  "type": "power_off"
+}
+`
+	dryRunJSONOutput = `
+{"method":"PUT","url":"%s/v2/firewalls/fw-id","body":{"name":"web","inbound_rules":[{"protocol":"tcp","ports":"22","sources":{"addresses":["0.0.0.0/0"]}}],"outbound_rules":null,"server_ids":[],"tags":[]}}
+`
+)
@@ -0,0 +1,149 @@
+package integration
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+)
+
+var _ = suite("errors", func(t *testing.T, when spec.G, it spec.S) {
+	var (
+		expect *require.Assertions
+		server *httptest.Server
+	)
+
+	it.Before(func() {
+		expect = require.New(t)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/v2/servers/1111":
+				w.Header().Set("X-Request-Id", "d6f5b4c2")
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"id":"not_found","message":"The resource you were accessing could not be found."}`))
+			case "/v2/servers/2222":
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"id":"too_many_requests","message":"API Rate limit exceeded."}`))
+			default:
+				t.Fatalf("received unexpected request: %s %s", req.Method, req.URL)
+			}
+		}))
+	})
+
+	it.After(func() {
+		server.Close()
+	})
+
+	when("the resource is not found", func() {
+		it("exits with the not found exit code", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"--max-retries", "0",
+				"compute",
+				"server",
+				"get",
+				"1111",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.Error(err)
+			expect.Equal(5, cmd.ProcessState.ExitCode())
+			expect.Contains(string(output), "Error: GET "+server.URL+"/v2/servers/1111: 404")
+		})
+	})
+
+	when("the output is json", func() {
+		it("writes the error to stderr as json", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"--max-retries", "0",
+				"-o", "json",
+				"compute",
+				"server",
+				"get",
+				"1111",
+			)
+			var stdout, stderr bytes.Buffer
+			cmd.Stdout = &stdout
+			cmd.Stderr = &stderr
+
+			err := cmd.Run()
+			expect.Error(err)
+			expect.Equal(5, cmd.ProcessState.ExitCode())
+			expect.Empty(stdout.String())
+			expect.JSONEq(fmt.Sprintf(notFoundErrorOutput, server.URL), stderr.String())
+		})
+	})
+
+	when("the request is rate limited", func() {
+		it("exits with the rate limited exit code", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"--max-retries", "0",
+				"compute",
+				"server",
+				"get",
+				"2222",
+			)
+
+			_, err := cmd.CombinedOutput()
+			expect.Error(err)
+			expect.Equal(7, cmd.ProcessState.ExitCode())
+		})
+	})
+
+	when("an argument is missing", func() {
+		it("exits with the usage exit code", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"compute",
+				"server",
+				"get",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.Error(err)
+			expect.Equal(2, cmd.ProcessState.ExitCode())
+			expect.Contains(string(output), "Error: (server.get) command is missing required arguments")
+		})
+	})
+
+	when("a flag is unknown", func() {
+		it("exits with the usage exit code", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"compute",
+				"server",
+				"get",
+				"1111",
+				"--bogus",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.Error(err)
+			expect.Equal(2, cmd.ProcessState.ExitCode())
+			expect.Contains(string(output), "Error: unknown flag: --bogus")
+		})
+	})
+})
+
+const notFoundErrorOutput = `
+{
+  "code": "not_found",
+  "message": "GET %s/v2/servers/1111: 404 The resource you were accessing could not be found.",
+  "request_id": "d6f5b4c2",
+  "status": 404,
+  "exit_code": 5
+}
+`
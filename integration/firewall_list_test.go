@@ -67,6 +67,40 @@ var _ = suite("compute/firewall/list", func(t *testing.T, when spec.G, it spec.S
 			}
 		})
 	})
+
+	when("a jsonpath output type is passed", func() {
+		it("prints the selected fields", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"-o", "jsonpath={.[*].id}",
+				"compute",
+				"firewall",
+				"list",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+			expect.Equal("e4b9c960-d385-4950-84f3-d102162e6be5", strings.TrimSpace(string(output)))
+		})
+	})
+
+	when("a go-template output type is passed", func() {
+		it("executes the template against the json output", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"-o", `go-template={{range .}}{{.name}}: {{(index .inbound_rules 0).ports}}{{end}}`,
+				"compute",
+				"firewall",
+				"list",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+			expect.Equal("test-firewall: 443", strings.TrimSpace(string(output)))
+		})
+	})
 })
 
 const firewallListResponse = `{
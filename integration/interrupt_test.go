@@ -0,0 +1,73 @@
+// +build !windows
+
+package integration
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+)
+
+var _ = suite("interrupt", func(t *testing.T, when spec.G, it spec.S) {
+	var (
+		expect   *require.Assertions
+		server   *httptest.Server
+		received chan struct{}
+		release  chan struct{}
+	)
+
+	it.Before(func() {
+		expect = require.New(t)
+		received = make(chan struct{}, 1)
+		release = make(chan struct{})
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			received <- struct{}{}
+
+			// Hang until the client gives up or the test finishes.
+			select {
+			case <-req.Context().Done():
+			case <-release:
+			}
+		}))
+	})
+
+	it.After(func() {
+		close(release)
+		server.Close()
+	})
+
+	when("the command is interrupted", func() {
+		it("cancels the request", func() {
+			var output bytes.Buffer
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"compute",
+				"region",
+				"list",
+			)
+			cmd.Stdout = &output
+			cmd.Stderr = &output
+
+			expect.NoError(cmd.Start())
+
+			select {
+			case <-received:
+			case <-time.After(10 * time.Second):
+				t.Fatal("the request was not received")
+			}
+			expect.NoError(cmd.Process.Signal(os.Interrupt))
+
+			expect.Error(cmd.Wait())
+			expect.Contains(output.String(), "Error: interrupted")
+		})
+	})
+})
@@ -0,0 +1,133 @@
+package integration
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+)
+
+var _ = suite("plan", func(t *testing.T, when spec.G, it spec.S) {
+	var (
+		expect   *require.Assertions
+		server   *httptest.Server
+		specPath string
+	)
+
+	it.Before(func() {
+		expect = require.New(t)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			auth := req.Header.Get("Authorization")
+			if auth != "Bearer some-magic-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			if req.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			switch req.URL.Path {
+			case "/v2/vpcs":
+				w.Write([]byte(planVPCsResponse))
+			case "/v2/servers":
+				w.Write([]byte(planServersResponse))
+			default:
+				dump, err := httputil.DumpRequest(req, true)
+				if err != nil {
+					t.Fatal("failed to dump request")
+				}
+
+				t.Fatalf("received unknown request: %s", dump)
+			}
+		}))
+
+		f, err := ioutil.TempFile("", "bl-plan-*.yaml")
+		expect.NoError(err)
+		_, err = f.WriteString(planSpec)
+		expect.NoError(err)
+		expect.NoError(f.Close())
+		specPath = f.Name()
+	})
+
+	it.After(func() {
+		os.Remove(specPath)
+	})
+
+	when("the stack spec differs from the live resources", func() {
+		it("lists the changes", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"plan",
+				"--file", specPath,
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+			expect.Equal(strings.TrimSpace(planOutput), strings.TrimSpace(string(output)))
+		})
+	})
+})
+
+const (
+	planSpec = `
+vpcs:
+  - name: prod
+    region: syd
+servers:
+  - name: web-1
+    region: syd
+    size: std-min
+    image: ubuntu-20-04-lts
+    vpc: prod
+    tags: [web]
+  - name: web-2
+    region: syd
+    size: std-min
+    image: ubuntu-20-04-lts
+    vpc: prod
+`
+	planOutput = `
+Action    Kind      Name     Detail
+update    server    web-1    +tag:web
+create    server    web-2    syd std-min ubuntu-20-04-lts
+`
+	planVPCsResponse = `
+{
+  "vpcs": [
+    {
+      "id": 5,
+      "name": "prod",
+      "region": "syd",
+      "ip_range": "10.240.0.0/16"
+    }
+  ],
+  "links": {},
+  "meta": {"total": 1}
+}`
+	planServersResponse = `
+{
+  "servers": [
+    {
+      "id": 5555,
+      "name": "web-1",
+      "region": {"slug": "syd"},
+      "vpc_id": 5,
+      "tags": []
+    }
+  ],
+  "links": {},
+  "meta": {"total": 1}
+}`
+)
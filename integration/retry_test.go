@@ -0,0 +1,90 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+)
+
+var _ = suite("retry", func(t *testing.T, when spec.G, it spec.S) {
+	var (
+		expect   *require.Assertions
+		server   *httptest.Server
+		attempts int
+	)
+
+	it.Before(func() {
+		expect = require.New(t)
+		attempts = 0
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/v2/regions":
+				auth := req.Header.Get("Authorization")
+				if auth != "Bearer some-magic-token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				attempts++
+				if attempts == 1 {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+					w.Write([]byte(`{"id":"too_many_requests","message":"API Rate limit exceeded."}`))
+					return
+				}
+
+				w.Write([]byte(regionListResponse))
+			default:
+				dump, err := httputil.DumpRequest(req, true)
+				if err != nil {
+					t.Fatal("failed to dump request")
+				}
+
+				t.Fatalf("received unknown request: %s", dump)
+			}
+		}))
+	})
+
+	when("a request is rate limited", func() {
+		it("retries the request", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"compute",
+				"region",
+				"list",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+			expect.Equal(strings.TrimSpace(regionListOutput), strings.TrimSpace(string(output)))
+			expect.Equal(2, attempts)
+		})
+	})
+
+	when("retries are disabled", func() {
+		it("returns the error", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"--max-retries", "0",
+				"compute",
+				"region",
+				"list",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.Error(err)
+			expect.Contains(string(output), "429 API Rate limit exceeded.")
+			expect.Equal(1, attempts)
+		})
+	})
+})
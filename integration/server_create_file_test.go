@@ -0,0 +1,208 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+)
+
+var _ = suite("compute/server/create/file", func(t *testing.T, when spec.G, it spec.S) {
+	var (
+		expect   *require.Assertions
+		server   *httptest.Server
+		dir      string
+		mu       sync.Mutex
+		requests map[string]map[string]interface{}
+		failName string
+	)
+
+	it.Before(func() {
+		expect = require.New(t)
+		requests = map[string]map[string]interface{}{}
+		failName = ""
+
+		var err error
+		dir, err = ioutil.TempDir("", "bl-server-spec")
+		expect.NoError(err)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/v2/servers":
+				if req.Header.Get("Authorization") != "Bearer some-magic-token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				if req.Method != http.MethodPost {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+
+				var r map[string]interface{}
+				err := json.NewDecoder(req.Body).Decode(&r)
+				expect.NoError(err)
+
+				name := r["name"].(string)
+				mu.Lock()
+				requests[name] = r
+				mu.Unlock()
+
+				if name == failName {
+					w.WriteHeader(http.StatusUnprocessableEntity)
+					w.Write([]byte(`{"id": "unprocessable_entity", "message": "size is unavailable"}`))
+					return
+				}
+
+				w.WriteHeader(http.StatusAccepted)
+				fmt.Fprintf(w, serverCreateFileResponse, 100+len(name), name)
+			default:
+				dump, err := httputil.DumpRequest(req, true)
+				if err != nil {
+					t.Fatal("failed to dump request")
+				}
+
+				t.Fatalf("received unknown request: %s", dump)
+			}
+		}))
+	})
+
+	it.After(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		expect.NoError(ioutil.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	when("a spec file is passed", func() {
+		it("creates every server of the file", func() {
+			writeFile("db-init.yaml", "#cloud-config\n")
+			specFile := writeFile("servers.yaml", serverCreateFileSpec)
+
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"compute",
+				"server",
+				"create",
+				"--file", specFile,
+				"--image", "ubuntu",
+				"--region", "syd",
+				"--concurrency", "2",
+				"--format", "Name",
+				"--no-header",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+			expect.Equal("web-01\nweb-02\nweb-03\ndb\n", string(output))
+
+			var names []string
+			for name := range requests {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			expect.Equal([]string{"db", "web-01", "web-02", "web-03"}, names)
+
+			expect.Equal("std-min", requests["web-01"]["size"])
+			expect.Equal("syd", requests["web-01"]["region"])
+			expect.Equal([]interface{}{"web"}, requests["web-01"]["tags"])
+			expect.Equal("std-4vcpu", requests["db"]["size"])
+			expect.Equal("#cloud-config\n", requests["db"]["user_data"])
+		})
+	})
+
+	when("some servers can't be created", func() {
+		it("displays the others and reports the failures", func() {
+			failName = "web-02"
+			specFile := writeFile("servers.yaml", "servers:\n  - name: web-{01..03}\n    size: std-min\n")
+
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"compute",
+				"server",
+				"create",
+				"-f", specFile,
+				"--image", "ubuntu",
+				"--region", "syd",
+				"--format", "ID,Name",
+			)
+
+			var stdout, stderr strings.Builder
+			cmd.Stdout = &stdout
+			cmd.Stderr = &stderr
+
+			err := cmd.Run()
+			expect.Error(err)
+			expect.Equal(1, cmd.ProcessState.ExitCode())
+			expect.Equal(strings.TrimSpace(serverCreateFileOutput), strings.TrimSpace(stdout.String()))
+			expect.Contains(stderr.String(), `Could not create Server "web-02"`)
+			expect.Contains(stderr.String(), "size is unavailable")
+			expect.Contains(stderr.String(), "Error: 1 of 3 Servers could not be created")
+		})
+	})
+
+	when("server names are passed with a spec file", func() {
+		it("returns a usage error", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"compute",
+				"server",
+				"create",
+				"some-server-name",
+				"--file", "servers.yaml",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.Error(err)
+			expect.Equal(2, cmd.ProcessState.ExitCode())
+			expect.Contains(string(output), "Please specify Server names or a server spec file, not both.")
+		})
+	})
+})
+
+const (
+	serverCreateFileResponse = `
+{
+  "server": {
+    "id": %d,
+    "name": %q,
+    "status": "new",
+    "networks": {"v4": []},
+    "image": {"distribution": "Ubuntu", "name": "20.04"},
+    "region": {"slug": "syd"}
+  }
+}`
+	serverCreateFileSpec = `
+servers:
+  - name: web-{01..03}
+    size: std-min
+    tags: [web]
+  - name: db
+    size: std-4vcpu
+    user_data_file: db-init.yaml
+`
+	serverCreateFileOutput = `
+ID     Name
+106    web-01
+106    web-03
+`
+)
@@ -0,0 +1,150 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/mail"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+)
+
+var _ = suite("compute/server/create/user-data", func(t *testing.T, when spec.G, it spec.S) {
+	var (
+		expect   *require.Assertions
+		server   *httptest.Server
+		dir      string
+		userData string
+		created  bool
+	)
+
+	it.Before(func() {
+		expect = require.New(t)
+		userData = ""
+		created = false
+
+		var err error
+		dir, err = ioutil.TempDir("", "bl-user-data")
+		expect.NoError(err)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/v2/servers":
+				if req.Header.Get("Authorization") != "Bearer some-magic-token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				var r struct {
+					UserData string `json:"user_data"`
+				}
+				err := json.NewDecoder(req.Body).Decode(&r)
+				expect.NoError(err)
+				userData = r.UserData
+				created = true
+
+				w.Write([]byte(serverCreateResponse))
+			default:
+				dump, err := httputil.DumpRequest(req, true)
+				if err != nil {
+					t.Fatal("failed to dump request")
+				}
+
+				t.Fatalf("received unknown request: %s", dump)
+			}
+		}))
+	})
+
+	it.After(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		expect.NoError(ioutil.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	when("several user-data files are passed", func() {
+		it("renders them into a multipart MIME document", func() {
+			cloudConfig := writeFile("cloud.yaml", "#cloud-config\nfqdn: {{.Name}}.{{.Values.domain}}\n")
+			script := writeFile("setup.sh", "#!/bin/sh\necho {{.Region}}\n")
+
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"compute",
+				"server",
+				"create",
+				"some-server-name",
+				"--image", "a-test-image",
+				"--region", "syd",
+				"--size", "a-test-size",
+				"--user-data-file", cloudConfig,
+				"--user-data-file", script,
+				"--user-data-var", "domain=example.com",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+
+			msg, err := mail.ReadMessage(strings.NewReader(userData))
+			expect.NoError(err)
+			_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+			expect.NoError(err)
+
+			r := multipart.NewReader(msg.Body, params["boundary"])
+			var parts []string
+			for {
+				p, err := r.NextPart()
+				if err != nil {
+					break
+				}
+				b, err := ioutil.ReadAll(p)
+				expect.NoError(err)
+				parts = append(parts, p.Header.Get("Content-Type")+"\n"+string(b))
+			}
+
+			expect.Equal([]string{
+				"text/cloud-config; charset=\"utf-8\"\n#cloud-config\nfqdn: some-server-name.example.com\n",
+				"text/x-shellscript; charset=\"utf-8\"\n#!/bin/sh\necho syd\n",
+			}, parts)
+		})
+	})
+
+	when("the cloud-config is invalid", func() {
+		it("fails without creating the server", func() {
+			cloudConfig := writeFile("cloud.yaml", "#cloud-config\npackages: [nginx\n")
+
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"compute",
+				"server",
+				"create",
+				"some-server-name",
+				"--image", "a-test-image",
+				"--region", "syd",
+				"--size", "a-test-size",
+				"--user-data-file", cloudConfig,
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.Error(err)
+			expect.Contains(string(output), "Error: Invalid user-data cloud.yaml: cloud-config is not valid YAML")
+			expect.False(created)
+		})
+	})
+})
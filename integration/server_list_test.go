@@ -94,6 +94,43 @@ var _ = suite("compute/server/list", func(t *testing.T, when spec.G, it spec.S)
 		})
 	})
 
+	when("a filter and sort order are provided", func() {
+		it("lists the matching servers in order", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"compute",
+				"server",
+				"list",
+				"--tag-name", "regions",
+				"--filter", "Status=active,Region in (my-region,not-regions)",
+				"--sort-by", "-id",
+				"--format", "ID,Region",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.NoError(err, fmt.Sprintf("received error output: %s", output))
+			expect.Equal(strings.TrimSpace(serverListSortedOutput), strings.TrimSpace(string(output)))
+		})
+	})
+
+	when("the filter names an unknown column", func() {
+		it("returns an error", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"compute",
+				"server",
+				"list",
+				"--filter", "Size=small",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.Error(err)
+			expect.Contains(string(output), `Error: unknown column "Size". Possible values: ID, Name,`)
+		})
+	})
+
 	when("there are no servers", func() {
 		it("lists only headers", func() {
 			cmd := exec.Command(builtBinaryPath,
@@ -171,6 +208,12 @@ ID      Name                Public IPv4    Private IPv4    Public IPv6    Memory
 	serverListRegionOutput = `
 ID      Name    Public IPv4    Private IPv4    Public IPv6    Memory    VCPUs    Disk    Region       Image                          VPC ID    Status    Tags        Features    Volumes
 1440                                                          0         0        0       my-region    some-distro some-image-name              active    test,yes    remotes     some-volume-id
+`
+
+	serverListSortedOutput = `
+ID      Region
+1440    my-region
+1111    not-regions
 `
 
 	serverListEmptyOutput = `
@@ -0,0 +1,54 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+)
+
+var _ = suite("timeout", func(t *testing.T, when spec.G, it spec.S) {
+	var (
+		expect  *require.Assertions
+		server  *httptest.Server
+		release chan struct{}
+	)
+
+	it.Before(func() {
+		expect = require.New(t)
+		release = make(chan struct{})
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			// Hang until the client gives up or the test finishes.
+			select {
+			case <-req.Context().Done():
+			case <-release:
+			}
+		}))
+	})
+
+	it.After(func() {
+		close(release)
+		server.Close()
+	})
+
+	when("a request takes longer than the timeout", func() {
+		it("returns an error", func() {
+			cmd := exec.Command(builtBinaryPath,
+				"-t", "some-magic-token",
+				"-u", server.URL,
+				"--timeout", "200ms",
+				"compute",
+				"region",
+				"list",
+			)
+
+			output, err := cmd.CombinedOutput()
+			expect.Error(err)
+			expect.Contains(string(output), "Error: timed out after 200ms")
+		})
+	})
+})
@@ -0,0 +1,74 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blcli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// The macOS Keychain is used through the security command. Exit status 44
+// means that there is no such item.
+const securityItemNotFound = 44
+
+func (s *keyringStore) Get(context string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", s.service, "-a", context, "-w").Output()
+	if err != nil {
+		return "", securityErr(err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func (s *keyringStore) Set(context, token string) error {
+	// With -w as its last option, security prompts for the password and
+	// its confirmation, so the token is passed on standard input rather
+	// than as an argument that other users could see.
+	cmd := exec.Command("security", "add-generic-password", "-U", "-s", s.service, "-a", context, "-w")
+	cmd.Stdin = strings.NewReader(token + "\n" + token + "\n")
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return securityErr(err)
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("unable to store the access token in the keychain: %s", msg)
+	}
+	return nil
+}
+
+func (s *keyringStore) Delete(context string) error {
+	err := exec.Command("security", "delete-generic-password", "-s", s.service, "-a", context).Run()
+	if err := securityErr(err); err != nil && err != ErrCredentialNotFound {
+		return err
+	}
+	return nil
+}
+
+func securityErr(err error) error {
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, exec.ErrNotFound):
+		return ErrKeyringUnavailable
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == securityItemNotFound:
+		return ErrCredentialNotFound
+	default:
+		return fmt.Errorf("unable to use the keychain: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+// +build !darwin,!windows
+
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blcli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// The Secret Service (GNOME Keyring, KWallet and others) is used through
+// secret-tool, from libsecret. It needs a D-Bus session, which headless
+// servers usually don't have.
+
+func (s *keyringStore) Get(context string) (string, error) {
+	if err := s.available(); err != nil {
+		return "", err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "lookup", "service", s.service, "context", context)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// secret-tool exits with 1 and says nothing when there is no
+		// such secret.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && strings.TrimSpace(stderr.String()) == "" {
+			return "", ErrCredentialNotFound
+		}
+		return "", secretToolErr(err, &stderr)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func (s *keyringStore) Set(context, token string) error {
+	if err := s.available(); err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "store", "--label", fmt.Sprintf("bl access token (%s)", context),
+		"service", s.service, "context", context)
+	cmd.Stdin = strings.NewReader(token)
+	cmd.Stderr = &stderr
+	return secretToolErr(cmd.Run(), &stderr)
+}
+
+func (s *keyringStore) Delete(context string) error {
+	if err := s.available(); err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "clear", "service", s.service, "context", context)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil && strings.TrimSpace(stderr.String()) != "" {
+		return secretToolErr(err, &stderr)
+	}
+	return nil
+}
+
+func (s *keyringStore) available() error {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return ErrKeyringUnavailable
+	}
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" && os.Getenv("XDG_RUNTIME_DIR") == "" {
+		return ErrKeyringUnavailable
+	}
+	return nil
+}
+
+func secretToolErr(err error, stderr *bytes.Buffer) error {
+	if err == nil {
+		return nil
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("unable to use the keyring: %s", msg)
+	}
+	return fmt.Errorf("unable to use the keyring: %v", err)
+}
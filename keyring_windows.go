@@ -0,0 +1,125 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blcli
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// The Windows Credential Manager is used through advapi32, with a generic
+// credential named bl-cli:<context> for each context.
+
+var (
+	advapi32       = syscall.NewLazyDLL("advapi32.dll")
+	procCredRead   = advapi32.NewProc("CredReadW")
+	procCredWrite  = advapi32.NewProc("CredWriteW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	errorNotFound           = syscall.Errno(1168)
+)
+
+// credential is the CREDENTIALW structure.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+func (s *keyringStore) target(context string) (*uint16, error) {
+	return syscall.UTF16PtrFromString(s.service + ":" + context)
+}
+
+func (s *keyringStore) Get(context string) (string, error) {
+	target, err := s.target(context)
+	if err != nil {
+		return "", err
+	}
+
+	var cred *credential
+	r, _, err := procCredRead.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		return "", credErr(err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	blob := (*[1 << 20]byte)(unsafe.Pointer(cred.CredentialBlob))[:cred.CredentialBlobSize:cred.CredentialBlobSize]
+	return string(blob), nil
+}
+
+func (s *keyringStore) Set(context, token string) error {
+	target, err := s.target(context)
+	if err != nil {
+		return err
+	}
+	user, err := syscall.UTF16PtrFromString(context)
+	if err != nil {
+		return err
+	}
+
+	blob := []byte(token)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+
+	r, _, err := procCredWrite.Call(uintptr(unsafe.Pointer(&cred)), 0)
+	if r == 0 {
+		return credErr(err)
+	}
+	return nil
+}
+
+func (s *keyringStore) Delete(context string) error {
+	target, err := s.target(context)
+	if err != nil {
+		return err
+	}
+
+	r, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0)
+	if r == 0 {
+		if err := credErr(err); err != ErrCredentialNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+func credErr(err error) error {
+	if err == errorNotFound {
+		return ErrCredentialNotFound
+	}
+	return fmt.Errorf("unable to use the Windows Credential Manager: %v", err)
+}
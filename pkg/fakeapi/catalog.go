@@ -0,0 +1,163 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/binarylane/go-binarylane"
+)
+
+var sizeSlugs = []string{"std-min", "std-1vcpu", "std-2vcpu", "std-4vcpu", "std-8vcpu"}
+
+// regions, sizes and images are fixed; they can't be changed through the API.
+var (
+	regions = []binarylane.Region{
+		{Slug: "syd", Name: "Sydney", Sizes: sizeSlugs, Available: true, Features: []string{"backups", "ipv6", "private_networking"}},
+		{Slug: "mel", Name: "Melbourne", Sizes: sizeSlugs, Available: true, Features: []string{"backups", "ipv6", "private_networking"}},
+		{Slug: "bne", Name: "Brisbane", Sizes: sizeSlugs, Available: true, Features: []string{"backups", "ipv6", "private_networking"}},
+		{Slug: "per", Name: "Perth", Sizes: sizeSlugs, Available: true, Features: []string{"backups", "ipv6", "private_networking"}},
+		{Slug: "sin", Name: "Singapore", Sizes: sizeSlugs, Available: true, Features: []string{"backups", "ipv6", "private_networking"}},
+	}
+
+	sizes = []binarylane.Size{
+		{Slug: "std-min", Memory: 1024, Vcpus: 1, Disk: 20, PriceMonthly: 7.5, PriceHourly: 0.0111, Available: true, Transfer: 1},
+		{Slug: "std-1vcpu", Memory: 2048, Vcpus: 1, Disk: 40, PriceMonthly: 15, PriceHourly: 0.0222, Available: true, Transfer: 2},
+		{Slug: "std-2vcpu", Memory: 4096, Vcpus: 2, Disk: 60, PriceMonthly: 30, PriceHourly: 0.0444, Available: true, Transfer: 3},
+		{Slug: "std-4vcpu", Memory: 8192, Vcpus: 4, Disk: 100, PriceMonthly: 60, PriceHourly: 0.0889, Available: true, Transfer: 4},
+		{Slug: "std-8vcpu", Memory: 16384, Vcpus: 8, Disk: 160, PriceMonthly: 120, PriceHourly: 0.1778, Available: true, Transfer: 5},
+	}
+
+	images = []binarylane.Image{
+		{ID: 1, Name: "Ubuntu 22.04 LTS", Type: "base", Distribution: "Ubuntu", Slug: "ubuntu-22.04", Public: true, MinDiskSize: 20, Status: "available"},
+		{ID: 2, Name: "Ubuntu 24.04 LTS", Type: "base", Distribution: "Ubuntu", Slug: "ubuntu-24.04", Public: true, MinDiskSize: 20, Status: "available"},
+		{ID: 3, Name: "Debian 12", Type: "base", Distribution: "Debian", Slug: "debian-12", Public: true, MinDiskSize: 20, Status: "available"},
+		{ID: 4, Name: "AlmaLinux 9", Type: "base", Distribution: "AlmaLinux", Slug: "almalinux-9", Public: true, MinDiskSize: 20, Status: "available"},
+	}
+)
+
+func init() {
+	slugs := make([]string, 0, len(regions))
+	for _, r := range regions {
+		slugs = append(slugs, r.Slug)
+	}
+	for i := range sizes {
+		sizes[i].Regions = slugs
+	}
+	for i := range images {
+		images[i].Regions = slugs
+	}
+}
+
+func findRegion(slug string) *binarylane.Region {
+	for i := range regions {
+		if regions[i].Slug == slug {
+			r := regions[i]
+			return &r
+		}
+	}
+	return nil
+}
+
+func findSize(slug string) *binarylane.Size {
+	for i := range sizes {
+		if sizes[i].Slug == slug {
+			s := sizes[i]
+			return &s
+		}
+	}
+	return nil
+}
+
+// findImage finds an image by its slug or its numeric ID.
+func findImage(v interface{}) *binarylane.Image {
+	for i := range images {
+		switch id := v.(type) {
+		case string:
+			if images[i].Slug != id && strconv.Itoa(images[i].ID) != id {
+				continue
+			}
+		case float64:
+			if float64(images[i].ID) != id {
+				continue
+			}
+		default:
+			continue
+		}
+		img := images[i]
+		return &img
+	}
+	return nil
+}
+
+func (a *API) serveRegions(w http.ResponseWriter, r *request) {
+	switch {
+	case len(r.path) != 0:
+		notFound(w)
+	case r.Method != http.MethodGet:
+		methodNotAllowed(w)
+	default:
+		writeList(w, r, "regions", regions)
+	}
+}
+
+func (a *API) serveSizes(w http.ResponseWriter, r *request) {
+	switch {
+	case len(r.path) != 0:
+		notFound(w)
+	case r.Method != http.MethodGet:
+		methodNotAllowed(w)
+	default:
+		writeList(w, r, "sizes", sizes)
+	}
+}
+
+func (a *API) serveImages(w http.ResponseWriter, r *request) {
+	switch {
+	case r.Method != http.MethodGet:
+		methodNotAllowed(w)
+	case len(r.path) == 0:
+		writeList(w, r, "images", images)
+	case len(r.path) == 1:
+		img := findImage(r.path[0])
+		if img == nil {
+			notFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"image": img})
+	default:
+		notFound(w)
+	}
+}
+
+func (a *API) serveAccount(w http.ResponseWriter, r *request) {
+	if len(r.path) != 0 {
+		notFound(w)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"account": binarylane.Account{
+			ServerLimit:   25,
+			Email:         "fake@example.com",
+			UUID:          "00000000-0000-4000-8000-000000000000",
+			EmailVerified: true,
+			Status:        "active",
+		},
+	})
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/binarylane/go-binarylane"
+)
+
+const defaultTTL = 1800
+
+var (
+	nameServers = []string{"ns1.binarylane.com.au", "ns2.binarylane.com.au", "ns3.binarylane.com.au"}
+
+	recordTypes = []string{"A", "AAAA", "CAA", "CNAME", "MX", "NS", "SRV", "TXT"}
+)
+
+func (a *API) serveDomains(w http.ResponseWriter, r *request) {
+	if len(r.path) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			writeList(w, r, "domains", a.domains)
+		case http.MethodPost:
+			a.createDomain(w, r)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	d := a.findDomain(r.path[0])
+	if d == nil {
+		notFound(w)
+		return
+	}
+
+	switch {
+	case len(r.path) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"domain": d})
+	case len(r.path) == 1 && r.Method == http.MethodDelete:
+		a.deleteDomain(d.Name)
+		noContent(w)
+	case len(r.path) == 1:
+		methodNotAllowed(w)
+	case r.path[1] == "records":
+		a.serveRecords(w, r, d)
+	default:
+		notFound(w)
+	}
+}
+
+func (a *API) findDomain(name string) *binarylane.Domain {
+	for _, d := range a.domains {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+func (a *API) createDomain(w http.ResponseWriter, r *request) {
+	var req binarylane.DomainCreateRequest
+	if !r.decode(w, &req) {
+		return
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(req.Name, "."))
+	if !strings.Contains(name, ".") {
+		unprocessable(w, "Name %q is not a valid domain name.", req.Name)
+		return
+	}
+	if a.findDomain(name) != nil {
+		unprocessable(w, "Name %q already exists.", name)
+		return
+	}
+
+	d := &binarylane.Domain{Name: name, TTL: defaultTTL}
+	a.domains = append(a.domains, d)
+
+	for _, ns := range nameServers {
+		a.addRecord(d, &binarylane.DomainRecord{Type: "NS", Name: "@", Data: ns, TTL: defaultTTL})
+	}
+	if req.IPAddress != "" {
+		a.addRecord(d, &binarylane.DomainRecord{Type: "A", Name: "@", Data: req.IPAddress, TTL: defaultTTL})
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"domain": d})
+}
+
+func (a *API) deleteDomain(name string) {
+	domains := a.domains[:0]
+	for _, d := range a.domains {
+		if d.Name != name {
+			domains = append(domains, d)
+		}
+	}
+	a.domains = domains
+	delete(a.records, name)
+}
+
+// addRecord adds a record to the domain, and updates the domain's zone file.
+func (a *API) addRecord(d *binarylane.Domain, rec *binarylane.DomainRecord) {
+	rec.ID = a.newID()
+	a.records[d.Name] = append(a.records[d.Name], rec)
+	a.updateZoneFile(d)
+}
+
+func (a *API) updateZoneFile(d *binarylane.Domain) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "$ORIGIN %s.\n$TTL %d\n", d.Name, d.TTL)
+	for _, rec := range a.records[d.Name] {
+		data := rec.Data
+		switch rec.Type {
+		case "MX":
+			data = fmt.Sprintf("%d %s", rec.Priority, rec.Data)
+		case "SRV":
+			data = fmt.Sprintf("%d %d %d %s", rec.Priority, rec.Weight, rec.Port, rec.Data)
+		case "TXT":
+			data = fmt.Sprintf("%q", rec.Data)
+		}
+		fmt.Fprintf(&b, "%s %d IN %s %s\n", rec.Name, rec.TTL, rec.Type, data)
+	}
+	d.ZoneFile = b.String()
+}
+
+func (a *API) serveRecords(w http.ResponseWriter, r *request, d *binarylane.Domain) {
+	if len(r.path) == 2 {
+		switch r.Method {
+		case http.MethodGet:
+			a.listRecords(w, r, d)
+		case http.MethodPost:
+			a.createRecord(w, r, d)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	if len(r.path) != 3 {
+		notFound(w)
+		return
+	}
+	id, _ := parseID(r.path[2])
+	var rec *binarylane.DomainRecord
+	for _, v := range a.records[d.Name] {
+		if v.ID == id {
+			rec = v
+		}
+	}
+	if rec == nil {
+		notFound(w)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"domain_record": rec})
+	case http.MethodPut:
+		var req binarylane.DomainRecordEditRequest
+		if !r.decode(w, &req) {
+			return
+		}
+		if req.Type != "" && req.Type != rec.Type {
+			unprocessable(w, "The type of a record can't be changed.")
+			return
+		}
+		if req.Name != "" {
+			rec.Name = req.Name
+		}
+		if req.Data != "" {
+			rec.Data = req.Data
+		}
+		if req.TTL != 0 {
+			rec.TTL = req.TTL
+		}
+		if req.Tag != "" {
+			rec.Tag = req.Tag
+		}
+		rec.Priority, rec.Port, rec.Weight, rec.Flags = req.Priority, req.Port, req.Weight, req.Flags
+		a.updateZoneFile(d)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"domain_record": rec})
+	case http.MethodDelete:
+		records := a.records[d.Name][:0]
+		for _, v := range a.records[d.Name] {
+			if v.ID != id {
+				records = append(records, v)
+			}
+		}
+		a.records[d.Name] = records
+		a.updateZoneFile(d)
+		noContent(w)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// listRecords lists the domain's records, filtered by the type and name in
+// the query. The name may be relative to the domain or fully qualified.
+func (a *API) listRecords(w http.ResponseWriter, r *request, d *binarylane.Domain) {
+	query := r.URL.Query()
+	recordType, name := query.Get("type"), strings.TrimSuffix(query.Get("name"), ".")
+
+	records := []*binarylane.DomainRecord{}
+	for _, rec := range a.records[d.Name] {
+		if recordType != "" && rec.Type != recordType {
+			continue
+		}
+		if name != "" && !recordHasName(d, rec, name) {
+			continue
+		}
+		records = append(records, rec)
+	}
+
+	writeList(w, r, "domain_records", records)
+}
+
+func recordHasName(d *binarylane.Domain, rec *binarylane.DomainRecord, name string) bool {
+	fqdn := rec.Name + "." + d.Name
+	if rec.Name == "@" {
+		fqdn = d.Name
+	}
+	return name == rec.Name || name == fqdn
+}
+
+func (a *API) createRecord(w http.ResponseWriter, r *request, d *binarylane.Domain) {
+	var req binarylane.DomainRecordEditRequest
+	if !r.decode(w, &req) {
+		return
+	}
+
+	switch {
+	case !containsString(recordTypes, req.Type):
+		unprocessable(w, "Type %q is not a supported record type.", req.Type)
+		return
+	case req.Data == "":
+		unprocessable(w, "Data is required.")
+		return
+	}
+
+	rec := &binarylane.DomainRecord{
+		Type:     req.Type,
+		Name:     req.Name,
+		Data:     req.Data,
+		Priority: req.Priority,
+		Port:     req.Port,
+		TTL:      req.TTL,
+		Weight:   req.Weight,
+		Flags:    req.Flags,
+		Tag:      req.Tag,
+	}
+	if rec.Name == "" {
+		rec.Name = "@"
+	}
+	if rec.TTL == 0 {
+		rec.TTL = defaultTTL
+	}
+	a.addRecord(d, rec)
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"domain_record": rec})
+}
@@ -0,0 +1,408 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fakeapi is an in-memory, stateful fake of the BinaryLane API, for
+// testing programs that use the API or shell out to bl.
+//
+// It implements servers, actions, domains, firewalls, load balancers, VPCs,
+// SSH keys and tags, along with the regions, sizes and images needed to
+// create servers. Lists are paginated and every response carries rate limit
+// headers, like the real API. Actions stay in progress for ActionDuration
+// before completing, and their effects, such as a server becoming active,
+// are applied when they complete.
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/binarylane/go-binarylane"
+)
+
+const (
+	// DefaultActionDuration is how long actions stay in progress when
+	// ActionDuration is not changed.
+	DefaultActionDuration = 3 * time.Second
+
+	// DefaultRateLimit is the number of requests allowed per hour when
+	// RateLimit is not changed.
+	DefaultRateLimit = 5000
+
+	defaultPerPage = 20
+	maxPerPage     = 200
+	rateWindow     = time.Hour
+)
+
+// API is a fake BinaryLane API. It is an http.Handler, so it can be served
+// with net/http or httptest. The exported fields may be changed before the
+// API starts serving requests.
+type API struct {
+	// Token is the only access token accepted, if it is set. Otherwise any
+	// token is accepted, but requests without one are still refused.
+	Token string
+
+	// ActionDuration is how long actions stay in progress. Actions complete
+	// immediately when it is zero.
+	ActionDuration time.Duration
+
+	// RateLimit is the number of requests allowed per hour, after which
+	// requests are answered with 429 Too Many Requests. Requests are not
+	// limited when it is zero.
+	RateLimit int
+
+	// Now returns the current time.
+	Now func() time.Time
+
+	mu sync.Mutex
+
+	nextID    int
+	rateStart time.Time
+	rateCount int
+	pending   []pendingEffect
+	servers   []*binarylane.Server
+	actions   []*binarylane.Action
+	domains   []*binarylane.Domain
+	records   map[string][]*binarylane.DomainRecord
+	firewalls []*binarylane.Firewall
+	lbs       []*binarylane.LoadBalancer
+	vpcs      []*binarylane.VPC
+	keys      []*binarylane.Key
+	tags      []string
+}
+
+// pendingEffect is a change to the state that happens once an action or a
+// resource that is being provisioned completes.
+type pendingEffect struct {
+	at    time.Time
+	apply func(now time.Time)
+}
+
+// New creates an empty fake API, with the default action duration and rate
+// limit.
+func New() *API {
+	return &API{
+		ActionDuration: DefaultActionDuration,
+		RateLimit:      DefaultRateLimit,
+		Now:            time.Now,
+		nextID:         1000,
+		records:        map[string][]*binarylane.DomainRecord{},
+	}
+}
+
+// ServeHTTP answers an API request.
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.authorize(w, r) || !a.limit(w) {
+		return
+	}
+	a.progress()
+
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(path) < 2 || path[0] != "v2" {
+		notFound(w)
+		return
+	}
+
+	req := &request{Request: r, path: path[2:]}
+	switch path[1] {
+	case "account":
+		if len(req.path) > 0 && req.path[0] == "keys" {
+			req.path = req.path[1:]
+			a.serveKeys(w, req)
+			return
+		}
+		a.serveAccount(w, req)
+	case "actions":
+		a.serveActions(w, req)
+	case "domains":
+		a.serveDomains(w, req)
+	case "firewalls":
+		a.serveFirewalls(w, req)
+	case "images":
+		a.serveImages(w, req)
+	case "load_balancers":
+		a.serveLoadBalancers(w, req)
+	case "regions":
+		a.serveRegions(w, req)
+	case "servers":
+		a.serveServers(w, req)
+	case "sizes":
+		a.serveSizes(w, req)
+	case "tags":
+		a.serveTags(w, req)
+	case "vpcs":
+		a.serveVPCs(w, req)
+	default:
+		notFound(w)
+	}
+}
+
+func (a *API) authorize(w http.ResponseWriter, r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" || (a.Token != "" && token != a.Token) {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Unable to authenticate you.")
+		return false
+	}
+	return true
+}
+
+// limit counts the request against the rate limit and sets the rate limit
+// headers. It answers the request and returns false when the limit has been
+// reached.
+func (a *API) limit(w http.ResponseWriter) bool {
+	if a.RateLimit <= 0 {
+		return true
+	}
+
+	now := a.Now()
+	if a.rateStart.IsZero() || !now.Before(a.rateStart.Add(rateWindow)) {
+		a.rateStart = now
+		a.rateCount = 0
+	}
+	reset := a.rateStart.Add(rateWindow)
+
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(a.RateLimit))
+	h.Set("RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+
+	if a.rateCount >= a.RateLimit {
+		h.Set("RateLimit-Remaining", "0")
+		h.Set("Retry-After", strconv.Itoa(int(reset.Sub(now).Seconds()+1)))
+		writeError(w, http.StatusTooManyRequests, "too_many_requests", "API Rate limit exceeded.")
+		return false
+	}
+
+	a.rateCount++
+	h.Set("RateLimit-Remaining", strconv.Itoa(a.RateLimit-a.rateCount))
+	return true
+}
+
+// after schedules a change to the state for when ActionDuration has passed,
+// applying it straight away when actions complete immediately.
+func (a *API) after(apply func(now time.Time)) {
+	a.pending = append(a.pending, pendingEffect{at: a.Now().Add(a.ActionDuration), apply: apply})
+	a.progress()
+}
+
+// progress applies the pending changes that are due, in the order they were
+// scheduled.
+func (a *API) progress() {
+	now := a.Now()
+
+	remaining := a.pending[:0]
+	for _, p := range a.pending {
+		if now.Before(p.at) {
+			remaining = append(remaining, p)
+			continue
+		}
+		p.apply(p.at)
+	}
+	a.pending = remaining
+}
+
+func (a *API) newID() int {
+	a.nextID++
+	return a.nextID
+}
+
+func (a *API) timestamp() string {
+	return a.Now().UTC().Format(time.RFC3339)
+}
+
+// request is an API request, with the path below the resource collection
+// split into segments.
+type request struct {
+	*http.Request
+	path []string
+}
+
+// decode reads the request body into v, answering the request with an error
+// and returning false when the body is invalid.
+func (r *request) decode(w http.ResponseWriter, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", fmt.Sprintf("The request body is invalid: %v.", err))
+		return false
+	}
+	return true
+}
+
+// baseURL is the URL the request was sent to, without the path.
+func (r *request) baseURL() string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// page returns the bounds of the requested page of a list of total items,
+// along with the links and meta of the response.
+func (r *request) page(total int) (start, end int, links *binarylane.Links, meta *binarylane.Meta) {
+	query := r.URL.Query()
+
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+
+	last := (total + perPage - 1) / perPage
+	if last < 1 {
+		last = 1
+	}
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageURL := func(p int) string {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set("page", strconv.Itoa(p))
+		q.Set("per_page", strconv.Itoa(perPage))
+		return r.baseURL() + r.URL.Path + "?" + q.Encode()
+	}
+
+	pages := &binarylane.Pages{}
+	if page > 1 {
+		pages.First = pageURL(1)
+		pages.Prev = pageURL(page - 1)
+	}
+	if page < last {
+		pages.Next = pageURL(page + 1)
+		pages.Last = pageURL(last)
+	}
+
+	start = (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+	end = start + perPage
+	if end > total {
+		end = total
+	}
+
+	return start, end, &binarylane.Links{Pages: pages}, &binarylane.Meta{Total: total}
+}
+
+// writeList answers the request with the requested page of list, which must
+// be a slice, keyed by name.
+func writeList(w http.ResponseWriter, r *request, name string, list interface{}) {
+	v := reflect.ValueOf(list)
+	start, end, links, meta := r.page(v.Len())
+
+	// An empty page is an empty list rather than null.
+	page := reflect.MakeSlice(v.Type(), 0, end-start)
+	page = reflect.AppendSlice(page, v.Slice(start, end))
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		name:    page.Interface(),
+		"links": links,
+		"meta":  meta,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, id, message string) {
+	writeJSON(w, status, map[string]string{"id": id, "message": message})
+}
+
+func notFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, "not_found", "The resource you were accessing could not be found.")
+}
+
+func methodNotAllowed(w http.ResponseWriter) {
+	writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "The method is not allowed for this resource.")
+}
+
+func unprocessable(w http.ResponseWriter, format string, args ...interface{}) {
+	writeError(w, http.StatusUnprocessableEntity, "unprocessable_entity", fmt.Sprintf(format, args...))
+}
+
+func noContent(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseID parses a numeric resource ID from a path segment.
+func parseID(s string) (int, bool) {
+	id, err := strconv.Atoi(s)
+	return id, err == nil && id > 0
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(list []string, s string) []string {
+	out := make([]string, 0, len(list))
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func containsInt(list []int, n int) bool {
+	for _, v := range list {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
+
+func removeInts(list []int, ns []int) []int {
+	out := make([]int, 0, len(list))
+	for _, v := range list {
+		if !containsInt(ns, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func addInts(list []int, ns []int) []int {
+	for _, n := range ns {
+		if !containsInt(list, n) {
+			list = append(list, n)
+		}
+	}
+	sort.Ints(list)
+	return list
+}
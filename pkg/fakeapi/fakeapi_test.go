@@ -0,0 +1,312 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/binarylane/go-binarylane"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGnX9Oe7mVx8vLGz4m8Qbq5E+gqPpqJkQ6m3T9Yk2nhB test@example.com"
+
+// fakeClock is the API's clock, which tests advance by hand.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestAPI(t *testing.T) (*API, *fakeClock, *binarylane.Client) {
+	clock := &fakeClock{now: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	api := New()
+	api.Now = clock.Now
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	return api, clock, newTestClient(t, server.URL, "some-magic-token")
+}
+
+func newTestClient(t *testing.T, url, token string) *binarylane.Client {
+	client, err := binarylane.New(&http.Client{Transport: tokenTransport(token)}, binarylane.SetBaseURL(url))
+	require.NoError(t, err)
+	return client
+}
+
+type tokenTransport string
+
+func (tt tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+string(tt))
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestServerLifecycle(t *testing.T) {
+	ctx := context.Background()
+	_, clock, client := newTestAPI(t)
+
+	server, resp, err := client.Servers.Create(ctx, &binarylane.ServerCreateRequest{
+		Name:   "web-1",
+		Region: "syd",
+		Size:   "std-min",
+		Image:  binarylane.ServerCreateImage{Slug: "ubuntu-22.04"},
+		Tags:   []string{"web"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "new", server.Status)
+	assert.Equal(t, 1024, server.Memory)
+	require.Len(t, resp.Links.Actions, 1)
+	assert.Equal(t, "create", resp.Links.Actions[0].Rel)
+
+	action, _, err := client.ServerActions.GetByURI(ctx, resp.Links.Actions[0].HREF)
+	require.NoError(t, err)
+	assert.Equal(t, binarylane.ActionInProgress, action.Status)
+
+	clock.Advance(DefaultActionDuration)
+
+	action, _, err = client.Actions.Get(ctx, action.ID)
+	require.NoError(t, err)
+	assert.Equal(t, binarylane.ActionCompleted, action.Status)
+	assert.NotNil(t, action.CompletedAt)
+
+	server, _, err = client.Servers.Get(ctx, server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "active", server.Status)
+
+	action, _, err = client.ServerActions.PowerOff(ctx, server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "power_off", action.Type)
+
+	server, _, err = client.Servers.Get(ctx, server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "active", server.Status)
+	assert.True(t, server.Locked)
+
+	clock.Advance(DefaultActionDuration)
+
+	server, _, err = client.Servers.Get(ctx, server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "off", server.Status)
+	assert.False(t, server.Locked)
+
+	actions, _, err := client.ServerActions.PowerOnByTag(ctx, "web")
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+
+	tag, _, err := client.Tags.Get(ctx, "web")
+	require.NoError(t, err)
+	assert.Equal(t, 1, tag.Resources.Count)
+
+	_, err = client.Servers.Delete(ctx, server.ID)
+	require.NoError(t, err)
+
+	_, _, err = client.Servers.Get(ctx, server.ID)
+	assert.Error(t, err)
+}
+
+func TestInvalidRequests(t *testing.T) {
+	ctx := context.Background()
+	_, _, client := newTestAPI(t)
+
+	_, resp, err := client.Servers.Create(ctx, &binarylane.ServerCreateRequest{
+		Name:   "web-1",
+		Region: "nowhere",
+		Size:   "std-min",
+		Image:  binarylane.ServerCreateImage{Slug: "ubuntu-22.04"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `422 Region "nowhere" is not available.`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	_, resp, err = client.Servers.Get(ctx, 1)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestPagination(t *testing.T) {
+	ctx := context.Background()
+	_, _, client := newTestAPI(t)
+
+	for i := 0; i < 25; i++ {
+		_, _, err := client.Domains.Create(ctx, &binarylane.DomainCreateRequest{Name: fmt.Sprintf("example-%02d.com", i)})
+		require.NoError(t, err)
+	}
+
+	domains, resp, err := client.Domains.List(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, domains, 20)
+	assert.Equal(t, 25, resp.Meta.Total)
+	assert.False(t, resp.Links.IsLastPage())
+
+	domains, resp, err = client.Domains.List(ctx, &binarylane.ListOptions{Page: 3, PerPage: 10})
+	require.NoError(t, err)
+	require.Len(t, domains, 5)
+	assert.Equal(t, "example-20.com", domains[0].Name)
+	assert.True(t, resp.Links.IsLastPage())
+
+	page, err := resp.Links.CurrentPage()
+	require.NoError(t, err)
+	assert.Equal(t, 3, page)
+}
+
+func TestRateLimit(t *testing.T) {
+	ctx := context.Background()
+	api, clock, client := newTestAPI(t)
+	api.RateLimit = 2
+
+	_, resp, err := client.Regions.List(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, resp.Rate.Limit)
+	assert.Equal(t, 1, resp.Rate.Remaining)
+	assert.Equal(t, clock.now.Add(time.Hour).Unix(), resp.Rate.Reset.Unix())
+
+	_, _, err = client.Regions.List(ctx, nil)
+	require.NoError(t, err)
+
+	_, resp, err = client.Regions.List(ctx, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "3601", resp.Header.Get("Retry-After"))
+
+	clock.Advance(time.Hour)
+
+	_, _, err = client.Regions.List(ctx, nil)
+	assert.NoError(t, err)
+}
+
+func TestToken(t *testing.T) {
+	ctx := context.Background()
+	api, _, client := newTestAPI(t)
+	api.Token = "the-right-token"
+
+	_, resp, err := client.Account.Get(ctx)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	client = newTestClient(t, client.BaseURL.String(), "the-right-token")
+	account, _, err := client.Account.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "active", account.Status)
+}
+
+func TestDomains(t *testing.T) {
+	ctx := context.Background()
+	_, _, client := newTestAPI(t)
+
+	_, _, err := client.Domains.Create(ctx, &binarylane.DomainCreateRequest{Name: "example.com", IPAddress: "192.0.2.1"})
+	require.NoError(t, err)
+
+	record, _, err := client.Domains.CreateRecord(ctx, "example.com", &binarylane.DomainRecordEditRequest{Type: "A", Name: "www", Data: "192.0.2.2"})
+	require.NoError(t, err)
+	assert.Equal(t, defaultTTL, record.TTL)
+
+	records, _, err := client.Domains.RecordsByType(ctx, "example.com", "A", nil)
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	records, _, err = client.Domains.RecordsByName(ctx, "example.com", "www.example.com", nil)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "192.0.2.2", records[0].Data)
+
+	domain, _, err := client.Domains.Get(ctx, "example.com")
+	require.NoError(t, err)
+	assert.Contains(t, domain.ZoneFile, "www 1800 IN A 192.0.2.2\n")
+
+	_, err = client.Domains.DeleteRecord(ctx, "example.com", record.ID)
+	require.NoError(t, err)
+
+	records, _, err = client.Domains.Records(ctx, "example.com", nil)
+	require.NoError(t, err)
+	assert.Len(t, records, 4)
+}
+
+func TestNetworking(t *testing.T) {
+	ctx := context.Background()
+	_, clock, client := newTestAPI(t)
+
+	key, _, err := client.Keys.Create(ctx, &binarylane.KeyCreateRequest{Name: "laptop", PublicKey: testPublicKey})
+	require.NoError(t, err)
+	assert.Regexp(t, `^([0-9a-f]{2}:){15}[0-9a-f]{2}$`, key.Fingerprint)
+
+	vpc, _, err := client.VPCs.Create(ctx, &binarylane.VPCCreateRequest{Name: "private", RegionSlug: "syd"})
+	require.NoError(t, err)
+	assert.True(t, vpc.Default)
+
+	server, _, err := client.Servers.Create(ctx, &binarylane.ServerCreateRequest{
+		Name:    "db-1",
+		Region:  "syd",
+		Size:    "std-2vcpu",
+		Image:   binarylane.ServerCreateImage{ID: 3},
+		SSHKeys: []binarylane.ServerCreateSSHKey{{Fingerprint: key.Fingerprint}},
+		VPCID:   vpc.ID,
+	})
+	require.NoError(t, err)
+	ip, err := server.PrivateIPv4()
+	require.NoError(t, err)
+	assert.NotEmpty(t, ip)
+
+	fw, _, err := client.Firewalls.Create(ctx, &binarylane.FirewallRequest{
+		Name:         "db",
+		InboundRules: []binarylane.InboundRule{{Protocol: "tcp", PortRange: "5432", Sources: &binarylane.Sources{Tags: []string{"web"}}}},
+		ServerIDs:    []int{server.ID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "waiting", fw.Status)
+
+	clock.Advance(DefaultActionDuration)
+
+	firewalls, _, err := client.Firewalls.ListByServer(ctx, server.ID, nil)
+	require.NoError(t, err)
+	require.Len(t, firewalls, 1)
+	assert.Equal(t, "succeeded", firewalls[0].Status)
+
+	lb, _, err := client.LoadBalancers.Create(ctx, &binarylane.LoadBalancerRequest{
+		Name:            "db-lb",
+		Region:          "syd",
+		ForwardingRules: []binarylane.ForwardingRule{{EntryProtocol: "tcp", EntryPort: 5432, TargetProtocol: "tcp", TargetPort: 5432}},
+		ServerIDs:       []int{server.ID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "new", lb.Status)
+	assert.Equal(t, "round_robin", lb.Algorithm)
+
+	_, err = client.VPCs.Delete(ctx, vpc.ID)
+	assert.Error(t, err)
+
+	_, err = client.Servers.Delete(ctx, server.ID)
+	require.NoError(t, err)
+
+	fw, _, err = client.Firewalls.Get(ctx, fw.ID)
+	require.NoError(t, err)
+	assert.Empty(t, fw.ServerIDs)
+
+	_, err = client.VPCs.Delete(ctx, vpc.ID)
+	assert.NoError(t, err)
+}
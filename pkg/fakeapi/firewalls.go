@@ -0,0 +1,261 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/binarylane/go-binarylane"
+)
+
+// serverIDsRequest is the body of requests that add servers to, or remove
+// servers from, firewalls and load balancers.
+type serverIDsRequest struct {
+	IDs []int `json:"server_ids"`
+}
+
+func (a *API) serveFirewalls(w http.ResponseWriter, r *request) {
+	if len(r.path) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			writeList(w, r, "firewalls", a.firewalls)
+		case http.MethodPost:
+			a.createFirewall(w, r)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	fw := a.findFirewall(r.path[0])
+	if fw == nil {
+		notFound(w)
+		return
+	}
+
+	if len(r.path) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, map[string]interface{}{"firewall": fw})
+		case http.MethodPut:
+			var req binarylane.FirewallRequest
+			if !r.decode(w, &req) || !a.validFirewall(w, &req) {
+				return
+			}
+			fw.Name = req.Name
+			fw.InboundRules, fw.OutboundRules = req.InboundRules, req.OutboundRules
+			fw.ServerIDs, fw.Tags = req.ServerIDs, req.Tags
+			a.applyFirewall(fw)
+			writeJSON(w, http.StatusOK, map[string]interface{}{"firewall": fw})
+		case http.MethodDelete:
+			firewalls := a.firewalls[:0]
+			for _, v := range a.firewalls {
+				if v != fw {
+					firewalls = append(firewalls, v)
+				}
+			}
+			a.firewalls = firewalls
+			noContent(w)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	if len(r.path) != 2 {
+		notFound(w)
+		return
+	}
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		methodNotAllowed(w)
+		return
+	}
+	remove := r.Method == http.MethodDelete
+
+	switch r.path[1] {
+	case "servers":
+		var req serverIDsRequest
+		if !r.decode(w, &req) || !a.validServerIDs(w, req.IDs) {
+			return
+		}
+		if remove {
+			fw.ServerIDs = removeInts(fw.ServerIDs, req.IDs)
+		} else {
+			fw.ServerIDs = addInts(fw.ServerIDs, req.IDs)
+		}
+	case "tags":
+		var req struct {
+			Tags []string `json:"tags"`
+		}
+		if !r.decode(w, &req) || !a.validTags(w, req.Tags) {
+			return
+		}
+		for _, t := range req.Tags {
+			if remove {
+				fw.Tags = removeString(fw.Tags, t)
+			} else if !containsString(fw.Tags, t) {
+				fw.Tags = append(fw.Tags, t)
+			}
+		}
+	case "rules":
+		var req binarylane.FirewallRulesRequest
+		if !r.decode(w, &req) {
+			return
+		}
+		if remove {
+			fw.InboundRules = removeInboundRules(fw.InboundRules, req.InboundRules)
+			fw.OutboundRules = removeOutboundRules(fw.OutboundRules, req.OutboundRules)
+		} else {
+			fw.InboundRules = append(fw.InboundRules, req.InboundRules...)
+			fw.OutboundRules = append(fw.OutboundRules, req.OutboundRules...)
+		}
+	default:
+		notFound(w)
+		return
+	}
+
+	a.applyFirewall(fw)
+	noContent(w)
+}
+
+func (a *API) findFirewall(id string) *binarylane.Firewall {
+	for _, fw := range a.firewalls {
+		if fw.ID == id {
+			return fw
+		}
+	}
+	return nil
+}
+
+func (a *API) createFirewall(w http.ResponseWriter, r *request) {
+	var req binarylane.FirewallRequest
+	if !r.decode(w, &req) || !a.validFirewall(w, &req) {
+		return
+	}
+
+	id := a.newID()
+	fw := &binarylane.Firewall{
+		ID:            fmt.Sprintf("%08x-0000-4000-8000-%012x", id, id),
+		Name:          req.Name,
+		InboundRules:  req.InboundRules,
+		OutboundRules: req.OutboundRules,
+		ServerIDs:     req.ServerIDs,
+		Tags:          req.Tags,
+		Created:       a.timestamp(),
+	}
+	a.firewalls = append(a.firewalls, fw)
+	a.applyFirewall(fw)
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{"firewall": fw})
+}
+
+// validFirewall checks a firewall request, answering the request with an
+// error and returning false when it is invalid. Missing lists are replaced
+// with empty ones.
+func (a *API) validFirewall(w http.ResponseWriter, req *binarylane.FirewallRequest) bool {
+	if req.Name == "" {
+		unprocessable(w, "Name is required.")
+		return false
+	}
+	if !a.validServerIDs(w, req.ServerIDs) || !a.validTags(w, req.Tags) {
+		return false
+	}
+
+	if req.InboundRules == nil {
+		req.InboundRules = []binarylane.InboundRule{}
+	}
+	if req.OutboundRules == nil {
+		req.OutboundRules = []binarylane.OutboundRule{}
+	}
+	if req.ServerIDs == nil {
+		req.ServerIDs = []int{}
+	}
+	if req.Tags == nil {
+		req.Tags = []string{}
+	}
+	return true
+}
+
+// applyFirewall marks the firewall as waiting until its changes have been
+// applied to its servers.
+func (a *API) applyFirewall(fw *binarylane.Firewall) {
+	fw.Status = "waiting"
+	fw.PendingChanges = []binarylane.PendingChange{}
+	for _, id := range fw.ServerIDs {
+		fw.PendingChanges = append(fw.PendingChanges, binarylane.PendingChange{ServerID: id, Status: "waiting"})
+	}
+
+	a.after(func(time.Time) {
+		fw.Status = "succeeded"
+		fw.PendingChanges = []binarylane.PendingChange{}
+	})
+}
+
+func (a *API) validServerIDs(w http.ResponseWriter, ids []int) bool {
+	for _, id := range ids {
+		if a.findServer(id) == nil {
+			unprocessable(w, "Server %d was not found.", id)
+			return false
+		}
+	}
+	return true
+}
+
+// validTags checks the tag names, creating the tags that don't exist yet.
+func (a *API) validTags(w http.ResponseWriter, tags []string) bool {
+	for _, t := range tags {
+		if !validTagName(t) {
+			unprocessable(w, "Tag %q is not a valid tag name.", t)
+			return false
+		}
+	}
+	for _, t := range tags {
+		a.ensureTag(t)
+	}
+	return true
+}
+
+func removeInboundRules(rules, remove []binarylane.InboundRule) []binarylane.InboundRule {
+	out := []binarylane.InboundRule{}
+	for _, rule := range rules {
+		keep := true
+		for _, rm := range remove {
+			if rule.Protocol == rm.Protocol && rule.PortRange == rm.PortRange {
+				keep = false
+			}
+		}
+		if keep {
+			out = append(out, rule)
+		}
+	}
+	return out
+}
+
+func removeOutboundRules(rules, remove []binarylane.OutboundRule) []binarylane.OutboundRule {
+	out := []binarylane.OutboundRule{}
+	for _, rule := range rules {
+		keep := true
+		for _, rm := range remove {
+			if rule.Protocol == rm.Protocol && rule.PortRange == rm.PortRange {
+				keep = false
+			}
+		}
+		if keep {
+			out = append(out, rule)
+		}
+	}
+	return out
+}
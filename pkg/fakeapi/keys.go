@@ -0,0 +1,143 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeapi
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/binarylane/go-binarylane"
+)
+
+func (a *API) serveKeys(w http.ResponseWriter, r *request) {
+	if len(r.path) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			writeList(w, r, "ssh_keys", a.keys)
+		case http.MethodPost:
+			a.createKey(w, r)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	key := a.findKey(r.path[0])
+	if key == nil || len(r.path) > 1 {
+		notFound(w)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"ssh_key": key})
+	case http.MethodPut:
+		var req binarylane.KeyUpdateRequest
+		if !r.decode(w, &req) {
+			return
+		}
+		if req.Name != "" {
+			key.Name = req.Name
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"ssh_key": key})
+	case http.MethodDelete:
+		keys := a.keys[:0]
+		for _, k := range a.keys {
+			if k != key {
+				keys = append(keys, k)
+			}
+		}
+		a.keys = keys
+		noContent(w)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// findKey finds a key by its ID or its fingerprint. The ID may be a string,
+// from a path, or a number, from a request body.
+func (a *API) findKey(v interface{}) *binarylane.Key {
+	var s string
+	switch id := v.(type) {
+	case string:
+		s = id
+	case float64:
+		s = strconv.Itoa(int(id))
+	default:
+		return nil
+	}
+
+	for _, k := range a.keys {
+		if strconv.Itoa(k.ID) == s || k.Fingerprint == s {
+			return k
+		}
+	}
+	return nil
+}
+
+func (a *API) createKey(w http.ResponseWriter, r *request) {
+	var req binarylane.KeyCreateRequest
+	if !r.decode(w, &req) {
+		return
+	}
+
+	if req.Name == "" {
+		unprocessable(w, "Name is required.")
+		return
+	}
+	fingerprint, ok := fingerprint(req.PublicKey)
+	if !ok {
+		unprocessable(w, "Key is invalid, type not supported.")
+		return
+	}
+	if a.findKey(fingerprint) != nil {
+		unprocessable(w, "SSH Key is already in use on your account.")
+		return
+	}
+
+	key := &binarylane.Key{
+		ID:          a.newID(),
+		Name:        req.Name,
+		Fingerprint: fingerprint,
+		PublicKey:   strings.TrimSpace(req.PublicKey),
+	}
+	a.keys = append(a.keys, key)
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"ssh_key": key})
+}
+
+// fingerprint returns the MD5 fingerprint of an authorized_keys format public
+// key.
+func fingerprint(publicKey string) (string, bool) {
+	fields := strings.Fields(publicKey)
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "ssh-") && !strings.HasPrefix(fields[0], "ecdsa-") {
+		return "", false
+	}
+
+	b, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return "", false
+	}
+
+	sum := md5.Sum(b)
+	parts := make([]string, len(sum))
+	for i, c := range sum {
+		parts[i] = fmt.Sprintf("%02x", c)
+	}
+	return strings.Join(parts, ":"), true
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/binarylane/go-binarylane"
+)
+
+func (a *API) serveLoadBalancers(w http.ResponseWriter, r *request) {
+	if len(r.path) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			writeList(w, r, "load_balancers", a.lbs)
+		case http.MethodPost:
+			a.createLoadBalancer(w, r)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	id, _ := parseID(r.path[0])
+	lb := a.findLoadBalancer(id)
+	if lb == nil {
+		notFound(w)
+		return
+	}
+
+	if len(r.path) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, map[string]interface{}{"load_balancer": lb})
+		case http.MethodPut:
+			var req binarylane.LoadBalancerRequest
+			if !r.decode(w, &req) || !a.validLoadBalancer(w, &req) {
+				return
+			}
+			setLoadBalancer(lb, &req)
+			writeJSON(w, http.StatusOK, map[string]interface{}{"load_balancer": lb})
+		case http.MethodDelete:
+			lbs := a.lbs[:0]
+			for _, v := range a.lbs {
+				if v != lb {
+					lbs = append(lbs, v)
+				}
+			}
+			a.lbs = lbs
+			noContent(w)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	if len(r.path) != 2 {
+		notFound(w)
+		return
+	}
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		methodNotAllowed(w)
+		return
+	}
+	remove := r.Method == http.MethodDelete
+
+	switch r.path[1] {
+	case "servers":
+		var req serverIDsRequest
+		if !r.decode(w, &req) || !a.validServerIDs(w, req.IDs) {
+			return
+		}
+		if remove {
+			lb.ServerIDs = removeInts(lb.ServerIDs, req.IDs)
+		} else {
+			lb.ServerIDs = addInts(lb.ServerIDs, req.IDs)
+		}
+	case "forwarding_rules":
+		var req struct {
+			Rules []binarylane.ForwardingRule `json:"forwarding_rules"`
+		}
+		if !r.decode(w, &req) {
+			return
+		}
+		if remove {
+			lb.ForwardingRules = removeForwardingRules(lb.ForwardingRules, req.Rules)
+		} else {
+			lb.ForwardingRules = append(lb.ForwardingRules, req.Rules...)
+		}
+	default:
+		notFound(w)
+		return
+	}
+
+	noContent(w)
+}
+
+func (a *API) findLoadBalancer(id int) *binarylane.LoadBalancer {
+	for _, lb := range a.lbs {
+		if lb.ID == id {
+			return lb
+		}
+	}
+	return nil
+}
+
+// createLoadBalancer creates a load balancer, which is new until it has been
+// provisioned.
+func (a *API) createLoadBalancer(w http.ResponseWriter, r *request) {
+	var req binarylane.LoadBalancerRequest
+	if !r.decode(w, &req) || !a.validLoadBalancer(w, &req) {
+		return
+	}
+
+	id := a.newID()
+	lb := &binarylane.LoadBalancer{
+		ID:      id,
+		IP:      fmt.Sprintf("198.51.100.%d", id%250+2),
+		Status:  "new",
+		Created: a.timestamp(),
+	}
+	setLoadBalancer(lb, &req)
+	a.lbs = append(a.lbs, lb)
+
+	a.after(func(time.Time) { lb.Status = "active" })
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{"load_balancer": lb})
+}
+
+// validLoadBalancer checks a load balancer request, answering the request
+// with an error and returning false when it is invalid. Defaults are filled
+// in for the settings that are not given.
+func (a *API) validLoadBalancer(w http.ResponseWriter, req *binarylane.LoadBalancerRequest) bool {
+	switch {
+	case req.Name == "":
+		unprocessable(w, "Name is required.")
+		return false
+	case findRegion(req.Region) == nil:
+		unprocessable(w, "Region %q is not available.", req.Region)
+		return false
+	case len(req.ForwardingRules) == 0:
+		unprocessable(w, "At least one forwarding rule is required.")
+		return false
+	}
+	if !a.validServerIDs(w, req.ServerIDs) || !a.validTags(w, req.Tags) {
+		return false
+	}
+	if req.VPCID != 0 && a.findVPC(req.VPCID) == nil {
+		unprocessable(w, "VPC %d was not found.", req.VPCID)
+		return false
+	}
+
+	if req.Algorithm == "" {
+		req.Algorithm = "round_robin"
+	}
+	if req.SizeSlug == "" {
+		req.SizeSlug = "lb-small"
+	}
+	if req.HealthCheck == nil {
+		req.HealthCheck = &binarylane.HealthCheck{
+			Protocol:               "tcp",
+			Port:                   80,
+			CheckIntervalSeconds:   10,
+			ResponseTimeoutSeconds: 5,
+			HealthyThreshold:       5,
+			UnhealthyThreshold:     3,
+		}
+	}
+	if req.StickySessions == nil {
+		req.StickySessions = &binarylane.StickySessions{Type: "none"}
+	}
+	return true
+}
+
+func setLoadBalancer(lb *binarylane.LoadBalancer, req *binarylane.LoadBalancerRequest) {
+	lb.Name = req.Name
+	lb.Region = findRegion(req.Region)
+	lb.SizeSlug = req.SizeSlug
+	lb.Algorithm = req.Algorithm
+	lb.ForwardingRules = req.ForwardingRules
+	lb.HealthCheck = req.HealthCheck
+	lb.StickySessions = req.StickySessions
+	lb.ServerIDs = req.ServerIDs
+	lb.Tag = req.Tag
+	lb.Tags = req.Tags
+	lb.RedirectHttpToHttps = req.RedirectHttpToHttps
+	lb.EnableProxyProtocol = req.EnableProxyProtocol
+	lb.EnableBackendKeepalive = req.EnableBackendKeepalive
+	lb.VPCID = req.VPCID
+}
+
+func removeForwardingRules(rules, remove []binarylane.ForwardingRule) []binarylane.ForwardingRule {
+	out := []binarylane.ForwardingRule{}
+	for _, rule := range rules {
+		keep := true
+		for _, rm := range remove {
+			if rule.EntryProtocol == rm.EntryProtocol && rule.EntryPort == rm.EntryPort {
+				keep = false
+			}
+		}
+		if keep {
+			out = append(out, rule)
+		}
+	}
+	return out
+}
@@ -0,0 +1,476 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/binarylane/go-binarylane"
+)
+
+// serverCreateRequest accepts both single and multiple server creates. The
+// image may be a slug or an ID, and SSH keys fingerprints or IDs.
+type serverCreateRequest struct {
+	Name              string        `json:"name"`
+	Names             []string      `json:"names"`
+	Region            string        `json:"region"`
+	Size              string        `json:"size"`
+	Image             interface{}   `json:"image"`
+	SSHKeys           []interface{} `json:"ssh_keys"`
+	Backups           bool          `json:"backups"`
+	IPv6              bool          `json:"ipv6"`
+	PrivateNetworking bool          `json:"private_networking"`
+	UserData          string        `json:"user_data"`
+	Tags              []string      `json:"tags"`
+	VPCID             int           `json:"vpc_id"`
+}
+
+func (a *API) serveServers(w http.ResponseWriter, r *request) {
+	if len(r.path) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			a.listServers(w, r)
+		case http.MethodPost:
+			a.createServers(w, r)
+		case http.MethodDelete:
+			a.deleteServersByTag(w, r)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	if r.path[0] == "actions" && len(r.path) == 1 {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w)
+			return
+		}
+		a.serverActionsByTag(w, r)
+		return
+	}
+
+	id, ok := parseID(r.path[0])
+	if !ok {
+		notFound(w)
+		return
+	}
+	s := a.findServer(id)
+	if s == nil {
+		notFound(w)
+		return
+	}
+
+	switch {
+	case len(r.path) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"server": s})
+	case len(r.path) == 1 && r.Method == http.MethodDelete:
+		a.deleteServer(id)
+		noContent(w)
+	case len(r.path) == 1:
+		methodNotAllowed(w)
+	case r.path[1] == "actions":
+		a.serveServerActions(w, r, s)
+	case len(r.path) > 2 || r.Method != http.MethodGet:
+		methodNotAllowed(w)
+	case r.path[1] == "kernels":
+		writeList(w, r, "kernels", []binarylane.Kernel{})
+	case r.path[1] == "backups":
+		writeList(w, r, "backups", []binarylane.Image{})
+	case r.path[1] == "snapshots":
+		writeList(w, r, "snapshots", []binarylane.Image{})
+	case r.path[1] == "neighbors":
+		writeList(w, r, "servers", []*binarylane.Server{})
+	case r.path[1] == "firewalls":
+		firewalls := []*binarylane.Firewall{}
+		for _, fw := range a.firewalls {
+			if containsInt(fw.ServerIDs, id) {
+				firewalls = append(firewalls, fw)
+			}
+		}
+		writeList(w, r, "firewalls", firewalls)
+	default:
+		notFound(w)
+	}
+}
+
+func (a *API) findServer(id int) *binarylane.Server {
+	for _, s := range a.servers {
+		if s.ID == id {
+			return s
+		}
+	}
+	return nil
+}
+
+// serversByTag returns the servers with the tag_name given in the query, or
+// all servers when it is not given.
+func (a *API) serversByTag(r *request) []*binarylane.Server {
+	tag := r.URL.Query().Get("tag_name")
+
+	servers := []*binarylane.Server{}
+	for _, s := range a.servers {
+		if tag == "" || containsString(s.Tags, tag) {
+			servers = append(servers, s)
+		}
+	}
+	return servers
+}
+
+func (a *API) listServers(w http.ResponseWriter, r *request) {
+	writeList(w, r, "servers", a.serversByTag(r))
+}
+
+func (a *API) createServers(w http.ResponseWriter, r *request) {
+	var req serverCreateRequest
+	if !r.decode(w, &req) {
+		return
+	}
+
+	names := req.Names
+	if len(names) == 0 && req.Name != "" {
+		names = []string{req.Name}
+	}
+	if len(names) == 0 {
+		unprocessable(w, "Name is required.")
+		return
+	}
+
+	region := findRegion(req.Region)
+	if region == nil {
+		unprocessable(w, "Region %q is not available.", req.Region)
+		return
+	}
+	size := findSize(req.Size)
+	if size == nil {
+		unprocessable(w, "Size %q is not available.", req.Size)
+		return
+	}
+	image := findImage(req.Image)
+	if image == nil {
+		unprocessable(w, "Image %v is not available.", req.Image)
+		return
+	}
+	for _, k := range req.SSHKeys {
+		if a.findKey(k) == nil {
+			unprocessable(w, "SSH key %v was not found.", k)
+			return
+		}
+	}
+	if req.VPCID != 0 {
+		vpc := a.findVPC(req.VPCID)
+		if vpc == nil {
+			unprocessable(w, "VPC %d was not found.", req.VPCID)
+			return
+		}
+		if vpc.RegionSlug != region.Slug {
+			unprocessable(w, "VPC %d is not in region %s.", req.VPCID, region.Slug)
+			return
+		}
+	}
+
+	servers := make([]*binarylane.Server, 0, len(names))
+	var actions []binarylane.LinkAction
+	for _, name := range names {
+		s := &binarylane.Server{
+			ID:        a.newID(),
+			Name:      name,
+			Memory:    size.Memory,
+			Vcpus:     size.Vcpus,
+			Disk:      size.Disk,
+			Region:    region,
+			Image:     image,
+			Size:      size,
+			SizeSlug:  size.Slug,
+			Features:  []string{},
+			Status:    "new",
+			Created:   a.timestamp(),
+			Tags:      []string{},
+			VolumeIDs: []string{},
+			VPCID:     req.VPCID,
+		}
+		s.Networks = &binarylane.Networks{V4: []binarylane.NetworkV4{publicIPv4(s.ID)}}
+		if req.Backups {
+			enableFeature(s, "backups")
+		}
+		if req.IPv6 {
+			enableFeature(s, "ipv6")
+		}
+		if req.PrivateNetworking || req.VPCID != 0 {
+			enableFeature(s, "private_networking")
+		}
+		for _, t := range req.Tags {
+			a.ensureTag(t)
+			if !containsString(s.Tags, t) {
+				s.Tags = append(s.Tags, t)
+			}
+		}
+		a.servers = append(a.servers, s)
+
+		action := a.startAction("create", s, func(now time.Time) {
+			s.Status = "active"
+		})
+		actions = append(actions, binarylane.LinkAction{
+			ID:   action.ID,
+			Rel:  "create",
+			HREF: fmt.Sprintf("%s/v2/actions/%d", r.baseURL(), action.ID),
+		})
+
+		servers = append(servers, s)
+	}
+
+	links := &binarylane.Links{Actions: actions}
+	if len(req.Names) > 0 {
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{"servers": servers, "links": links})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{"server": servers[0], "links": links})
+}
+
+func (a *API) deleteServersByTag(w http.ResponseWriter, r *request) {
+	if r.URL.Query().Get("tag_name") == "" {
+		unprocessable(w, "tag_name is required to delete servers in bulk.")
+		return
+	}
+	for _, s := range a.serversByTag(r) {
+		a.deleteServer(s.ID)
+	}
+	noContent(w)
+}
+
+// deleteServer removes the server, and removes it from the firewalls and
+// load balancers it belongs to.
+func (a *API) deleteServer(id int) {
+	servers := a.servers[:0]
+	for _, s := range a.servers {
+		if s.ID != id {
+			servers = append(servers, s)
+		}
+	}
+	a.servers = servers
+
+	for _, fw := range a.firewalls {
+		fw.ServerIDs = removeInts(fw.ServerIDs, []int{id})
+	}
+	for _, lb := range a.lbs {
+		lb.ServerIDs = removeInts(lb.ServerIDs, []int{id})
+	}
+}
+
+func publicIPv4(id int) binarylane.NetworkV4 {
+	return binarylane.NetworkV4{
+		IPAddress: fmt.Sprintf("203.0.113.%d", id%250+2),
+		Netmask:   "255.255.255.0",
+		Gateway:   "203.0.113.1",
+		Type:      "public",
+	}
+}
+
+// enableFeature adds a feature to the server, along with the network the
+// feature provides.
+func enableFeature(s *binarylane.Server, feature string) {
+	if containsString(s.Features, feature) {
+		return
+	}
+	s.Features = append(s.Features, feature)
+
+	switch feature {
+	case "ipv6":
+		s.Networks.V6 = append(s.Networks.V6, binarylane.NetworkV6{
+			IPAddress: fmt.Sprintf("2001:db8::%x", s.ID),
+			Netmask:   64,
+			Gateway:   "2001:db8::1",
+			Type:      "public",
+		})
+	case "private_networking":
+		s.Networks.V4 = append(s.Networks.V4, binarylane.NetworkV4{
+			IPAddress: fmt.Sprintf("10.240.%d.%d", s.ID/250%250, s.ID%250+2),
+			Netmask:   "255.255.0.0",
+			Type:      "private",
+		})
+	}
+}
+
+// serverActionRequest is the body of a server action. Only the fields of the
+// action's type are set.
+type serverActionRequest struct {
+	Type  string      `json:"type"`
+	Name  string      `json:"name"`
+	Size  string      `json:"size"`
+	Image interface{} `json:"image"`
+}
+
+func (a *API) serveServerActions(w http.ResponseWriter, r *request, s *binarylane.Server) {
+	switch {
+	case len(r.path) == 2 && r.Method == http.MethodGet:
+		actions := []*binarylane.Action{}
+		for _, action := range a.actions {
+			if action.ResourceType == "server" && action.ResourceID == s.ID {
+				actions = append(actions, action)
+			}
+		}
+		writeList(w, r, "actions", actions)
+	case len(r.path) == 2 && r.Method == http.MethodPost:
+		var req serverActionRequest
+		if !r.decode(w, &req) {
+			return
+		}
+		action, msg := a.serverAction(s, req)
+		if action == nil {
+			unprocessable(w, msg)
+			return
+		}
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"action": action})
+	case len(r.path) == 3 && r.Method == http.MethodGet:
+		id, _ := parseID(r.path[2])
+		action := a.findAction(id)
+		if action == nil || action.ResourceID != s.ID {
+			notFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"action": action})
+	case len(r.path) <= 3:
+		methodNotAllowed(w)
+	default:
+		notFound(w)
+	}
+}
+
+func (a *API) serverActionsByTag(w http.ResponseWriter, r *request) {
+	if r.URL.Query().Get("tag_name") == "" {
+		unprocessable(w, "tag_name is required.")
+		return
+	}
+
+	var req serverActionRequest
+	if !r.decode(w, &req) {
+		return
+	}
+
+	actions := []*binarylane.Action{}
+	for _, s := range a.serversByTag(r) {
+		action, msg := a.serverAction(s, req)
+		if action == nil {
+			unprocessable(w, msg)
+			return
+		}
+		actions = append(actions, action)
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"actions": actions})
+}
+
+// serverAction starts an action on a server, returning a message explaining
+// why when the action is not valid.
+func (a *API) serverAction(s *binarylane.Server, req serverActionRequest) (*binarylane.Action, string) {
+	var apply func(now time.Time)
+
+	switch req.Type {
+	case "power_on", "reboot", "power_cycle":
+		apply = func(time.Time) { s.Status = "active" }
+	case "power_off", "shutdown":
+		apply = func(time.Time) { s.Status = "off" }
+	case "resize":
+		size := findSize(req.Size)
+		if size == nil {
+			return nil, fmt.Sprintf("Size %q is not available.", req.Size)
+		}
+		apply = func(time.Time) {
+			s.Size, s.SizeSlug = size, size.Slug
+			s.Memory, s.Vcpus, s.Disk = size.Memory, size.Vcpus, size.Disk
+		}
+	case "rename":
+		if req.Name == "" {
+			return nil, "Name is required."
+		}
+		apply = func(time.Time) { s.Name = req.Name }
+	case "rebuild":
+		image := findImage(req.Image)
+		if image == nil {
+			return nil, fmt.Sprintf("Image %v is not available.", req.Image)
+		}
+		apply = func(time.Time) { s.Image = image }
+	case "snapshot":
+		apply = func(time.Time) { s.SnapshotIDs = append(s.SnapshotIDs, a.newID()) }
+	case "enable_backups":
+		apply = func(time.Time) { enableFeature(s, "backups") }
+	case "disable_backups":
+		apply = func(time.Time) { s.Features = removeString(s.Features, "backups") }
+	case "enable_ipv6":
+		apply = func(time.Time) { enableFeature(s, "ipv6") }
+	case "enable_private_networking":
+		apply = func(time.Time) { enableFeature(s, "private_networking") }
+	case "restore", "password_reset", "change_kernel":
+		apply = func(time.Time) {}
+	default:
+		return nil, fmt.Sprintf("Action type %q is not supported.", req.Type)
+	}
+
+	return a.startAction(req.Type, s, apply), ""
+}
+
+// startAction starts an action on a server. The server is locked until the
+// action completes, when the action's effect is applied.
+func (a *API) startAction(actionType string, s *binarylane.Server, apply func(now time.Time)) *binarylane.Action {
+	action := &binarylane.Action{
+		ID:           a.newID(),
+		Status:       binarylane.ActionInProgress,
+		Type:         actionType,
+		StartedAt:    &binarylane.Timestamp{Time: a.Now().UTC().Truncate(time.Second)},
+		ResourceID:   s.ID,
+		ResourceType: "server",
+		Region:       s.Region,
+		RegionSlug:   s.Region.Slug,
+	}
+	a.actions = append(a.actions, action)
+
+	s.Locked = true
+	a.after(func(now time.Time) {
+		apply(now)
+		s.Locked = false
+		action.Status = binarylane.ActionCompleted
+		action.CompletedAt = &binarylane.Timestamp{Time: now.UTC().Truncate(time.Second)}
+	})
+
+	return action
+}
+
+func (a *API) findAction(id int) *binarylane.Action {
+	for _, action := range a.actions {
+		if action.ID == id {
+			return action
+		}
+	}
+	return nil
+}
+
+func (a *API) serveActions(w http.ResponseWriter, r *request) {
+	switch {
+	case r.Method != http.MethodGet:
+		methodNotAllowed(w)
+	case len(r.path) == 0:
+		writeList(w, r, "actions", a.actions)
+	case len(r.path) == 1:
+		id, _ := strconv.Atoi(r.path[0])
+		action := a.findAction(id)
+		if action == nil {
+			notFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"action": action})
+	default:
+		notFound(w)
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/binarylane/go-binarylane"
+)
+
+var tagName = regexp.MustCompile(`^[a-zA-Z0-9_\-:]{1,255}$`)
+
+func validTagName(name string) bool {
+	return tagName.MatchString(name)
+}
+
+func (a *API) serveTags(w http.ResponseWriter, r *request) {
+	if len(r.path) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			tags := make([]*binarylane.Tag, 0, len(a.tags))
+			for _, name := range a.tags {
+				tags = append(tags, a.tag(r, name))
+			}
+			writeList(w, r, "tags", tags)
+		case http.MethodPost:
+			var req binarylane.TagCreateRequest
+			if !r.decode(w, &req) {
+				return
+			}
+			if !validTagName(req.Name) {
+				unprocessable(w, "Tag %q is not a valid tag name.", req.Name)
+				return
+			}
+			a.ensureTag(req.Name)
+			writeJSON(w, http.StatusCreated, map[string]interface{}{"tag": a.tag(r, req.Name)})
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	name := r.path[0]
+	if !containsString(a.tags, name) {
+		notFound(w)
+		return
+	}
+
+	switch {
+	case len(r.path) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"tag": a.tag(r, name)})
+	case len(r.path) == 1 && r.Method == http.MethodDelete:
+		a.deleteTag(name)
+		noContent(w)
+	case len(r.path) == 1:
+		methodNotAllowed(w)
+	case len(r.path) == 2 && r.path[1] == "resources":
+		a.tagResources(w, r, name)
+	default:
+		notFound(w)
+	}
+}
+
+// ensureTag creates the tag if it doesn't exist. Like the real API, tags are
+// created when they are first used.
+func (a *API) ensureTag(name string) {
+	if !containsString(a.tags, name) {
+		a.tags = append(a.tags, name)
+	}
+}
+
+// tag returns the tag with a summary of the servers it is applied to.
+func (a *API) tag(r *request, name string) *binarylane.Tag {
+	servers := &binarylane.TaggedServersResources{}
+	for _, s := range a.servers {
+		if containsString(s.Tags, name) {
+			servers.Count++
+			servers.LastTagged = s
+			servers.LastTaggedURI = fmt.Sprintf("%s/v2/servers/%d", r.baseURL(), s.ID)
+		}
+	}
+
+	return &binarylane.Tag{
+		Name: name,
+		Resources: &binarylane.TaggedResources{
+			Count:         servers.Count,
+			LastTaggedURI: servers.LastTaggedURI,
+			Servers:       servers,
+		},
+	}
+}
+
+// deleteTag deletes the tag and removes it from every resource.
+func (a *API) deleteTag(name string) {
+	a.tags = removeString(a.tags, name)
+	for _, s := range a.servers {
+		s.Tags = removeString(s.Tags, name)
+	}
+	for _, fw := range a.firewalls {
+		fw.Tags = removeString(fw.Tags, name)
+	}
+	for _, lb := range a.lbs {
+		lb.Tags = removeString(lb.Tags, name)
+	}
+}
+
+// tagResources tags or untags servers, which are the only resources the fake
+// supports tagging directly.
+func (a *API) tagResources(w http.ResponseWriter, r *request, name string) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		methodNotAllowed(w)
+		return
+	}
+
+	var req binarylane.TagResourcesRequest
+	if !r.decode(w, &req) {
+		return
+	}
+
+	var servers []*binarylane.Server
+	for _, res := range req.Resources {
+		if res.Type != binarylane.ServerResourceType {
+			unprocessable(w, "Resource type %q can't be tagged.", res.Type)
+			return
+		}
+		id, _ := parseID(res.ID)
+		s := a.findServer(id)
+		if s == nil {
+			unprocessable(w, "Server %s was not found.", res.ID)
+			return
+		}
+		servers = append(servers, s)
+	}
+
+	for _, s := range servers {
+		if r.Method == http.MethodDelete {
+			s.Tags = removeString(s.Tags, name)
+		} else if !containsString(s.Tags, name) {
+			s.Tags = append(s.Tags, name)
+		}
+	}
+	noContent(w)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeapi
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/binarylane/go-binarylane"
+)
+
+func (a *API) serveVPCs(w http.ResponseWriter, r *request) {
+	if len(r.path) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			writeList(w, r, "vpcs", a.vpcs)
+		case http.MethodPost:
+			a.createVPC(w, r)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	id, _ := parseID(r.path[0])
+	vpc := a.findVPC(id)
+	if vpc == nil || len(r.path) > 1 {
+		notFound(w)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"vpc": vpc})
+	case http.MethodPut, http.MethodPatch:
+		var req binarylane.VPCUpdateRequest
+		if !r.decode(w, &req) {
+			return
+		}
+		if req.Name == "" && r.Method == http.MethodPut {
+			unprocessable(w, "Name is required.")
+			return
+		}
+		if req.Name != "" {
+			vpc.Name = req.Name
+		}
+		if req.Description != "" || r.Method == http.MethodPut {
+			vpc.Description = req.Description
+		}
+		if req.Default != nil && *req.Default {
+			a.makeDefaultVPC(vpc)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"vpc": vpc})
+	case http.MethodDelete:
+		a.deleteVPC(w, vpc)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+func (a *API) findVPC(id int) *binarylane.VPC {
+	for _, vpc := range a.vpcs {
+		if vpc.ID == id {
+			return vpc
+		}
+	}
+	return nil
+}
+
+// createVPC creates a VPC. The first VPC in a region is its default VPC.
+func (a *API) createVPC(w http.ResponseWriter, r *request) {
+	var req binarylane.VPCCreateRequest
+	if !r.decode(w, &req) {
+		return
+	}
+
+	switch {
+	case req.Name == "":
+		unprocessable(w, "Name is required.")
+		return
+	case findRegion(req.RegionSlug) == nil:
+		unprocessable(w, "Region %q is not available.", req.RegionSlug)
+		return
+	}
+
+	id := a.newID()
+	if req.IPRange == "" {
+		req.IPRange = fmt.Sprintf("10.%d.0.0/16", 100+id%150)
+	} else if _, _, err := net.ParseCIDR(req.IPRange); err != nil {
+		unprocessable(w, "IP range %q is not a valid CIDR block.", req.IPRange)
+		return
+	}
+
+	vpc := &binarylane.VPC{
+		ID:          id,
+		Name:        req.Name,
+		Description: req.Description,
+		IPRange:     req.IPRange,
+		RegionSlug:  req.RegionSlug,
+		CreatedAt:   a.Now().UTC().Truncate(time.Second),
+		Default:     true,
+	}
+	for _, v := range a.vpcs {
+		if v.RegionSlug == vpc.RegionSlug && v.Default {
+			vpc.Default = false
+		}
+	}
+	a.vpcs = append(a.vpcs, vpc)
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"vpc": vpc})
+}
+
+func (a *API) makeDefaultVPC(vpc *binarylane.VPC) {
+	for _, v := range a.vpcs {
+		if v.RegionSlug == vpc.RegionSlug {
+			v.Default = v == vpc
+		}
+	}
+}
+
+// deleteVPC deletes a VPC, unless it has members.
+func (a *API) deleteVPC(w http.ResponseWriter, vpc *binarylane.VPC) {
+	for _, s := range a.servers {
+		if s.VPCID == vpc.ID {
+			writeError(w, http.StatusConflict, "conflict", "A VPC with members can't be deleted.")
+			return
+		}
+	}
+
+	vpcs := a.vpcs[:0]
+	for _, v := range a.vpcs {
+		if v != vpc {
+			vpcs = append(vpcs, v)
+		}
+	}
+	a.vpcs = vpcs
+	noContent(w)
+}
@@ -19,19 +19,20 @@ import (
 	"net/http/httputil"
 )
 
-// recorder traces http connections. It sends the output to a request and
-// response channels.
+// recorder captures http connections. It passes the dump of every request
+// to onRequest before it is sent, and the request with the dumps of it and
+// its response to onResponse once it is answered.
 type recorder struct {
-	wrap http.RoundTripper
-	req  chan string
-	resp chan string
+	wrap       http.RoundTripper
+	onRequest  func(reqDump []byte)
+	onResponse func(req *http.Request, reqDump, respDump []byte)
 }
 
-func newRecorder(transport http.RoundTripper) *recorder {
+func newRecorder(transport http.RoundTripper, onRequest func(reqDump []byte), onResponse func(req *http.Request, reqDump, respDump []byte)) *recorder {
 	return &recorder{
-		wrap: transport,
-		req:  make(chan string),
-		resp: make(chan string),
+		wrap:       transport,
+		onRequest:  onRequest,
+		onResponse: onResponse,
 	}
 }
 
@@ -40,15 +41,22 @@ func (rec *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
 	if err != nil {
 		return nil, fmt.Errorf("transport.Recorder: dumping request, %v", err)
 	}
-	rec.req <- string(reqBytes)
+	if rec.onRequest != nil {
+		rec.onRequest(reqBytes)
+	}
 
 	resp, rerr := rec.wrap.RoundTrip(req)
+	if rerr != nil {
+		return resp, rerr
+	}
 
 	respBytes, err := httputil.DumpResponse(resp, true)
 	if err != nil {
 		return nil, fmt.Errorf("transport.Recorder: dumping response, %v", err)
 	}
-	rec.resp <- string(respBytes)
+	if rec.onResponse != nil {
+		rec.onResponse(req, reqBytes, respBytes)
+	}
 
-	return resp, rerr
+	return resp, nil
 }
@@ -0,0 +1,177 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blcli
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is the number of times a request is retried when
+	// --max-retries is not set.
+	DefaultMaxRetries = 5
+
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+
+	// retryMaxWait is the longest wait requested by the API that is honoured.
+	// Responses asking for a longer wait are returned to the caller.
+	retryMaxWait = time.Minute
+)
+
+// retryTransport retries requests that are rate limited (429) or that fail
+// with a server error (5xx). Rate limited requests were not processed by
+// the API and are always retried; server errors are only retried for
+// idempotent methods.
+type retryTransport struct {
+	wrap       http.RoundTripper
+	maxRetries int
+
+	now   func() time.Time
+	sleep func(req *http.Request, d time.Duration) error
+	rand  func() float64
+}
+
+func newRetryTransport(transport http.RoundTripper, maxRetries int) *retryTransport {
+	return &retryTransport{
+		wrap:       transport,
+		maxRetries: maxRetries,
+		now:        time.Now,
+		sleep:      sleepContext,
+		rand:       rand.Float64,
+	}
+}
+
+// RoundTrip sends the request, retrying it as needed. The caller's request
+// is only sent once; each retry sends a clone of it with a fresh body, as a
+// RoundTripper must not modify the request it is given.
+func (rt *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attemptReq := req
+	for attempt := 0; ; attempt++ {
+		resp, err := rt.wrap.RoundTrip(attemptReq)
+		if err != nil || attempt >= rt.maxRetries || !rt.shouldRetry(req, resp) {
+			return resp, err
+		}
+
+		delay, ok := rt.delay(resp, attempt)
+		if !ok {
+			return resp, nil
+		}
+
+		attemptReq = req.Clone(req.Context())
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			attemptReq.Body = body
+		}
+
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+
+		if err := rt.sleep(req, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (rt *retryTransport) shouldRetry(req *http.Request, resp *http.Response) bool {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+		return isIdempotent(req.Method)
+	default:
+		return false
+	}
+}
+
+// delay returns how long to wait before the next attempt. The wait asked for
+// by the Retry-After or RateLimit-Reset headers is used when present, and
+// jittered exponential backoff otherwise. It returns false when the API asks
+// for a wait longer than retryMaxWait.
+func (rt *retryTransport) delay(resp *http.Response, attempt int) (time.Duration, bool) {
+	if d, ok := rt.serverDelay(resp); ok {
+		return d, d <= retryMaxWait
+	}
+
+	backoff := retryBaseDelay << uint(attempt)
+	if backoff > retryMaxDelay || backoff <= 0 {
+		backoff = retryMaxDelay
+	}
+
+	// Wait between half and all of the backoff so that concurrent clients
+	// don't retry in lockstep.
+	return backoff/2 + time.Duration(rt.rand()*float64(backoff/2)), true
+}
+
+func (rt *retryTransport) serverDelay(resp *http.Response) (time.Duration, bool) {
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return nonNegative(t.Sub(rt.now())), true
+		}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if v := resp.Header.Get("RateLimit-Reset"); v != "" {
+			if reset, err := strconv.ParseInt(v, 10, 64); err == nil && reset > 0 {
+				return nonNegative(time.Unix(reset, 0).Sub(rt.now())), true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleepContext waits for d, returning early with an error if the request is
+// canceled.
+func sleepContext(req *http.Request, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blcli
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRetryServer responds with the given statuses in turn, then 200, and
+// records the bodies it receives.
+func testRetryServer(header http.Header, statuses ...int) (*httptest.Server, *[]string) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		bodies = append(bodies, string(b))
+
+		if len(bodies) > len(statuses) {
+			w.Write([]byte("ok"))
+			return
+		}
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(statuses[len(bodies)-1])
+	}))
+	return server, &bodies
+}
+
+func testRetryTransport(maxRetries int, waits *[]time.Duration) *retryTransport {
+	rt := newRetryTransport(http.DefaultTransport, maxRetries)
+	rt.now = func() time.Time { return time.Unix(1000, 0) }
+	rt.rand = func() float64 { return 0.5 }
+	rt.sleep = func(_ *http.Request, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+	return rt
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		header   http.Header
+		statuses []int
+		status   int
+		waits    []time.Duration
+	}{
+		{
+			name:     "retries server errors with backoff",
+			method:   http.MethodGet,
+			statuses: []int{502, 503, 500},
+			status:   200,
+			waits:    []time.Duration{375 * time.Millisecond, 750 * time.Millisecond, 1500 * time.Millisecond},
+		},
+		{
+			name:     "gives up after the maximum retries",
+			method:   http.MethodGet,
+			statuses: []int{502, 502, 502, 502},
+			status:   502,
+			waits:    []time.Duration{375 * time.Millisecond, 750 * time.Millisecond, 1500 * time.Millisecond},
+		},
+		{
+			name:     "does not retry server errors for non-idempotent methods",
+			method:   http.MethodPost,
+			statuses: []int{502},
+			status:   502,
+		},
+		{
+			name:     "retries rate limited non-idempotent requests",
+			method:   http.MethodPost,
+			header:   http.Header{"Retry-After": {"2"}},
+			statuses: []int{429},
+			status:   200,
+			waits:    []time.Duration{2 * time.Second},
+		},
+		{
+			name:     "honours RateLimit-Reset",
+			method:   http.MethodGet,
+			header:   http.Header{"Ratelimit-Reset": {"1007"}},
+			statuses: []int{429},
+			status:   200,
+			waits:    []time.Duration{7 * time.Second},
+		},
+		{
+			name:     "honours an HTTP date in Retry-After",
+			method:   http.MethodGet,
+			header:   http.Header{"Retry-After": {time.Unix(1003, 0).UTC().Format(http.TimeFormat)}},
+			statuses: []int{503},
+			status:   200,
+			waits:    []time.Duration{3 * time.Second},
+		},
+		{
+			name:     "does not wait longer than a minute",
+			method:   http.MethodGet,
+			header:   http.Header{"Retry-After": {"3600"}},
+			statuses: []int{429},
+			status:   429,
+		},
+		{
+			name:     "does not retry client errors",
+			method:   http.MethodGet,
+			statuses: []int{404},
+			status:   404,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server, bodies := testRetryServer(tt.header, tt.statuses...)
+			defer server.Close()
+
+			var waits []time.Duration
+			client := &http.Client{Transport: testRetryTransport(3, &waits)}
+
+			req, err := http.NewRequest(tt.method, server.URL, bytes.NewBufferString(`{"name":"web"}`))
+			require.NoError(t, err)
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.waits, waits)
+			assert.Len(t, *bodies, len(tt.waits)+1)
+			for _, b := range *bodies {
+				assert.Equal(t, `{"name":"web"}`, b)
+			}
+		})
+	}
+}
+
+func TestRetryTransportCanceled(t *testing.T) {
+	server, _ := testRetryServer(http.Header{"Retry-After": {"30"}}, 429)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, 3)}
+	start := time.Now()
+	_, err = client.Do(req.WithContext(ctx))
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < 10*time.Second, "the wait for Retry-After was not interrupted")
+}
+
+func TestRetryTransportKeepsRequest(t *testing.T) {
+	server, bodies := testRetryServer(nil, 503, 503)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPut, server.URL, bytes.NewBufferString(`{"name":"web"}`))
+	require.NoError(t, err)
+	body := req.Body
+
+	var waits []time.Duration
+	resp, err := testRetryTransport(3, &waits).RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, *bodies, 3)
+	assert.True(t, req.Body == body, "the caller's request body was replaced")
+}
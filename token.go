@@ -0,0 +1,45 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blcli
+
+import (
+	"context"
+	"net/http"
+)
+
+type accessTokenKey struct{}
+
+// WithAccessToken returns a context whose API requests are made with the
+// access token instead of the client's own, so that one client can check
+// the tokens of several auth contexts.
+func WithAccessToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, accessTokenKey{}, token)
+}
+
+// accessTokenTransport replaces the Authorization header of requests whose
+// context has an access token.
+type accessTokenTransport struct {
+	wrap http.RoundTripper
+}
+
+func (at *accessTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, ok := req.Context().Value(accessTokenKey{}).(string)
+	if !ok {
+		return at.wrap.RoundTrip(req)
+	}
+
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+token)
+	return at.wrap.RoundTrip(r)
+}